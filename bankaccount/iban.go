@@ -0,0 +1,117 @@
+// Package bankaccount validates the bank accounts of the beneficiaries of
+// forfaiting payouts and screens their names against sanctions lists.
+package bankaccount
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrIBANCountry  = errors.New("unknown IBAN country")
+	ErrIBANLength   = errors.New("wrong IBAN length for the country")
+	ErrIBANChars    = errors.New("IBAN may contain only letters and digits")
+	ErrIBANChecksum = errors.New("wrong IBAN check digits")
+	ErrBICFormat    = errors.New("SWIFT (BIC) must be 8 or 11 letters and digits: bank, country, location and optional branch")
+	ErrBICCountry   = errors.New("country of SWIFT (BIC) differs from the one of IBAN")
+)
+
+// ibanLengths are the lengths of the IBANs per country as published in the
+// IBAN registry of SWIFT.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16,
+	"BG": 22, "BH": 22, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28,
+	"CZ": 24, "DE": 22, "DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24,
+	"FI": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18,
+	"GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23,
+	"IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32,
+	"LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MD": 24, "ME": 22,
+	"MK": 19, "MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15, "PK": 24,
+	"PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "SA": 24,
+	"SC": 31, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+// Normalize removes the spaces from the IBAN or BIC and upper cases it.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// ValidateIBAN checks the country, the length and the check digits of the
+// IBAN. Spaces and lower case letters are accepted.
+func ValidateIBAN(iban string) error {
+	iban = Normalize(iban)
+	if len(iban) < 4 {
+		return ErrIBANLength
+	}
+	for _, r := range iban {
+		if !isAlnum(r) {
+			return ErrIBANChars
+		}
+	}
+
+	n, ok := ibanLengths[iban[:2]]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrIBANCountry, iban[:2])
+	}
+	if len(iban) != n {
+		return fmt.Errorf("%w: %d characters instead of %d for %s", ErrIBANLength, len(iban), n, iban[:2])
+	}
+
+	// ISO 7064 MOD 97-10 over the BBAN followed by the country code and the
+	// check digits, with the letters as numbers from 10 to 35
+	var rem int
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		default:
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		}
+	}
+	if rem != 1 {
+		return ErrIBANChecksum
+	}
+	return nil
+}
+
+// ValidateBIC checks the format of the SWIFT code (BIC).
+func ValidateBIC(bic string) error {
+	bic = Normalize(bic)
+	if len(bic) != 8 && len(bic) != 11 {
+		return ErrBICFormat
+	}
+	for i, r := range bic {
+		switch {
+		case i < 6 && !(r >= 'A' && r <= 'Z'):
+			return ErrBICFormat
+		case !isAlnum(r):
+			return ErrBICFormat
+		}
+	}
+	return nil
+}
+
+// Validate checks the IBAN and the SWIFT code of an account and whether they
+// belong to the same country. The SWIFT code is optional.
+func Validate(iban, bic string) []error {
+	var errs []error
+	if err := ValidateIBAN(iban); err != nil {
+		errs = append(errs, err)
+	}
+	if Normalize(bic) == "" {
+		return errs
+	}
+	if err := ValidateBIC(bic); err != nil {
+		return append(errs, err)
+	}
+	if len(errs) == 0 && Normalize(bic)[4:6] != Normalize(iban)[:2] {
+		errs = append(errs, ErrBICCountry)
+	}
+	return errs
+}
+
+func isAlnum(r rune) bool {
+	return r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
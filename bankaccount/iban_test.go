@@ -0,0 +1,65 @@
+package bankaccount
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateIBAN(t *testing.T) {
+	cases := []struct {
+		iban string
+		err  error
+	}{
+		{"LV80BANK0000435195001", nil},
+		{"lv80 bank 0000 4351 9500 1", nil},
+		{"DE89 3704 0044 0532 0130 00", nil},
+		{"GB82WEST12345698765432", nil},
+		{"BG80BNBG96611020345678", nil},
+		{"LV80BANK0000435195002", ErrIBANChecksum},
+		{"LV80BANK000043519500", ErrIBANLength},
+		{"XX80BANK0000435195001", ErrIBANCountry},
+		{"LV80-BANK-0000-4351-9500-1", ErrIBANChars},
+		{"111111111", ErrIBANCountry},
+		{"", ErrIBANLength},
+	}
+	for _, c := range cases {
+		if err := ValidateIBAN(c.iban); !errors.Is(err, c.err) {
+			t.Errorf("%q: expected error %v; got %v", c.iban, c.err, err)
+		}
+	}
+}
+
+func TestValidateBIC(t *testing.T) {
+	cases := []struct {
+		bic string
+		err error
+	}{
+		{"HABALV22", nil},
+		{"habalv22xxx", nil},
+		{"DEUTDEFF500", nil},
+		{"HABALV2", ErrBICFormat},
+		{"HAB1LV22", ErrBICFormat},
+		{"HABALV22XX", ErrBICFormat},
+		{"HABALV22-XX", ErrBICFormat},
+	}
+	for _, c := range cases {
+		if err := ValidateBIC(c.bic); !errors.Is(err, c.err) {
+			t.Errorf("%q: expected error %v; got %v", c.bic, c.err, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if errs := Validate("LV80BANK0000435195001", ""); len(errs) != 0 {
+		t.Errorf("expected no errors without SWIFT; got %v", errs)
+	}
+	if errs := Validate("LV80BANK0000435195001", "HABALV22"); len(errs) != 0 {
+		t.Errorf("expected no errors; got %v", errs)
+	}
+	if errs := Validate("LV80BANK0000435195001", "DEUTDEFF"); len(errs) != 1 || errs[0] != ErrBICCountry {
+		t.Errorf("expected %v; got %v", ErrBICCountry, errs)
+	}
+	if errs := Validate("LV80BANK0000435195002", "HABA"); len(errs) != 2 {
+		t.Errorf("expected IBAN and SWIFT errors; got %v", errs)
+	}
+}
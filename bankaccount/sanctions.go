@@ -0,0 +1,223 @@
+package bankaccount
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Screener checks names against a sanctions list.
+type Screener interface {
+	// Screen returns the entries of the list the name matches.
+	Screen(name string) []Match
+}
+
+// Match is an entry of a sanctions list matched by a name.
+type Match struct {
+	// Name is the matching name, or alias, of the sanctioned entity.
+	Name string
+
+	// Reference identifies the entity in the list, Programme is the
+	// sanctions regime it falls under, if known.
+	Reference string
+	Programme string
+}
+
+func (m Match) String() string {
+	var details []string
+	for _, s := range []string{m.Reference, m.Programme} {
+		if s != "" {
+			details = append(details, s)
+		}
+	}
+	if len(details) == 0 {
+		return m.Name
+	}
+	return fmt.Sprintf("%s (%s)", m.Name, strings.Join(details, ", "))
+}
+
+// List is a sanctions list loaded in memory.
+type List struct {
+	entries []listEntry
+}
+
+type listEntry struct {
+	Match
+	tokens []string
+}
+
+// Len returns the count of the names in the list.
+func (l *List) Len() int {
+	return len(l.entries)
+}
+
+func (l *List) add(m Match) {
+	if tokens := nameTokens(m.Name); len(tokens) > 0 {
+		l.entries = append(l.entries, listEntry{Match: m, tokens: tokens})
+	}
+}
+
+// Screen returns the entries of the list matching the name. A name matches
+// an entry if it has the same words in any order, or if it contains all the
+// words of an entry of at least two words. Case, diacritics, punctuation and
+// legal forms, e.g. Ltd or SIA, are ignored.
+func (l *List) Screen(name string) []Match {
+	tokens := nameTokens(name)
+	if len(tokens) == 0 {
+		return nil
+	}
+	words := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		words[t] = true
+	}
+
+	var (
+		result []Match
+		seen   = make(map[Match]bool)
+	)
+	for _, e := range l.entries {
+		if seen[e.Match] || !matches(e.tokens, tokens, words) {
+			continue
+		}
+		seen[e.Match] = true
+		result = append(result, e.Match)
+	}
+	return result
+}
+
+func matches(entry, name []string, words map[string]bool) bool {
+	if len(entry) < 2 && len(entry) != len(name) {
+		return false
+	}
+	for _, t := range entry {
+		if !words[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// Load reads a sanctions list from the file. XML files are read as the EU
+// consolidated financial sanctions list, anything else as text with a name
+// per line.
+func Load(path string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		return ParseEU(f)
+	}
+	return ParseText(f)
+}
+
+type euExport struct {
+	Entities []struct {
+		Reference   string `xml:"euReferenceNumber,attr"`
+		LogicalID   string `xml:"logicalId,attr"`
+		Regulations []struct {
+			Programme string `xml:"programme,attr"`
+		} `xml:"regulation"`
+		Aliases []struct {
+			WholeName string `xml:"wholeName,attr"`
+		} `xml:"nameAlias"`
+	} `xml:"sanctionEntity"`
+}
+
+// ParseEU reads the EU consolidated list of persons, groups and entities
+// subject to financial sanctions in its XML format.
+func ParseEU(r io.Reader) (*List, error) {
+	var export euExport
+	if err := xml.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("sanctions list: %w", err)
+	}
+
+	var l List
+	for _, e := range export.Entities {
+		ref := e.Reference
+		if ref == "" {
+			ref = e.LogicalID
+		}
+		var programme string
+		if len(e.Regulations) > 0 {
+			programme = e.Regulations[0].Programme
+		}
+		for _, a := range e.Aliases {
+			l.add(Match{Name: strings.TrimSpace(a.WholeName), Reference: ref, Programme: programme})
+		}
+	}
+	return &l, nil
+}
+
+// ParseText reads a sanctions list with a name per line. Empty lines and
+// lines starting with # are skipped.
+func ParseText(r io.Reader) (*List, error) {
+	var (
+		l List
+		s = bufio.NewScanner(r)
+	)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		l.add(Match{Name: line})
+	}
+	return &l, s.Err()
+}
+
+// legalForms are left out of the names when matching.
+var legalForms = map[string]bool{
+	"ag": true, "as": true, "bv": true, "co": true, "corp": true,
+	"gmbh": true, "inc": true, "jsc": true, "llc": true, "ltd": true,
+	"nv": true, "oao": true, "ojsc": true, "ooo": true, "oy": true,
+	"pjsc": true, "plc": true, "sa": true, "sia": true, "spa": true,
+	"srl": true, "uab": true, "zao": true,
+}
+
+// folds maps the letters with diacritics common in European names to their
+// base letters.
+var folds = strings.NewReplacer(
+	"ā", "a", "á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a", "ą", "a", "ă", "a",
+	"č", "c", "ć", "c", "ç", "c",
+	"ď", "d", "đ", "d",
+	"ē", "e", "é", "e", "è", "e", "ê", "e", "ë", "e", "ė", "e", "ę", "e", "ě", "e",
+	"ģ", "g", "ğ", "g",
+	"ī", "i", "í", "i", "ì", "i", "î", "i", "ï", "i", "į", "i", "ı", "i",
+	"ķ", "k",
+	"ļ", "l", "ł", "l", "ľ", "l",
+	"ņ", "n", "ń", "n", "ñ", "n", "ň", "n",
+	"ō", "o", "ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ő", "o", "ø", "o",
+	"ř", "r",
+	"š", "s", "ś", "s", "ş", "s", "ș", "s", "ß", "ss",
+	"ť", "t", "ţ", "t", "ț", "t",
+	"ū", "u", "ú", "u", "ù", "u", "û", "u", "ü", "u", "ų", "u", "ů", "u", "ű", "u",
+	"ý", "y", "ÿ", "y",
+	"ž", "z", "ź", "z", "ż", "z",
+)
+
+// nameTokens returns the sorted words of the name without diacritics,
+// punctuation and legal forms.
+func nameTokens(name string) []string {
+	name = folds.Replace(strings.ToLower(name))
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens []string
+	for _, w := range words {
+		if !legalForms[w] {
+			tokens = append(tokens, w)
+		}
+	}
+	sort.Strings(tokens)
+	return tokens
+}
@@ -0,0 +1,68 @@
+package bankaccount
+
+import (
+	"strings"
+	"testing"
+)
+
+const euList = `<?xml version="1.0" encoding="UTF-8"?>
+<export xmlns="http://eu.europa.ec/fpi/fsd/export" generationDate="2020-10-19T10:00:00.000+02:00">
+  <sanctionEntity euReferenceNumber="EU.27.28" logicalId="13">
+    <regulation programme="IRQ"/>
+    <subjectType code="person"/>
+    <nameAlias firstName="Saddam" lastName="Hussein Al-Tikriti" wholeName="Saddam Hussein Al-Tikriti"/>
+    <nameAlias wholeName="Abu Ali"/>
+  </sanctionEntity>
+  <sanctionEntity euReferenceNumber="EU.3904.67" logicalId="120">
+    <regulation programme="UKR"/>
+    <subjectType code="enterprise"/>
+    <nameAlias wholeName="Šķēršļu Būve SIA"/>
+    <nameAlias wholeName="Kerch"/>
+  </sanctionEntity>
+</export>`
+
+func TestScreen(t *testing.T) {
+	list, err := ParseEU(strings.NewReader(euList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Len() != 4 {
+		t.Fatalf("expected 4 names; got %d", list.Len())
+	}
+
+	cases := []struct {
+		name  string
+		match string
+	}{
+		{"Saddam Hussein Al-Tikriti", "Saddam Hussein Al-Tikriti (EU.27.28, IRQ)"},
+		{"AL TIKRITI, Saddam Hussein", "Saddam Hussein Al-Tikriti (EU.27.28, IRQ)"},
+		{"Abu Ali Trading", "Abu Ali (EU.27.28, IRQ)"},
+		{"SKERSLU BUVE Ltd.", "Šķēršļu Būve SIA (EU.3904.67, UKR)"},
+		{"Kerch", "Kerch (EU.3904.67, UKR)"},
+		{"Kerch Renovation", ""},
+		{"Ali", ""},
+		{"Renesco SIA", ""},
+	}
+	for _, c := range cases {
+		matches := list.Screen(c.name)
+		switch {
+		case c.match == "" && len(matches) > 0:
+			t.Errorf("%q: expected no match; got %v", c.name, matches)
+		case c.match != "" && (len(matches) != 1 || matches[0].String() != c.match):
+			t.Errorf("%q: expected %s; got %v", c.name, c.match, matches)
+		}
+	}
+}
+
+func TestParseText(t *testing.T) {
+	list, err := ParseText(strings.NewReader("# local list\n\nJohn Doe\n  Acme Holdings Ltd  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Len() != 2 {
+		t.Fatalf("expected 2 names; got %d", list.Len())
+	}
+	if m := list.Screen("ACME HOLDINGS"); len(m) != 1 || m[0].String() != "Acme Holdings Ltd" {
+		t.Errorf("expected Acme Holdings Ltd; got %v", m)
+	}
+}
@@ -0,0 +1,369 @@
+// Package bankstatement parses bank account statements, either ISO 20022
+// CAMT.053 XML files or CSV exports, into their entries.
+package bankstatement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/bankaccount"
+)
+
+const dateLayout = "2006-01-02"
+
+var dateLayouts = []string{dateLayout, "02.01.2006", "02/01/2006", "2006.01.02", time.RFC3339, "2006-01-02T15:04:05"}
+
+var (
+	ErrNoHeader = errors.New("bankstatement: missing date, amount or iban column")
+	ErrNoData   = errors.New("bankstatement: no entries")
+)
+
+// Entry is a single transfer booked on the account.
+type Entry struct {
+	// Ref is the reference of the entry given by the bank, if any.
+	Ref string
+
+	Date     time.Time
+	Amount   float64
+	Currency string
+
+	// Debit tells whether the transfer was made from the account, e.g. a
+	// payout, as opposed to received.
+	Debit bool
+
+	// IBAN and Name identify the other party of the transfer.
+	IBAN string
+	Name string
+
+	// Reference is the remittance information of the transfer.
+	Reference string
+
+	// seq counts the earlier entries of the statement without a bank
+	// reference and with the same contents.
+	seq int
+}
+
+// ID returns the reference of the entry given by the bank or, if there is
+// none, a hash of its contents and of the number of identical entries before
+// it in the statement. It tells apart entries imported twice, as long as the
+// statements imported include all the entries of the days they cover.
+// Identical transfers split across statements that cut a day short are taken
+// for the same.
+func (e Entry) ID() string {
+	if e.Ref != "" {
+		return e.Ref
+	}
+	s := fmt.Sprintf("%s|%.2f|%s|%t|%s|%s",
+		e.Date.Format(dateLayout), e.Amount, e.Currency, e.Debit, e.IBAN, e.Reference)
+	if e.seq > 0 {
+		s += fmt.Sprintf("|%d", e.seq)
+	}
+	h := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+// numberTwins sets the sequence of the entries without a bank reference, so
+// that identical transfers of the statement get different IDs.
+func numberTwins(entries []Entry) {
+	seen := make(map[string]int)
+	for i := range entries {
+		if entries[i].Ref != "" {
+			continue
+		}
+		id := entries[i].ID()
+		entries[i].seq = seen[id]
+		seen[id]++
+	}
+}
+
+// Cents returns the amount in the minor units of the currency.
+func (e Entry) Cents() int64 {
+	return int64(math.Round(e.Amount * 100))
+}
+
+// Parse reads the entries of the statement out of r. XML files are parsed as
+// CAMT.053, anything else as CSV.
+func Parse(name string, r io.Reader) ([]Entry, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(path.Ext(name), ".xml") || bytes.HasPrefix(bytes.TrimSpace(raw), []byte("<")) {
+		return ParseCAMT053(bytes.NewReader(raw))
+	}
+	return ParseCSV(bytes.NewReader(raw))
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtParty struct {
+	Name string `xml:"Nm"`
+}
+
+type camtAccount struct {
+	IBAN string `xml:"Id>IBAN"`
+}
+
+type camtTransaction struct {
+	AccountServicerRef string      `xml:"Refs>AcctSvcrRef"`
+	EndToEndID         string      `xml:"Refs>EndToEndId"`
+	Amount             camtAmount  `xml:"Amt"`
+	TxAmount           camtAmount  `xml:"AmtDtls>TxAmt>Amt"`
+	Debtor             camtParty   `xml:"RltdPties>Dbtr"`
+	DebtorAccount      camtAccount `xml:"RltdPties>DbtrAcct"`
+	Creditor           camtParty   `xml:"RltdPties>Cdtr"`
+	CreditorAccount    camtAccount `xml:"RltdPties>CdtrAcct"`
+	Unstructured       []string    `xml:"RmtInf>Ustrd"`
+	Structured         []string    `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+}
+
+type camtEntry struct {
+	Ref                string            `xml:"NtryRef"`
+	AccountServicerRef string            `xml:"AcctSvcrRef"`
+	Amount             camtAmount        `xml:"Amt"`
+	CreditDebit        string            `xml:"CdtDbtInd"`
+	BookingDate        camtDate          `xml:"BookgDt"`
+	ValueDate          camtDate          `xml:"ValDt"`
+	Transactions       []camtTransaction `xml:"NtryDtls>TxDtls"`
+}
+
+type camtDocument struct {
+	Entries []camtEntry `xml:"BkToCstmrStmt>Stmt>Ntry"`
+}
+
+// ParseCAMT053 reads the entries of a CAMT.053 bank to customer statement.
+// Batch entries with several transactions give an entry per transaction.
+func ParseCAMT053(r io.Reader) ([]Entry, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("bankstatement: %w", err)
+	}
+
+	var result []Entry
+	for i, n := range doc.Entries {
+		date, err := n.BookingDate.parse()
+		if err != nil {
+			if date, err = n.ValueDate.parse(); err != nil {
+				return nil, fmt.Errorf("bankstatement: entry %d: %w", i+1, err)
+			}
+		}
+
+		e := Entry{
+			Ref:      n.AccountServicerRef,
+			Date:     date,
+			Currency: n.Amount.Currency,
+			Debit:    n.CreditDebit == "DBIT",
+		}
+		if e.Ref == "" {
+			e.Ref = n.Ref
+		}
+		if e.Amount, err = parseAmount(n.Amount.Value); err != nil {
+			return nil, fmt.Errorf("bankstatement: entry %d: %w", i+1, err)
+		}
+
+		if len(n.Transactions) == 0 {
+			result = append(result, e)
+			continue
+		}
+		for j, tx := range n.Transactions {
+			te := e
+			te.fill(tx)
+			if len(n.Transactions) > 1 {
+				amt := tx.TxAmount
+				if amt.Value == "" {
+					amt = tx.Amount
+				}
+				if te.Amount, err = parseAmount(amt.Value); err != nil {
+					return nil, fmt.Errorf("bankstatement: entry %d, transaction %d: %w", i+1, j+1, err)
+				}
+				if amt.Currency != "" {
+					te.Currency = amt.Currency
+				}
+				switch {
+				case tx.AccountServicerRef != "":
+					te.Ref = tx.AccountServicerRef
+				case e.Ref != "":
+					te.Ref = fmt.Sprintf("%s/%d", e.Ref, j+1)
+				}
+			}
+			result = append(result, te)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, ErrNoData
+	}
+	numberTwins(result)
+	return result, nil
+}
+
+// fill sets the other party and the remittance information of the entry out
+// of the transaction.
+func (e *Entry) fill(tx camtTransaction) {
+	if e.Debit {
+		e.IBAN, e.Name = tx.CreditorAccount.IBAN, tx.Creditor.Name
+	} else {
+		e.IBAN, e.Name = tx.DebtorAccount.IBAN, tx.Debtor.Name
+	}
+	e.IBAN = bankaccount.Normalize(e.IBAN)
+	e.Reference = strings.TrimSpace(strings.Join(append(tx.Structured, tx.Unstructured...), " "))
+	if e.Reference == "" {
+		e.Reference = tx.EndToEndID
+	}
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	if d.Date != "" {
+		return parseDate(d.Date)
+	}
+	if d.DateTime != "" {
+		return parseDate(d.DateTime)
+	}
+	return time.Time{}, errors.New("no date")
+}
+
+// ParseCSV reads the entries of a CSV statement out of r. The first record
+// must be a header with `date`, `amount` and `iban` columns. Optional columns
+// are `currency`, `name`, `reference`, `id` and `direction` (D or C). Without
+// direction negative amounts are debits. Both comma and semicolon separated
+// files are accepted, the latter with decimal comma.
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(bytes.NewReader(raw))
+	if h := firstLine(raw); strings.Count(h, ";") > strings.Count(h, ",") {
+		cr.Comma = ';'
+	}
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			err = ErrNoHeader
+		}
+		return nil, err
+	}
+
+	cols := map[string]int{}
+	for i, h := range header {
+		switch h = strings.ToLower(strings.TrimSpace(h)); h {
+		case "date", "amount", "currency", "iban", "name", "reference", "id", "direction":
+			cols[h] = i
+		}
+	}
+	for _, c := range []string{"date", "amount", "iban"} {
+		if _, ok := cols[c]; !ok {
+			return nil, ErrNoHeader
+		}
+	}
+
+	var result []Entry
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if field("amount") == "" {
+			continue
+		}
+
+		var e Entry
+		if e.Date, err = parseDate(field("date")); err != nil {
+			return nil, fmt.Errorf("bankstatement: line %d: %w", line, err)
+		}
+		if e.Amount, err = parseAmount(field("amount")); err != nil {
+			return nil, fmt.Errorf("bankstatement: line %d: %w", line, err)
+		}
+		switch strings.ToUpper(field("direction")) {
+		case "D", "DBIT", "DEBIT":
+			e.Debit = true
+		case "C", "CRDT", "CREDIT":
+		case "":
+			e.Debit = e.Amount < 0
+		default:
+			return nil, fmt.Errorf("bankstatement: line %d: bad direction %q", line, field("direction"))
+		}
+		e.Amount = math.Abs(e.Amount)
+		e.Ref = field("id")
+		e.Currency = strings.ToUpper(field("currency"))
+		e.IBAN = bankaccount.Normalize(field("iban"))
+		e.Name = field("name")
+		e.Reference = field("reference")
+		result = append(result, e)
+	}
+
+	if len(result) == 0 {
+		return nil, ErrNoData
+	}
+	numberTwins(result)
+	return result, nil
+}
+
+func firstLine(b []byte) string {
+	s := string(b)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad date %q", s)
+}
+
+// parseAmount parses amounts with either decimal point or comma, with or
+// without spaces between the thousands.
+func parseAmount(s string) (float64, error) {
+	s = strings.Join(strings.Fields(s), "")
+	if strings.Contains(s, ",") {
+		if strings.Contains(s, ".") {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	}
+	a, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad amount %q", s)
+	}
+	return a, nil
+}
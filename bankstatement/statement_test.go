@@ -0,0 +1,198 @@
+package bankstatement
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const camt053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Id>STMT-1</Id>
+      <Ntry>
+        <NtryRef>N1</NtryRef>
+        <Amt Ccy="EUR">12500.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><Dt>2020-10-19</Dt></BookgDt>
+        <AcctSvcrRef>BANK-001</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <RltdPties>
+              <Cdtr><Nm>Renesco SIA</Nm></Cdtr>
+              <CdtrAcct><Id><IBAN>LV80 BANK 0000 4351 9500 1</IBAN></Id></CdtrAcct>
+            </RltdPties>
+            <RmtInf><Ustrd>FA1234ABCD-01</Ustrd></RmtInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">300.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <ValDt><DtTm>2020-10-20T09:30:00</DtTm></ValDt>
+        <AcctSvcrRef>BANK-002</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><AcctSvcrRef>BANK-002-A</AcctSvcrRef></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">100.00</Amt></TxAmt></AmtDtls>
+            <RltdPties><DbtrAcct><Id><IBAN>LV10BANK0000000000001</IBAN></Id></DbtrAcct></RltdPties>
+          </TxDtls>
+          <TxDtls>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">200.00</Amt></TxAmt></AmtDtls>
+            <RltdPties><DbtrAcct><Id><IBAN>LV10BANK0000000000002</IBAN></Id></DbtrAcct></RltdPties>
+            <RmtInf><Strd><CdtrRefInf><Ref>RF18539007547034</Ref></CdtrRefInf></Strd></RmtInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+func TestParseCAMT053(t *testing.T) {
+	entries, err := Parse("statement.xml", strings.NewReader(camt053))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries; got %d", len(entries))
+	}
+
+	want := []Entry{
+		{
+			Ref:       "BANK-001",
+			Date:      time.Date(2020, time.October, 19, 0, 0, 0, 0, time.UTC),
+			Amount:    12500,
+			Currency:  "EUR",
+			Debit:     true,
+			IBAN:      "LV80BANK0000435195001",
+			Name:      "Renesco SIA",
+			Reference: "FA1234ABCD-01",
+		},
+		{
+			Ref:      "BANK-002-A",
+			Date:     time.Date(2020, time.October, 20, 9, 30, 0, 0, time.UTC),
+			Amount:   100,
+			Currency: "EUR",
+			IBAN:     "LV10BANK0000000000001",
+		},
+		{
+			Ref:       "BANK-002/2",
+			Date:      time.Date(2020, time.October, 20, 9, 30, 0, 0, time.UTC),
+			Amount:    200,
+			Currency:  "EUR",
+			IBAN:      "LV10BANK0000000000002",
+			Reference: "RF18539007547034",
+		},
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d: expected %+v; got %+v", i, want[i], entries[i])
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		count int
+		first Entry
+		err   error
+	}{
+		{
+			name:  "comma",
+			input: "date,amount,currency,iban,reference\n2020-10-19,-12500.00,eur,LV80 BANK 0000 4351 9500 1,FA1234ABCD-01\n2020-10-20,300,EUR,LV10BANK0000000000001,\n",
+			count: 2,
+			first: Entry{
+				Date:      time.Date(2020, time.October, 19, 0, 0, 0, 0, time.UTC),
+				Amount:    12500,
+				Currency:  "EUR",
+				Debit:     true,
+				IBAN:      "LV80BANK0000435195001",
+				Reference: "FA1234ABCD-01",
+			},
+		},
+		{
+			name:  "semicolon with decimal comma and direction",
+			input: "Id;Date;Amount;Direction;IBAN;Name\nX1;19.10.2020;12 500,50;D;LV80BANK0000435195001;Renesco\n",
+			count: 1,
+			first: Entry{
+				Ref:    "X1",
+				Date:   time.Date(2020, time.October, 19, 0, 0, 0, 0, time.UTC),
+				Amount: 12500.5,
+				Debit:  true,
+				IBAN:   "LV80BANK0000435195001",
+				Name:   "Renesco",
+			},
+		},
+		{
+			name:  "no header",
+			input: "foo,bar\n2020-01-01,2\n",
+			err:   ErrNoHeader,
+		},
+		{
+			name:  "no data",
+			input: "date,amount,iban\n",
+			err:   ErrNoData,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entries, err := Parse("statement.csv", strings.NewReader(c.input))
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected error %v; got %v", c.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(entries) != c.count {
+				t.Fatalf("expected %d entries; got %d", c.count, len(entries))
+			}
+			if entries[0] != c.first {
+				t.Errorf("expected first entry %+v; got %+v", c.first, entries[0])
+			}
+		})
+	}
+}
+
+func TestEntryID(t *testing.T) {
+	e := Entry{Date: time.Date(2020, time.October, 19, 0, 0, 0, 0, time.UTC), Amount: 10, IBAN: "LV80BANK0000435195001"}
+	if id := e.ID(); id != e.ID() || !strings.HasPrefix(id, "sha256:") {
+		t.Errorf("expected a stable hash; got %s", id)
+	}
+	other := e
+	other.Amount = 11
+	if other.ID() == e.ID() {
+		t.Error("expected different entries to have different IDs")
+	}
+	e.Ref = "BANK-001"
+	if e.ID() != "BANK-001" {
+		t.Errorf("expected the bank reference; got %s", e.ID())
+	}
+}
+
+func TestEntryIDTwins(t *testing.T) {
+	input := "date,amount,iban\n2020-10-19,100,LV80BANK0000435195001\n2020-10-19,100,LV80BANK0000435195001\n"
+	ids := func() []string {
+		entries, err := ParseCSV(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+		return ids
+	}
+
+	first := ids()
+	if len(first) != 2 || first[0] == first[1] {
+		t.Fatalf("expected identical transfers to have different IDs; got %v", first)
+	}
+	if again := ids(); again[0] != first[0] || again[1] != first[1] {
+		t.Errorf("expected the same IDs when imported again; got %v and %v", first, again)
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/services"
+)
+
+// purge permanently deletes the records which have been in the recycle bin
+// for longer than the configured retention period once a day. Users inactive
+// for longer than the data retention period are anonymized as well.
+func purge(env *services.Env) {
+	rb := controller.NewRecycleBin(env)
+	gdpr := controller.NewGDPR(env)
+	for {
+		n, err := rb.Purge(time.Now().Add(-env.RecycleBin.Retention()))
+		if err != nil {
+			log.Println("purge:", err)
+		} else if n > 0 {
+			log.Printf("purge: %d records purged", n)
+		}
+
+		if years := env.Retention.InactiveUserYears; years > 0 {
+			n, err := gdpr.AnonymizeInactive(time.Now().AddDate(-years, 0, 0))
+			if err != nil {
+				log.Println("retention:", err)
+			} else if n > 0 {
+				log.Printf("retention: %d inactive users anonymized", n)
+			}
+		}
+
+		time.Sleep(24 * time.Hour)
+	}
+}
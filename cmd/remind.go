@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/services"
+)
+
+// remind emails the hosts of the meetings whose next contact is due soon,
+// notifies the fund managers of overdue forfaiting tranches and generates the
+// scheduled reports due once an hour.
+func remind(env *services.Env) {
+	m := controller.NewMeeting(env)
+	fa := controller.NewForfaitingAgreement(env)
+	rep := controller.NewReport(env)
+	for {
+		n, err := m.RemindNextContact(time.Now())
+		if err != nil {
+			log.Println("remind:", err)
+		} else if n > 0 {
+			log.Printf("remind: %d meetings reminded of", n)
+		}
+
+		n, err = fa.NotifyOverdue(time.Now())
+		if err != nil {
+			log.Println("remind:", err)
+		} else if n > 0 {
+			log.Printf("remind: %d overdue tranches notified of", n)
+		}
+
+		n, err = rep.RunDue(time.Now())
+		if err != nil {
+			log.Println("remind:", err)
+		} else if n > 0 {
+			log.Printf("remind: %d scheduled reports generated", n)
+		}
+
+		time.Sleep(time.Hour)
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/services"
+)
+
+// refreshReporting refreshes the reporting views marked stale by changes to
+// the records they are computed from once a minute.
+func refreshReporting(env *services.Env) {
+	r := controller.NewReporting(env)
+	for {
+		n, err := r.RefreshStale(time.Now())
+		if err != nil {
+			log.Println("reporting:", err)
+		} else if n > 0 {
+			log.Printf("reporting: %d views refreshed", n)
+		}
+
+		time.Sleep(time.Minute)
+	}
+}
@@ -63,6 +63,9 @@ func serve(_ *cobra.Command, _ []string) {
 	}()
 
 	go watchdog(url + "/debug/ping")
+	go purge(env)
+	go remind(env)
+	go refreshReporting(env)
 
 	log.Printf("Listening on %s", url)
 	if err := s.Serve(l); err != nethttp.ErrServerClosed {
@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/google/uuid"
@@ -28,6 +29,32 @@ type Paths struct {
 	Migrations string `toml:"migrations"`
 	LaTeX      string `toml:"latex"`
 	Uploads    string `toml:"uploads"`
+
+	// Sanctions is the sanctions list file the beneficiaries of the bank
+	// accounts are screened against; no screening if not set.
+	Sanctions string `toml:"sanctions"`
+}
+
+// RecycleBin configures how long soft deleted records are kept before they
+// are purged.
+type RecycleBin struct {
+	RetentionDays int `toml:"retention_days"`
+}
+
+// Retention returns the retention period, 30 days if not configured.
+func (rb RecycleBin) Retention() time.Duration {
+	days := rb.RetentionDays
+	if days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Retention configures how long personal data is kept.
+type Retention struct {
+	// InactiveUserYears after which users who haven't logged in are
+	// anonymized; never if not set.
+	InactiveUserYears int `toml:"inactive_user_years"`
 }
 
 type DB struct {
@@ -62,6 +89,9 @@ type Config struct {
 	DB      DB      `toml:"psql"`
 	Session Session `toml:"session"`
 	Mail    Mail    `toml:"mail"`
+
+	RecycleBin RecycleBin `toml:"recycle_bin"`
+	Retention  Retention  `toml:"retention"`
 }
 
 // Dependency stores an ID and Kind of an entity.
@@ -103,6 +133,10 @@ func Load() Config {
 		log.Fatalf("upload path(%s): %v", cfg.Paths.LaTeX, err)
 	}
 
+	if cfg.Paths.Sanctions != "" && !filepath.IsAbs(cfg.Paths.Sanctions) {
+		cfg.Paths.Sanctions = filepath.Join(GetPath(), cfg.Paths.Sanctions)
+	}
+
 	return cfg
 }
 
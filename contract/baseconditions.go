@@ -0,0 +1,74 @@
+package contract
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// baseconditions holds the names of the baseconditions tables, indexed by
+// how many years the base year is before the year n. baseyears holds the
+// base year tables the same way.
+var (
+	baseconditions = [...]string{"baseconditions_n", "baseconditions_n_1", "baseconditions_n_2"}
+	baseyears      = [...]string{"baseyear_n", "baseyear_n_1", "baseyear_n_2"}
+)
+
+// SetHeatingPeriod sets the heating days D_Apk and their average outdoor
+// temperature T1 of month m in the base year n-offset, where offset is
+// between 0 and 2. The degree days of the month follow from both once the
+// contract is saved.
+func (c *Contract) SetHeatingPeriod(offset int, m time.Month, days int, t1 float64) error {
+	if offset < 0 || offset >= len(baseconditions) {
+		return fmt.Errorf("invalid base year offset %d", offset)
+	}
+
+	bc, ok := c.Tables[baseconditions[offset]]
+	if !ok || int(m) > bc.Len() {
+		return fmt.Errorf("missing %s table", baseconditions[offset])
+	}
+	by, ok := c.Tables[baseyears[offset]]
+	if !ok || int(m) > by.Len() {
+		return fmt.Errorf("missing %s table", baseyears[offset])
+	}
+
+	by.Row(int(m) - 1)[1] = Cell(strconv.Itoa(days))
+	bc.Row(int(m) - 1)[1] = Cell(strconv.Itoa(days))
+	bc.Row(int(m) - 1)[2] = FormatFloat(t1)
+	return nil
+}
+
+// MonitoringYears is the count of monitoring years in the monitoring tables.
+const MonitoringYears = 20
+
+// SetMonitoringPeriod sets the heating days D_Apk and their average outdoor
+// temperature T1 of month m in the monitoring year y, counting from 1. The
+// degree days of the month follow from both and the indoor temperature of the
+// month in the base year n once the contract is saved.
+func (c *Contract) SetMonitoringPeriod(y int, m time.Month, days int, t1 float64) error {
+	if y < 1 || y > MonitoringYears {
+		return fmt.Errorf("invalid monitoring year %d", y)
+	}
+
+	mc, ok := c.Tables["monitoring_conditions"]
+	if !ok {
+		// contracts created before the table was added
+		t, err := newMonitoringConditionsTable()
+		if err != nil {
+			return err
+		}
+		c.Tables["monitoring_conditions"], mc = t, t
+	}
+
+	i := (y-1)*12 + int(m) - 1
+	if i >= mc.Len() {
+		return fmt.Errorf("missing monitoring_conditions table")
+	}
+	mc.Row(i)[2] = Cell(strconv.Itoa(days))
+	mc.Row(i)[3] = FormatFloat(t1)
+
+	if mp, ok := c.Tables["monitoring_phase_table"]; ok && i < mp.Len() {
+		mp.Row(i)[2] = Cell(strconv.Itoa(days))
+	}
+	return nil
+}
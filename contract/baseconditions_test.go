@@ -0,0 +1,30 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSetHeatingPeriod(t *testing.T) {
+	c := New(uuid.New())
+
+	if err := c.SetHeatingPeriod(3, time.January, 1, 0); err == nil {
+		t.Error("Expected an error for an invalid base year")
+	}
+
+	if err := c.SetHeatingPeriod(1, time.February, 20, -2.5); err != nil {
+		t.Fatal(err)
+	}
+	bc := c.Tables["baseconditions_n_1"]
+	bc.Row(1)[3] = "20"
+	populateBasecondition(bc, c.Tables["baseyear_n_1"])
+
+	if got := bc.Row(1); got[1] != "20" || got[2] != "-2.50" || got[4] != "450" {
+		t.Errorf("Unexpected baseconditions row %v", got)
+	}
+	if got := c.Tables["baseyear_n_1"].Row(1)[1]; got != "20" {
+		t.Errorf("Expected 20 heating days in the base year, got %v", got)
+	}
+}
@@ -30,6 +30,16 @@ type Calculation struct {
 	ETMFG     float64
 	AMP       float64
 	OM1       float64
+
+	// QMeasured is the heat energy consumption for space heating and
+	// circulation of the last complete monitoring year and QSaved its
+	// difference to QApkCzRef, the base of QIetG, after QMeasured is
+	// normalised to the reference degree days GDDRef with the degree days
+	// GDDS of that year. They are only set when Measured is true.
+	QMeasured float64
+	QSaved    float64
+	GDDS      float64
+	Measured  bool
 }
 
 // Calculate contract's dynamic data.
@@ -83,10 +93,12 @@ func calculate(contr *Contract, project models.Project) (calc *Calculation, err
 	tabl.rows[5][4] = FormatFloat(tn1)
 	tabl.rows[5][5] = FormatFloat(tn)
 
+	calc.QTRef = referenceRow(tabl, 0, 3)
 	calc.QApkRef = referenceRow(tabl, 1, 3)
 	calc.QCzRef = referenceRow(tabl, 2, 3)
 	calc.QKuRef = referenceRow(tabl, 1, 3)
 	calc.QApkCzRef = referenceRow(tabl, 4, 3)
+	calc.GDDRef = referenceRow(tabl, 6, 3)
 
 	tabl.rows[3][3] = Cell(calcdhw(contr.Tables["baseyear_n_2"]))
 	tabl.rows[3][4] = Cell(calcdhw(contr.Tables["baseyear_n_1"]))
@@ -98,6 +110,17 @@ func calculate(contr *Contract, project models.Project) (calc *Calculation, err
 	calc.QApkCzG = calc.QApkCzRef[3] - calc.QIetG
 	calc.QMApkCzG = calc.QApkCzG / 12
 
+	populateMonitoringConditions(contr.Tables["monitoring_conditions"], contr.Tables["baseconditions_n"])
+	if q, y, ok := measuredConsumption(contr.Tables["monitoring_phase_table"]); ok {
+		gdds, ok := monitoringDegreeDays(contr.Tables["monitoring_conditions"], y)
+		if ok && gdds > 0 && calc.GDDRef[3] > 0 {
+			calc.GDDS = gdds
+			calc.QMeasured = q
+			calc.QSaved = calc.QApkCzRef[3] - normalise(q, calc.GDDRef[3], gdds)
+			calc.Measured = true
+		}
+	}
+
 	om1, err := contr.Tables["operation_maintenance_budget"].Total(1)
 	if err != nil {
 		return nil, fmt.Errorf("OM1: %w", err)
@@ -285,6 +308,26 @@ func populateBasecondition(bctabl, bytabl Table) {
 	}
 }
 
+// populateMonitoringConditions computes the degree days of the months in the
+// monitoring conditions table which have an outdoor temperature, using the
+// indoor temperature of the same month in the baseconditions table bctabl.
+func populateMonitoringConditions(mctabl, bctabl Table) {
+	for i := range mctabl.Rows() {
+		row := mctabl.Row(i)
+		if row[3] == "" || i%12 >= bctabl.Len() {
+			row[4] = ""
+			continue
+		}
+
+		dapk := row[2].Decimal()
+		t1 := row[3].Decimal()
+		t3 := bctabl.Row(i % 12)[3].Decimal()
+
+		// gdd = dapk(T3-t1)
+		row[4] = Cell(t3.Sub(t1).Mul(dapk).Round(2).String())
+	}
+}
+
 // Ending Balance      = beginning_balance - principal (principal: payment - (interest+eurobor))
 func endingBalance(beginningBalance, payment, interest string, eurobor decimal.Decimal) string {
 	bbal, _ := decimal.NewFromString(beginningBalance)
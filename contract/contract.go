@@ -64,6 +64,10 @@ func (c *Contract) BeforeSave(tx *gorm.DB) (err error) {
 	c.Fields["calculations_qietg"] = strconv.FormatFloat(calc.QIetG, 'f', 2, 64)
 	c.Fields["calculations_qapkczg"] = strconv.FormatFloat(calc.QApkCzG, 'f', 2, 64)
 	c.Fields["calculations_om1"] = strconv.FormatFloat(calc.OM1, 'f', 2, 64)
+	c.Fields["calculations_qsaved"] = ""
+	if calc.Measured {
+		c.Fields["calculations_qsaved"] = strconv.FormatFloat(calc.QSaved, 'f', 2, 64)
+	}
 
 	return nil
 }
@@ -0,0 +1,128 @@
+package contract
+
+import (
+	"sort"
+	"strings"
+)
+
+// icReport is the data passed to the indoorclima template. It is the usual
+// template context with the language the report should be rendered in.
+type icReport struct {
+	TemplateContext
+
+	Lang string
+}
+
+// icPipes is the data passed to the indoorclima_pipes template.
+type icPipes struct {
+	Lang  string
+	Title string
+	Pipes JSONPipe
+}
+
+// icZone is a named zone of the building envelope, as zones are kept in a map.
+type icZone struct {
+	Name string
+	ZoneModel
+}
+
+// icCaptions holds the english captions of the indoor clima report keyed by
+// caption. Their translations are in the translation catalogs.
+var icCaptions = map[string]string{
+	"title":                                "Indoor climate calculation report",
+	"project":                              "Project",
+	"address":                              "Address",
+	"general":                              "General data",
+	"heated_volume":                        "Heated volume of the building",
+	"total_ht":                             "Total heat loss coefficient",
+	"heatgains_internal":                   "Internal heat gains",
+	"heatgains_solar":                      "Solar heat gains",
+	"distribution_losses_basement":         "Distribution losses, basement",
+	"distribution_losses_attic":            "Distribution losses, technical attic",
+	"periods":                              "Base years",
+	"indicator":                            "Indicator",
+	"indoor_temp":                          "Indoor temperature",
+	"outdoor_air_temp":                     "Outdoor air temperature",
+	"airex_windows":                        "Air exchange through windows",
+	"airex_building":                       "Air changes per hour",
+	"airex_total":                          "Total air exchange",
+	"total_energy_consumption":             "Total heat energy consumption",
+	"total_energy_consumption_circulation": "Space heating and circulation losses",
+	"circulation_losses":                   "Circulation losses outside the heating season",
+	"total_measured":                       "Measured space heating consumption",
+	"total_calculated":                     "Calculated space heating consumption",
+	"deviation":                            "Deviation of calculated from measured",
+	"zones":                                "Building envelope zones",
+	"zone":                                 "Zone",
+	"area":                                 "Area",
+	"basement_pipes":                       "Space heating pipes in the basement",
+	"attic_pipes":                          "Space heating pipes in the technical attic",
+	"quality":                              "Insulation",
+	"installed_length":                     "Length",
+	"diameter":                             "Diameter",
+	"heat_loss_unit":                       "Heat loss per meter",
+	"heat_loss_year":                       "Heat loss per year",
+	"quality_good":                         "Good",
+	"quality_poor":                         "Poor",
+	"quality_no":                           "None",
+	"no_data":                              "No data.",
+}
+
+// icLabel returns the caption key translated in lang.
+func icLabel(lang, key string) string {
+	en, ok := icCaptions[key]
+	if !ok {
+		return key
+	}
+	return translateText(lang, en)
+}
+
+// sortedZones returns the zones sorted by their name.
+func sortedZones(z JSONZone) []icZone {
+	var result = make([]icZone, 0, len(z))
+	for k, v := range z {
+		result = append(result, icZone{Name: k, ZoneModel: v})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// deviation returns the deviation of calculated from measured in percents
+// formatted for LaTeX table cell.
+func deviation(measured, calculated float64) string {
+	if measured == 0 {
+		return "{-}"
+	}
+	return FormatFloat((calculated - measured) / measured * 100).String()
+}
+
+var texEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// TexEscape escapes LaTeX special characters in user input.
+func TexEscape(s string) string {
+	return texEscaper.Replace(s)
+}
+
+// icQuality returns the translated insulation quality of a pipe.
+func icQuality(lang string, q Quality) string {
+	switch q {
+	case QualityGood:
+		return icLabel(lang, "quality_good")
+	case QualityPoor:
+		return icLabel(lang, "quality_poor")
+	case QualityNo:
+		return icLabel(lang, "quality_no")
+	}
+	return "{-}"
+}
@@ -0,0 +1,101 @@
+package contract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestIndoorClimaReport(t *testing.T) {
+	t.Parallel()
+
+	ic := NewIndoorClima(uuid.New())
+	ic.HeatedVolumeBuilding = 1234.5
+	ic.Zones["window_abc1_zone1"] = ZoneModel{Area: 10, UValue: 1.5}
+	ic.AtticPipes = JSONPipe{{Quality: QualityPoor, InstalledLength: 20, Diameter: 0.05}}
+	ic.Calculate(New(uuid.New()).Tables, 100, 5)
+
+	cases := []struct {
+		country models.Country
+		root    string
+		want    []string
+	}{
+		{
+			country: models.CountryLatvia,
+			root:    "en_indoorclima.tex",
+			want:    []string{"Indoor climate calculation report", "Poor"},
+		},
+		{
+			country: models.CountryLatvia,
+			root:    "indoorclima.tex",
+			want:    []string{"Iekštelpu mikroklimata aprēķina pārskats", "Slikta"},
+		},
+		{
+			country: models.CountryBulgaria,
+			root:    "indoorclima.tex",
+			want:    []string{"Доклад за изчисление на вътрешния климат", "Лоша"},
+		},
+		{
+			country: models.CountrySlovakia,
+			root:    "indoorclima.tex",
+			want:    []string{"Správa o výpočte vnútornej klímy", "Slabá"},
+		},
+		{
+			country: models.CountryGermany,
+			root:    "indoorclima.tex",
+			want:    []string{"Iekštelpu mikroklimata aprēķina pārskats"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.country.String()+"/"+c.root, func(t *testing.T) {
+			tc := TemplateContext{
+				Project:     models.Project{Name: "Project_1 & co", Country: c.country},
+				Asset:       models.Asset{Address: `{"streetAddress": "Brivibas 1", "city": "Riga", "postcode": "LV-1010", "country": "Latvia"}`},
+				IndoorClima: *ic,
+			}
+
+			doc, err := NewDocument(tc)
+			if err != nil {
+				t.Fatalf("NewDocumentFromPath: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := doc.tex.ExecuteTemplate(&buf, c.root, doc.ctx); err != nil {
+				t.Fatalf("execute template: %v", err)
+			}
+
+			out := buf.String()
+			want := append(c.want,
+				`Project\_1 \& co`,
+				`window\_abc1\_zone1`,
+				"1234.50",
+				"LV-1010 Brivibas 1 Riga Latvia",
+				`\end{document}`)
+			for _, w := range want {
+				if !strings.Contains(out, w) {
+					t.Errorf("expected %q in the report", w)
+				}
+			}
+		})
+	}
+}
+
+func TestDeviation(t *testing.T) {
+	for _, c := range []struct {
+		measured, calculated float64
+		want                 string
+	}{
+		{measured: 100, calculated: 110, want: "10.00"},
+		{measured: 100, calculated: 95, want: "-5.00"},
+		{measured: 0, calculated: 95, want: "{-}"},
+	} {
+		if got := deviation(c.measured, c.calculated); got != c.want {
+			t.Errorf("deviation(%v, %v) = %q; want %q", c.measured, c.calculated, got, c.want)
+		}
+	}
+}
@@ -140,6 +140,17 @@ func (ic IndoorClima) Dependencies() []config.Dependency {
 	return []config.Dependency{}
 }
 
+// HeatedArea returns the heated area A_apk of the building, as summed up in
+// the last row of the energy fee calculation table.
+func HeatedArea(tables Tables) float64 {
+	t := tables["calc_energy_fee"]
+	if t.Len() == 0 {
+		return 0
+	}
+	aapk, _ := strconv.ParseFloat(t.Row(t.Len()-1).Cell(4).String(), 64)
+	return aapk
+}
+
 // Calculate the Indoor temperature according the formula in the
 // LABEEF FINANCIAL AND TECHNICAL RULES AND GUIDELINES FOR ENERGY
 // EFFICIENCY MEASURES:
@@ -22,17 +22,6 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-// supportedCountries declares projects in which country this module is
-// capable of building PDF contract for.
-var supportedCountries = []models.Country{models.CountryAustria, models.CountryBulgaria, models.CountryLatvia, models.CountryPoland, models.CountryRomania,
-	models.CountryAlbania, models.CountryAndorra, models.CountryArmenia, models.CountryBelarus, models.CountryBelgium, models.CountryBosniaHerzegovina,
-	models.CountryCroatia, models.CountryCyprus, models.CountryCzech, models.CountryDenmark, models.CountryEstonia, models.CountryFinland, models.CountryFrance,
-	models.CountryGeorgia, models.CountryGermany, models.CountryGreece, models.CountryHungary, models.CountryIreland, models.CountryItaly, models.CountryKosovo,
-	models.CountryLiechtenstein, models.CountryLithuania, models.CountryLuxembourg, models.CountryMalta, models.CountryMoldova, models.CountryMonaco,
-	models.CountryMontenegro, models.CountryNetherlands, models.CountryMacedonia, models.CountryNorway, models.CountryPortugal, models.CountryRussia,
-	models.CountrySanMarino, models.CountrySerbia, models.CountrySlovenia, models.CountrySpain, models.CountrySweden, models.CountrySwitzerland,
-	models.CountryTurkey, models.CountryUkraine, models.CountryUK, models.CountryVatican, models.CountrySlovakia}
-
 // baseTemplate is a templated with parsed preamble and english contract and
 // should be used as a base template for each contract template.
 //
@@ -57,33 +46,13 @@ func init() {
 	baseTemplate = template.Must(template.New("").Funcs(fmap).
 		ParseGlob(filepath.Join(path, "*.tex")))
 
-	// Make sure we can create pdf value for each supported country.
-	for _, c := range supportedCountries {
-		if c.IsConsortium() {
-			// TODO this is hack because initially we do
-			// not have all adapted versions; after adding
-			// all, refactor this section and simplify it
-			// a bit
-			lang := strings.ToLower(string(c)) + "_adp"
-
-			tc := TemplateContext{Project: models.Project{Country: c}}
-			if _, err := NewDocument(tc); err != nil {
-				panic(fmt.Sprintf("Can not generate contracts for %s: %v", c, err))
-			}
+	if err := loadRegistry(); err != nil {
+		panic(fmt.Sprintf("load countries registry: %v", err))
+	}
 
-			if c.HasAdaptedContract() {
-				// TODO add adapted versions here
-				if _, err := NewDocumentFromLanguage(tc, lang); err != nil {
-					panic(fmt.Sprintf("Can not generate contracts for %s: %v", lang, err))
-				}
-			}
-		} else {
-			// Generate the latvian contract for all non-consortium countries.
-			tc := TemplateContext{Project: models.Project{Country: c}}
-			if _, err := NewDocumentFromLanguage(tc, "latvia"); err != nil {
-				panic(fmt.Sprintf("Can not generate contracts for %s: %v", c, err))
-			}
-		}
+	// Make sure we can create pdf value for each registered country.
+	if err := checkTemplates(); err != nil {
+		panic(fmt.Sprintf("Can not generate contracts for %v", err))
 	}
 }
 
@@ -102,6 +71,7 @@ type TemplateContext struct {
 	FABankAcc     models.BankAccount
 	EUROBOR       float64
 	VAT           float64
+	IndoorClima   IndoorClima
 }
 
 type document struct {
@@ -112,7 +82,7 @@ type document struct {
 // NewDocument creates a country specific pdf template determined
 // by the Project's country in the TemplateContext.
 func NewDocument(ctx TemplateContext) (*document, error) {
-	cp, err := contractPath(ctx.Project.Country.String())
+	cp, err := contractPath(DocumentsOf(ctx.Project.Country).Templates)
 	if err != nil {
 		return nil, err
 	}
@@ -120,9 +90,9 @@ func NewDocument(ctx TemplateContext) (*document, error) {
 }
 
 // NewDocumentFromLanguage create a new tex template for the giving
-// language.  Usually the language should be one of the
-// `supportedCountries` (e.g. `bulgaria`) or adapted version for that
-// country (e.g. `<country>_adp`).
+// language.  Usually the language should be one of the templates
+// directories in the countries registry (e.g. `bulgaria` or
+// `bulgaria_adp`).
 func NewDocumentFromLanguage(ctx TemplateContext, language string) (*document, error) {
 	cp, err := contractPath(language)
 	if err != nil {
@@ -150,28 +120,49 @@ func newDocument(ctx TemplateContext, path string) (*document, error) {
 	}, err
 }
 
+// xelatex runs xetex command from given dir and stdin from given io.Reader
+// with jobname "out" and blocks until it finishes. On successful run the
+// output file is located in 'dir/out.pdf'.
+//
+// ctx is used to kill the process if the context become done before the
+// command completes on its own.
+//
+// The templates are uploaded by users, so shell escapes are disabled and
+// files may be read and written only in dir and the TeX installation.
+var xelatex = texOp{
+	do: func(ctx context.Context, r io.Reader, dir string) error {
+		cmd := exec.CommandContext(ctx, "xelatex",
+			"-no-shell-escape", "-halt-on-error", "-jobname=out")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"openin_any=p", "openout_any=p", "TEXMFOUTPUT="+dir)
+		cmd.Stdin = r
+		// Uncomment this while debugging TeX files.
+		// cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	},
+	output: "out.pdf",
+}
+
 // GeneratePDF produces PDF file of a project contract after performing all the calculations.
 func (p *document) GeneratePDF(ctx context.Context, rootFile string) (*FileInTempDir, error) {
-	// genPDF runs xetex command from given dir and stdin from given
-	// io.Reader with jobname "out" and blocks until it finishes. On
-	// successful run the output file is located in 'dir/out.pdf'.
-	//
-	// ctx is used to kill the process if the context become done before
-	// the command completes on its own.
-	genPDF := texOp{
-		do: func(ctx context.Context, r io.Reader, dir string) error {
-			cmd := exec.CommandContext(ctx, "xelatex",
-				"-halt-on-error", "-jobname=out")
-			cmd.Dir = dir
-			cmd.Stdin = r
-			// Uncomment this while debugging TeX files.
-			// cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			return cmd.Run()
-		},
-		output: "out.pdf",
+	return p.generate(ctx, xelatex, rootFile)
+}
+
+// RenderPDF produces PDF file of a standalone LaTeX document read from r,
+// i.e. one not based on the contract templates.
+func RenderPDF(ctx context.Context, r io.Reader) (*FileInTempDir, error) {
+	dir, err := ioutil.TempDir("", "sunshine_latex")
+	if err != nil {
+		return nil, err
 	}
-	return p.generate(ctx, genPDF, rootFile)
+
+	if err := xelatex.do(ctx, r, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("latex: %w", err)
+	}
+	return OpenFileInTempDir(filepath.Join(dir, xelatex.output))
 }
 
 // GenerateTeX dumps raw LaTex file of project's contract after performing all the calculations.
@@ -400,21 +391,41 @@ var fmap = template.FuncMap{
 	"translate": func(lang string, t Table) Table {
 		return translate(lang, t)
 	},
-	"asset_address": func(addr string) string {
-		var aj map[string]string
-		err := json.Unmarshal([]byte(addr), &aj)
-		if err != nil {
-			return ""
-		}
-
-		return fmt.Sprintf(
-			"\"%s %s %s %s\"",
-			aj["postcode"],
-			aj["streetAddress"],
-			aj["city"],
-			aj["country"],
-		)
+	"language": func(c models.Country) string {
+		return DocumentsOf(c).Language
 	},
+	"indoorclima_report": func(lang string, ctx TemplateContext) icReport {
+		return icReport{TemplateContext: ctx, Lang: lang}
+	},
+	"indoorclima_pipes": func(lang, title string, p JSONPipe) icPipes {
+		return icPipes{Lang: lang, Title: title, Pipes: p}
+	},
+	"ic_label":   icLabel,
+	"ic_zones":   sortedZones,
+	"ic_quality": icQuality,
+	"deviation":  deviation,
+	"num": func(f float64) string {
+		return FormatFloat(f).String()
+	},
+	"tex_escape": TexEscape,
+	"asset_address": AssetAddress,
+}
+
+// AssetAddress formats the address of an asset, kept as JSON, for documents.
+func AssetAddress(addr string) string {
+	var aj map[string]string
+	err := json.Unmarshal([]byte(addr), &aj)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"\"%s %s %s %s\"",
+		aj["postcode"],
+		aj["streetAddress"],
+		aj["city"],
+		aj["country"],
+	)
 }
 
 // totalFormat returns a summed row with given function to modify the result
@@ -485,12 +496,16 @@ func translate(lang string, t Table) Table {
 
 // unmarshalLang the `target` and return the value for that `lang`. If
 // the unmarshaler cannot properly do the job, it will return the
-// `target` as it is.
+// `target` as it is. Languages without inline translation are looked
+// up in the translation catalogs by the english value.
 func unmarshalLang(lang string, target string) string {
 	var keys map[string]string
 	err := json.Unmarshal([]byte(target), &keys)
 	if err != nil {
 		return target
 	}
-	return keys[lang]
+	if v, ok := keys[lang]; ok {
+		return v
+	}
+	return translateText(lang, keys["en"])
 }
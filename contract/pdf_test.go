@@ -135,8 +135,8 @@ func TestPDF(t *testing.T) {
 
 func TestPerCountry(t *testing.T) {
 	t.Parallel()
-	for _, c := range supportedCountries {
-		if c.IsConsortium() {
+	for _, c := range models.Countries() {
+		if c.Consortium() {
 			t.Run(c.String(), func(t *testing.T) {
 				tctx := TemplateContext{
 					Contract: *New(uuid.New()),
@@ -0,0 +1,168 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+const (
+	// registryFile is the name of the file in the LaTeX path which maps
+	// countries to their templates.
+	registryFile = "countries.json"
+
+	// catalogsDir is the directory in the LaTeX path with the translation
+	// catalogs, one <language>.json file per language.
+	catalogsDir = "i18n"
+)
+
+// Documents describes how the documents of projects in a country are
+// rendered.
+type Documents struct {
+	// Country the templates are registered for.
+	Country models.Country `json:"-"`
+
+	// Templates is the directory with the templates of the native
+	// documents, relative to the LaTeX path.
+	Templates string `json:"templates"`
+
+	// Language is the code of the native language in the translations,
+	// e.g. "bg".
+	Language string `json:"language"`
+
+	// English maps documents (e.g. "contract") to a directory with their
+	// adapted english version. Documents which are not in it are rendered
+	// out of the en_ prefixed templates in Templates.
+	English map[string]string `json:"english"`
+}
+
+// Root returns the templates directory and the root template file of the
+// document (e.g. "contract", "agreement") in its native or english version.
+func (d Documents) Root(document string, english bool) (dir, file string) {
+	if !english {
+		return d.Templates, document + ".tex"
+	}
+	if dir, ok := d.English[document]; ok {
+		return dir, document + ".tex"
+	}
+	return d.Templates, "en_" + document + ".tex"
+}
+
+// registry maps countries to their templates. The countries of the consortium,
+// see models.Country.Consortium, have templates of their own. Others use the
+// ones of Fallback.
+type registry struct {
+	Fallback  models.Country               `json:"fallback"`
+	Countries map[models.Country]Documents `json:"countries"`
+}
+
+var (
+	countries registry
+
+	// catalogs holds the translations of english texts keyed by language.
+	// They are used for texts which have no inline translation in the
+	// given language.
+	catalogs = make(map[string]map[string]string)
+)
+
+// DocumentsOf returns how documents of projects in c are rendered.
+func DocumentsOf(c models.Country) Documents {
+	if d, ok := countries.Countries[c]; ok {
+		return d
+	}
+	return countries.Countries[countries.Fallback]
+}
+
+// loadRegistry reads the countries registry and the translation catalogs out
+// of the LaTeX path.
+func loadRegistry() error {
+	b, err := ioutil.ReadFile(filepath.Join(paths.LaTeX, registryFile))
+	if err != nil {
+		return err
+	}
+
+	var r registry
+	if err := json.Unmarshal(b, &r); err != nil {
+		return fmt.Errorf("%s: %w", registryFile, err)
+	}
+
+	for _, c := range models.ConsortiumCountries() {
+		if _, ok := r.Countries[c]; !ok {
+			return fmt.Errorf("%s: %s: missing templates", registryFile, c)
+		}
+	}
+	for c, d := range r.Countries {
+		if err := c.Valid(); err != nil {
+			return fmt.Errorf("%s: %w", registryFile, err)
+		}
+		if !c.Consortium() {
+			return fmt.Errorf("%s: %s: templates of a country outside the consortium", registryFile, c)
+		}
+		if d.Templates == "" || d.Language == "" {
+			return fmt.Errorf("%s: %s: missing templates or language", registryFile, c)
+		}
+		d.Country = c
+		r.Countries[c] = d
+	}
+	if _, ok := r.Countries[r.Fallback]; !ok {
+		return fmt.Errorf("%s: fallback %q is not registered", registryFile, r.Fallback)
+	}
+
+	dir := filepath.Join(paths.LaTeX, catalogsDir)
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+
+		var catalog map[string]string
+		if err := json.Unmarshal(b, &catalog); err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		catalogs[strings.TrimSuffix(filepath.Base(f), ".json")] = catalog
+	}
+
+	countries = r
+	return nil
+}
+
+// translateText returns the english text translated in lang via the
+// translation catalogs. The text itself is returned if there is no such
+// translation.
+func translateText(lang, text string) string {
+	if t, ok := catalogs[lang][text]; ok {
+		return t
+	}
+	return text
+}
+
+// checkTemplates makes sure a document of each registered country can be
+// created.
+func checkTemplates() error {
+	for c, d := range countries.Countries {
+		dirs := map[string]bool{d.Templates: true}
+		for _, dir := range d.English {
+			dirs[dir] = true
+		}
+
+		tc := TemplateContext{Project: models.Project{Country: c}}
+		for dir := range dirs {
+			if _, err := NewDocumentFromLanguage(tc, dir); err != nil {
+				return fmt.Errorf("%s: %w", c, err)
+			}
+		}
+	}
+	return nil
+}
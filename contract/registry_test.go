@@ -0,0 +1,95 @@
+package contract
+
+import (
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+func TestDocumentsOf(t *testing.T) {
+	cases := []struct {
+		country  models.Country
+		registry models.Country
+		language string
+	}{
+		{models.CountryLatvia, models.CountryLatvia, "lv"},
+		{models.CountryBulgaria, models.CountryBulgaria, "bg"},
+		{models.CountrySlovakia, models.CountrySlovakia, "sk"},
+		{models.CountryLithuania, models.CountryLatvia, "lv"},
+		{models.CountryCroatia, models.CountryLatvia, "lv"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.country.String(), func(t *testing.T) {
+			d := DocumentsOf(c.country)
+			if d.Country != c.registry {
+				t.Errorf("country: want %s, got %s", c.registry, d.Country)
+			}
+			if d.Language != c.language {
+				t.Errorf("language: want %s, got %s", c.language, d.Language)
+			}
+			if c.country.Consortium() != (c.country == c.registry) {
+				t.Errorf("consortium: want %v", c.country == c.registry)
+			}
+		})
+	}
+}
+
+func TestDocumentsRoot(t *testing.T) {
+	cases := []struct {
+		country  models.Country
+		document string
+		english  bool
+		dir      string
+		file     string
+	}{
+		{models.CountryLatvia, "contract", false, "latvia", "contract.tex"},
+		{models.CountryLatvia, "contract", true, "latvia", "en_contract.tex"},
+		{models.CountryBulgaria, "contract", true, "bulgaria_adp", "contract.tex"},
+		{models.CountryBulgaria, "agreement", true, "bulgaria", "en_agreement.tex"},
+		{models.CountryRomania, "contract", false, "romania", "contract.tex"},
+		{models.CountryGermany, "indoorclima", false, "latvia", "indoorclima.tex"},
+	}
+
+	for _, c := range cases {
+		dir, file := DocumentsOf(c.country).Root(c.document, c.english)
+		if dir != c.dir || file != c.file {
+			t.Errorf("%s/%s/%v: want %s/%s, got %s/%s",
+				c.country, c.document, c.english, c.dir, c.file, dir, file)
+		}
+	}
+}
+
+func TestTranslationCatalogs(t *testing.T) {
+	catalogs["lt"] = map[string]string{"Total": "Iš viso"}
+	defer delete(catalogs, "lt")
+
+	cases := []struct {
+		lang   string
+		target string
+		want   string
+	}{
+		{"lv", `{"en":"Total","lv":"Kopā"}`, "Kopā"},
+		{"lt", `{"en":"Total","lv":"Kopā"}`, "Iš viso"},
+		{"hr", `{"en":"Total","lv":"Kopā"}`, "Total"},
+		{"lt", "Total", "Total"},
+	}
+
+	for _, c := range cases {
+		if got := unmarshalLang(c.lang, c.target); got != c.want {
+			t.Errorf("%s %s: want %q, got %q", c.lang, c.target, c.want, got)
+		}
+	}
+}
+
+func TestShippedCatalogs(t *testing.T) {
+	if got, want := icLabel("sk", "title"), "Správa o výpočte vnútornej klímy"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := icLabel("bg", "no_data"), "Няма данни."; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := icLabel("en", "no_data"), "No data."; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,105 @@
+package contract
+
+import (
+	"strconv"
+
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+// measuredConsumption returns the heat energy consumption for space heating
+// and circulation of the latest year in the monitoring table which has all
+// twelve months reported, so that it has the same base as QApkCzRef, and the
+// number of that year, counting from 1. It is the total heat energy
+// consumption without the energy which heated the domestic hot water. The
+// third result is false if there is no such year yet.
+func measuredConsumption(t Table) (float64, int, bool) {
+	var (
+		total  float64
+		months int
+		year   int
+		result float64
+	)
+
+	for i, row := range t.rows {
+		if len(row) < 6 {
+			continue
+		}
+		if row[3] != "" {
+			total += rowFloat64(row, 3) - dhwEnergy(rowFloat64(row, 4), rowFloat64(row, 5))
+			months++
+		}
+
+		if i%12 != 11 {
+			continue
+		}
+		if months == 12 {
+			result, year = total, i/12+1
+		}
+		total, months = 0, 0
+	}
+
+	return result, year, year > 0
+}
+
+// dhwEnergy returns the energy in MWh needed to heat v cubic meters of
+// domestic hot water from the cold water temperature to t degrees Celsius.
+func dhwEnergy(v, t float64) float64 {
+	const (
+		tw  = 10    // cold water temperature, °C
+		ro  = 1000  // density of water, kg/m³
+		c   = 4186  // specific heat of water, J/(kg·K)
+		mwh = 3.6e9 // J
+	)
+
+	if v <= 0 || t <= tw {
+		return 0
+	}
+	return v * ro * c * (t - tw) / mwh
+}
+
+// monitoringDegreeDays returns the degree days of monitoring year y, counting
+// from 1, from the monitoring conditions table. The second result is false if
+// the outdoor temperature of any month of the year is missing.
+func monitoringDegreeDays(t Table, y int) (float64, bool) {
+	var (
+		total  float64
+		months int
+	)
+
+	for i := (y - 1) * 12; i < y*12 && i < t.Len(); i++ {
+		row := t.rows[i]
+		if len(row) < 5 || row[3] == "" {
+			return 0, false
+		}
+		total += rowFloat64(row, 4)
+		months++
+	}
+
+	return total, months == 12
+}
+
+// normalise converts the heat energy consumption q of a year with gdds degree
+// days to the reference conditions of gddref degree days.
+func normalise(q, gddref, gdds float64) float64 {
+	return q * gddref / gdds
+}
+
+// Savings calculates the expected and measured savings of a project from its
+// contract, using f to convert the saved final energy.
+func Savings(c Contract, f models.EnergyFactor) models.ProjectSavings {
+	result := models.ProjectSavings{
+		Project: c.Project,
+		Factor:  &f,
+	}
+
+	if qietg, err := strconv.ParseFloat(c.Fields["calculations_qietg"], 64); err == nil {
+		result.Expected = models.NewSavings(qietg, f)
+	}
+
+	if qsaved, err := strconv.ParseFloat(c.Fields["calculations_qsaved"], 64); err == nil {
+		measured := models.NewSavings(qsaved, f)
+		result.Measured = &measured
+	}
+
+	return result
+}
@@ -0,0 +1,140 @@
+package contract
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestMeasuredConsumption(t *testing.T) {
+	year := func(t *Table, y, months int, v Cell) {
+		for i := 0; i < months; i++ {
+			t.rows[y*12+i][3] = v
+		}
+	}
+
+	cases := []struct {
+		name  string
+		fill  func(t *Table)
+		exp   float64
+		year  int
+		found bool
+	}{
+		{
+			name: "empty",
+			fill: func(t *Table) {},
+		},
+		{
+			name: "incomplete year",
+			fill: func(t *Table) { year(t, 0, 11, "1") },
+		},
+		{
+			name:  "one year",
+			fill:  func(t *Table) { year(t, 0, 12, "1.5") },
+			exp:   18,
+			year:  1,
+			found: true,
+		},
+		{
+			name: "latest complete year",
+			fill: func(t *Table) {
+				year(t, 0, 12, "1")
+				year(t, 1, 12, "2")
+				year(t, 2, 3, "3")
+			},
+			exp:   24,
+			year:  2,
+			found: true,
+		},
+		{
+			name: "without domestic hot water",
+			fill: func(t *Table) {
+				year(t, 0, 12, "2")
+				t.rows[0][4], t.rows[0][5] = "86", "60"
+			},
+			exp:   19,
+			year:  1,
+			found: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tbl, err := newMPTable()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.fill(&tbl)
+
+			got, y, found := measuredConsumption(tbl)
+			if found != c.found || y != c.year || math.Abs(got-c.exp) > 0.01 {
+				t.Errorf("measuredConsumption() = %v, %v, %v; want %v, %v, %v", got, y, found, c.exp, c.year, c.found)
+			}
+		})
+	}
+}
+
+func TestMonitoringDegreeDays(t *testing.T) {
+	c := New(uuid.New())
+	for m := time.January; m <= time.December; m++ {
+		if err := c.SetMonitoringPeriod(2, m, 10, 0); err != nil {
+			t.Fatal(err)
+		}
+		c.Tables["baseconditions_n"].Row(int(m) - 1)[3] = "20"
+	}
+	if err := c.SetMonitoringPeriod(MonitoringYears+1, time.January, 1, 0); err == nil {
+		t.Error("Expected an error for an invalid monitoring year")
+	}
+
+	mc := c.Tables["monitoring_conditions"]
+	populateMonitoringConditions(mc, c.Tables["baseconditions_n"])
+
+	if _, ok := monitoringDegreeDays(mc, 1); ok {
+		t.Error("Expected no degree days for the first monitoring year")
+	}
+	if gdd, ok := monitoringDegreeDays(mc, 2); !ok || gdd != 2400 {
+		t.Errorf("monitoringDegreeDays() = %v, %v; want 2400, true", gdd, ok)
+	}
+	if got := c.Tables["monitoring_phase_table"].Row(12)[2]; got != "10" {
+		t.Errorf("Expected 10 heating days in the monitoring table, got %v", got)
+	}
+}
+
+func TestSavings(t *testing.T) {
+	f := models.EnergyFactor{CO2: 0.2, PrimaryEnergy: 1.5}
+	ctr := Contract{
+		Project: uuid.New(),
+		Fields: JSONMap{
+			"calculations_qietg":  "100",
+			"calculations_qsaved": "",
+		},
+	}
+
+	s := Savings(ctr, f)
+	if s.Project != ctr.Project {
+		t.Errorf("Savings().Project = %v; want %v", s.Project, ctr.Project)
+	}
+	if exp := (models.Savings{Energy: 100, PrimaryEnergy: 150, CO2: 20}); s.Expected != exp {
+		t.Errorf("Savings().Expected = %v; want %v", s.Expected, exp)
+	}
+	if s.Measured != nil {
+		t.Errorf("Savings().Measured = %v; want nil", s.Measured)
+	}
+
+	ctr.Fields["calculations_qsaved"] = "50"
+	s = Savings(ctr, f)
+	if exp := (models.Savings{Energy: 50, PrimaryEnergy: 75, CO2: 10}); s.Measured == nil || *s.Measured != exp {
+		t.Errorf("Savings().Measured = %v; want %v", s.Measured, exp)
+	}
+}
+
+func TestNormalise(t *testing.T) {
+	// a colder year than the reference consumes more for the same building
+	if got := normalise(120, 3000, 3600); got != 100 {
+		t.Errorf("normalise() = %v; want 100", got)
+	}
+}
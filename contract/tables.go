@@ -9,7 +9,7 @@ import (
 type Tables map[string]Table
 
 func NewTables() Tables {
-	var errs [26]error
+	var errs [27]error
 	v := make(Tables)
 	v["renovation_overall_budget"], errs[0] = NewTable(
 		[]Column{
@@ -448,6 +448,7 @@ func NewTables() Tables {
 	)
 	//Create monitoring phase table
 	v["monitoring_phase_table"], errs[17] = newMPTable()
+	v["monitoring_conditions"], errs[26] = newMonitoringConditionsTable()
 
 	//Annex 4 table baseline-Conditions
 	baseConditionsColumns := []Column{
@@ -881,6 +882,28 @@ func newMPTable() (Table, error) {
 
 }
 
+// newMonitoringConditionsTable creates the table with the heating days and
+// their average outdoor temperature of each month of the monitoring years, in
+// the same order as the monitoring phase table.
+func newMonitoringConditionsTable() (Table, error) {
+	columns := []Column{
+		Column{Name: `{"en": "Year", "pl": "Rok", "ro": "An", "au": "Jahr", "lv":"Gadā", "bg": "Година"}`, Kind: Count, Headers: []string{`{"en": "Symbol", "pl": "Oznaczenie", "ro": "Simbol", "au": "Symbol", "lv":"Simbol", "bg": "Символ"}`, `{"en": "Unit", "pl": "Jednostka", "ro": "Unitate", "au": "Einheit", "lv":"Vienība", "bg": "Единици"}`}},
+		Column{Name: `{"en": "Month", "pl": "Miesiąc", "ro": "Lună", "au": "Monat", "lv":"Mēnesī", "bg": "Месец"}`, Kind: Name, Headers: []string{`{"en": "Symbol", "pl": "Oznaczenie", "ro": "Simbol", "au": "Symbol", "lv":"Simbol", "bg": "Символ"}`, `{"en": "Unit", "pl": "Jednostka", "ro": "Unitate", "au": "Einheit", "lv":"Vienība", "bg": "Единици"}`}},
+		Column{Name: `{"en": "Heating days", "pl": "Liczba dni ogrzewania", "ro": "Numărul zilelor de încălzire efectivă", "au": "Anzahl Heiztage", "lv":"Apkures dienu skaits", "bg": "Брой на дни"}`, Kind: Count, Headers: []string{"$D_{Apk}$", `{"en": "Days", "pl": "Dni", "ro": "Zile", "au": "Tage", "lv":"Dienas", "bg": "Дни"}`}},
+		Column{Name: `{"en": "Outdoor temperature", "pl": "Temperatura zewnętrzna", "ro": "Temperatură exterioară", "au": "Außentemperatur", "lv":"Ārējā gaisa temperatūra", "bg": "Средна температура на външния въздух"}`, Kind: Temperature, Headers: []string{"$T_{1}$", "°C"}},
+		Column{Name: `{"en": "Degree Days", "pl": "Stopniodni", "ro": "Numărul zilelor ce necestită încălzire", "au": "Heizgradtage", "lv":"Grādu dienas", "bg": "Денградуси"}`, Kind: Count, Headers: []string{"GDD", "-"}},
+	}
+
+	var rows []Row
+	for y := 1; y <= MonitoringYears; y++ {
+		for m := 1; m <= 12; m++ {
+			rows = append(rows, Row{Cell(fmt.Sprintf("%d", y)), Cell(fmt.Sprintf("%d", m)), "", "", ""})
+		}
+	}
+
+	return NewTable(columns, rows...)
+}
+
 func newMeasurementsTable() (Table, error) {
 
 	measurementColumns := []Column{
@@ -0,0 +1,162 @@
+package contract
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+// maxTemplateFileSize is the maximum size of a single file in a template
+// bundle archive.
+const maxTemplateFileSize = 1 << 20
+
+// Template is a versioned bundle of LaTeX templates for a country. Its files
+// are laid over the templates on disk for that country so a bundle may hold
+// only the files which wording differs.
+type Template struct {
+	models.Value
+
+	Country     models.Country `json:"country"`
+	Version     int            `json:"version"`
+	Description string         `json:"description"`
+	Files       JSONMap        `json:"files"`
+	Active      bool           `json:"active"`
+	Author      *uuid.UUID     `json:"author" gorm:"type:uuid; null"`
+}
+
+func (Template) TableName() string {
+	return "contract_templates"
+}
+
+// FileNames returns the sorted names of the bundle files.
+func (t Template) FileNames() []string {
+	var names = make([]string, 0, len(t.Files))
+	for n := range t.Files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadTemplateFiles reads a zip archive of a template bundle. Only .tex files
+// are allowed and directories in the archive are ignored, i.e. files are
+// keyed by their base name.
+func ReadTemplateFiles(r io.Reader) (JSONMap, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+
+	var files = make(JSONMap)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name := path.Base(f.Name)
+		if path.Ext(name) != ".tex" {
+			return nil, fmt.Errorf("%s: only .tex files are allowed", f.Name)
+		}
+		if _, ok := files[name]; ok {
+			return nil, fmt.Errorf("%s: duplicated file name", f.Name)
+		}
+		if f.UncompressedSize64 > maxTemplateFileSize {
+			return nil, fmt.Errorf("%s: file is too big", f.Name)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		b, err := ioutil.ReadAll(io.LimitReader(rc, maxTemplateFileSize))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		files[name] = string(b)
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no templates in archive")
+	}
+	return files, nil
+}
+
+// NewDocumentFromTemplate creates a document from the templates of given
+// language laid over with the files of t. If t is nil it is the same as
+// NewDocumentFromLanguage.
+func NewDocumentFromTemplate(ctx TemplateContext, language string, t *Template) (*document, error) {
+	doc, err := NewDocumentFromLanguage(ctx, language)
+	if err != nil || t == nil {
+		return doc, err
+	}
+
+	for _, name := range t.FileNames() {
+		if _, err := doc.tex.New(name).Parse(t.Files[name]); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// ValidateTemplate checks whether the files of t parse along with the base
+// templates and the contract and agreement of the country can be rendered
+// with a sample contract.
+func ValidateTemplate(t Template) error {
+	if !t.Country.Consortium() {
+		return fmt.Errorf("templates for %s are not supported", t.Country)
+	}
+
+	doc, err := NewSampleDocument(t)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range []string{"contract.tex", "agreement.tex"} {
+		if err := doc.execute(ioutil.Discard, root); err != nil {
+			return fmt.Errorf("%s: %w", root, err)
+		}
+	}
+	return nil
+}
+
+// NewSampleDocument creates a document for previewing t filled with a sample
+// contract.
+func NewSampleDocument(t Template) (*document, error) {
+	return NewDocumentFromTemplate(sampleContext(t.Country), DocumentsOf(t.Country).Templates, &t)
+}
+
+func sampleContext(c models.Country) TemplateContext {
+	return TemplateContext{
+		Contract:    *New(uuid.Nil),
+		Project:     models.Project{Name: "Sample project", Country: c},
+		Attachments: make(map[string]string),
+	}
+}
+
+// execute renders the root template into w without running the Markdown and
+// LaTeX steps of the pipeline.
+func (p *document) execute(w io.Writer, root string) error {
+	if p.tex.Lookup(root) == nil {
+		return fmt.Errorf("template %q not defined", root)
+	}
+
+	ctx := p.ctx
+	ctx.Markdown = strings.NewReader("")
+	return p.tex.ExecuteTemplate(w, root, ctx)
+}
@@ -0,0 +1,152 @@
+package contract
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+func zipFiles(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestReadTemplateFiles(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		want  []string
+		err   bool
+	}{
+		{
+			name:  "ok",
+			files: map[string]string{"latvia/annex1.tex": "a", "annex2.tex": "b"},
+			want:  []string{"annex1.tex", "annex2.tex"},
+		},
+		{
+			name:  "not tex",
+			files: map[string]string{"annex1.tex": "a", "image.png": "b"},
+			err:   true,
+		},
+		{
+			name:  "duplicated",
+			files: map[string]string{"a/annex1.tex": "a", "b/annex1.tex": "b"},
+			err:   true,
+		},
+		{
+			name:  "empty",
+			files: map[string]string{},
+			err:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			files, err := ReadTemplateFiles(zipFiles(t, c.files))
+			if (err != nil) != c.err {
+				t.Fatalf("expected error: %v; got %v", c.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			got := Template{Files: files}.FileNames()
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("expected files %v; got %v", c.want, got)
+			}
+		})
+	}
+
+	if _, err := ReadTemplateFiles(strings.NewReader("not a zip")); err == nil {
+		t.Error("expected error on invalid archive")
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	cases := []struct {
+		name    string
+		country models.Country
+		files   JSONMap
+		err     bool
+	}{
+		{
+			name:    "empty bundle",
+			country: models.CountryLatvia,
+			files:   JSONMap{},
+		},
+		{
+			name:    "override annex",
+			country: models.CountryBulgaria,
+			files:   JSONMap{"sign.tex": `{{.Project.Name}} signed`},
+		},
+		{
+			name:    "parse error",
+			country: models.CountryLatvia,
+			files:   JSONMap{"sign.tex": `{{.Project.Name`},
+			err:     true,
+		},
+		{
+			name:    "exec error",
+			country: models.CountryLatvia,
+			files:   JSONMap{"contract.tex": `{{.NoSuchField}}`},
+			err:     true,
+		},
+		{
+			name:    "not consortium",
+			country: models.CountryGermany,
+			files:   JSONMap{},
+			err:     true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateTemplate(Template{Country: c.country, Files: c.files})
+			if (err != nil) != c.err {
+				t.Errorf("expected error: %v; got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestNewDocumentFromTemplate(t *testing.T) {
+	tmpl := Template{
+		Country: models.CountryLatvia,
+		Files:   JSONMap{"indoorclima.tex": `custom {{.Project.Name}}`},
+	}
+
+	doc, err := NewSampleDocument(tmpl)
+	if err != nil {
+		t.Fatalf("NewSampleDocument: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.execute(&buf, "indoorclima.tex"); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if buf.String() != "custom Sample project" {
+		t.Errorf("expected overridden template; got %q", buf.String())
+	}
+
+	if err := doc.execute(&buf, "en_indoorclima.tex"); err != nil {
+		t.Errorf("expected base templates to be kept; got %v", err)
+	}
+}
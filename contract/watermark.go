@@ -0,0 +1,73 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Watermark stamps each page of the PDF file with the stamp, e.g.
+// CONFIDENTIAL, across the page and the lines at its foot, e.g. who
+// downloaded it and when.
+func Watermark(ctx context.Context, pdf, stamp string, lines ...string) (*FileInTempDir, error) {
+	dir, err := ioutil.TempDir("", "sunshine_latex")
+	if err != nil {
+		return nil, err
+	}
+
+	// the stored files carry no extension pdfpages could tell the format by
+	src, err := filepath.Abs(pdf)
+	if err == nil {
+		err = os.Symlink(src, filepath.Join(dir, "in.pdf"))
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	var tex bytes.Buffer
+	if err := watermarkTeX(&tex, stamp, lines); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	if err := xelatex.do(ctx, &tex, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("latex: %w", err)
+	}
+	os.Remove(filepath.Join(dir, "in.pdf"))
+	return OpenFileInTempDir(filepath.Join(dir, xelatex.output))
+}
+
+func watermarkTeX(buf *bytes.Buffer, stamp string, lines []string) error {
+	escaped := make([]string, len(lines))
+	for i, l := range lines {
+		escaped[i] = TexEscape(l)
+	}
+	return watermarkTemplate.Execute(buf, struct {
+		Stamp string
+		Foot  string
+	}{TexEscape(stamp), strings.Join(escaped, ` \textbullet{} `)})
+}
+
+var watermarkTemplate = template.Must(template.New("watermark").
+	Delims("<<", ">>").
+	Parse(`\documentclass{article}
+\usepackage{pdfpages}
+\usepackage{xcolor}
+\begin{document}
+\includepdf[pages=-,fitpaper,picturecommand*={%
+<<- if .Stamp >>
+\put(\LenToUnit{.5\paperwidth},\LenToUnit{.5\paperheight}){%
+\makebox(0,0){\rotatebox{45}{\scalebox{6}{\color{red!20}\bfseries <<.Stamp>>}}}}%
+<<- end >>
+\put(\LenToUnit{.5\paperwidth},\LenToUnit{6mm}){%
+\makebox(0,0){\scriptsize\color{gray} <<.Foot>>}}%
+}]{in.pdf}
+\end{document}
+`))
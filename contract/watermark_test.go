@@ -0,0 +1,36 @@
+package contract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWatermarkTeX(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := watermarkTeX(&buf, "CONFIDENTIAL", []string{"Jane Doe <jane_doe@example.com>", "Downloaded 2020-01-02 15:04 UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tex := buf.String()
+	for _, want := range []string{
+		`\includepdf[pages=-`,
+		`{in.pdf}`,
+		`\bfseries CONFIDENTIAL`,
+		`Jane Doe <jane\_doe@example.com> \textbullet{} Downloaded 2020-01-02 15:04 UTC`,
+	} {
+		if !strings.Contains(tex, want) {
+			t.Errorf("Expected %q in\n%s", want, tex)
+		}
+	}
+
+	buf.Reset()
+	if err := watermarkTeX(&buf, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), `\rotatebox`) {
+		t.Errorf("Expected no stamp, got\n%s", buf.String())
+	}
+}
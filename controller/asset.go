@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/sentry"
@@ -135,7 +134,7 @@ func (a *Asset) Update(ctx context.Context, id uuid.UUID, r io.Reader) (*models.
 				New:        newFields,
 				Country:    asset.Country,
 			}
-			if !asset.Country.IsConsortium() {
+			if !asset.Country.Consortium() {
 				admins = []uuid.UUID{getANWManager(a.store)}
 			}
 			notifyAll(ctx, a.notifier, admins, n)
@@ -292,7 +291,7 @@ func (a *Asset) UploadFile(ctx context.Context, aid uuid.UUID, form RequestForm)
 	return uploadFile(ctx, a.store, a.notifier, form, doc, a.uploadPath)
 }
 
-func (a *Asset) GetFile(ctx context.Context, aid uuid.UUID, filename string) (*models.Attachment, *os.File, error) {
+func (a *Asset) GetFile(ctx context.Context, aid uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
 	doc, err := a.store.Get(ctx, aid)
 	if err != nil {
 		return nil, nil, err
@@ -302,7 +301,7 @@ func (a *Asset) GetFile(ctx context.Context, aid uuid.UUID, filename string) (*m
 		return nil, nil, ErrUnauthorized
 	}
 
-	return getFile(ctx, a.store, aid, filename, a.uploadPath)
+	return getFile(ctx, a.store, aid, filename, a.uploadPath, fr)
 }
 
 func (a *Asset) DeleteFile(ctx context.Context, id uuid.UUID, filename string) error {
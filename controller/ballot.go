@@ -0,0 +1,785 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/mail"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/matcornic/hermes/v2"
+)
+
+// Ballot holds the residents' votes on the decisions about their buildings.
+type Ballot struct {
+	st         stores.Store
+	mailer     services.Mailer
+	uploadPath string
+}
+
+func NewBallot(env *services.Env) *Ballot {
+	return &Ballot{
+		st:         env.AssetStore,
+		mailer:     env.Mailer,
+		uploadPath: env.Paths.Uploads,
+	}
+}
+
+// Tally is the count of the votes of a ballot.
+type Tally struct {
+	Options []OptionTally
+
+	// EligibleFlats are the flats with verified residents and the ones which
+	// voted; EligibleArea is their area.
+	EligibleFlats int
+	EligibleArea  float64
+
+	// TotalFlats are all the flats of the asset; TotalArea is their area.
+	TotalFlats int
+	TotalArea  float64
+
+	VotedFlats int
+	VotedArea  float64
+
+	// Turnout is the share of all the flats, or their area, which voted.
+	Turnout       float64
+	QuorumReached bool
+
+	// Winner is the index of the option which won, if any.
+	Winner *int
+}
+
+// OptionTally is the count of the votes for an option of a ballot.
+type OptionTally struct {
+	Option string
+	Flats  int
+	Area   float64
+
+	// Share is the share of the votes cast, or their area, for the option.
+	Share float64
+}
+
+// asset returns the asset if the user manages its ballots.
+func (b *Ballot) asset(ctx context.Context, id uuid.UUID) (*models.Asset, error) {
+	doc, err := b.st.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	asset := doc.Data.(*models.Asset)
+	if !b.manages(ctx, *asset) {
+		return nil, ErrUnauthorized
+	}
+	return asset, nil
+}
+
+func (b *Ballot) manages(ctx context.Context, asset models.Asset) bool {
+	return Can(ctx, ManageBallots, asset.Owner, asset.Country) ||
+		(asset.ESCO != nil && Can(ctx, ManageBallots, *asset.ESCO, asset.Country))
+}
+
+// isResident reports whether the user is the verified resident of a flat of
+// the asset.
+func (b *Ballot) isResident(uid, asset uuid.UUID) (bool, error) {
+	var n int
+	err := b.st.DB().Model(&models.Flat{}).
+		Where("asset_id = ? AND resident_id = ? AND verified_at IS NOT NULL", asset, uid).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// canView reports whether the user manages the ballots of the asset or
+// resides in it.
+func (b *Ballot) canView(ctx context.Context, assetID uuid.UUID) error {
+	cv := services.FromContext(ctx)
+	if !cv.Authorized() {
+		return ErrUnauthorized
+	}
+
+	doc, err := b.st.Get(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if b.manages(ctx, *doc.Data.(*models.Asset)) {
+		return nil
+	}
+
+	ok, err := b.isResident(cv.User.ID, assetID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// Flats returns the flats of the asset.
+func (b *Ballot) Flats(ctx context.Context, assetID uuid.UUID) ([]models.Flat, error) {
+	if _, err := b.asset(ctx, assetID); err != nil {
+		return nil, err
+	}
+
+	var flats []models.Flat
+	err := b.st.DB().Where("asset_id = ?", assetID).
+		Order("length(number), number").
+		Find(&flats).Error
+	return flats, err
+}
+
+// SaveFlat adds the flat to its asset or updates its number and area.
+func (b *Ballot) SaveFlat(ctx context.Context, flat models.Flat) (*models.Flat, error) {
+	if flat.ID != uuid.Nil {
+		old, err := b.flat(flat.ID)
+		if err != nil {
+			return nil, err
+		}
+		old.Number, old.Area = flat.Number, flat.Area
+		flat = *old
+	}
+
+	if _, err := b.asset(ctx, flat.Asset); err != nil {
+		return nil, err
+	}
+	if flat.Number == "" {
+		return nil, fmt.Errorf("%w: flat without number", ErrBadInput)
+	}
+	if flat.Area < 0 {
+		return nil, fmt.Errorf("%w: negative area", ErrBadInput)
+	}
+
+	if err := b.st.DB().Save(&flat).Error; err != nil {
+		if stores.IsDuplicatedRecord(err) {
+			err = fmt.Errorf("%w: flat %s", ErrDuplicate, flat.Number)
+		}
+		return nil, err
+	}
+	return &flat, nil
+}
+
+// DeleteFlat removes the flat from its asset. Its votes are kept.
+func (b *Ballot) DeleteFlat(ctx context.Context, id uuid.UUID) error {
+	flat, err := b.flat(id)
+	if err != nil {
+		return err
+	}
+	if _, err := b.asset(ctx, flat.Asset); err != nil {
+		return err
+	}
+	return b.st.DB().Delete(flat).Error
+}
+
+// VerifyResident sets the user as the verified resident of the flat, e.g.
+// after the user claimed residency in the building. A nil user leaves the
+// flat without resident.
+func (b *Ballot) VerifyResident(ctx context.Context, flatID uuid.UUID, userID *uuid.UUID) (*models.Flat, error) {
+	flat, err := b.flat(flatID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.asset(ctx, flat.Asset); err != nil {
+		return nil, err
+	}
+
+	flat.Resident, flat.VerifiedAt, flat.VerifiedBy = nil, nil, nil
+	if userID != nil {
+		var u models.User
+		err := b.st.DB().Where("id = ?", *userID).First(&u).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("%w: no user %v", ErrBadInput, *userID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		flat.Resident = userID
+		flat.VerifiedAt = &now
+		flat.VerifiedBy = &services.FromContext(ctx).User.ID
+	}
+
+	err = b.st.DB().Model(flat).UpdateColumns(map[string]interface{}{
+		"resident_id": flat.Resident,
+		"verified_at": flat.VerifiedAt,
+		"verified_by": flat.VerifiedBy,
+	}).Error
+	return flat, err
+}
+
+func (b *Ballot) flat(id uuid.UUID) (*models.Flat, error) {
+	var flat models.Flat
+	err := b.st.DB().Where("id = ?", id).First(&flat).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, fmt.Errorf("%w: no flat %v", ErrNotFound, id)
+	}
+	return &flat, err
+}
+
+// Create opens the ballot and emails the verified residents of the building
+// about it.
+func (b *Ballot) Create(ctx context.Context, ballot models.Ballot) (*models.Ballot, error) {
+	asset, err := b.asset(ctx, ballot.Asset)
+	if err != nil {
+		return nil, err
+	}
+
+	if ballot.Project != nil {
+		doc, err := b.st.FromKind("project").Get(ctx, *ballot.Project)
+		if err != nil {
+			return nil, err
+		}
+		if doc.Data.(*models.Project).Asset != asset.ID {
+			return nil, fmt.Errorf("%w: the project is not of the asset", ErrBadInput)
+		}
+	}
+
+	if ballot.Basis == "" {
+		ballot.Basis = models.BallotBasisFlats
+	}
+	if err := validBallot(ballot, time.Now()); err != nil {
+		return nil, err
+	}
+
+	ballot.Value = models.Value{}
+	ballot.Author = &services.FromContext(ctx).User.ID
+	ballot.ClosedAt, ballot.Digest = nil, ""
+	if err := b.st.DB().Create(&ballot).Error; err != nil {
+		return nil, err
+	}
+
+	go b.announce(*asset, ballot)
+	return &ballot, nil
+}
+
+func validBallot(ballot models.Ballot, now time.Time) error {
+	switch {
+	case strings.TrimSpace(ballot.Question) == "":
+		return fmt.Errorf("%w: ballot without question", ErrBadInput)
+	case len(ballot.Options) < 2:
+		return fmt.Errorf("%w: a ballot needs at least two options", ErrBadInput)
+	case ballot.Basis != models.BallotBasisFlats && ballot.Basis != models.BallotBasisArea:
+		return fmt.Errorf("%w: bad ballot basis: %v", ErrBadInput, ballot.Basis)
+	case ballot.Quorum <= 0 || ballot.Quorum > 1:
+		return fmt.Errorf("%w: the quorum must be a share above 0 and up to 1", ErrBadInput)
+	case ballot.Majority < 0.5 || ballot.Majority >= 1:
+		return fmt.Errorf("%w: the majority must be a share from 0.5 and below 1", ErrBadInput)
+	case !ballot.Deadline.After(now):
+		return fmt.Errorf("%w: the deadline has passed", ErrBadInput)
+	}
+
+	seen := make(map[string]bool)
+	for _, o := range ballot.Options {
+		o = strings.TrimSpace(o)
+		if o == "" || seen[o] {
+			return fmt.Errorf("%w: empty or repeated option", ErrBadInput)
+		}
+		seen[o] = true
+	}
+	return nil
+}
+
+// announce emails the verified residents of the building about the ballot.
+func (b *Ballot) announce(asset models.Asset, ballot models.Ballot) {
+	var to []mail.Address
+	err := b.st.DB().Table("users u").
+		Select("DISTINCT u.name, u.email AS address").
+		Joins("JOIN flats f ON f.resident_id = u.id").
+		Where("f.asset_id = ? AND f.verified_at IS NOT NULL AND f.deleted_at IS NULL", asset.ID).
+		Where("u.deleted_at IS NULL AND u.is_active").
+		Scan(&to).Error
+	if err != nil || len(to) == 0 {
+		log.Printf("announce ballot %v to %d residents: %v", ballot.ID, len(to), err)
+		return
+	}
+
+	err = b.mailer.Send(to, "Residents' vote: "+ballot.Question,
+		hermes.Email{Body: hermes.Body{
+			Intros: []string{
+				fmt.Sprintf("The residents of %s are asked to vote on: %s", contract.AssetAddress(asset.Address), ballot.Question),
+				fmt.Sprintf("The vote is open until %s.", ballot.Deadline.UTC().Format(time.RFC1123)),
+			},
+			Actions: []hermes.Action{{
+				Button: hermes.Button{
+					Text: "Vote",
+					Link: fmt.Sprintf("%s/assets/%s/ballots/%s", b.mailer.URL(), asset.ID, ballot.ID),
+				},
+			}},
+		}},
+	)
+	log.Printf("announce ballot %v to %d residents: %v", ballot.ID, len(to), err)
+}
+
+// Get returns the ballot.
+func (b *Ballot) Get(ctx context.Context, id uuid.UUID) (*models.Ballot, error) {
+	var ballot models.Ballot
+	err := b.st.DB().Where("id = ?", id).First(&ballot).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, fmt.Errorf("%w: no ballot %v", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.canView(ctx, ballot.Asset); err != nil {
+		return nil, err
+	}
+	return &ballot, nil
+}
+
+// List returns the ballots of the asset, the latest first.
+func (b *Ballot) List(ctx context.Context, assetID uuid.UUID) ([]models.Ballot, error) {
+	if err := b.canView(ctx, assetID); err != nil {
+		return nil, err
+	}
+
+	var ballots []models.Ballot
+	err := b.st.DB().Where("asset_id = ?", assetID).Order("created_at DESC").Find(&ballots).Error
+	return ballots, err
+}
+
+// Vote records the choice of the flat, whose verified resident the user
+// must be. Each flat votes once.
+func (b *Ballot) Vote(ctx context.Context, ballotID, flatID uuid.UUID, option int) (*models.Vote, error) {
+	ballot, err := b.Get(ctx, ballotID)
+	if err != nil {
+		return nil, err
+	}
+	if !ballot.Open(time.Now()) {
+		return nil, fmt.Errorf("%w: the ballot is closed", ErrBadInput)
+	}
+	if option < 0 || option >= len(ballot.Options) {
+		return nil, fmt.Errorf("%w: no option %d", ErrBadInput, option)
+	}
+
+	flat, err := b.flat(flatID)
+	if err != nil {
+		return nil, err
+	}
+	cv := services.FromContext(ctx)
+	if flat.Asset != ballot.Asset || !flat.Verified() || *flat.Resident != cv.User.ID {
+		return nil, ErrUnauthorized
+	}
+
+	vote := models.Vote{
+		Ballot: ballot.ID,
+		Flat:   flat.ID,
+		User:   &cv.User.ID,
+		Option: option,
+	}
+
+	// start transaction block
+	tx := b.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// the ballot is locked so that it is not closed while the vote is cast
+	if err := lockBallot(tx, ballot); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !ballot.Open(time.Now()) {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: the ballot is closed", ErrBadInput)
+	}
+
+	if err := tx.Create(&vote).Error; err != nil {
+		tx.Rollback()
+		if stores.IsDuplicatedRecord(err) {
+			err = fmt.Errorf("%w: flat %s has already voted", ErrDuplicate, flat.Number)
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	// end transaction block
+
+	return &vote, nil
+}
+
+// lockBallot reloads the ballot within the transaction tx, locking its row
+// until tx ends.
+func lockBallot(tx *gorm.DB, ballot *models.Ballot) error {
+	return tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ?", ballot.ID).
+		First(ballot).Error
+}
+
+// Tally counts the votes of the ballot so far.
+func (b *Ballot) Tally(ctx context.Context, id uuid.UUID) (*Tally, error) {
+	ballot, err := b.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	flats, votes, err := b.votes(b.st.DB(), *ballot)
+	if err != nil {
+		return nil, err
+	}
+	t := tally(*ballot, flats, votes)
+	return &t, nil
+}
+
+// votes returns the flats of the ballot's asset and the votes of the ballot,
+// read with db. Flats deleted after they voted are counted with their votes.
+func (b *Ballot) votes(db *gorm.DB, ballot models.Ballot) ([]models.Flat, []models.Vote, error) {
+	var (
+		flats []models.Flat
+		votes []models.Vote
+	)
+	err := db.Unscoped().
+		Where("asset_id = ?", ballot.Asset).
+		Where("deleted_at IS NULL OR id IN (SELECT flat_id FROM votes WHERE ballot_id = ?)", ballot.ID).
+		Order("number").
+		Find(&flats).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	err = db.Where("ballot_id = ?", ballot.ID).Order("created_at, id").Find(&votes).Error
+	return flats, votes, err
+}
+
+// tally counts the votes of the flats.
+func tally(ballot models.Ballot, flats []models.Flat, votes []models.Vote) Tally {
+	t := Tally{Options: make([]OptionTally, len(ballot.Options))}
+	for i, o := range ballot.Options {
+		t.Options[i].Option = o
+	}
+
+	voted := make(map[uuid.UUID]int, len(votes))
+	for _, v := range votes {
+		if v.Option >= 0 && v.Option < len(t.Options) {
+			voted[v.Flat] = v.Option
+		}
+	}
+
+	for _, f := range flats {
+		t.TotalFlats++
+		t.TotalArea += f.Area
+
+		option, ok := voted[f.ID]
+		if !ok && !f.Verified() {
+			continue
+		}
+
+		t.EligibleFlats++
+		t.EligibleArea += f.Area
+		if ok {
+			t.VotedFlats++
+			t.VotedArea += f.Area
+			t.Options[option].Flats++
+			t.Options[option].Area += f.Area
+		}
+	}
+
+	weight := func(flats int, area float64) float64 {
+		if ballot.Basis == models.BallotBasisArea {
+			return area
+		}
+		return float64(flats)
+	}
+
+	if total := weight(t.TotalFlats, t.TotalArea); total > 0 {
+		t.Turnout = weight(t.VotedFlats, t.VotedArea) / total
+	}
+	t.QuorumReached = t.VotedFlats > 0 && t.Turnout >= ballot.Quorum
+
+	cast := weight(t.VotedFlats, t.VotedArea)
+	for i := range t.Options {
+		o := &t.Options[i]
+		if cast > 0 {
+			o.Share = weight(o.Flats, o.Area) / cast
+		}
+		if t.QuorumReached && o.Share > ballot.Majority {
+			i := i
+			t.Winner = &i
+		}
+	}
+	return t
+}
+
+// Close counts the votes of the ballot and attaches the report of the result
+// to its project, or its asset if it has none. The ballot is closed once its
+// deadline has passed or all the flats of the asset have voted.
+func (b *Ballot) Close(ctx context.Context, id uuid.UUID) (*models.Ballot, error) {
+	ballot, err := b.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := b.asset(ctx, ballot.Asset)
+	if err != nil {
+		return nil, err
+	}
+
+	// start transaction block
+	tx := b.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// the ballot is locked so that no vote is cast while it is counted
+	if err := lockBallot(tx, ballot); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if ballot.ClosedAt != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: the ballot is already closed", ErrBadInput)
+	}
+
+	flats, votes, err := b.votes(tx, *ballot)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	t := tally(*ballot, flats, votes)
+
+	// the database keeps microseconds, which the digest must match
+	now := time.Now().Truncate(time.Microsecond)
+	if now.Before(ballot.Deadline) && t.VotedFlats < t.TotalFlats {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: the ballot is open until %s", ErrBadInput, ballot.Deadline.UTC().Format(time.RFC1123))
+	}
+
+	ballot.ClosedAt = &now
+	if ballot.Digest, err = ballotDigest(*ballot, votes); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var tex bytes.Buffer
+	err = ballotReport.Execute(&tex, ballotReportData(*asset, *ballot, t, flats, votes, services.FromContext(ctx).User.Name))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	pdf, err := contract.RenderPDF(ctx, &tex)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to generate the result report: %w", err)
+	}
+	defer pdf.Close()
+
+	st, err := pdf.Stat()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	target := ballot.Asset
+	if ballot.Project != nil {
+		target = *ballot.Project
+	}
+	up := Upload{
+		File:        pdf,
+		Filename:    fmt.Sprintf("vote-result-%s.pdf", now.Format("2006-01-02")),
+		Size:        st.Size(),
+		ContentType: "application/pdf",
+		UploadType:  "residents vote result",
+	}
+	att, err := saveUpload(tx, up, target, b.uploadPath)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("fail to upload %s: %w", up.Filename, err)
+	}
+	written := []string{filepath.Join(b.uploadPath, fmt.Sprintf("%s-%s", target, att.ID))}
+
+	err = tx.Model(ballot).UpdateColumns(map[string]interface{}{
+		"closed_at": ballot.ClosedAt,
+		"digest":    ballot.Digest,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		removeFiles(written)
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		removeFiles(written)
+		return nil, err
+	}
+	// end transaction block
+
+	return ballot, nil
+}
+
+// ballotDigest returns the SHA-256 hash of the ballot and its votes, so that
+// the result report can be checked against the records.
+func ballotDigest(ballot models.Ballot, votes []models.Vote) (string, error) {
+	type vote struct {
+		Flat   uuid.UUID  `json:"flat"`
+		User   *uuid.UUID `json:"user"`
+		Option int        `json:"option"`
+		At     time.Time  `json:"at"`
+	}
+	record := struct {
+		ID       uuid.UUID  `json:"id"`
+		Question string     `json:"question"`
+		Options  []string   `json:"options"`
+		Basis    string     `json:"basis"`
+		Quorum   float64    `json:"quorum"`
+		Majority float64    `json:"majority"`
+		Deadline time.Time  `json:"deadline"`
+		ClosedAt *time.Time `json:"closed_at"`
+		Votes    []vote     `json:"votes"`
+	}{
+		ID:       ballot.ID,
+		Question: ballot.Question,
+		Options:  ballot.Options,
+		Basis:    string(ballot.Basis),
+		Quorum:   ballot.Quorum,
+		Majority: ballot.Majority,
+		Deadline: ballot.Deadline.UTC(),
+		ClosedAt: ballot.ClosedAt,
+		Votes:    make([]vote, len(votes)),
+	}
+	if record.ClosedAt != nil {
+		at := record.ClosedAt.UTC()
+		record.ClosedAt = &at
+	}
+	for i, v := range votes {
+		record.Votes[i] = vote{v.Flat, v.User, v.Option, v.CreatedAt.UTC()}
+	}
+	sort.Slice(record.Votes, func(i, j int) bool {
+		return bytes.Compare(record.Votes[i].Flat[:], record.Votes[j].Flat[:]) < 0
+	})
+
+	h := sha256.New()
+	if err := json.NewEncoder(h).Encode(record); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type flatVote struct {
+	Number string
+	Area   float64
+	Option string
+	At     time.Time
+}
+
+type ballotReportInput struct {
+	Asset     models.Asset
+	Ballot    models.Ballot
+	Tally     Tally
+	Votes     []flatVote
+	CountedBy string
+}
+
+func ballotReportData(asset models.Asset, ballot models.Ballot, t Tally, flats []models.Flat, votes []models.Vote, countedBy string) ballotReportInput {
+	byID := make(map[uuid.UUID]models.Flat, len(flats))
+	for _, f := range flats {
+		byID[f.ID] = f
+	}
+
+	d := ballotReportInput{Asset: asset, Ballot: ballot, Tally: t, CountedBy: countedBy}
+	for _, v := range votes {
+		f, ok := byID[v.Flat]
+		if !ok || v.Option < 0 || v.Option >= len(ballot.Options) {
+			continue
+		}
+		d.Votes = append(d.Votes, flatVote{f.Number, f.Area, ballot.Options[v.Option], v.CreatedAt})
+	}
+	return d
+}
+
+var ballotReport = texDocument("ballot", "11pt", "margin=2cm", template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04 MST")
+	},
+	"percent": func(share float64) string {
+		return fmt.Sprintf("%.2f\\%%", share*100)
+	},
+	"deref": func(i *int) int { return *i },
+	"area": func(a float64) string {
+		return fmt.Sprintf("%.2f", a)
+	},
+	"asset_address": contract.AssetAddress,
+}, `
+\section*{Result of the residents' vote}
+
+\begin{tabular}{ll}
+Building & << tex (asset_address .Asset.Address) >> \\
+Cadastre & << tex .Asset.Cadastre >> \\
+Question & << tex .Ballot.Question >> \\
+Votes weighted by & << if eq (print .Ballot.Basis) "area" >>area of the flats<< else >>flats<< end >> \\
+Quorum & << percent .Ballot.Quorum >> \\
+Majority & more than << percent .Ballot.Majority >> of the votes cast \\
+Deadline & << date .Ballot.Deadline >> \\
+\end{tabular}
+
+\subsection*{Result}
+\begin{tabular}{lrrr}
+Option & Flats & Area, m\textsuperscript{2} & Share \\
+\hline
+<<- range .Tally.Options >>
+<< tex .Option >> & << .Flats >> & << area .Area >> & << percent .Share >> \\
+<<- end >>
+\hline
+Voted & << .Tally.VotedFlats >> & << area .Tally.VotedArea >> & \\
+Eligible & << .Tally.EligibleFlats >> & << area .Tally.EligibleArea >> & \\
+All flats & << .Tally.TotalFlats >> & << area .Tally.TotalArea >> & \\
+\end{tabular}
+
+\medskip
+Turnout: << percent .Tally.Turnout >>, the quorum was << if not .Tally.QuorumReached >>not << end >>reached.
+
+<<- if .Tally.Winner >>
+\textbf{Decision: << tex (index .Ballot.Options (deref .Tally.Winner)) >>.}
+<<- else >>
+\textbf{No option reached the required majority.}
+<<- end >>
+
+\subsection*{Votes}
+<<- if .Votes >>
+\begin{longtable}{lrll}
+Flat & Area, m\textsuperscript{2} & Vote & Cast on \\
+\hline
+<<- range .Votes >>
+<< tex .Number >> & << area .Area >> & << tex .Option >> & << date .At >> \\
+<<- end >>
+\end{longtable}
+<<- else >>
+No votes were cast.
+<<- end >>
+
+\subsection*{Certification}
+Counted by << tex .CountedBy >> on << with .Ballot.ClosedAt >><< date . >><< end >>.
+
+The SHA-256 digest of the ballot and its votes is:\\
+\texttt{<< .Ballot.Digest >>}
+
+\vspace{2cm}
+\begin{tabular}{p{7cm}p{7cm}}
+\hrulefill & \hrulefill \\
+Chair of the residents & Counted by \\
+\end{tabular}
+`)
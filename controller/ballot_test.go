@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestTally(t *testing.T) {
+	var (
+		now      = time.Now()
+		resident = uuid.New()
+		flats    = make([]models.Flat, 5)
+	)
+	for i := range flats {
+		flats[i] = models.Flat{
+			Value:      models.Value{ID: uuid.New()},
+			Area:       float64(10 * (i + 1)),
+			Resident:   &resident,
+			VerifiedAt: &now,
+		}
+	}
+	// the last flat has no verified resident and does not vote
+	flats[4].VerifiedAt = nil
+
+	votes := []models.Vote{
+		{Flat: flats[0].ID, Option: 0},
+		{Flat: flats[1].ID, Option: 0},
+		{Flat: flats[3].ID, Option: 1},
+	}
+
+	cases := []struct {
+		name    string
+		ballot  models.Ballot
+		turnout float64
+		quorum  bool
+		winner  *int
+	}{
+		{
+			name:    "flats",
+			ballot:  models.Ballot{Options: []string{"yes", "no"}, Basis: models.BallotBasisFlats, Quorum: 0.5, Majority: 0.5},
+			turnout: 0.6,
+			quorum:  true,
+			winner:  new(int),
+		},
+		{
+			// yes: 10 + 20 m², no: 40 m² of 150 m²
+			name:    "area",
+			ballot:  models.Ballot{Options: []string{"yes", "no"}, Basis: models.BallotBasisArea, Quorum: 0.4, Majority: 0.5},
+			turnout: 70.0 / 150,
+			quorum:  true,
+			winner:  func() *int { i := 1; return &i }(),
+		},
+		{
+			// the flat without a verified resident counts against the quorum
+			name:    "no quorum",
+			ballot:  models.Ballot{Options: []string{"yes", "no"}, Basis: models.BallotBasisFlats, Quorum: 0.7, Majority: 0.5},
+			turnout: 0.6,
+		},
+		{
+			name:    "no majority",
+			ballot:  models.Ballot{Options: []string{"yes", "no"}, Basis: models.BallotBasisFlats, Quorum: 0.5, Majority: 0.7},
+			turnout: 0.6,
+			quorum:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := tally(c.ballot, flats, votes)
+			if got.EligibleFlats != 4 || got.TotalFlats != 5 || got.VotedFlats != 3 {
+				t.Errorf("Expected 3 of 4 eligible of 5 flats voted, got %d of %d of %d", got.VotedFlats, got.EligibleFlats, got.TotalFlats)
+			}
+			if got.Turnout < c.turnout-1e-9 || got.Turnout > c.turnout+1e-9 {
+				t.Errorf("Expected turnout %v, got %v", c.turnout, got.Turnout)
+			}
+			if got.QuorumReached != c.quorum {
+				t.Errorf("Expected quorum reached %v, got %v", c.quorum, got.QuorumReached)
+			}
+			switch {
+			case c.winner == nil && got.Winner != nil:
+				t.Errorf("Expected no winner, got %d", *got.Winner)
+			case c.winner != nil && (got.Winner == nil || *got.Winner != *c.winner):
+				t.Errorf("Expected winner %d, got %v", *c.winner, got.Winner)
+			}
+		})
+	}
+}
+
+func TestValidBallot(t *testing.T) {
+	now := time.Now()
+	valid := models.Ballot{
+		Question: "Renovate the building?",
+		Options:  []string{"yes", "no"},
+		Basis:    models.BallotBasisArea,
+		Quorum:   0.5,
+		Majority: 0.5,
+		Deadline: now.Add(time.Hour),
+	}
+	if err := validBallot(valid, now); err != nil {
+		t.Fatalf("Expected valid ballot, got %v", err)
+	}
+
+	for name, change := range map[string]func(*models.Ballot){
+		"no question":     func(b *models.Ballot) { b.Question = " " },
+		"one option":      func(b *models.Ballot) { b.Options = b.Options[:1] },
+		"repeated option": func(b *models.Ballot) { b.Options = []string{"yes", "yes"} },
+		"bad basis":       func(b *models.Ballot) { b.Basis = "votes" },
+		"no quorum":       func(b *models.Ballot) { b.Quorum = 0 },
+		"low majority":    func(b *models.Ballot) { b.Majority = 0.3 },
+		"past deadline":   func(b *models.Ballot) { b.Deadline = now.Add(-time.Hour) },
+	} {
+		b := valid
+		change(&b)
+		if err := validBallot(b, now); !errors.Is(err, ErrBadInput) {
+			t.Errorf("%s: expected ErrBadInput, got %v", name, err)
+		}
+	}
+}
+
+func TestBallotDigest(t *testing.T) {
+	closed := time.Date(2020, time.October, 19, 10, 0, 0, 0, time.UTC)
+	ballot := models.Ballot{
+		Value:    models.Value{ID: uuid.New()},
+		Question: "Renovate the building?",
+		Options:  []string{"yes", "no"},
+		ClosedAt: &closed,
+	}
+	votes := []models.Vote{
+		{Flat: uuid.New(), Option: 0},
+		{Flat: uuid.New(), Option: 1},
+	}
+
+	d1, err := ballotDigest(ballot, votes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, _ := ballotDigest(ballot, []models.Vote{votes[1], votes[0]})
+	if d1 != d2 || len(d1) != 64 {
+		t.Errorf("Expected the same digest regardless of the order of the votes, got %s and %s", d1, d2)
+	}
+
+	votes[0].Option = 1
+	if d3, _ := ballotDigest(ballot, votes); d3 == d1 {
+		t.Error("Expected the digest to change with the votes")
+	}
+}
+
+func TestBallotReport(t *testing.T) {
+	var (
+		now    = time.Date(2020, time.October, 19, 10, 0, 0, 0, time.UTC)
+		flat   = models.Flat{Value: models.Value{ID: uuid.New()}, Number: "12", Area: 54.3}
+		ballot = models.Ballot{
+			Question: "Renovate & insulate?",
+			Options:  []string{"yes", "no"},
+			Basis:    models.BallotBasisArea,
+			Quorum:   0.5,
+			Majority: 0.5,
+			Deadline: now,
+			ClosedAt: &now,
+			Digest:   "abc",
+		}
+		votes = []models.Vote{{Value: models.Value{CreatedAt: now}, Flat: flat.ID, Option: 0}}
+	)
+	flat.Resident, flat.VerifiedAt = &flat.ID, &now
+	d := ballotReportData(models.Asset{Address: `{"postcode": "LV-1010", "streetAddress": "Brīvības iela 10", "city": "Rīga", "country": "Latvia"}`}, ballot,
+		tally(ballot, []models.Flat{flat}, votes), []models.Flat{flat}, votes, "Jānis")
+
+	var buf bytes.Buffer
+	if err := ballotReport.Execute(&buf, d); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	tex := buf.String()
+
+	for _, want := range []string{
+		`Building & "LV-1010 Brīvības iela 10 Rīga Latvia" \\`,
+		`Question & Renovate \& insulate? \\`,
+		`Votes weighted by & area of the flats \\`,
+		`yes & 1 & 54.30 & 100.00\% \\`,
+		`\textbf{Decision: yes.}`,
+		`12 & 54.30 & yes & 2020-10-19 10:00 UTC \\`,
+		`Counted by Jānis on 2020-10-19 10:00 UTC.`,
+	} {
+		if !strings.Contains(tex, want) {
+			t.Errorf("Expected %q in the report, got:\n%s", want, tex)
+		}
+	}
+}
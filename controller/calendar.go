@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/ical"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+
+	"github.com/google/uuid"
+	"github.com/matcornic/hermes/v2"
+)
+
+// meetingDuration is the length of the meetings in calendars as their end
+// is not recorded.
+const meetingDuration = time.Hour
+
+// reminderLead is how long before the next contact of a meeting its host is
+// reminded of it.
+const reminderLead = 24 * time.Hour
+
+// feedPeriod is how far back the calendar feeds go.
+const feedPeriod = 365 * 24 * time.Hour
+
+// event returns the calendar event of the meeting.
+func (m *Meeting) event(meet models.Meeting) ical.Event {
+	e := ical.Event{
+		UID:         fmt.Sprintf("%s@%s", meet.ID, m.host()),
+		Sequence:    meet.Sequence,
+		Stamp:       meet.UpdatedAt,
+		Start:       meet.Date,
+		End:         meet.Date.Add(meetingDuration),
+		Summary:     meet.Name,
+		Location:    meet.Location,
+		Description: meet.Objective,
+		Status:      ical.StatusConfirmed,
+	}
+	if meet.DeletedAt != nil {
+		e.Status = ical.StatusCancelled
+	}
+	if h := meet.HostEntity; h != nil && h.Email != "" {
+		e.Organizer = &mail.Address{Name: h.Name, Address: h.Email}
+	}
+	for _, g := range meet.Guests {
+		if g.Email != "" {
+			e.Attendees = append(e.Attendees, mail.Address{Name: g.Name, Address: g.Email})
+		}
+	}
+	return e
+}
+
+// host returns the host name of the platform, which makes the IDs of the
+// events unique.
+func (m *Meeting) host() string {
+	if u, err := url.Parse(m.url); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "sunshine"
+}
+
+// invite emails the guests an invitation to the meeting, an update or a
+// cancellation of it depending on the method.
+func (m *Meeting) invite(meet models.Meeting, method ical.Method, guests []models.MeetingGuest) {
+	var to []mail.Address
+	for _, g := range guests {
+		if g.Email != "" {
+			to = append(to, mail.Address{Name: g.Name, Address: g.Email})
+		}
+	}
+	if len(to) == 0 {
+		return
+	}
+
+	e := m.event(meet)
+	subject := "Invitation: " + meet.Name
+	intro := fmt.Sprintf("You are invited to %s on %s.", meet.Name, meet.Date.UTC().Format(time.RFC1123))
+	switch {
+	case method == ical.MethodCancel:
+		e.Status = ical.StatusCancelled
+		subject = "Cancelled: " + meet.Name
+		intro = fmt.Sprintf("%s on %s has been cancelled.", meet.Name, meet.Date.UTC().Format(time.RFC1123))
+	case meet.Sequence > 0:
+		subject = "Updated invitation: " + meet.Name
+		intro = fmt.Sprintf("%s has been changed and will take place on %s.", meet.Name, meet.Date.UTC().Format(time.RFC1123))
+	}
+
+	intros := []string{intro}
+	if meet.Location != "" {
+		intros = append(intros, "Location: "+meet.Location)
+	}
+	if meet.Objective != "" {
+		intros = append(intros, "Objective: "+meet.Objective)
+	}
+
+	cal := ical.Calendar{Method: method, Events: []ical.Event{e}}
+	err := m.mailer.Send(to, subject,
+		hermes.Email{Body: hermes.Body{Intros: intros}},
+		services.Attachment{
+			Filename:    "invite.ics",
+			ContentType: cal.ContentType(),
+			Content:     []byte(cal.String()),
+		},
+	)
+	log.Printf("Sending %s of meeting %s to %d guests: %v", strings.ToLower(string(method)), meet.ID, len(to), err)
+}
+
+// removedGuests returns the guests in old who are not in new.
+func removedGuests(old, new []models.MeetingGuest) []models.MeetingGuest {
+	kept := make(map[string]bool, len(new))
+	for _, g := range new {
+		kept[strings.ToLower(g.Email)] = true
+	}
+
+	var result []models.MeetingGuest
+	for _, g := range old {
+		if !kept[strings.ToLower(g.Email)] {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// Calendar returns the calendar with the meeting.
+func (m *Meeting) Calendar(ctx context.Context, id uuid.UUID) (*ical.Calendar, error) {
+	doc, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	meet := doc.Data.(*models.Meeting)
+	return &ical.Calendar{
+		Method: ical.MethodPublish,
+		Events: []ical.Event{m.event(*meet)},
+	}, nil
+}
+
+// CalendarFeed returns a new URL of the calendar feed with the meetings of
+// the user. The URLs returned before stop working.
+func (m *Meeting) CalendarFeed(ctx context.Context) (string, error) {
+	cv := services.FromContext(ctx)
+	if !cv.Authorized() {
+		return "", ErrUnauthorized
+	}
+
+	err := m.st.DB().
+		Where("user_id = ? AND purpose = ?", cv.User.ID, models.CalendarToken).
+		Delete(&models.Token{}).Error
+	if err != nil {
+		return "", err
+	}
+
+	token, err := m.tokens.Create(ctx, models.CalendarToken, cv.User.ID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/calendar/%s.ics", m.url, token.ID), nil
+}
+
+// Feed returns the calendar with the meetings of the organizations and the
+// projects of the user with the token and the ones the user is a guest of.
+func (m *Meeting) Feed(ctx context.Context, token uuid.UUID) (*ical.Calendar, error) {
+	t, err := m.tokens.Get(ctx, models.CalendarToken, token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	var u models.User
+	if err := m.st.DB().Where("id = ?", t.UserID).First(&u).Error; err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	var meetings []models.Meeting
+	err = m.st.DB().
+		Preload("HostEntity").
+		Preload("Guests").
+		Where(`host IN (SELECT organization_id FROM organization_roles WHERE user_id = ?)
+			OR project IN (SELECT project_id FROM project_roles WHERE user_id = ?)
+			OR id IN (SELECT meeting_id FROM meeting_guests WHERE lower(email) = lower(?) AND deleted_at IS NULL)`,
+			u.ID, u.ID, u.Email).
+		Where("date > ?", time.Now().Add(-feedPeriod)).
+		Order("date").
+		Find(&meetings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	cal := ical.Calendar{Name: "Sunshine meetings", Method: ical.MethodPublish}
+	for _, meet := range meetings {
+		cal.Events = append(cal.Events, m.event(meet))
+	}
+	return &cal, nil
+}
+
+// RemindNextContact emails the users of the hosts of the meetings whose next
+// contact is due within reminderLead and returns how many meetings they were
+// reminded of.
+func (m *Meeting) RemindNextContact(now time.Time) (int, error) {
+	var meetings []models.Meeting
+	err := m.st.DB().
+		Where("next_contact BETWEEN ? AND ?", now, now.Add(reminderLead)).
+		Where("reminded_at IS NULL OR reminded_at < next_contact - ? * INTERVAL '1 second'", reminderLead.Seconds()).
+		Find(&meetings).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, meet := range meetings {
+		var to []mail.Address
+		err := m.st.DB().Table("users u").
+			Select("u.name, u.email AS address").
+			Joins("JOIN organization_roles r ON r.user_id = u.id").
+			Where("r.organization_id = ? AND u.deleted_at IS NULL AND u.is_active", meet.Host).
+			Scan(&to).Error
+		if err != nil {
+			return n, err
+		}
+
+		if len(to) > 0 {
+			err = m.mailer.Send(to, "Reminder: next contact for "+meet.Name,
+				hermes.Email{Body: hermes.Body{Intros: []string{
+					fmt.Sprintf("The next contact for %s is due on %s.", meet.Name, meet.NextContact.UTC().Format(time.RFC1123)),
+				}}},
+			)
+			if err != nil {
+				log.Printf("remind of next contact of meeting %v: %v", meet.ID, err)
+				continue
+			}
+		}
+
+		if err := m.st.DB().Model(&meet).UpdateColumn("reminded_at", now).Error; err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
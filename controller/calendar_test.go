@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/ical"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestMeetingEvent(t *testing.T) {
+	m := &Meeting{url: "https://sunshine.example"}
+	meet := models.Meeting{
+		Value:      models.Value{ID: uuid.New()},
+		Name:       "Kick-off",
+		Date:       time.Date(2020, time.October, 19, 10, 0, 0, 0, time.UTC),
+		Sequence:   3,
+		HostEntity: &models.Organization{Name: "Renesco", Email: "info@renesco.example"},
+		Guests: []models.MeetingGuest{
+			{Name: "Jānis", Email: "janis@example.com"},
+			{Name: "No email"},
+		},
+	}
+
+	e := m.event(meet)
+	if e.UID != meet.ID.String()+"@sunshine.example" {
+		t.Errorf("Unexpected UID %q", e.UID)
+	}
+	if e.Sequence != 3 || !e.End.Equal(meet.Date.Add(meetingDuration)) || e.Status != ical.StatusConfirmed {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	if e.Organizer == nil || e.Organizer.Address != "info@renesco.example" {
+		t.Errorf("Expected the host as organizer, got %v", e.Organizer)
+	}
+	if len(e.Attendees) != 1 {
+		t.Errorf("Expected only the guests with email as attendees, got %v", e.Attendees)
+	}
+}
+
+func TestRemovedGuests(t *testing.T) {
+	old := []models.MeetingGuest{{Email: "a@example.com"}, {Email: "B@example.com"}}
+	new := []models.MeetingGuest{{Email: "b@example.com"}, {Email: "c@example.com"}}
+
+	removed := removedGuests(old, new)
+	if len(removed) != 1 || removed[0].Email != "a@example.com" {
+		t.Errorf("Expected a@example.com to be removed, got %v", removed)
+	}
+}
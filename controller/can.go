@@ -82,6 +82,9 @@ const (
 	DownloadAssetFile Action = superuser | pfm | anm | lear | lsigns | leaas | members | pd | ca
 	ValidateAsset     Action = superuser | pfm | anm | ca
 
+	// residents' ballots
+	ManageBallots Action = superuser | pfm | anm | lear | leaas | lsigns | ca
+
 	// project actions
 	CreateProject       Action = logged
 	ListProjects        Action = logged
@@ -95,6 +98,8 @@ const (
 	ChangeFundManager   Action = superuser | pfm | anm | pd | ca
 	AssignPM            Action = superuser | pfm | anm | pm | plsign | ca
 	CommentProject      Action = superuser | pfm | anm | pm | paco | plsign | tama | fm | ca
+	ModerateComments    Action = superuser | pfm | anm | pm | ca
+	GetProjectSavings   Action = superuser | pfm | anm | pm | paco | plsign | tama | teme | pd | lear | investor | fm | ca
 
 	// contract actions
 	DownloadProjectContract     Action = superuser | pfm | anm | pm | paco | plsign | tama | teme | pd | lear | fm | ca
@@ -107,10 +112,12 @@ const (
 	UpdateProjectContractTable  Action = superuser | pfm | anm | pm | paco | plsign | tama | teme | fm | ca
 	UpdateProjectMaintenance    Action = superuser | pfm | anm | pm | paco | plsign | fm | ca
 	GetProjectMaintenance       Action = superuser | pfm | anm | pm | paco | plsign | tama | teme | pd | lear | fm | ca
+	SetProjectContractTemplate  Action = superuser | pfm | anm | pm | ca
 
 	// indoor clima actions
 	GetProjectIndoorClima    Action = superuser | pfm | anm | pm | paco | plsign | tama | teme | pd | ca
 	UpdateProjectIndoorClima Action = superuser | pfm | anm | pm | paco | plsign | tama | teme | ca
+	FillProjectWeatherData   Action = superuser | pfm | anm | pm | paco | plsign | fm | ca
 
 	// milestones
 	AdvanceProjectToWorkPhase       Action = superuser | pfm | anm | pd | pm | ca
@@ -148,8 +155,9 @@ const (
 	UpdateFP           Action = UpdateFA
 
 	// gdpr
-	GetGDPRRequest   Action = superuser | pfm | anm | dpo
-	ListGDPRRequests Action = superuser | pfm | anm | dpo
+	GetGDPRRequest    Action = superuser | pfm | anm | dpo
+	ListGDPRRequests  Action = superuser | pfm | anm | dpo
+	ReviewGDPRRequest Action = superuser | pfm | anm | dpo
 
 	// portfolio
 	AddPortfolioRole      Action = superuser | pfm | anm
@@ -163,6 +171,40 @@ const (
 	addEurobor Action = superuser | anm | pfm
 	SetVat     Action = superuser | anm | pfm
 	GetCountry Action = superuser | anm | pfm
+
+	// energy factors
+	SetEnergyFactor   Action = superuser | anm | pfm | ca
+	ListEnergyFactors Action = logged
+
+	// weather data
+	ManageWeatherData Action = superuser | anm | pfm | ca
+
+	// contract templates
+	ManageContractTemplates Action = superuser | anm | pfm | ca
+
+	// duplicates
+	ManageDuplicates Action = superuser | anm | pfm | ca
+
+	// recycle bin
+	ManageRecycleBin Action = superuser | anm | pfm
+
+	// review chains and checklists of forfaiting applications
+	ManageReviewChains Action = superuser | anm | pfm | ca
+	ManageFAChecklists Action = superuser | anm | pfm | ca
+
+	// investor data rooms
+	ManageDataRoom Action = superuser | pfm | anm | pm | pd | fm | ca
+
+	// download logs
+	ListProjectDownloads Action = superuser | pfm | anm | pm | dpo | ca
+	ListUserDownloads    Action = superuser | pfm | anm | dpo | self
+
+	// watermarks of downloaded PDFs
+	ManageWatermarks Action = superuser | pfm | anm | pm | ca
+
+	// scheduled reports and the reporting views
+	ManageReports    Action = superuser | pfm | anm | ca
+	RefreshReporting Action = superuser | pfm | anm
 )
 
 func roleAction(u models.User, target uuid.UUID, country models.Country) Action {
@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"stageai.tech/sunshine/sunshine/bankaccount"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// DefaultChecklist is the due-diligence checklist of the forfaiting
+// applications of the countries without one of their own.
+var DefaultChecklist = []models.FAChecklistItem{
+	{Name: "Signed EPC", UploadTypes: []string{"signed epc", "epc contracts"}, Required: true},
+	{Name: "Energy audit", UploadTypes: []string{"energy audit report"}, Required: true},
+	{Name: "Residents' decision", UploadTypes: []string{"residents vote result", "commitment protocol meeting", "commitment protocol survey"}, Required: true},
+	{Name: "Bank confirmation", UploadTypes: []string{"fa bank confirmation"}, Required: true},
+	{Name: "Financial statements", UploadTypes: []string{"fa financial statements"}, Required: true},
+	{Name: "Insurance policies", UploadTypes: []string{"insurance policies"}},
+	{Name: "Bank account", DataPoint: dataPoint(models.FADataBankAccount), Required: true},
+	{Name: "Beneficiary", DataPoint: dataPoint(models.FADataBeneficiary), Required: true},
+	{Name: "Forfaiting manager", DataPoint: dataPoint(models.FADataManager), Required: true},
+	{Name: "Fund manager", DataPoint: dataPoint(models.FADataFundManager)},
+}
+
+// ChecklistStatus is an item of the due-diligence checklist and whether the
+// application meets it.
+type ChecklistStatus struct {
+	models.FAChecklistItem
+
+	Done bool
+
+	// Attachments are the files meeting the document item.
+	Attachments []models.Attachment
+}
+
+// Checklist is the due-diligence checklist of a forfaiting application.
+type Checklist struct {
+	ForfaitingApplication uuid.UUID
+	Items                 []ChecklistStatus
+
+	// Score is the weighted share of the items done, from 0 to 1.
+	Score float64
+
+	// Complete reports whether the required items are done, Missing are the
+	// names of those which are not.
+	Complete bool
+	Missing  []string
+}
+
+// Checklist returns the due-diligence checklist of the forfaiting
+// application, checked against the files attached to its project, its
+// building, its work and monitoring phases and the application itself.
+func (f *ForfaitingAgreement) Checklist(ctx context.Context, id uuid.UUID) (*Checklist, error) {
+	fa, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	prjd, err := f.st.FromKind("project").Get(ctx, fa.Project)
+	if err != nil {
+		return nil, err
+	}
+	prj := prjd.Data.(*models.Project)
+
+	items, err := f.checklist(prj.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []string
+	for _, item := range items {
+		types = append(types, item.UploadTypes...)
+	}
+
+	// the results of the residents' ballots without a project are attached
+	// to the building
+	owners, err := attachmentOwners(f.st.DB(), *prj)
+	if err != nil {
+		return nil, err
+	}
+
+	var atts []models.Attachment
+	if len(types) > 0 {
+		err = f.st.DB().
+			Where("owner_id IN (?) AND upload_type IN (?)", owners, types).
+			Order("created_at DESC").
+			Find(&atts).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c := check(items, atts, dataPoints(*fa, *prj))
+	c.ForfaitingApplication = fa.ID
+	return &c, nil
+}
+
+// ChecklistItems returns the due-diligence checklist of the forfaiting
+// applications of the country.
+func (f *ForfaitingAgreement) ChecklistItems(ctx context.Context, country models.Country) ([]models.FAChecklistItem, error) {
+	if !Can(ctx, ListFAByCountry|ManageFAChecklists, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+	return f.checklist(country)
+}
+
+// SetChecklist replaces the due-diligence checklist of the forfaiting
+// applications of the country. No items restore the default checklist.
+func (f *ForfaitingAgreement) SetChecklist(ctx context.Context, country models.Country, items []models.FAChecklistItem) ([]models.FAChecklistItem, error) {
+	if err := country.Valid(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+	if !Can(ctx, ManageFAChecklists, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+	if err := validChecklist(items); err != nil {
+		return nil, err
+	}
+
+	// start transaction block
+	tx := f.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("country = ?", country).Delete(&models.FAChecklistItem{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].ID = uuid.Nil
+		items[i].Country = country
+		items[i].Position = i + 1
+		if items[i].Weight == 0 {
+			items[i].Weight = 1
+		}
+		if err := tx.Create(&items[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	// end transaction block
+
+	return f.checklist(country)
+}
+
+// checklist returns the checklist of the country, or the default one.
+func (f *ForfaitingAgreement) checklist(country models.Country) ([]models.FAChecklistItem, error) {
+	var items []models.FAChecklistItem
+	err := f.st.DB().
+		Where("country = ?", country).
+		Order("position").
+		Find(&items).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+	if len(items) == 0 {
+		items = make([]models.FAChecklistItem, len(DefaultChecklist))
+		copy(items, DefaultChecklist)
+		for i := range items {
+			items[i].Country = country
+			items[i].Position = i + 1
+			items[i].Weight = 1
+		}
+	}
+	return items, nil
+}
+
+// validChecklist checks that each item has a name and is either a document
+// of known upload types or a known data point.
+func validChecklist(items []models.FAChecklistItem) error {
+	for _, item := range items {
+		if strings.TrimSpace(item.Name) == "" {
+			return fmt.Errorf("%w: checklist item without a name", ErrBadInput)
+		}
+		if item.Weight < 0 {
+			return fmt.Errorf("%w: %s: negative weight", ErrBadInput, item.Name)
+		}
+		if (len(item.UploadTypes) > 0) == (item.DataPoint != nil) {
+			return fmt.Errorf("%w: %s: either upload types or a data point", ErrBadInput, item.Name)
+		}
+		for _, t := range item.UploadTypes {
+			if _, ok := models.UploadTypes[t]; !ok {
+				return fmt.Errorf("%w: %s: unknown upload type %q", ErrBadInput, item.Name, t)
+			}
+		}
+		if item.DataPoint != nil {
+			if _, ok := dataPointNames[*item.DataPoint]; !ok {
+				return fmt.Errorf("%w: %s: unknown data point %q", ErrBadInput, item.Name, *item.DataPoint)
+			}
+		}
+	}
+	return nil
+}
+
+var dataPointNames = map[models.FADataPoint]struct{}{
+	models.FADataBankAccount: {},
+	models.FADataBeneficiary: {},
+	models.FADataManager:     {},
+	models.FADataFundManager: {},
+}
+
+// dataPoints returns which data points of the application are filled in.
+func dataPoints(fa models.ForfaitingApplication, prj models.Project) map[models.FADataPoint]bool {
+	ba := fa.BankAccount
+	return map[models.FADataPoint]bool{
+		models.FADataBankAccount: len(bankaccount.Validate(ba.IBAN, ba.SWIFT)) == 0,
+		models.FADataBeneficiary: strings.TrimSpace(ba.BeneficiaryName) != "" && strings.TrimSpace(ba.BankNameAddress) != "",
+		models.FADataManager:     fa.ManagerID != uuid.Nil,
+		models.FADataFundManager: prj.FundManager != nil,
+	}
+}
+
+// check checks the items against the attachments and the data points and
+// scores the completeness of the dossier.
+func check(items []models.FAChecklistItem, atts []models.Attachment, data map[models.FADataPoint]bool) Checklist {
+	byType := make(map[models.UploadType][]models.Attachment)
+	for _, a := range atts {
+		byType[a.UploadType] = append(byType[a.UploadType], a)
+	}
+
+	var (
+		c           = Checklist{Items: make([]ChecklistStatus, len(items)), Complete: true}
+		done, total int
+	)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Position < items[j].Position })
+	for i, item := range items {
+		s := ChecklistStatus{FAChecklistItem: item}
+		for _, t := range item.UploadTypes {
+			s.Attachments = append(s.Attachments, byType[models.UploadType(t)]...)
+		}
+		if item.DataPoint != nil {
+			s.Done = data[*item.DataPoint]
+		} else {
+			s.Done = len(s.Attachments) > 0
+		}
+
+		weight := item.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		total += weight
+		if s.Done {
+			done += weight
+		} else if item.Required {
+			c.Complete = false
+			c.Missing = append(c.Missing, item.Name)
+		}
+		c.Items[i] = s
+	}
+
+	if total > 0 {
+		c.Score = float64(done) / float64(total)
+	}
+	return c
+}
+
+func dataPoint(d models.FADataPoint) *models.FADataPoint {
+	return &d
+}
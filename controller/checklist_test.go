@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+func TestCheck(t *testing.T) {
+	items := []models.FAChecklistItem{
+		{Position: 1, Name: "Signed EPC", UploadTypes: []string{"signed epc", "epc contracts"}, Required: true, Weight: 2},
+		{Position: 2, Name: "Energy audit", UploadTypes: []string{"energy audit report"}, Required: true, Weight: 1},
+		{Position: 3, Name: "Insurance policies", UploadTypes: []string{"insurance policies"}, Weight: 1},
+		{Position: 4, Name: "Bank account", DataPoint: dataPoint(models.FADataBankAccount), Required: true, Weight: 1},
+		{Position: 5, Name: "Fund manager", DataPoint: dataPoint(models.FADataFundManager), Weight: 1},
+	}
+	atts := []models.Attachment{
+		{Name: "epc.pdf", UploadType: "epc contracts"},
+		{Name: "policy.pdf", UploadType: "insurance policies"},
+		{Name: "leaflet.pdf", UploadType: "general leaflet"},
+	}
+	data := map[models.FADataPoint]bool{models.FADataBankAccount: true}
+
+	c := check(items, atts, data)
+	if c.Complete {
+		t.Errorf("Expected the dossier incomplete without the energy audit")
+	}
+	if len(c.Missing) != 1 || c.Missing[0] != "Energy audit" {
+		t.Errorf("Expected only the energy audit missing, got %v", c.Missing)
+	}
+	if c.Score != 4.0/6 {
+		t.Errorf("Expected score 4/6, got %v", c.Score)
+	}
+	if !c.Items[0].Done || len(c.Items[0].Attachments) != 1 || c.Items[0].Attachments[0].Name != "epc.pdf" {
+		t.Errorf("Expected the signed EPC done by epc.pdf, got %v", c.Items[0])
+	}
+	if c.Items[4].Done {
+		t.Errorf("Expected no fund manager")
+	}
+
+	atts = append(atts, models.Attachment{Name: "audit.pdf", UploadType: "energy audit report"})
+	if c := check(items, atts, data); !c.Complete || len(c.Missing) != 0 {
+		t.Errorf("Expected the dossier complete, got %v missing", c.Missing)
+	}
+}
+
+func TestValidChecklist(t *testing.T) {
+	cases := []struct {
+		name  string
+		items []models.FAChecklistItem
+		err   error
+	}{
+		{name: "default", items: DefaultChecklist},
+		{name: "no name", items: []models.FAChecklistItem{{UploadTypes: []string{"signed epc"}}}, err: ErrBadInput},
+		{name: "unknown upload type", items: []models.FAChecklistItem{{Name: "EPC", UploadTypes: []string{"epc"}}}, err: ErrBadInput},
+		{name: "nothing to check", items: []models.FAChecklistItem{{Name: "EPC"}}, err: ErrBadInput},
+		{
+			name: "both",
+			items: []models.FAChecklistItem{{
+				Name:        "EPC",
+				UploadTypes: []string{"signed epc"},
+				DataPoint:   dataPoint(models.FADataManager),
+			}},
+			err: ErrBadInput,
+		},
+		{
+			name:  "unknown data point",
+			items: []models.FAChecklistItem{{Name: "Owner", DataPoint: dataPoint("owner")}},
+			err:   ErrBadInput,
+		},
+	}
+	for _, c := range cases {
+		if err := validChecklist(c.items); !errors.Is(err, c.err) {
+			t.Errorf("%s: expected error %v, got %v", c.name, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,832 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Comment handles the comment threads of the projects, about the project
+// itself or its work phase, forfaiting reviews and contract tables and
+// fields. Threads on a contract field or table cell may suggest a change to
+// it, applied as a normal update of the contract once accepted.
+type Comment struct {
+	st         stores.Store
+	notifier   stores.Notifier
+	contract   *Contract
+	uploadPath string
+	validator  *validator.Validate
+}
+
+func NewComment(env *services.Env) *Comment {
+	return &Comment{
+		st:         env.ProjectStore,
+		notifier:   env.Notifier,
+		contract:   NewContract(env),
+		uploadPath: env.Paths.Uploads,
+		validator:  env.Validator,
+	}
+}
+
+// Post adds the comment to the project with the files attached to it. A
+// comment with a parent replies to its thread and reopens it if it is
+// resolved. The mentioned members and those taking part in the thread are
+// notified.
+func (c *Comment) Post(ctx context.Context, projectID uuid.UUID, cmt models.ProjectComment, mentions []uuid.UUID, files []Upload) (*models.ProjectComment, error) {
+	prj, err := c.project(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !Can(ctx, CommentProject, prj.ID, prj.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	var thread []models.ProjectComment
+	if cmt.Parent != nil {
+		if cmt.Suggestion != nil {
+			return nil, fmt.Errorf("%w: changes are suggested in the first comment of a thread", ErrBadInput)
+		}
+		root, err := c.get(*cmt.Parent)
+		if err != nil {
+			return nil, err
+		}
+		if root.ProjectID != prj.ID || root.Parent != nil {
+			return nil, fmt.Errorf("%w: replies go to the first comment of a thread of the project", ErrBadInput)
+		}
+		if thread, err = c.thread(*root); err != nil {
+			return nil, err
+		}
+		cmt.TargetKind, cmt.TargetID, cmt.TargetName = root.TargetKind, root.TargetID, root.TargetName
+		cmt.TargetRow, cmt.TargetColumn = root.TargetRow, root.TargetColumn
+	}
+	cmt.SuggestionStatus = ""
+	if err := validComment(&cmt); err != nil {
+		return nil, err
+	}
+	if cmt.Parent == nil {
+		if err := c.targetExists(ctx, prj.ID, cmt); err != nil {
+			return nil, err
+		}
+	}
+	cmt.SuggestionOriginal = nil
+	if cmt.Suggestion != nil {
+		orig, err := c.suggested(ctx, prj.ID, cmt)
+		if err != nil {
+			return nil, err
+		}
+		cmt.SuggestionOriginal = &orig
+	}
+
+	members, err := c.members(*prj)
+	if err != nil {
+		return nil, err
+	}
+	if cmt.Mentions, err = mentioned(members, mentions); err != nil {
+		return nil, err
+	}
+
+	cv := services.FromContext(ctx)
+	cmt.ID = uuid.Nil
+	cmt.ProjectID = prj.ID
+	cmt.UserID = cv.User.ID
+	cmt.EditedAt, cmt.ResolvedAt, cmt.ResolvedBy = nil, nil, nil
+	cmt.DecidedAt, cmt.DecidedBy = nil, nil
+
+	// start transaction block
+	tx := c.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create(&cmt).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if cmt.Parent != nil {
+		err := tx.Model(&models.ProjectComment{}).
+			Where("id = ?", *cmt.Parent).
+			Updates(map[string]interface{}{"resolved_at": nil, "resolved_by": nil}).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// the files written are removed unless the comment is saved with them
+	var written []string
+	for _, f := range files {
+		f.UploadType = "comment attachment"
+		att, err := saveUpload(tx, f, cmt.ID, c.uploadPath)
+		if err != nil {
+			tx.Rollback()
+			removeFiles(written)
+			return nil, fmt.Errorf("fail to upload file: %w", err)
+		}
+		written = append(written, filepath.Join(c.uploadPath, fmt.Sprintf("%s-%s", cmt.ID, att.ID)))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		removeFiles(written)
+		return nil, err
+	}
+	// end transaction block
+
+	c.notify(ctx, *prj, cmt, cmt.Mentions, thread)
+	return c.get(cmt.ID)
+}
+
+// Edit replaces the content and mentions of the comment, keeping what it
+// said before in its history. Only the author edits a comment and only the
+// members mentioned anew are notified.
+func (c *Comment) Edit(ctx context.Context, id uuid.UUID, content string, mentions []uuid.UUID) (*models.ProjectComment, error) {
+	cmt, prj, err := c.comment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cv := services.FromContext(ctx)
+	if cmt.UserID != cv.User.ID || !Can(ctx, CommentProject, prj.ID, prj.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	old := *cmt
+	cmt.Content = content
+	if err := validComment(cmt); err != nil {
+		return nil, err
+	}
+	members, err := c.members(*prj)
+	if err != nil {
+		return nil, err
+	}
+	if cmt.Mentions, err = mentioned(members, mentions); err != nil {
+		return nil, err
+	}
+
+	// start transaction block
+	tx := c.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	rev := models.ProjectCommentRevision{
+		CommentID: cmt.ID,
+		Content:   old.Content,
+		EditedBy:  &cv.User.ID,
+	}
+	if err := tx.Create(&rev).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	err = tx.Model(&models.ProjectComment{}).
+		Where("id = ?", cmt.ID).
+		Updates(map[string]interface{}{
+			"content":   cmt.Content,
+			"mentions":  cmt.Mentions,
+			"edited_at": now,
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	c.notify(ctx, *prj, *cmt, newMentions(old.Mentions, cmt.Mentions), nil)
+	return c.get(cmt.ID)
+}
+
+// Delete deletes the comment, along with the replies to it if it starts a
+// thread. Comments are deleted by their authors and the project managers.
+func (c *Comment) Delete(ctx context.Context, id uuid.UUID) error {
+	cmt, prj, err := c.comment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cmt.UserID != services.FromContext(ctx).User.ID && !Can(ctx, ModerateComments, prj.ID, prj.Country) {
+		return ErrUnauthorized
+	}
+
+	return c.st.DB().
+		Where("id = ? OR parent_id = ?", cmt.ID, cmt.ID).
+		Delete(&models.ProjectComment{}).Error
+}
+
+// Resolve marks the thread started by the comment as resolved or reopens
+// it.
+func (c *Comment) Resolve(ctx context.Context, id uuid.UUID, resolved bool) (*models.ProjectComment, error) {
+	cmt, prj, err := c.comment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !Can(ctx, CommentProject, prj.ID, prj.Country) {
+		return nil, ErrUnauthorized
+	}
+	if cmt.Parent != nil {
+		return nil, fmt.Errorf("%w: only threads are resolved", ErrBadInput)
+	}
+
+	upd := map[string]interface{}{"resolved_at": nil, "resolved_by": nil}
+	if resolved {
+		upd = map[string]interface{}{
+			"resolved_at": time.Now(),
+			"resolved_by": services.FromContext(ctx).User.ID,
+		}
+	}
+	err = c.st.DB().Model(&models.ProjectComment{}).Where("id = ?", cmt.ID).Updates(upd).Error
+	if err != nil {
+		return nil, err
+	}
+	return c.get(cmt.ID)
+}
+
+// Suggestions returns the open change suggestions for the contract fields or
+// tables of the project, oldest first, optionally only the ones for the
+// field or table with the name.
+func (c *Comment) Suggestions(ctx context.Context, projectID uuid.UUID, kind models.CommentTarget, name *string) ([]models.ProjectComment, error) {
+	prj, err := c.project(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !canReadComments(ctx, *prj) {
+		return nil, ErrUnauthorized
+	}
+
+	q := c.st.DB().Where("project_id = ? AND target_kind = ? AND suggestion_status = ?",
+		prj.ID, kind, models.SuggestionOpen)
+	if name != nil {
+		q = q.Where("target_name = ?", *name)
+	}
+
+	var cmts []models.ProjectComment
+	return cmts, q.Order("created_at").Find(&cmts).Error
+}
+
+// AcceptSuggestion applies the change suggested in the comment to the
+// contract, as an update of the field or table by the user, and resolves its
+// thread. Suggestions for a field or cell changed since are not accepted.
+func (c *Comment) AcceptSuggestion(ctx context.Context, id uuid.UUID) (*models.ProjectComment, error) {
+	cmt, prj, err := c.suggestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// start transaction block
+	tx := c.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// the contract is locked so that it does not change until accepted
+	err = tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("project_id = ?", prj.ID).
+		First(&contract.Contract{}).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := c.apply(ctx, stores.NewContractStore(tx, c.validator), *prj, *cmt); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := c.decide(ctx, tx, *cmt, models.SuggestionAccepted); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	// end transaction block
+
+	c.notifyDecision(ctx, *prj, *cmt, models.SuggestionAccepted)
+	return c.get(cmt.ID)
+}
+
+// apply updates the contract field or table cell with the change suggested
+// in the comment, saving the contract with st. It fails if the field or cell
+// changed since the suggestion.
+func (c *Comment) apply(ctx context.Context, st stores.Store, prj models.Project, cmt models.ProjectComment) error {
+	current, err := c.suggested(ctx, prj.ID, cmt)
+	if err != nil {
+		return err
+	}
+	if cmt.SuggestionOriginal != nil && current != *cmt.SuggestionOriginal {
+		return fmt.Errorf("%w: the %s changed since the suggestion", ErrBadInput, cmt.TargetKind)
+	}
+
+	switch cmt.TargetKind {
+	case models.CommentOnContractField:
+		fields, err := c.contract.GetFields(ctx, prj.ID)
+		if err != nil {
+			return err
+		}
+		upd := make(contract.JSONMap, len(fields)+1)
+		for k, v := range fields {
+			upd[k] = v
+		}
+		upd[cmt.TargetName] = *cmt.Suggestion
+		_, err = c.contract.updateFields(ctx, st, prj.ID, upd)
+		return err
+	case models.CommentOnContractTable:
+		vars := map[string]string{"table": cmt.TargetName}
+		t, err := c.contract.GetTable(ctx, prj.ID, vars)
+		if err != nil {
+			return err
+		}
+		upd, err := setCell(*t, *cmt.TargetRow, *cmt.TargetColumn, *cmt.Suggestion)
+		if err != nil {
+			return err
+		}
+		_, err = c.contract.updateTable(ctx, st, prj.ID, upd, vars)
+		return err
+	}
+	return nil
+}
+
+// RejectSuggestion rejects the change suggested in the comment and resolves
+// its thread. Suggestions are rejected by those who may update the contract.
+func (c *Comment) RejectSuggestion(ctx context.Context, id uuid.UUID) (*models.ProjectComment, error) {
+	cmt, prj, err := c.suggestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	action := UpdateProjectContractFields
+	if cmt.TargetKind == models.CommentOnContractTable {
+		action = UpdateProjectContractTable
+	}
+	if !Can(ctx, action, prj.ID, prj.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	if err := c.decide(ctx, c.st.DB(), *cmt, models.SuggestionRejected); err != nil {
+		return nil, err
+	}
+	c.notifyDecision(ctx, *prj, *cmt, models.SuggestionRejected)
+	return c.get(cmt.ID)
+}
+
+// Threads returns the first comments of the threads of the project, newest
+// first, optionally only the ones about the target and resolved or not.
+func (c *Comment) Threads(ctx context.Context, projectID uuid.UUID, kind *models.CommentTarget,
+	targetID *uuid.UUID, targetName *string, resolved *bool) ([]models.ProjectComment, error) {
+	prj, err := c.project(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !canReadComments(ctx, *prj) {
+		return nil, ErrUnauthorized
+	}
+
+	q := c.st.DB().Where("project_id = ? AND parent_id IS NULL", prj.ID)
+	if kind != nil {
+		q = q.Where("target_kind = ?", *kind)
+	}
+	if targetID != nil {
+		q = q.Where("target_id = ?", *targetID)
+	}
+	if targetName != nil {
+		q = q.Where("target_name = ?", *targetName)
+	}
+	if resolved != nil {
+		if *resolved {
+			q = q.Where("resolved_at IS NOT NULL")
+		} else {
+			q = q.Where("resolved_at IS NULL")
+		}
+	}
+
+	var cmts []models.ProjectComment
+	return cmts, q.Order("created_at DESC").Find(&cmts).Error
+}
+
+// Replies returns the replies to the thread started by the comment, oldest
+// first.
+func (c *Comment) Replies(ctx context.Context, cmt models.ProjectComment) ([]models.ProjectComment, error) {
+	if cmt.Parent != nil {
+		return nil, nil
+	}
+	thread, err := c.thread(cmt)
+	if err != nil {
+		return nil, err
+	}
+	return thread[1:], nil
+}
+
+// Attachments returns the files attached to the comment.
+func (c *Comment) Attachments(ctx context.Context, cmt models.ProjectComment) ([]models.Attachment, error) {
+	var atts []models.Attachment
+	return atts, c.st.DB().Where("owner_id = ?", cmt.ID).Order("created_at").Find(&atts).Error
+}
+
+// History returns what the comment said before each of its edits, oldest
+// first.
+func (c *Comment) History(ctx context.Context, cmt models.ProjectComment) ([]models.ProjectCommentRevision, error) {
+	var revs []models.ProjectCommentRevision
+	return revs, c.st.DB().Where("comment_id = ?", cmt.ID).Order("created_at").Find(&revs).Error
+}
+
+// Download opens the file attached to the comment and logs the download.
+// PDFs are stamped with the watermark of the file or of the project, if any,
+// and who downloaded them and when.
+func (c *Comment) Download(ctx context.Context, id uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
+	cmt, prj, err := c.comment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canReadComments(ctx, *prj) {
+		return nil, nil, ErrUnauthorized
+	}
+
+	var att models.Attachment
+	if err := c.st.DB().Where("owner_id = ? AND name = ?", cmt.ID, filename).First(&att).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	if att.Watermark, err = watermarkOf(c.st.DB(), att, &prj.ID); err != nil {
+		return nil, nil, err
+	}
+
+	dl := models.Download{
+		Project:    &prj.ID,
+		Owner:      cmt.ID,
+		Attachment: &att.ID,
+		Name:       att.Name,
+	}
+	f, err := openFile(ctx, c.st.DB(), filepath.Join(c.uploadPath, fmt.Sprintf("%s-%s", cmt.ID, att.ID)), att, &dl, fr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &att, f, nil
+}
+
+func (c *Comment) project(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	doc, err := c.st.FromKind("project").Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Data.(*models.Project), nil
+}
+
+// comment returns the comment with the id and its project.
+func (c *Comment) comment(ctx context.Context, id uuid.UUID) (*models.ProjectComment, *models.Project, error) {
+	cmt, err := c.get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	prj, err := c.project(ctx, cmt.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cmt, prj, nil
+}
+
+// suggestion returns the comment with the id and its project if it suggests
+// a change still open.
+func (c *Comment) suggestion(ctx context.Context, id uuid.UUID) (*models.ProjectComment, *models.Project, error) {
+	cmt, prj, err := c.comment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cmt.SuggestionStatus != models.SuggestionOpen {
+		return nil, nil, fmt.Errorf("%w: the comment suggests no open change", ErrBadInput)
+	}
+	return cmt, prj, nil
+}
+
+// decide records the decision on the change suggested in the comment and
+// resolves its thread, with db which may be a transaction. Suggestions are
+// decided only once.
+func (c *Comment) decide(ctx context.Context, db *gorm.DB, cmt models.ProjectComment, status models.SuggestionStatus) error {
+	cv := services.FromContext(ctx)
+	now := time.Now()
+	res := db.Model(&models.ProjectComment{}).
+		Where("id = ? AND suggestion_status = ?", cmt.ID, models.SuggestionOpen).
+		Updates(map[string]interface{}{
+			"suggestion_status": status,
+			"decided_at":        now,
+			"decided_by":        cv.User.ID,
+			"resolved_at":       now,
+			"resolved_by":       cv.User.ID,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: the comment suggests no open change", ErrBadInput)
+	}
+	return nil
+}
+
+// notifyDecision notifies the author of the suggestion in the comment of the
+// decision on it, unless the author decided it.
+func (c *Comment) notifyDecision(ctx context.Context, prj models.Project, cmt models.ProjectComment, status models.SuggestionStatus) {
+	cv := services.FromContext(ctx)
+	if cmt.UserID == cv.User.ID {
+		return
+	}
+
+	action := models.UserActionAcceptSuggestion
+	if status == models.SuggestionRejected {
+		action = models.UserActionRejectSuggestion
+	}
+	notifyAll(ctx, c.notifier, []uuid.UUID{cmt.UserID}, models.Notification{
+		Action:     action,
+		UserID:     cv.User.ID,
+		UserKey:    cv.User.Name,
+		TargetID:   prj.ID,
+		TargetKey:  prj.Name,
+		TargetType: models.ProjectT,
+		New:        *cmt.Suggestion,
+		Country:    prj.Country,
+	})
+}
+
+// suggested returns the current value of the contract field or table cell
+// the comment suggests a change to.
+func (c *Comment) suggested(ctx context.Context, projectID uuid.UUID, cmt models.ProjectComment) (string, error) {
+	switch cmt.TargetKind {
+	case models.CommentOnContractField:
+		ctr, err := c.contract.buildContext(ctx, projectID, nil)
+		if err != nil {
+			return "", err
+		}
+		return ctr.contract.Fields[cmt.TargetName], nil
+	case models.CommentOnContractTable:
+		ctr, err := c.contract.buildContext(ctx, projectID, map[string]string{"table": cmt.TargetName}, withTable)
+		if err != nil {
+			return "", err
+		}
+		t := ctr.contract.Tables[ctr.table]
+		row, column := *cmt.TargetRow, *cmt.TargetColumn
+		if row < 0 || row >= t.Len() || column < 0 || column >= t.ColumnLen() {
+			return "", fmt.Errorf("%w: no cell at row %d, column %d", ErrBadInput, row, column)
+		}
+		return t.Row(row)[column].String(), nil
+	}
+	return "", nil
+}
+
+func (c *Comment) get(id uuid.UUID) (*models.ProjectComment, error) {
+	var cmt models.ProjectComment
+	if err := c.st.DB().Where("id = ?", id).First(&cmt).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &cmt, nil
+}
+
+// thread returns the comment starting a thread followed by the replies to
+// it, oldest first.
+func (c *Comment) thread(root models.ProjectComment) ([]models.ProjectComment, error) {
+	var replies []models.ProjectComment
+	err := c.st.DB().Where("parent_id = ?", root.ID).Order("created_at").Find(&replies).Error
+	return append([]models.ProjectComment{root}, replies...), err
+}
+
+// members returns the users who may be mentioned in the comments of the
+// project.
+func (c *Comment) members(prj models.Project) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := c.st.DB().Table("project_roles").
+		Where("project_id = ?", prj.ID).
+		Pluck("DISTINCT user_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids = append(ids, prj.PortfolioDirector)
+	if prj.FundManager != nil {
+		ids = append(ids, *prj.FundManager)
+	}
+	return ids, nil
+}
+
+// targetExists checks the work phase or forfaiting review the comment is
+// about belongs to the project and the contract table has the cell, if any.
+func (c *Comment) targetExists(ctx context.Context, projectID uuid.UUID, cmt models.ProjectComment) error {
+	var q *gorm.DB
+	switch cmt.TargetKind {
+	case models.CommentOnContractTable:
+		if cmt.TargetRow == nil {
+			return nil
+		}
+		t, err := c.contract.GetTable(ctx, projectID, map[string]string{"table": cmt.TargetName})
+		if err != nil {
+			return err
+		}
+		_, err = setCell(*t, *cmt.TargetRow, *cmt.TargetColumn, "")
+		return err
+	case models.CommentOnWorkPhase:
+		q = c.st.DB().Table("work_phase").
+			Where("id = ? AND project_id = ? AND deleted_at IS NULL", *cmt.TargetID, projectID)
+	case models.CommentOnFAReview:
+		q = c.st.DB().Table("fa_reviews r").
+			Joins("JOIN forfaiting_applications fa ON fa.id = r.forfaiting_application_id").
+			Where("r.id = ? AND fa.project_id = ? AND r.deleted_at IS NULL", *cmt.TargetID, projectID)
+	default:
+		return nil
+	}
+
+	var n int
+	if err := q.Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: no such %s in the project", ErrBadInput, cmt.TargetKind)
+	}
+	return nil
+}
+
+// notify notifies the mentioned users and the other ones taking part in the
+// thread of the comment, except its author.
+func (c *Comment) notify(ctx context.Context, prj models.Project, cmt models.ProjectComment, mentions []string, thread []models.ProjectComment) {
+	cv := services.FromContext(ctx)
+	n := models.Notification{
+		UserID:     cv.User.ID,
+		UserKey:    cv.User.Name,
+		TargetID:   prj.ID,
+		TargetKey:  prj.Name,
+		TargetType: models.ProjectT,
+		New:        cmt.Content,
+		Country:    prj.Country,
+	}
+
+	skip := map[uuid.UUID]bool{cv.User.ID: true}
+	var recs []uuid.UUID
+	for _, m := range mentions {
+		if id, err := uuid.Parse(m); err == nil && !skip[id] {
+			skip[id] = true
+			recs = append(recs, id)
+		}
+	}
+	n.Action = models.UserActionMention
+	notifyAll(ctx, c.notifier, recs, n)
+
+	recs = nil
+	for _, t := range thread {
+		if !skip[t.UserID] {
+			skip[t.UserID] = true
+			recs = append(recs, t.UserID)
+		}
+	}
+	n.Action = models.UserActionComment
+	notifyAll(ctx, c.notifier, recs, n)
+}
+
+func canReadComments(ctx context.Context, prj models.Project) bool {
+	ids := []uuid.UUID{prj.ID, prj.Owner}
+	for _, id := range prj.ConsortiumOrgs {
+		ids = append(ids, uuid.MustParse(id))
+	}
+	return canGetProject(ctx, GetProject, prj.Country, ids...)
+}
+
+// validComment trims the content of the comment and checks it has what its
+// target and suggestion, if any, need.
+func validComment(cmt *models.ProjectComment) error {
+	cmt.Content = strings.TrimSpace(cmt.Content)
+	if cmt.Content == "" {
+		return fmt.Errorf("%w: empty comment", ErrBadInput)
+	}
+
+	if cmt.TargetKind == "" {
+		cmt.TargetKind = models.CommentOnProject
+	}
+	if err := cmt.TargetKind.Valid(); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+
+	cell := cmt.TargetRow != nil || cmt.TargetColumn != nil
+	switch cmt.TargetKind {
+	case models.CommentOnProject:
+		cmt.TargetID, cmt.TargetName = nil, ""
+	case models.CommentOnWorkPhase, models.CommentOnFAReview:
+		if cmt.TargetID == nil {
+			return fmt.Errorf("%w: comments on a %s need its id", ErrBadInput, cmt.TargetKind)
+		}
+		cmt.TargetName = ""
+	case models.CommentOnContractTable:
+		if _, ok := contract.NewTables()[cmt.TargetName]; !ok {
+			return fmt.Errorf("%w: no contract table %q", ErrBadInput, cmt.TargetName)
+		}
+		if cell && (cmt.TargetRow == nil || cmt.TargetColumn == nil || *cmt.TargetRow < 0 || *cmt.TargetColumn < 0) {
+			return fmt.Errorf("%w: comments on a table cell need its row and column", ErrBadInput)
+		}
+		cmt.TargetID = nil
+	case models.CommentOnContractField:
+		if _, ok := contract.NewFields()[cmt.TargetName]; !ok {
+			return fmt.Errorf("%w: no contract field %q", ErrBadInput, cmt.TargetName)
+		}
+		cmt.TargetID = nil
+	}
+	if cmt.TargetKind != models.CommentOnContractTable {
+		cmt.TargetRow, cmt.TargetColumn, cell = nil, nil, false
+	}
+
+	// changes are suggested for a field or a cell only
+	switch {
+	case cmt.Suggestion == nil:
+		cmt.SuggestionStatus = ""
+	case cmt.TargetKind != models.CommentOnContractField && !cell:
+		return fmt.Errorf("%w: changes are suggested for a contract field or table cell", ErrBadInput)
+	case cmt.SuggestionStatus == "":
+		cmt.SuggestionStatus = models.SuggestionOpen
+	}
+	return nil
+}
+
+// setCell returns a copy of the table with the value in the cell.
+func setCell(t contract.Table, row, column int, value string) (contract.Table, error) {
+	if row < 0 || row >= t.Len() || column < 0 || column >= t.ColumnLen() {
+		return contract.Table{}, fmt.Errorf("%w: no cell at row %d, column %d", ErrBadInput, row, column)
+	}
+
+	rows := t.Rows()
+	cells := make(contract.Row, len(rows[row]))
+	copy(cells, rows[row])
+	cells[column] = contract.Cell(value)
+	rows[row] = cells
+
+	res, err := contract.NewTable(t.Columns(), rows...)
+	if err != nil {
+		return contract.Table{}, err
+	}
+	res.SetTitle(t.Title())
+	return res, nil
+}
+
+// mentioned checks the users mentioned are members and returns them without
+// duplicates.
+func mentioned(members, mentions []uuid.UUID) ([]string, error) {
+	isMember := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		isMember[m] = true
+	}
+
+	var ids []string
+	seen := make(map[uuid.UUID]bool, len(mentions))
+	for _, m := range mentions {
+		if !isMember[m] {
+			return nil, fmt.Errorf("%w: %s is not a member of the project", ErrBadInput, m)
+		}
+		if !seen[m] {
+			seen[m] = true
+			ids = append(ids, m.String())
+		}
+	}
+	return ids, nil
+}
+
+// newMentions returns the mentions which are not in old.
+func newMentions(old, mentions []string) []string {
+	had := make(map[string]bool, len(old))
+	for _, m := range old {
+		had[m] = true
+	}
+
+	var res []string
+	for _, m := range mentions {
+		if !had[m] {
+			res = append(res, m)
+		}
+	}
+	return res
+}
@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+)
+
+func TestValidComment(t *testing.T) {
+	id := uuid.New()
+
+	cmt := models.ProjectComment{Content: " Looks fine ", TargetID: &id, TargetName: "baseyear_n"}
+	if err := validComment(&cmt); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if cmt.Content != "Looks fine" || cmt.TargetKind != models.CommentOnProject || cmt.TargetID != nil || cmt.TargetName != "" {
+		t.Errorf("Expected a trimmed comment on the project, got %+v", cmt)
+	}
+
+	cmt = models.ProjectComment{Content: "Base year", TargetKind: models.CommentOnContractTable, TargetID: &id, TargetName: "baseyear_n"}
+	if err := validComment(&cmt); err != nil || cmt.TargetID != nil {
+		t.Errorf("Expected a comment on the table only, got %v %+v", err, cmt)
+	}
+
+	value, row := "3.5", 1
+	cmt = models.ProjectComment{Content: "Lower it", TargetKind: models.CommentOnContractField, TargetName: "interest_rate_percent", TargetRow: &row, Suggestion: &value}
+	if err := validComment(&cmt); err != nil || cmt.TargetRow != nil || cmt.SuggestionStatus != models.SuggestionOpen {
+		t.Errorf("Expected an open suggestion for the field, got %v %+v", err, cmt)
+	}
+
+	cases := map[string]models.ProjectComment{
+		"empty":                 {Content: " "},
+		"target":                {Content: "c", TargetKind: "meeting"},
+		"work phase id":         {Content: "c", TargetKind: models.CommentOnWorkPhase},
+		"fa review id":          {Content: "c", TargetKind: models.CommentOnFAReview},
+		"contract table":        {Content: "c", TargetKind: models.CommentOnContractTable, TargetName: "nosuchtable"},
+		"contract field":        {Content: "c", TargetKind: models.CommentOnContractField, TargetName: "nosuchfield"},
+		"cell without column":   {Content: "c", TargetKind: models.CommentOnContractTable, TargetName: "baseyear_n", TargetRow: &row},
+		"suggestion on project": {Content: "c", Suggestion: &value},
+		"suggestion on table":   {Content: "c", TargetKind: models.CommentOnContractTable, TargetName: "baseyear_n", Suggestion: &value},
+	}
+	for name, c := range cases {
+		if err := validComment(&c); !errors.Is(err, ErrBadInput) {
+			t.Errorf("%s: expected bad input, got %v", name, err)
+		}
+	}
+}
+
+func TestSetCell(t *testing.T) {
+	cols := []contract.Column{{Name: "name", Kind: contract.String}, {Name: "value", Kind: contract.Decimal}}
+	tbl, err := contract.NewTable(cols, contract.Row{"a", "1"}, contract.Row{"b", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl.SetTitle("Values")
+
+	got, err := setCell(tbl, 1, 1, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Row(1)[1] != "3" || got.Row(0)[1] != "1" || got.Title() != "Values" {
+		t.Errorf("Unexpected table %v", got.Rows())
+	}
+	if tbl.Row(1)[1] != "2" {
+		t.Errorf("Expected the table left as it was, got %v", tbl.Rows())
+	}
+
+	for _, cell := range [][2]int{{2, 0}, {0, 2}, {-1, 0}} {
+		if _, err := setCell(tbl, cell[0], cell[1], "x"); !errors.Is(err, ErrBadInput) {
+			t.Errorf("%v: expected bad input, got %v", cell, err)
+		}
+	}
+}
+
+func TestMentioned(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	got, err := mentioned([]uuid.UUID{a, b}, []uuid.UUID{b, a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{b.String(), a.String()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := mentioned([]uuid.UUID{a}, []uuid.UUID{b}); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected bad input mentioning a non member, got %v", err)
+	}
+}
+
+func TestNewMentions(t *testing.T) {
+	got := newMentions([]string{"a", "b"}, []string{"b", "c"})
+	if !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("Expected [c], got %v", got)
+	}
+}
+
+func TestCommentThreads(t *testing.T) {
+	e := services.NewTestEnv(t)
+	contr := NewComment(e)
+
+	user := stores.NewTestUser(t, e.UserStore)
+	prj := stores.NewTestProject(t, e.ProjectStore, stores.TPrjWithPm(user.ID))
+	randomu := stores.NewTestUser(t, e.UserStore)
+
+	ctx := services.NewTestContext(t, e, user)
+	root, err := contr.Post(ctx, prj.ID, models.ProjectComment{
+		Content:    "Is the base year right?",
+		TargetKind: models.CommentOnContractTable,
+		TargetName: "baseyear_n",
+	}, []uuid.UUID{user.ID}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Mentions) != 1 || root.TargetName != "baseyear_n" {
+		t.Errorf("Unexpected comment %+v", root)
+	}
+
+	if _, err := contr.Post(ctx, prj.ID, models.ProjectComment{Content: "c"}, []uuid.UUID{randomu.ID}, nil); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected bad input mentioning a non member, got %v", err)
+	}
+	if _, err := contr.Post(services.NewTestContext(t, e, randomu), prj.ID, models.ProjectComment{Content: "c"}, nil, nil); err != ErrUnauthorized {
+		t.Errorf("Expected unauthorized, got %v", err)
+	}
+
+	if _, err := contr.Resolve(ctx, root.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := contr.Post(ctx, prj.ID, models.ProjectComment{Content: "Yes", Parent: &root.ID}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.TargetKind != models.CommentOnContractTable {
+		t.Errorf("Expected the reply on the table of the thread, got %v", reply.TargetKind)
+	}
+
+	unresolved := false
+	threads, err := contr.Threads(ctx, prj.ID, nil, nil, nil, &unresolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 1 || threads[0].ResolvedAt != nil {
+		t.Fatalf("Expected the thread reopened by the reply, got %+v", threads)
+	}
+	if replies, err := contr.Replies(ctx, threads[0]); err != nil || len(replies) != 1 {
+		t.Errorf("Expected a reply, got %v %v", replies, err)
+	}
+
+	edited, err := contr.Edit(ctx, reply.ID, "No", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Content != "No" || edited.EditedAt == nil {
+		t.Errorf("Unexpected edit %+v", edited)
+	}
+	if history, err := contr.History(ctx, *edited); err != nil || len(history) != 1 || history[0].Content != "Yes" {
+		t.Errorf("Expected the previous content in the history, got %v %v", history, err)
+	}
+	if _, err := contr.Edit(services.NewTestContext(t, e, randomu), reply.ID, "Maybe", nil); err != ErrUnauthorized {
+		t.Errorf("Expected only the author to edit, got %v", err)
+	}
+
+	if err := contr.Delete(ctx, root.ID); err != nil {
+		t.Fatal(err)
+	}
+	if threads, err := contr.Threads(ctx, prj.ID, nil, nil, nil, nil); err != nil || len(threads) != 0 {
+		t.Errorf("Expected no threads left, got %v %v", threads, err)
+	}
+}
+
+func TestContractSuggestions(t *testing.T) {
+	e := services.NewTestEnv(t)
+	contr := NewComment(e)
+
+	user := stores.NewTestUser(t, e.UserStore)
+	prj := stores.NewTestProject(t, e.ProjectStore, stores.TPrjWithPm(user.ID))
+	stores.NewTestContract(t, e.ContractStore, prj)
+	randomu := stores.NewTestUser(t, e.UserStore)
+
+	ctx := services.NewTestContext(t, e, user)
+	field := "interest_rate_percent"
+	suggest := func(value string) *models.ProjectComment {
+		cmt, err := contr.Post(ctx, prj.ID, models.ProjectComment{
+			Content:    "Should be lower",
+			TargetKind: models.CommentOnContractField,
+			TargetName: field,
+			Suggestion: &value,
+		}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cmt
+	}
+
+	accepted, rejected := suggest("3.5"), suggest("2")
+	if sugs, err := contr.Suggestions(ctx, prj.ID, models.CommentOnContractField, &field); err != nil || len(sugs) != 2 {
+		t.Fatalf("Expected two open suggestions, got %v %v", sugs, err)
+	}
+
+	if _, err := contr.RejectSuggestion(services.NewTestContext(t, e, randomu), rejected.ID); err != ErrUnauthorized {
+		t.Errorf("Expected unauthorized, got %v", err)
+	}
+
+	cmt, err := contr.AcceptSuggestion(ctx, accepted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmt.SuggestionStatus != models.SuggestionAccepted || cmt.ResolvedAt == nil || cmt.DecidedBy == nil {
+		t.Errorf("Unexpected accepted suggestion %+v", cmt)
+	}
+	fields, err := contr.contract.GetFields(ctx, prj.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields[field] != "3.5" {
+		t.Errorf("Expected the suggestion applied, got %q", fields[field])
+	}
+	if _, err := contr.AcceptSuggestion(ctx, accepted.ID); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected bad input accepting twice, got %v", err)
+	}
+	if _, err := contr.AcceptSuggestion(ctx, rejected.ID); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected bad input accepting a suggestion for a changed field, got %v", err)
+	}
+
+	if cmt, err := contr.RejectSuggestion(ctx, rejected.ID); err != nil || cmt.SuggestionStatus != models.SuggestionRejected {
+		t.Errorf("Expected the suggestion rejected, got %v %v", cmt, err)
+	}
+	if sugs, err := contr.Suggestions(ctx, prj.ID, models.CommentOnContractField, &field); err != nil || len(sugs) != 0 {
+		t.Errorf("Expected no open suggestions, got %v %v", sugs, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"html"
 	"io"
 	"io/ioutil"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/contract"
 	"stageai.tech/sunshine/sunshine/models"
@@ -73,7 +74,10 @@ func (c *Contract) GetTable(ctx context.Context, id uuid.UUID, vars map[string]s
 	return &table, nil
 }
 
-func (c *Contract) DownloadContract(ctx context.Context, id uuid.UUID, language, format string) (*contract.FileInTempDir, string, error) {
+// DownloadContract generates the contract of the project in the language
+// and format, stamped with the watermark of the project if it is a PDF, and
+// logs the download.
+func (c *Contract) DownloadContract(ctx context.Context, id uuid.UUID, language, format string) (File, string, error) {
 	ctr, err := c.buildContext(ctx, id, nil)
 	if err != nil {
 		return nil, "", err
@@ -84,41 +88,80 @@ func (c *Contract) DownloadContract(ctx context.Context, id uuid.UUID, language,
 
 	}
 
-	var (
-		prjcountry = ctr.project.Country.LegalCountry()
-		texFile    string
-		op         func(context.Context, string) (*contract.FileInTempDir, error)
-	)
+	return c.download(ctx, ctr, language, format, ctr.project.Watermark, models.Download{})
+}
+
+// download generates the contract in the language and format, stamped with
+// the watermark if it is a PDF, and logs the download as dl.
+func (c *Contract) download(ctx context.Context, ctr contractCTX, language, format string, wm models.Watermark, dl models.Download) (File, string, error) {
+	tmpl, err := c.projectTemplate(ctr.project)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pdf, name, err := c.contractFile(ctx, ctr, language, format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var file File = pdf
+	if wm != models.WatermarkNone && format == "pdf" {
+		defer pdf.Close()
+		file, err = contract.Watermark(ctx, pdf.Name(), wm.Stamp(), downloadMark(ctx, time.Now())...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to watermark the contract: %w", err)
+		}
+	} else {
+		wm = models.WatermarkNone
+	}
+
+	dl.Project = &ctr.id
+	dl.Owner = ctr.id
+	dl.Name = name
+	dl.ContractUpdatedAt = &ctr.doc.Timestamp
+	dl.Watermark = wm
+	if tmpl != nil {
+		dl.TemplateVersion = &tmpl.Version
+	}
+	if err := logDownload(ctx, c.pst.DB(), &dl); err != nil {
+		file.Close()
+		return nil, "", err
+	}
+	return file, name, nil
+}
 
+// contractFile generates the contract of the project in the language and
+// format.
+func (c *Contract) contractFile(ctx context.Context, ctr contractCTX, language, format string) (*contract.FileInTempDir, string, error) {
+	var english bool
 	switch language {
 	case "native":
-		texFile = "contract.tex"
 	case "english":
-		texFile = "en_contract.tex"
-
-		// This is needed because of the adapted english
-		// versions contracts. If new adapted contract is
-		// added, it should be added here as well, if not it
-		// will be build the `base` version. Eventually when
-		// every contract has adapted english version,
-		// `texFile` should be equal every time to
-		// `contract.tex` and this if will become
-		// redundant. Also base version of the contract will
-		// be still needed because of sanity check.
-		if prjcountry == models.CountryBulgaria.String() ||
-			prjcountry == models.CountryRomania.String() {
-			texFile = "contract.tex"
-			prjcountry = prjcountry + "_adp"
-		}
+		english = true
 	default:
 		return nil, "", fmt.Errorf("bad language: %v", language)
 	}
 
-	doc, err := contract.NewDocumentFromLanguage(newTemplateContext(ctr, c.url), prjcountry)
+	docs := contract.DocumentsOf(ctr.project.Country)
+	dir, texFile := docs.Root("contract", english)
+
+	// Adapted english contracts are not covered by the country templates.
+	var (
+		tmpl *contract.Template
+		err  error
+	)
+	if dir == docs.Templates {
+		if tmpl, err = c.projectTemplate(ctr.project); err != nil {
+			return nil, "", err
+		}
+	}
+
+	doc, err := contract.NewDocumentFromTemplate(newTemplateContext(ctr, c.url), dir, tmpl)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate contract template: %w", err)
 	}
 
+	var op func(context.Context, string) (*contract.FileInTempDir, error)
 	switch format {
 	case "pdf":
 		op = doc.GeneratePDF
@@ -146,7 +189,22 @@ func (c *Contract) DownloadAgreement(ctx context.Context, id uuid.UUID, format,
 		return nil, "", ErrUnauthorized
 	}
 
-	doc, err := contract.NewDocument(newTemplateContext(ctr, c.url))
+	var english bool
+	switch language {
+	case "native":
+	case "english":
+		english = true
+	default:
+		return nil, "", fmt.Errorf("bad language: %v", language)
+	}
+	dir, texFile := contract.DocumentsOf(ctr.project.Country).Root("agreement", english)
+
+	tmpl, err := c.projectTemplate(ctr.project)
+	if err != nil {
+		return nil, "", err
+	}
+
+	doc, err := contract.NewDocumentFromTemplate(newTemplateContext(ctr, c.url), dir, tmpl)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate contract template: %w", err)
 	}
@@ -161,17 +219,7 @@ func (c *Contract) DownloadAgreement(ctx context.Context, id uuid.UUID, format,
 		return nil, "", fmt.Errorf("bad format: %v", format)
 	}
 
-	var lng string
-	switch language {
-	case "native":
-		lng = "agreement.tex"
-	case "english":
-		lng = "en_agreement.tex"
-	default:
-		return nil, "", fmt.Errorf("bad language: %v", language)
-	}
-
-	file, err := op(ctx, lng)
+	file, err := op(ctx, texFile)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate document for agreement: %w", err)
 	}
@@ -180,6 +228,12 @@ func (c *Contract) DownloadAgreement(ctx context.Context, id uuid.UUID, format,
 }
 
 func (c *Contract) UpdateTable(ctx context.Context, id uuid.UUID, table contract.Table, vars map[string]string) (*contract.Table, error) {
+	return c.updateTable(ctx, c.cst, id, table, vars)
+}
+
+// updateTable replaces the table of the contract of the project and saves
+// it with st, which may be backed by a transaction.
+func (c *Contract) updateTable(ctx context.Context, st stores.Store, id uuid.UUID, table contract.Table, vars map[string]string) (*contract.Table, error) {
 	ctr, err := c.buildContext(ctx, id, vars, withTable)
 	if err != nil {
 		return nil, err
@@ -200,7 +254,7 @@ func (c *Contract) UpdateTable(ctx context.Context, id uuid.UUID, table contract
 	}
 	ctr.contract.Tables[ctr.table] = *newTable
 
-	ctr.doc, err = c.cst.Update(ctx, ctr.doc)
+	ctr.doc, err = st.Update(ctx, ctr.doc)
 	if err != nil {
 		return nil, err
 	}
@@ -210,6 +264,12 @@ func (c *Contract) UpdateTable(ctx context.Context, id uuid.UUID, table contract
 }
 
 func (c *Contract) UpdateFields(ctx context.Context, id uuid.UUID, fields contract.JSONMap) (*models.Document, error) {
+	return c.updateFields(ctx, c.cst, id, fields)
+}
+
+// updateFields replaces the fields of the contract of the project and saves
+// them with st, which may be backed by a transaction.
+func (c *Contract) updateFields(ctx context.Context, st stores.Store, id uuid.UUID, fields contract.JSONMap) (*models.Document, error) {
 	ctr, err := c.buildContext(ctx, id, nil)
 	if err != nil {
 		return nil, err
@@ -220,7 +280,7 @@ func (c *Contract) UpdateFields(ctx context.Context, id uuid.UUID, fields contra
 		return nil, ErrUnauthorized
 	}
 
-	return c.cst.Update(ctx, ctr.doc)
+	return st.Update(ctx, ctr.doc)
 }
 
 func (c *Contract) GetFields(ctx context.Context, id uuid.UUID) (contract.JSONMap, error) {
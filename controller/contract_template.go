@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+)
+
+// UploadTemplate stores a new version of the contract templates of a country
+// out of a zip archive with .tex files. The version is validated, but not
+// activated.
+func (c *Contract) UploadTemplate(ctx context.Context, country models.Country, description string, up Upload) (*contract.Template, error) {
+	if !Can(ctx, ManageContractTemplates, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+
+	files, err := contract.ReadTemplateFiles(up.File)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+
+	t := contract.Template{
+		Country:     country,
+		Description: description,
+		Files:       files,
+	}
+	if err := contract.ValidateTemplate(t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+
+	cv := services.FromContext(ctx)
+	if cv.Authorized() {
+		t.Author = &cv.User.ID
+	}
+
+	tx := c.cst.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	err = tx.Model(contract.Template{}).
+		Where("country = ?", country).
+		Select("COALESCE(MAX(version), 0) + 1").
+		Row().Scan(&t.Version)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Create(&t).Error; err != nil {
+		tx.Rollback()
+		if stores.IsDuplicatedRecord(err) {
+			return nil, ErrDuplicate
+		}
+		return nil, err
+	}
+
+	return &t, tx.Commit().Error
+}
+
+// ActivateTemplate makes the template version the one used for all projects
+// of its country which have not selected another version.
+func (c *Contract) ActivateTemplate(ctx context.Context, id uuid.UUID) (*contract.Template, error) {
+	t, err := c.template(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !Can(ctx, ManageContractTemplates, uuid.Nil, t.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	// Templates on disk may have changed since the upload.
+	if err := contract.ValidateTemplate(*t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+
+	tx := c.cst.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	err = tx.Model(contract.Template{}).
+		Where("country = ? AND active", t.Country).
+		Update("active", false).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(t).Update("active", true).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return t, tx.Commit().Error
+}
+
+// ListTemplates returns all template versions, optionally only for given
+// country, newest first.
+func (c *Contract) ListTemplates(ctx context.Context, country models.Country) ([]contract.Template, error) {
+	if !Can(ctx, ManageContractTemplates, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+
+	var result []contract.Template
+	db := c.cst.DB()
+	if country != "" {
+		db = db.Where("country = ?", country)
+	}
+	return result, db.Order("country, version DESC").Find(&result).Error
+}
+
+// PreviewTemplate renders root out of the template version filled with a
+// sample contract.
+func (c *Contract) PreviewTemplate(ctx context.Context, id uuid.UUID, root, format string) (*contract.FileInTempDir, string, error) {
+	t, err := c.template(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !Can(ctx, ManageContractTemplates, uuid.Nil, t.Country) {
+		return nil, "", ErrUnauthorized
+	}
+
+	doc, err := contract.NewSampleDocument(*t)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+
+	var op func(context.Context, string) (*contract.FileInTempDir, error)
+	switch format {
+	case "pdf":
+		op = doc.GeneratePDF
+	case "tex":
+		op = doc.GenerateTeX
+	default:
+		return nil, "", fmt.Errorf("%w: bad format: %v", ErrBadInput, format)
+	}
+
+	file, err := op(ctx, root)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate template preview: %w", err)
+	}
+
+	return file, fmt.Sprintf("preview_%s_v%d_%s.%s", t.Country, t.Version, root, format), nil
+}
+
+// SetProjectTemplate selects the template version used for the project's
+// documents. A nil template resets the project to the active version of its
+// country.
+func (c *Contract) SetProjectTemplate(ctx context.Context, pid uuid.UUID, tid *uuid.UUID) error {
+	doc, err := c.pst.Get(ctx, pid)
+	if err != nil {
+		return err
+	}
+	prj := doc.Data.(*models.Project)
+
+	if !Can(ctx, SetProjectContractTemplate, prj.ID, prj.Country) {
+		return ErrUnauthorized
+	}
+
+	if tid != nil {
+		t, err := c.template(*tid)
+		if err != nil {
+			return err
+		}
+		if t.Country != contract.DocumentsOf(prj.Country).Country {
+			return fmt.Errorf("%w: template is for %s", ErrBadInput, t.Country)
+		}
+	}
+
+	return c.pst.DB().Model(prj).UpdateColumn("contract_template", tid).Error
+}
+
+// projectTemplate returns the template version which is to be laid over the
+// templates on disk for the project's documents or nil if there is no such.
+func (c *Contract) projectTemplate(prj *models.Project) (*contract.Template, error) {
+	if prj.ContractTemplate != nil {
+		return c.template(*prj.ContractTemplate)
+	}
+
+	var t contract.Template
+	err := c.cst.DB().
+		Where("country = ? AND active", contract.DocumentsOf(prj.Country).Country).
+		First(&t).Error
+	if stores.IsRecordNotFound(err) {
+		return nil, nil
+	}
+	return &t, err
+}
+
+func (c *Contract) template(id uuid.UUID) (*contract.Template, error) {
+	var t contract.Template
+	err := c.cst.DB().Where("id = ?", id).First(&t).Error
+	if stores.IsRecordNotFound(err) {
+		return nil, ErrNotFound
+	}
+	return &t, err
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+)
+
+func templateUpload(t *testing.T, files map[string]string) Upload {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return Upload{File: &buf, Filename: "templates.zip", Size: int64(buf.Len())}
+}
+
+func TestContractTemplates(t *testing.T) {
+	e := services.NewTestEnv(t)
+	c := NewContract(e)
+
+	admin := stores.NewTestAdmin(t, e.UserStore)
+	random := stores.NewTestUser(t, e.UserStore)
+	actx := services.NewTestContext(t, e, admin)
+
+	cases := []struct {
+		name  string
+		ctx   context.Context
+		files map[string]string
+		err   error
+	}{
+		{
+			name:  "ok admin",
+			ctx:   actx,
+			files: map[string]string{"sign.tex": "signed"},
+		},
+		{
+			name:  "ok second version",
+			ctx:   actx,
+			files: map[string]string{"latvia/sign.tex": "signed again"},
+		},
+		{
+			name:  "bad template",
+			ctx:   actx,
+			files: map[string]string{"sign.tex": "{{.Foo"},
+			err:   ErrBadInput,
+		},
+		{
+			name:  "fail random guy",
+			ctx:   services.NewTestContext(t, e, random),
+			files: map[string]string{"sign.tex": "signed"},
+			err:   ErrUnauthorized,
+		},
+	}
+
+	var versions []uuid.UUID
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := c.UploadTemplate(tc.ctx, models.CountryLatvia, tc.name, templateUpload(t, tc.files))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Got unexpected err; exp: %v, got: %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if tmpl.Active {
+				t.Error("Expected uploaded template to be inactive")
+			}
+			versions = append(versions, tmpl.ID)
+		})
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 uploaded versions, got %d", len(versions))
+	}
+
+	for _, id := range versions {
+		if _, err := c.ActivateTemplate(actx, id); err != nil {
+			t.Fatalf("ActivateTemplate: %v", err)
+		}
+	}
+
+	list, err := c.ListTemplates(actx, models.CountryLatvia)
+	if err != nil {
+		t.Fatalf("ListTemplates: %v", err)
+	}
+	var active int
+	for _, tmpl := range list {
+		if tmpl.Active {
+			active++
+			if tmpl.ID != versions[1] {
+				t.Errorf("Expected the last activated version to be active, got %v", tmpl.ID)
+			}
+		}
+	}
+	if active != 1 {
+		t.Errorf("Expected exactly one active version, got %d", active)
+	}
+
+	prj := stores.NewTestProject(t, e.ProjectStore)
+	if err := c.SetProjectTemplate(actx, prj.ID, &versions[0]); err != nil {
+		t.Fatalf("SetProjectTemplate: %v", err)
+	}
+
+	p, err := e.ProjectStore.Get(actx, prj.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := c.projectTemplate(p.Data.(*models.Project))
+	if err != nil {
+		t.Fatalf("projectTemplate: %v", err)
+	}
+	if tmpl == nil || tmpl.ID != versions[0] {
+		t.Errorf("Expected project's selected version, got %v", tmpl)
+	}
+
+	if err := c.SetProjectTemplate(services.NewTestContext(t, e, random), prj.ID, nil); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -2,8 +2,11 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/services"
 	"stageai.tech/sunshine/sunshine/stores"
@@ -38,3 +41,57 @@ func (c *Country) GetCountry(ctx context.Context, country models.Country) (*mode
 	}
 	return &ctrv, c.st.DB().Where("country = ?", country).First(&ctrv).Error
 }
+
+// SetEnergyFactor adds a new energy factor for a country and heating type. If
+// f.ValidFrom is not set, the factor is in effect from now on.
+func (c *Country) SetEnergyFactor(ctx context.Context, f models.EnergyFactor) (*models.EnergyFactor, error) {
+	if !Can(ctx, SetEnergyFactor, uuid.Nil, f.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	if f.HeatingType != models.HeatingDistrict && f.HeatingType != models.HeatingBuilding {
+		return nil, fmt.Errorf("%w: invalid heating type", ErrBadInput)
+	}
+	if f.CO2 < 0 || f.PrimaryEnergy < 0 {
+		return nil, fmt.Errorf("%w: factors must not be negative", ErrBadInput)
+	}
+	if f.ValidFrom.IsZero() {
+		f.ValidFrom = time.Now()
+	}
+
+	if err := c.st.DB().Create(&f).Error; err != nil {
+		if stores.IsDuplicatedRecord(err) {
+			err = ErrDuplicate
+		}
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ListEnergyFactors returns the history of energy factors of a country, the
+// most recent first.
+func (c *Country) ListEnergyFactors(ctx context.Context, country models.Country) ([]models.EnergyFactor, error) {
+	if !Can(ctx, ListEnergyFactors, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+
+	var result []models.EnergyFactor
+	return result, c.st.DB().
+		Where("country = ?", country).
+		Order("valid_from DESC").
+		Find(&result).Error
+}
+
+// energyFactor returns the factor for given country and heating type, which
+// is in effect at the given time.
+func energyFactor(db *gorm.DB, c models.Country, h models.Heating, at time.Time) (*models.EnergyFactor, error) {
+	var f models.EnergyFactor
+	err := db.Where("country = ? AND heating_type = ?", c, h).
+		Where("valid_from <= ?", at).
+		Order("valid_from DESC").
+		First(&f).Error
+	if stores.IsRecordNotFound(err) {
+		err = ErrNotFound
+	}
+	return &f, err
+}
@@ -2,7 +2,9 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/services"
@@ -12,6 +14,7 @@ import (
 func TestCountry(t *testing.T) {
 	t.Run("setVat", setVat)
 	t.Run("getCountry", getCountry)
+	t.Run("energyFactors", energyFactors)
 }
 
 func setVat(t *testing.T) {
@@ -94,3 +97,91 @@ func getCountry(t *testing.T) {
 		})
 	}
 }
+
+func energyFactors(t *testing.T) {
+	e := services.NewTestEnv(t)
+	ct := NewCountry(e)
+
+	admin := stores.NewTestAdmin(t, e.UserStore)
+	random := stores.NewTestUser(t, e.UserStore)
+
+	old := time.Now().AddDate(-1, 0, 0)
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		factor models.EnergyFactor
+		err    error
+	}{
+		{
+			name:   "ok admin",
+			ctx:    services.NewTestContext(t, e, admin),
+			factor: models.EnergyFactor{Country: models.CountryLatvia, HeatingType: models.HeatingDistrict, CO2: 0.2, PrimaryEnergy: 1.2, ValidFrom: old},
+		},
+		{
+			name:   "ok newer",
+			ctx:    services.NewTestContext(t, e, admin),
+			factor: models.EnergyFactor{Country: models.CountryLatvia, HeatingType: models.HeatingDistrict, CO2: 0.1, PrimaryEnergy: 1.1},
+		},
+		{
+			name:   "duplicate",
+			ctx:    services.NewTestContext(t, e, admin),
+			factor: models.EnergyFactor{Country: models.CountryLatvia, HeatingType: models.HeatingDistrict, CO2: 0.2, PrimaryEnergy: 1.2, ValidFrom: old},
+			err:    ErrDuplicate,
+		},
+		{
+			name:   "bad heating type",
+			ctx:    services.NewTestContext(t, e, admin),
+			factor: models.EnergyFactor{Country: models.CountryLatvia, CO2: 0.2},
+			err:    ErrBadInput,
+		},
+		{
+			name:   "negative factor",
+			ctx:    services.NewTestContext(t, e, admin),
+			factor: models.EnergyFactor{Country: models.CountryLatvia, HeatingType: models.HeatingBuilding, CO2: -1},
+			err:    ErrBadInput,
+		},
+		{
+			name:   "fail random guy",
+			ctx:    services.NewTestContext(t, e, random),
+			factor: models.EnergyFactor{Country: models.CountryLatvia, HeatingType: models.HeatingBuilding},
+			err:    ErrUnauthorized,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ct.SetEnergyFactor(c.ctx, c.factor)
+			if !errors.Is(err, c.err) {
+				t.Errorf("Got unexpected err; exp: %v, got: %v", c.err, err)
+			}
+		})
+	}
+
+	list, err := ct.ListEnergyFactors(services.NewTestContext(t, e, random), models.CountryLatvia)
+	if err != nil {
+		t.Fatalf("ListEnergyFactors: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 energy factors, got %d", len(list))
+	}
+
+	f, err := energyFactor(e.DB, models.CountryLatvia, models.HeatingDistrict, time.Now())
+	if err != nil {
+		t.Fatalf("energyFactor: %v", err)
+	}
+	if f.CO2 != 0.1 {
+		t.Errorf("Expected the newest factor, got %v", f)
+	}
+
+	f, err = energyFactor(e.DB, models.CountryLatvia, models.HeatingDistrict, old.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("energyFactor: %v", err)
+	}
+	if f.CO2 != 0.2 {
+		t.Errorf("Expected the old factor, got %v", f)
+	}
+
+	if _, err = energyFactor(e.DB, models.CountryLatvia, models.HeatingBuilding, time.Now()); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
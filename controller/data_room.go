@@ -0,0 +1,456 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+type DataRoom struct {
+	st         stores.Store
+	fa         *ForfaitingAgreement
+	contract   *Contract
+	uploadPath string
+}
+
+func NewDataRoom(env *services.Env) *DataRoom {
+	return &DataRoom{
+		st:         env.ProjectStore,
+		fa:         NewForfaitingAgreement(env),
+		contract:   NewContract(env),
+		uploadPath: env.Paths.Uploads,
+	}
+}
+
+// DataRoomItem is a document of a data room along with its attachment.
+type DataRoomItem struct {
+	models.DataRoomDocument
+
+	File models.Attachment
+}
+
+// DataRoomFinancials are the key figures of the forfaiting of the project of
+// a data room.
+type DataRoomFinancials struct {
+	// Approved reports whether the review chain of the forfaiting
+	// application is approved.
+	Approved bool
+
+	Currency    models.Currency
+	Total       float64
+	Paid        float64
+	Outstanding float64
+	Overdue     float64
+}
+
+// Save creates the data room of the project or updates the given fields of
+// it.
+func (d *DataRoom) Save(ctx context.Context, projectID uuid.UUID,
+	title, description *string, includeContract, watermark *bool) (*models.DataRoom, error) {
+	prj, err := d.project(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !Can(ctx, ManageDataRoom, prj.ID, prj.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	room := models.DataRoom{Project: prj.ID, Watermark: true}
+	err = d.st.DB().Where("project_id = ?", prj.ID).First(&room).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+
+	if title != nil {
+		room.Title = *title
+	}
+	if description != nil {
+		room.Description = *description
+	}
+	if includeContract != nil {
+		room.IncludeContract = *includeContract
+	}
+	if watermark != nil {
+		room.Watermark = *watermark
+	}
+	return &room, d.st.DB().Save(&room).Error
+}
+
+// SetDocuments replaces the documents of the data room of the project. The
+// documents must be attached to the project, its building, its work or
+// monitoring phase or its forfaiting application.
+func (d *DataRoom) SetDocuments(ctx context.Context, projectID uuid.UUID, docs []models.DataRoomDocument) (*models.DataRoom, error) {
+	room, prj, manager, err := d.room(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !manager {
+		return nil, ErrUnauthorized
+	}
+
+	owners, err := attachmentOwners(d.st.DB(), *prj)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.Attachment
+	}
+	var count int
+	err = d.st.DB().Model(&models.Attachment{}).
+		Where("id IN (?) AND owner_id IN (?)", ids, owners).
+		Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if count != len(uniqueIDs(ids)) {
+		return nil, fmt.Errorf("%w: documents must be attached to the project", ErrBadInput)
+	}
+
+	// start transaction block
+	tx := d.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("data_room_id = ?", room.ID).Delete(&models.DataRoomDocument{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		doc.ID = uuid.Nil
+		doc.DataRoomID = room.ID
+		if err := tx.Create(&doc).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	// end transaction block
+
+	room, _, _, err = d.room(ctx, projectID)
+	return room, err
+}
+
+// Grant gives the investor access to the data room of the project until
+// expiresAt, if given. Granting an investor again updates the expiry.
+func (d *DataRoom) Grant(ctx context.Context, projectID, investor uuid.UUID, expiresAt *time.Time) (*models.DataRoomGrant, error) {
+	room, prj, manager, err := d.room(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !manager {
+		return nil, ErrUnauthorized
+	}
+
+	var count int
+	err = d.st.DB().Model(&models.CountryRole{}).
+		Where("user_id = ? AND country = ? AND role = ?", investor, prj.Country, models.InvestorRole).
+		Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%w: the user is no investor in %s", ErrBadInput, prj.Country)
+	}
+
+	g := models.DataRoomGrant{DataRoomID: room.ID, Investor: investor}
+	err = d.st.DB().Where("data_room_id = ? AND investor_id = ?", room.ID, investor).First(&g).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+	g.ExpiresAt = expiresAt
+	g.GrantedBy = &services.FromContext(ctx).User.ID
+	return &g, d.st.DB().Save(&g).Error
+}
+
+// Revoke withdraws the access of an investor to a data room.
+func (d *DataRoom) Revoke(ctx context.Context, grantID uuid.UUID) error {
+	var g models.DataRoomGrant
+	err := d.st.DB().Where("id = ?", grantID).First(&g).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var room models.DataRoom
+	if err := d.st.DB().Where("id = ?", g.DataRoomID).First(&room).Error; err != nil {
+		return err
+	}
+	prj, err := d.project(ctx, room.Project)
+	if err != nil {
+		return err
+	}
+	if !Can(ctx, ManageDataRoom, prj.ID, prj.Country) {
+		return ErrUnauthorized
+	}
+	return d.st.DB().Delete(&g).Error
+}
+
+// Get returns the data room of the project to its managers and the
+// investors granted access to it.
+func (d *DataRoom) Get(ctx context.Context, projectID uuid.UUID) (*models.DataRoom, error) {
+	room, _, _, err := d.room(ctx, projectID)
+	return room, err
+}
+
+// Items returns the documents of the data room along with their files.
+func (d *DataRoom) Items(ctx context.Context, room *models.DataRoom) ([]DataRoomItem, error) {
+	if _, _, _, err := d.room(ctx, room.Project); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(room.Documents))
+	for i, doc := range room.Documents {
+		ids[i] = doc.Attachment
+	}
+	var atts []models.Attachment
+	if err := d.st.DB().Where("id IN (?)", ids).Find(&atts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Attachment, len(atts))
+	for _, a := range atts {
+		byID[a.ID] = a
+	}
+
+	var items []DataRoomItem
+	for _, doc := range room.Documents {
+		if a, ok := byID[doc.Attachment]; ok {
+			items = append(items, DataRoomItem{DataRoomDocument: doc, File: a})
+		}
+	}
+	return items, nil
+}
+
+// Financials returns the key figures of the forfaiting of the project of the
+// data room, if it applied for it.
+func (d *DataRoom) Financials(ctx context.Context, room *models.DataRoom) (*DataRoomFinancials, error) {
+	if _, _, _, err := d.room(ctx, room.Project); err != nil {
+		return nil, err
+	}
+
+	var fa models.ForfaitingApplication
+	err := d.st.DB().Where("project_id = ?", room.Project).First(&fa).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := d.fa.schedule(fa.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	f := DataRoomFinancials{
+		Approved:    fa.Approved(),
+		Total:       s.Total,
+		Paid:        s.Paid,
+		Outstanding: s.Outstanding,
+		Overdue:     s.Overdue,
+	}
+	if len(s.Tranches) > 0 {
+		f.Currency = s.Tranches[0].Currency
+	}
+	return &f, nil
+}
+
+// Monitoring returns the reviews of the monitoring phase of the project of
+// the data room.
+func (d *DataRoom) Monitoring(ctx context.Context, room *models.DataRoom) ([]models.MPReview, error) {
+	if _, _, _, err := d.room(ctx, room.Project); err != nil {
+		return nil, err
+	}
+
+	var result []models.MPReview
+	return result, d.st.DB().
+		Joins("JOIN monitoring_phase mp ON mp.id = mp_reviews.mp_id").
+		Where("mp.project_id = ? AND mp.deleted_at IS NULL", room.Project).
+		Order("mp_reviews.created_at").
+		Find(&result).Error
+}
+
+// Grants returns who has access to the data room, to its managers.
+func (d *DataRoom) Grants(ctx context.Context, room *models.DataRoom) ([]models.DataRoomGrant, error) {
+	if _, _, manager, err := d.room(ctx, room.Project); err != nil {
+		return nil, err
+	} else if !manager {
+		return nil, ErrUnauthorized
+	}
+	return room.Grants, nil
+}
+
+// Downloads returns the log of the downloads from the data room, latest
+// first, to its managers.
+func (d *DataRoom) Downloads(ctx context.Context, room *models.DataRoom) ([]models.Download, error) {
+	if _, _, manager, err := d.room(ctx, room.Project); err != nil {
+		return nil, err
+	} else if !manager {
+		return nil, ErrUnauthorized
+	}
+
+	var result []models.Download
+	return result, d.st.DB().
+		Where("data_room_id = ?", room.ID).
+		Order("created_at DESC").
+		Find(&result).Error
+}
+
+// Download opens a document of the data room of the project and logs the
+// download. PDFs are stamped as confidential if the data room says so, or
+// else with their own watermark or the one of the project.
+func (d *DataRoom) Download(ctx context.Context, projectID, attachmentID uuid.UUID, fr FileRequest) (*models.Attachment, File, error) {
+	room, _, _, err := d.room(ctx, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found bool
+	for _, doc := range room.Documents {
+		found = found || doc.Attachment == attachmentID
+	}
+	if !found {
+		return nil, nil, ErrNotFound
+	}
+
+	var att models.Attachment
+	if err := d.st.DB().Where("id = ?", attachmentID).First(&att).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrNotFound
+		}
+		return nil, nil, err
+	}
+	if room.Watermark && isPDF(att) {
+		att.Watermark = models.WatermarkConfidential
+	} else if att.Watermark, err = watermarkOf(d.st.DB(), att, &room.Project); err != nil {
+		return nil, nil, err
+	}
+
+	dl := models.Download{
+		Project:    &room.Project,
+		Owner:      att.Owner,
+		Attachment: &att.ID,
+		Name:       att.Name,
+		DataRoom:   &room.ID,
+	}
+	path := filepath.Join(d.uploadPath, fmt.Sprintf("%s-%s", att.Owner, att.ID))
+	file, err := openFile(ctx, d.st.DB(), path, att, &dl, fr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &att, file, nil
+}
+
+// DownloadContract generates the contract of the project of the data room
+// as PDF in the language, native or english, and logs the download. It is
+// stamped as confidential if the data room says so, or else with the
+// watermark of the project.
+func (d *DataRoom) DownloadContract(ctx context.Context, projectID uuid.UUID, language string) (File, string, error) {
+	room, _, _, err := d.room(ctx, projectID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !room.IncludeContract {
+		return nil, "", ErrNotFound
+	}
+
+	ctr, err := d.contract.buildContext(ctx, projectID, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	wm := ctr.project.Watermark
+	if room.Watermark {
+		wm = models.WatermarkConfidential
+	}
+	return d.contract.download(ctx, ctr, language, "pdf", wm, models.Download{DataRoom: &room.ID})
+}
+
+// room returns the data room of the project, the project and whether the
+// user manages the data room. Investors need an active grant.
+func (d *DataRoom) room(ctx context.Context, projectID uuid.UUID) (*models.DataRoom, *models.Project, bool, error) {
+	prj, err := d.project(ctx, projectID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	var room models.DataRoom
+	err = d.st.DB().Where("project_id = ?", prj.ID).First(&room).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil, false, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if Can(ctx, ManageDataRoom, prj.ID, prj.Country) {
+		return &room, prj, true, nil
+	}
+	cv := services.FromContext(ctx)
+	if !cv.Authorized() {
+		return nil, nil, false, ErrUnauthorized
+	}
+	for _, g := range room.Grants {
+		if g.Investor == cv.User.ID && g.ActiveAt(time.Now()) {
+			return &room, prj, false, nil
+		}
+	}
+	return nil, nil, false, ErrUnauthorized
+}
+
+func (d *DataRoom) project(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	doc, err := d.st.FromKind("project").Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Data.(*models.Project), nil
+}
+
+// attachmentOwners returns what the files of the project may be attached to:
+// the project, its building, its work and monitoring phases and its
+// forfaiting application.
+func attachmentOwners(db *gorm.DB, prj models.Project) ([]uuid.UUID, error) {
+	owners := []uuid.UUID{prj.ID, prj.Asset}
+	for _, table := range []string{"work_phase", "monitoring_phase", "forfaiting_applications"} {
+		var ids []uuid.UUID
+		err := db.Table(table).
+			Where("project_id = ? AND deleted_at IS NULL", prj.ID).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return nil, err
+		}
+		owners = append(owners, ids...)
+	}
+	return owners, nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	var result []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+)
+
+func TestDownloadMark(t *testing.T) {
+	now := time.Date(2020, 1, 2, 17, 4, 0, 0, time.FixedZone("EET", 2*60*60))
+	if got := downloadMark(context.Background(), now); !reflect.DeepEqual(got, []string{"Downloaded 2020-01-02 15:04 UTC"}) {
+		t.Errorf("Expected the time only without a user, got %v", got)
+	}
+
+	ctx := services.WithContext(context.Background(), &models.Token{
+		User: models.User{Name: "Jane Doe", Email: "jane@example.com"},
+	})
+	want := []string{"Jane Doe <jane@example.com>", "Downloaded 2020-01-02 15:04 UTC"}
+	if got := downloadMark(ctx, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestDataRoomGrantActive(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	if !(models.DataRoomGrant{}).ActiveAt(now) {
+		t.Errorf("Expected a grant without expiry active")
+	}
+	if !(models.DataRoomGrant{ExpiresAt: &future}).ActiveAt(now) {
+		t.Errorf("Expected a grant expiring later active")
+	}
+	if (models.DataRoomGrant{ExpiresAt: &past}).ActiveAt(now) {
+		t.Errorf("Expected an expired grant inactive")
+	}
+}
+
+func TestIsPDF(t *testing.T) {
+	cases := []struct {
+		att  models.Attachment
+		want bool
+	}{
+		{models.Attachment{Name: "epc.pdf", ContentType: "application/octet-stream"}, true},
+		{models.Attachment{Name: "EPC.PDF"}, true},
+		{models.Attachment{Name: "epc", ContentType: "application/pdf"}, true},
+		{models.Attachment{Name: "photo.jpg", ContentType: "image/jpeg"}, false},
+	}
+	for _, c := range cases {
+		if got := isPDF(c.att); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.att.Name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// Downloads returns who downloaded the files and the contract of the
+// project and when, the latest first.
+func (p *Project) Downloads(ctx context.Context, id uuid.UUID, first, offset int) ([]models.Download, error) {
+	doc, err := p.st.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !Can(ctx, ListProjectDownloads, id, doc.Data.(*models.Project).Country) {
+		return nil, ErrUnauthorized
+	}
+	return downloads(p.st.DB().Where("project_id = ?", id), first, offset)
+}
+
+// Downloads returns the files and contracts the user downloaded and when,
+// the latest first.
+func (u *User) Downloads(ctx context.Context, id uuid.UUID, first, offset int) ([]models.Download, error) {
+	doc, err := u.st.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !Can(ctx, ListUserDownloads, id, doc.Data.(*models.User).Country) {
+		return nil, ErrUnauthorized
+	}
+	return downloads(u.st.DB().Where("user_id = ?", id), first, offset)
+}
+
+// SetWatermark sets the watermark stamped on the downloaded PDFs of the
+// project, or on one file of it if attachmentID is given. Files with no
+// watermark of their own get the one of the project.
+func (p *Project) SetWatermark(ctx context.Context, id uuid.UUID, attachmentID *uuid.UUID, wm models.Watermark) error {
+	if err := wm.Valid(); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+
+	doc, err := p.st.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	prj := doc.Data.(*models.Project)
+	if !Can(ctx, ManageWatermarks, id, prj.Country) {
+		return ErrUnauthorized
+	}
+
+	if attachmentID == nil {
+		return p.st.DB().Model(&models.Project{}).Where("id = ?", id).Update("watermark", wm).Error
+	}
+
+	owners, err := attachmentOwners(p.st.DB(), *prj)
+	if err != nil {
+		return err
+	}
+	res := p.st.DB().Model(&models.Attachment{}).
+		Where("id = ? AND owner_id IN (?)", *attachmentID, owners).
+		Update("watermark", wm)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: no such file of the project", ErrNotFound)
+	}
+	return nil
+}
+
+func downloads(db *gorm.DB, first, offset int) ([]models.Download, error) {
+	if offset != 0 {
+		db = db.Offset(offset)
+	}
+	if first != 0 {
+		db = db.Limit(first)
+	}
+
+	var result []models.Download
+	return result, db.Order("created_at DESC").Find(&result).Error
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestProjectOf(t *testing.T) {
+	prj := uuid.New()
+	cases := []struct {
+		name string
+		doc  *models.Document
+		want *uuid.UUID
+	}{
+		{"project", &models.Document{ID: prj, Data: &models.Project{}}, &prj},
+		{"work phase", &models.Document{ID: uuid.New(), Data: &models.WorkPhase{Project: prj}}, &prj},
+		{"forfaiting application", &models.Document{ID: uuid.New(), Data: &models.ForfaitingApplication{Project: prj}}, &prj},
+		{"task", &models.Document{ID: uuid.New(), Data: &models.Task{Project: prj}}, &prj},
+		{"asset", &models.Document{ID: uuid.New(), Data: &models.Asset{}}, nil},
+		{"user", &models.Document{ID: uuid.New(), Data: &models.User{}}, nil},
+	}
+	for _, c := range cases {
+		got := projectOf(c.doc)
+		if (got == nil) != (c.want == nil) || got != nil && *got != *c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestWatermarkValid(t *testing.T) {
+	for _, wm := range []models.Watermark{models.WatermarkNone, models.WatermarkDraft, models.WatermarkConfidential} {
+		if err := wm.Valid(); err != nil {
+			t.Errorf("%q: unexpected error %v", wm, err)
+		}
+	}
+	if err := models.Watermark("secret").Valid(); err == nil {
+		t.Errorf("Expected an unknown watermark invalid")
+	}
+	if s := models.WatermarkDraft.Stamp(); s != "DRAFT" {
+		t.Errorf("Expected stamp DRAFT, got %s", s)
+	}
+}
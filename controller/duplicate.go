@@ -0,0 +1,416 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"stageai.tech/sunshine/sunshine/dedup"
+	"stageai.tech/sunshine/sunshine/geo"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// Duplicate detects assets and organizations entered more than once and
+// merges them.
+type Duplicate struct {
+	st         stores.Store
+	uploadPath string
+}
+
+func NewDuplicate(env *services.Env) *Duplicate {
+	return &Duplicate{
+		st:         env.AssetStore,
+		uploadPath: env.Paths.Uploads,
+	}
+}
+
+// Asset returns the likely duplicates of the asset, i.e. assets of the same
+// country nearby or with the same cadastre number.
+func (d *Duplicate) Asset(ctx context.Context, id uuid.UUID) ([]dedup.Match, error) {
+	if !Can(ctx, GetAsset, id, "") {
+		return nil, ErrUnauthorized
+	}
+
+	var asset models.Asset
+	if err := d.st.DB().Where("id = ?", id).First(&asset).Error; err != nil {
+		return nil, err
+	}
+
+	box := geo.Around(asset.Coordinates, dedup.MaxDistance)
+	var candidates []models.Asset
+	err := d.st.DB().
+		Where("id <> ? AND country = ?", id, asset.Country).
+		Where(`(coords[1] BETWEEN ? AND ? AND coords[2] BETWEEN ? AND ?)
+			OR regexp_replace(cadastre, '\D', '', 'g') = NULLIF(?, '')`,
+			box.South, box.North, box.West, box.East, dedup.Digits(asset.Cadastre)).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dedup.AssetMatches(asset, candidates), nil
+}
+
+// Organization returns the likely duplicates of the organization among the
+// organizations of the same country.
+func (d *Duplicate) Organization(ctx context.Context, id uuid.UUID) ([]dedup.Match, error) {
+	if !Can(ctx, GetOrganization, id, "") {
+		return nil, ErrUnauthorized
+	}
+
+	var org models.Organization
+	if err := d.st.DB().Where("id = ?", id).First(&org).Error; err != nil {
+		return nil, err
+	}
+
+	var candidates []models.Organization
+	err := d.st.DB().
+		Select("id, name, vat, registration_number, address, country").
+		Where("id <> ? AND country = ?", id, org.Country).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dedup.OrganizationMatches(org, candidates), nil
+}
+
+// AssetReport returns the likely duplicate assets, optionally only of the
+// country.
+func (d *Duplicate) AssetReport(ctx context.Context, country models.Country) ([]dedup.Match, error) {
+	if !Can(ctx, ManageDuplicates, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+
+	q := d.st.DB().Select("id, address, cadastre, coords, country")
+	if country != "" {
+		q = q.Where("country = ?", country)
+	}
+
+	var assets []models.Asset
+	if err := q.Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return dedup.Assets(assets), nil
+}
+
+// OrganizationReport returns the likely duplicate organizations, optionally
+// only of the country.
+func (d *Duplicate) OrganizationReport(ctx context.Context, country models.Country) ([]dedup.Match, error) {
+	if !Can(ctx, ManageDuplicates, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+
+	q := d.st.DB().Select("id, name, vat, registration_number, address, country")
+	if country != "" {
+		q = q.Where("country = ?", country)
+	}
+
+	var orgs []models.Organization
+	if err := q.Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	return dedup.Organizations(orgs), nil
+}
+
+// MergeAssets moves the projects, project creation requests, notifications
+// and attachments of the duplicate asset to the one kept and deletes the
+// duplicate.
+func (d *Duplicate) MergeAssets(ctx context.Context, keep, duplicate uuid.UUID) error {
+	var kept, dup models.Asset
+	if err := d.mergeable(ctx, &kept, &dup, keep, duplicate); err != nil {
+		return err
+	}
+
+	return d.merge(ctx, keep, duplicate, &dup, []statement{
+		{"UPDATE projects SET asset = ? WHERE asset = ?", nil},
+		{"UPDATE create_project_request SET asset_id = ? WHERE asset_id = ?", nil},
+	})
+}
+
+// MergeOrganizations moves the assets, projects, roles, meetings,
+// notifications and attachments of the duplicate organization to the one kept
+// and deletes the duplicate. Roles the kept organization already has,
+// including its LEAR, are dropped.
+func (d *Duplicate) MergeOrganizations(ctx context.Context, keep, duplicate uuid.UUID) error {
+	var kept, dup models.Organization
+	if err := d.mergeable(ctx, &kept, &dup, keep, duplicate); err != nil {
+		return err
+	}
+
+	return d.merge(ctx, keep, duplicate, &dup, []statement{
+		{"UPDATE assets SET owner_id = ? WHERE owner_id = ?", nil},
+		{"UPDATE assets SET esco_id = ? WHERE esco_id = ?", nil},
+		{"UPDATE projects SET owner = ? WHERE owner = ?", nil},
+		{"UPDATE projects SET consortium_orgs = " + arrayReplace("consortium_orgs", "text") + " WHERE ?::text = ANY(consortium_orgs)",
+			[]interface{}{duplicate, keep, duplicate}},
+		{"UPDATE create_project_request SET organization_id = ? WHERE organization_id = ?", nil},
+		{"UPDATE meetings SET host = ? WHERE host = ?", nil},
+		{`UPDATE organization_roles r SET organization_id = ?
+			WHERE organization_id = ? AND NOT EXISTS (
+				SELECT 1 FROM organization_roles k
+				WHERE k.organization_id = ? AND (k.position = 'lear' AND r.position = 'lear'
+					OR k.user_id = r.user_id AND k.position = r.position))`,
+			[]interface{}{keep, duplicate, keep}},
+		{"DELETE FROM organization_roles WHERE organization_id = ?", []interface{}{duplicate}},
+	})
+}
+
+// MergeUsers moves the roles, project responsibilities, tasks, comments,
+// reviews, meeting invitations, notifications and attachments of the
+// duplicate user to the one kept and deletes the duplicate. Roles the kept user already has are dropped
+// and platform wide privileges of the duplicate are not carried over.
+func (d *Duplicate) MergeUsers(ctx context.Context, keep, duplicate uuid.UUID) error {
+	var kept, dup models.User
+	if err := d.mergeable(ctx, &kept, &dup, keep, duplicate); err != nil {
+		return err
+	}
+
+	stmts := []statement{
+		{"UPDATE projects SET portfolio_director = ? WHERE portfolio_director = ?", nil},
+		{"UPDATE projects SET fund_manager = ? WHERE fund_manager = ?", nil},
+		{"UPDATE tasks SET assignee = ? WHERE assignee = ?", nil},
+		{"UPDATE tasks SET author = ? WHERE author = ?", nil},
+		{"UPDATE meeting_action_items SET assignee = ? WHERE assignee = ?", nil},
+		{"UPDATE flats SET resident_id = ? WHERE resident_id = ?", nil},
+		{"UPDATE flats SET verified_by = ? WHERE verified_by = ?", nil},
+		{"UPDATE ballots SET author = ? WHERE author = ?", nil},
+		{"UPDATE votes SET user_id = ? WHERE user_id = ?", nil},
+		{"UPDATE forfaiting_transfers SET recorded_by = ? WHERE recorded_by = ?", nil},
+		{"UPDATE task_comments SET author = ? WHERE author = ?", nil},
+		{"UPDATE project_comments SET author = ? WHERE author = ?", nil},
+		{"UPDATE project_comments SET resolved_by = ? WHERE resolved_by = ?", nil},
+		{"UPDATE project_comments SET decided_by = ? WHERE decided_by = ?", nil},
+		{"UPDATE project_comments SET mentions = " + arrayReplace("mentions", "uuid") + " WHERE ?::uuid = ANY(mentions)",
+			[]interface{}{duplicate, keep, duplicate}},
+		{"UPDATE project_comment_revisions SET edited_by = ? WHERE edited_by = ?", nil},
+		{"UPDATE fa_reviews SET author = ? WHERE author = ?", nil},
+		{"UPDATE fa_review_decisions SET author = ? WHERE author = ?", nil},
+		{"UPDATE data_room_grants SET granted_by = ? WHERE granted_by = ?", nil},
+		{`UPDATE data_room_grants g SET investor_id = ?
+			WHERE investor_id = ? AND deleted_at IS NULL AND NOT EXISTS (
+				SELECT 1 FROM data_room_grants k
+				WHERE k.data_room_id = g.data_room_id AND k.investor_id = ? AND k.deleted_at IS NULL)`,
+			[]interface{}{keep, duplicate, keep}},
+		{"DELETE FROM data_room_grants WHERE investor_id = ?", []interface{}{duplicate}},
+		{"UPDATE downloads SET user_id = ? WHERE user_id = ?", nil},
+		{"UPDATE report_definitions SET author_id = ? WHERE author_id = ?", nil},
+		{"UPDATE wp_reviews SET author = ? WHERE author = ?", nil},
+		{"UPDATE mp_reviews SET author = ? WHERE author = ?", nil},
+		{"UPDATE forfaiting_applications SET manager_id = ? WHERE manager_id = ?", nil},
+		{"UPDATE contract_templates SET author = ? WHERE author = ?", nil},
+		{"UPDATE create_project_request SET user_id = ? WHERE user_id = ?", nil},
+		{"UPDATE gdpr_requests SET user_id = ? WHERE user_id = ?", nil},
+		{"UPDATE social_profiles SET user_id = ? WHERE user_id = ?", nil},
+		{"UPDATE notifications SET recipient = ? WHERE recipient = ?", nil},
+		{"UPDATE notifications SET user_id = ?, user_key = ? WHERE user_id = ?",
+			[]interface{}{keep, kept.Name, duplicate}},
+		// guests are invited by their email
+		{`UPDATE meeting_guests g SET name = ?, email = ?
+			WHERE lower(email) = lower(?) AND NOT EXISTS (
+				SELECT 1 FROM meeting_guests k
+				WHERE k.meeting_id = g.meeting_id AND lower(k.email) = lower(?) AND k.deleted_at IS NULL)`,
+			[]interface{}{kept.Name, kept.Email, dup.Email, kept.Email}},
+		{"DELETE FROM meeting_guests WHERE lower(email) = lower(?)", []interface{}{dup.Email}},
+		{"DELETE FROM tokens WHERE user_id = ?", []interface{}{duplicate}},
+	}
+	stmts = append(stmts, moveRoles("organization_roles", "organization_id", "position", keep, duplicate)...)
+	stmts = append(stmts, moveRoles("project_roles", "project_id", "position", keep, duplicate)...)
+	stmts = append(stmts, moveRoles("country_roles", "country", "role", keep, duplicate)...)
+
+	return d.merge(ctx, keep, duplicate, &dup, stmts)
+}
+
+// moveRoles returns the statements moving the roles of the duplicate user in
+// table to the one kept unless it already has them, in which case they are
+// deleted.
+func moveRoles(table, scope, role string, keep, duplicate uuid.UUID) []statement {
+	return []statement{
+		{fmt.Sprintf(`UPDATE %[1]s r SET user_id = ?
+			WHERE user_id = ? AND NOT EXISTS (
+				SELECT 1 FROM %[1]s k
+				WHERE k.user_id = ? AND k.%[2]s = r.%[2]s AND k.%[3]s = r.%[3]s)`, table, scope, role),
+			[]interface{}{keep, duplicate, keep}},
+		{fmt.Sprintf("DELETE FROM %s WHERE user_id = ?", table), []interface{}{duplicate}},
+	}
+}
+
+// arrayReplace returns the SQL expression replacing the first argument with
+// the second in the array column of given element type. Elements are kept in
+// their order and only once, the kept record may already be in the array.
+func arrayReplace(column, typ string) string {
+	return fmt.Sprintf(`ARRAY(
+		SELECT e FROM unnest(array_replace(%[1]s, ?::%[2]s, ?::%[2]s)) WITH ORDINALITY AS t(e, i)
+		GROUP BY e ORDER BY min(i))`, column, typ)
+}
+
+// Redirect returns the ID of the record the one with the given ID was merged
+// into.
+func (d *Duplicate) Redirect(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	var m models.Merge
+	err := d.st.DB().Where("source = ?", id).First(&m).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return uuid.Nil, fmt.Errorf("%w: %v was not merged", ErrNotFound, id)
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return m.Target, nil
+}
+
+// Merges returns the audit trail of the records merged into the one with the
+// given ID, the latest first.
+func (d *Duplicate) Merges(ctx context.Context, id uuid.UUID) ([]models.Merge, error) {
+	if !Can(ctx, ManageDuplicates, uuid.Nil, "") {
+		return nil, ErrUnauthorized
+	}
+
+	var merges []models.Merge
+	err := d.st.DB().Where("target = ?", id).Order("created_at DESC").Find(&merges).Error
+	return merges, err
+}
+
+// statement is a SQL statement of a merge. Without args the IDs of the kept
+// and the duplicate record are its arguments.
+type statement struct {
+	sql  string
+	args []interface{}
+}
+
+// mergeable loads the records to be merged into kept and dup and checks the
+// current user is allowed to merge them.
+func (d *Duplicate) mergeable(ctx context.Context, kept, dup models.Entity, keep, duplicate uuid.UUID) error {
+	if keep == duplicate {
+		return fmt.Errorf("%w: cannot merge %v with itself", ErrBadInput, keep)
+	}
+
+	if err := d.st.DB().Where("id = ?", keep).First(kept).Error; err != nil {
+		return err
+	}
+	if err := d.st.DB().Where("id = ?", duplicate).First(dup).Error; err != nil {
+		return err
+	}
+
+	kc, dc := entityCountry(kept), entityCountry(dup)
+	if kc != dc {
+		return fmt.Errorf("%w: cannot merge records of %s and %s", ErrBadInput, kc, dc)
+	}
+	if !Can(ctx, ManageDuplicates, uuid.Nil, kc) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+func entityCountry(e models.Entity) models.Country {
+	switch v := e.(type) {
+	case *models.Asset:
+		return v.Country
+	case *models.Organization:
+		return v.Country
+	case *models.User:
+		return v.Country
+	default:
+		return ""
+	}
+}
+
+// merge runs the statements, moves the attachments and notifications to the
+// kept record, deletes the duplicate and records the merge in a single
+// transaction. The uploaded files are renamed before it is committed and
+// renamed back if it fails.
+func (d *Duplicate) merge(ctx context.Context, keep, duplicate uuid.UUID, dup models.Entity, stmts []statement) error {
+	var atts []models.Attachment
+	if err := d.st.DB().Where("owner_id = ?", duplicate).Find(&atts).Error; err != nil {
+		return err
+	}
+
+	stmts = append(stmts,
+		statement{"UPDATE attachments SET owner_id = ? WHERE owner_id = ?", nil},
+		statement{"UPDATE notifications SET target_id = ? WHERE target_id = ?", nil},
+		// records merged into the duplicate earlier now redirect to keep
+		statement{"UPDATE merges SET target = ? WHERE target = ?", nil},
+	)
+
+	m := models.Merge{
+		ID:     uuid.New(),
+		Kind:   dup.Kind(),
+		Source: duplicate,
+		Target: keep,
+	}
+	if cv := services.FromContext(ctx); cv.Authorized() {
+		m.Author = &cv.User.ID
+	}
+
+	tx := d.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	for _, s := range stmts {
+		args := s.args
+		if args == nil {
+			args = []interface{}{keep, duplicate}
+		}
+		if err := tx.Exec(s.sql, args...).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Delete(dup).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(&m).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// uploaded files are named after their owner
+	renamed, err := d.renameUploads(atts, duplicate, keep)
+	if err != nil {
+		d.renameUploads(renamed, keep, duplicate)
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		d.renameUploads(renamed, keep, duplicate)
+		return err
+	}
+	return nil
+}
+
+// renameUploads renames the uploaded files of the attachments from owner from
+// to owner to. It returns the attachments whose files were renamed, also when
+// it fails. Attachments without file are skipped.
+func (d *Duplicate) renameUploads(atts []models.Attachment, from, to uuid.UUID) ([]models.Attachment, error) {
+	var renamed []models.Attachment
+	for _, a := range atts {
+		err := os.Rename(
+			filepath.Join(d.uploadPath, fmt.Sprintf("%s-%s", from, a.ID)),
+			filepath.Join(d.uploadPath, fmt.Sprintf("%s-%s", to, a.ID)),
+		)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return renamed, err
+		}
+		renamed = append(renamed, a)
+	}
+	return renamed, nil
+}
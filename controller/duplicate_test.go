@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+)
+
+func TestDuplicateAssets(t *testing.T) {
+	e := services.NewTestEnv(t)
+	c := NewDuplicate(e)
+
+	a1 := stores.NewTestAsset(t, e.AssetStore,
+		stores.TAWithAddr("Brīvības iela 10, Rīga"),
+		stores.TAWithCoords(models.Coords{Lat: 56.9566, Lng: 24.1191}),
+	)
+	a2 := stores.NewTestAsset(t, e.AssetStore,
+		stores.TAWithAddr("Brivibas 10, Riga"),
+		stores.TAWithCoords(models.Coords{Lat: 56.9570, Lng: 24.1200}),
+	)
+	prj := stores.NewTestProject(t, e.ProjectStore, stores.TPrjWithAsset(a2.ID))
+	att := stores.NewTestAttachment(t, e.AssetStore, a2.ID)
+
+	ctx := services.NewTestContext(t, e, stores.NewTestUser(t, e.UserStore))
+	actx := services.NewTestContext(t, e, stores.NewTestAdmin(t, e.UserStore))
+
+	matches, err := c.Asset(ctx, a1.ID)
+	if err != nil {
+		t.Fatalf("Asset: %v", err)
+	}
+	if len(matches) != 1 || matches[0].B != a2.ID {
+		t.Fatalf("Expected a match with %v, got %v", a2.ID, matches)
+	}
+
+	if err := c.MergeAssets(ctx, a1.ID, a2.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if err := c.MergeAssets(actx, a1.ID, a1.ID); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected ErrBadInput, got %v", err)
+	}
+	if err := c.MergeAssets(actx, a1.ID, a2.ID); err != nil {
+		t.Fatalf("MergeAssets: %v", err)
+	}
+
+	var p models.Project
+	if err := e.DB.Where("id = ?", prj.ID).First(&p).Error; err != nil {
+		t.Fatalf("load project: %v", err)
+	}
+	if p.Asset != a1.ID {
+		t.Errorf("Expected project of asset %v, got %v", a1.ID, p.Asset)
+	}
+
+	var a models.Attachment
+	if err := e.DB.Where("id = ?", att.ID).First(&a).Error; err != nil {
+		t.Fatalf("load attachment: %v", err)
+	}
+	if a.Owner != a1.ID {
+		t.Errorf("Expected attachment of asset %v, got %v", a1.ID, a.Owner)
+	}
+
+	if _, err := e.AssetStore.Get(actx, a2.ID); err == nil {
+		t.Errorf("Expected the duplicate to be deleted")
+	}
+	if id, err := c.Redirect(ctx, a2.ID); err != nil || id != a1.ID {
+		t.Errorf("Expected redirect to %v, got %v, %v", a1.ID, id, err)
+	}
+}
+
+func TestDuplicateUsers(t *testing.T) {
+	e := services.NewTestEnv(t)
+	c := NewDuplicate(e)
+
+	keep := stores.NewTestUser(t, e.UserStore)
+	dup := stores.NewTestUser(t, e.UserStore)
+	org := stores.NewTestOrg(t, e.OrganizationStore, keep.ID, dup.ID)
+	prj := stores.NewTestProject(t, e.ProjectStore, stores.TPrjWithPm(dup.ID))
+	mtng := stores.NewTestMeeting(t, e.MeetingsStore, func(m *models.Meeting) {
+		u := dup.Data.(*models.User)
+		m.Guests = []models.MeetingGuest{{Name: u.Name, Email: u.Email, Type: models.StakeHoldersTypeNGO}}
+	})
+
+	admin := stores.NewTestAdmin(t, e.UserStore)
+	actx := services.NewTestContext(t, e, admin)
+
+	if err := c.MergeUsers(actx, keep.ID, dup.ID); err != nil {
+		t.Fatalf("MergeUsers: %v", err)
+	}
+
+	var n int
+	e.DB.Model(&models.ProjectRole{}).Where("project_id = ? AND user_id = ?", prj.ID, keep.ID).Count(&n)
+	if n != 1 {
+		t.Errorf("Expected the project role to be moved, got %d roles", n)
+	}
+	e.DB.Model(&models.OrganizationRole{}).Where("organization_id = ? AND user_id = ?", org.ID, dup.ID).Count(&n)
+	if n != 0 {
+		t.Errorf("Expected no organization roles of the duplicate, got %d", n)
+	}
+
+	e.DB.Model(&models.MeetingGuest{}).Where("meeting_id = ? AND email = ?", mtng.ID, keep.Data.(*models.User).Email).Count(&n)
+	if n != 1 {
+		t.Errorf("Expected the meeting guest to be moved, got %d guests", n)
+	}
+
+	merges, err := c.Merges(actx, keep.ID)
+	if err != nil {
+		t.Fatalf("Merges: %v", err)
+	}
+	if len(merges) != 1 || merges[0].Source != dup.ID || *merges[0].Author != admin.ID {
+		t.Errorf("Expected the merge to be recorded, got %v", merges)
+	}
+
+	// merging the kept user further redirects the duplicate too
+	other := stores.NewTestUser(t, e.UserStore)
+	if err := c.MergeUsers(actx, other.ID, keep.ID); err != nil {
+		t.Fatalf("MergeUsers: %v", err)
+	}
+	if id, err := c.Redirect(actx, dup.ID); err != nil || id != other.ID {
+		t.Errorf("Expected redirect to %v, got %v, %v", other.ID, id, err)
+	}
+}
+
+func TestRenameUploads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d := Duplicate{uploadPath: dir}
+	from, to := uuid.New(), uuid.New()
+	atts := []models.Attachment{{Value: models.Value{ID: uuid.New()}}, {Value: models.Value{ID: uuid.New()}}}
+	if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%s-%s", from, atts[0].ID)), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := d.renameUploads(atts, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(renamed) != 1 || renamed[0].ID != atts[0].ID {
+		t.Errorf("Expected only the attachment with a file renamed, got %v", renamed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("%s-%s", to, atts[0].ID))); err != nil {
+		t.Errorf("Expected the file of the new owner, got %v", err)
+	}
+
+	if _, err := d.renameUploads(renamed, to, from); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("%s-%s", from, atts[0].ID))); err != nil {
+		t.Errorf("Expected the file renamed back, got %v", err)
+	}
+}
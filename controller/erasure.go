@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// erasedName replaces the name of anonymized users and meeting guests.
+const erasedName = "Anonymized"
+
+// erasedText replaces personal data within free text, e.g. notifications.
+const erasedText = "[erased]"
+
+// erasure reports what was done to anonymize a user.
+type erasure struct {
+	User          uuid.UUID
+	Files         int
+	Roles         int
+	LEARs         int // organizations whose LEAR was reassigned
+	Notifications int
+	MeetingGuests int
+	Comments      int
+	Mentions      int // comments the user was mentioned in
+
+	// files are the uploaded files of the user to remove
+	files []string
+}
+
+// anonymize erases the personal data of the user within the transaction tx,
+// i.e. the profile, the uploaded files, the roles, the comments and the
+// traces in notifications, mentions and meeting guests. The user record is
+// kept with its ID so that the projects and reviews it took part in stay
+// consistent. The uploaded files are to be removed with removeFiles once tx
+// is committed.
+func anonymize(tx *gorm.DB, uploadPath string, u models.User) (*erasure, error) {
+	var atts []models.Attachment
+	if err := tx.Unscoped().Where("owner_id = ?", u.ID).Find(&atts).Error; err != nil {
+		return nil, err
+	}
+
+	e := erasure{User: u.ID, Files: len(atts)}
+	for _, a := range atts {
+		e.files = append(e.files, filepath.Join(uploadPath, fmt.Sprintf("%s-%s", a.Owner, a.ID)))
+	}
+
+	// stmt is a statement whose affected rows are counted in n.
+	type stmt struct {
+		n    *int
+		sql  string
+		args []interface{}
+	}
+
+	// the first LEAA becomes LEAR of the organizations the user is LEAR of
+	stmts := []stmt{
+		{&e.LEARs, `UPDATE organization_roles r SET position = 'lear'
+			WHERE r.id IN (
+				SELECT DISTINCT ON (a.organization_id) a.id
+				FROM organization_roles a
+				JOIN organization_roles l ON l.organization_id = a.organization_id
+				WHERE l.user_id = ? AND l.position = 'lear' AND a.position = 'leaa' AND a.user_id <> ?
+				ORDER BY a.organization_id, a.created_at)`, []interface{}{u.ID, u.ID}},
+		{&e.Roles, "DELETE FROM organization_roles WHERE user_id = ?", []interface{}{u.ID}},
+		{&e.Roles, "DELETE FROM project_roles WHERE user_id = ?", []interface{}{u.ID}},
+		{&e.Roles, "DELETE FROM country_roles WHERE user_id = ?", []interface{}{u.ID}},
+		{nil, "DELETE FROM social_profiles WHERE user_id = ?", []interface{}{u.ID}},
+		{nil, "DELETE FROM tokens WHERE user_id = ?", []interface{}{u.ID}},
+		{nil, "DELETE FROM attachments WHERE owner_id = ?", []interface{}{u.ID}},
+		{&e.Notifications, "DELETE FROM notifications WHERE recipient = ?", []interface{}{u.ID}},
+		{&e.Notifications, "UPDATE notifications SET user_key = '' WHERE user_id = ?", []interface{}{u.ID}},
+		{&e.Notifications, "UPDATE notifications SET target_key = '' WHERE target_id = ?", []interface{}{u.ID}},
+		// the comments of the user are kept as placeholders so that the
+		// threads they are in stay readable
+		{&e.Comments, "UPDATE project_comments SET content = ? WHERE author = ?", []interface{}{erasedText, u.ID}},
+		{nil, `UPDATE project_comment_revisions SET content = ?
+			WHERE comment_id IN (SELECT id FROM project_comments WHERE author = ?)`, []interface{}{erasedText, u.ID}},
+		{&e.Mentions, "UPDATE project_comments SET mentions = array_remove(mentions, ?::uuid) WHERE ?::uuid = ANY(mentions)", []interface{}{u.ID, u.ID}},
+	}
+	for _, v := range personalData(u) {
+		stmts = append(stmts, stmt{&e.Notifications, `UPDATE notifications SET old = replace(old, ?, ?), new = replace(new, ?, ?)
+			WHERE strpos(old, ?) > 0 OR strpos(new, ?) > 0`, []interface{}{v, erasedText, v, erasedText, v, v}})
+	}
+	if u.Email != "" {
+		stmts = append(stmts, stmt{&e.MeetingGuests, "UPDATE meeting_guests SET name = ?, email = '', phone = '', signature = '' WHERE lower(email) = lower(?)",
+			[]interface{}{erasedName, u.Email}})
+	}
+	stmts = append(stmts, stmt{nil, `UPDATE users SET name = ?, email = ?, password = '', address = '', avatar = '',
+		identity = '', telephone = '', is_active = false, anonymized_at = now()
+		WHERE id = ?`, []interface{}{erasedName, fmt.Sprintf("%s@anonymized.invalid", u.ID), u.ID}})
+
+	for _, s := range stmts {
+		res := tx.Exec(s.sql, s.args...)
+		if err := res.Error; err != nil {
+			return nil, err
+		}
+		if s.n != nil {
+			*s.n += int(res.RowsAffected)
+		}
+	}
+	return &e, nil
+}
+
+// personalData returns the values identifying the user which may appear in
+// free text. Values too short to be told apart from other text are left out.
+func personalData(u models.User) []string {
+	var result []string
+	for _, v := range []string{u.Name, u.Email, u.Telephone, u.Address} {
+		if v = strings.TrimSpace(v); len(v) >= 4 {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// certificate returns the text of the certificate of completing the erasure
+// requested with the request.
+func (e erasure) certificate(req models.GDPRRequest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "GDPR erasure certificate\n\n")
+	fmt.Fprintf(&b, "Request:       %s\n", req.ID)
+	fmt.Fprintf(&b, "Data subject:  %s\n", e.User)
+	if req.CompletedAt != nil {
+		fmt.Fprintf(&b, "Completed at:  %s\n", req.CompletedAt.UTC().Format(time.RFC3339))
+	}
+	if req.Reviewer != nil {
+		fmt.Fprintf(&b, "Approved by:   %s\n", *req.Reviewer)
+	}
+	fmt.Fprintf(&b, "\nThe personal data of the data subject has been erased:\n")
+	fmt.Fprintf(&b, "- profile: name, email, telephone, address, identity and avatar\n")
+	fmt.Fprintf(&b, "- uploaded files deleted: %d\n", e.Files)
+	fmt.Fprintf(&b, "- roles detached: %d\n", e.Roles)
+	fmt.Fprintf(&b, "- organizations with LEAR reassigned: %d\n", e.LEARs)
+	fmt.Fprintf(&b, "- notification entries scrubbed: %d\n", e.Notifications)
+	fmt.Fprintf(&b, "- meeting guest records anonymized: %d\n", e.MeetingGuests)
+	fmt.Fprintf(&b, "- comments erased: %d\n", e.Comments)
+	fmt.Fprintf(&b, "- mentions removed: %d\n", e.Mentions)
+	return b.String()
+}
+
+// AnonymizeInactive anonymizes the users who haven't logged in since the
+// given time and returns how many were anonymized. Platform administrators
+// are never anonymized.
+func (g GDPR) AnonymizeInactive(since time.Time) (int, error) {
+	var users []models.User
+	err := g.db.Unscoped().
+		Where("anonymized_at IS NULL AND COALESCE(last_login, updated_at) < ?", since).
+		Where("NOT is_admin AND NOT platform_manager AND NOT admin_network_manager").
+		Find(&users).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, u := range users {
+		if err := g.anonymizeInactive(u); err != nil {
+			log.Printf("anonymize user %v: %v", u.ID, err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (g GDPR) anonymizeInactive(u models.User) error {
+	tx := g.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	e, err := anonymize(tx, g.uppath, u)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	removeFiles(e.files)
+	return nil
+}
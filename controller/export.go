@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/jinzhu/gorm"
+)
+
+// accessData is everything linked to a user, exported on a subject access
+// request.
+type accessData struct {
+	Profile         *models.User            `json:"profile"`
+	Organizations   []models.Organization   `json:"organizations"`
+	Projects        []models.Project        `json:"projects"`
+	Meetings        []models.Meeting        `json:"meetings"`
+	Notifications   []models.Notification   `json:"notifications"`
+	ProjectComments []models.ProjectComment `json:"project_comments"`
+	TaskComments    []models.TaskComment    `json:"task_comments"`
+	Tasks           []models.Task           `json:"tasks"`
+	Votes           []models.Vote           `json:"votes"`
+	Attachments     []models.Attachment     `json:"attachments"`
+}
+
+// collectAccessData gathers the data linked to the user.
+func collectAccessData(db *gorm.DB, u models.User) (*accessData, error) {
+	var d accessData
+
+	var profile models.User
+	err := db.Unscoped().
+		Preload("SocialProfiles").
+		Preload("ProjectRoles").
+		Preload("OrganizationRoles").
+		Preload("CountryRoles").
+		Where("id = ?", u.ID).
+		First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	// the password hash is a credential, not data about the user
+	profile.Password = ""
+	d.Profile = &profile
+
+	queries := []struct {
+		dst   interface{}
+		query *gorm.DB
+	}{
+		{&d.Organizations, db.Where("id IN (SELECT organization_id FROM organization_roles WHERE user_id = ?)", u.ID)},
+		{&d.Projects, db.Where("id IN (SELECT project_id FROM project_roles WHERE user_id = ?) OR portfolio_director = ? OR fund_manager = ?",
+			u.ID, u.ID, u.ID)},
+		{&d.Meetings, db.Preload("Guests", "lower(email) = lower(?)", u.Email).
+			Where("id IN (SELECT meeting_id FROM meeting_guests WHERE lower(email) = lower(?) AND deleted_at IS NULL)", u.Email)},
+		{&d.Notifications, db.Where("recipient = ? OR user_id = ?", u.ID, u.ID)},
+		{&d.ProjectComments, db.Where("author = ?", u.ID)},
+		{&d.TaskComments, db.Where("author = ?", u.ID)},
+		{&d.Tasks, db.Where("assignee = ?", u.ID)},
+		{&d.Votes, db.Where("user_id = ?", u.ID)},
+		{&d.Attachments, db.Where("owner_id = ?", u.ID)},
+	}
+	for _, q := range queries {
+		if err := q.query.Order("created_at").Find(q.dst).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &d, nil
+}
+
+// summaryData is the data summarised in the PDF of an access archive along
+// with the uploaded files missing from it.
+type summaryData struct {
+	*accessData
+	Missing []models.Attachment
+}
+
+// accessArchive writes a ZIP archive with the data as JSON, a PDF summary
+// of it and the files uploaded for the user. Files missing from the disk are
+// listed in the summary.
+func accessArchive(ctx context.Context, w io.Writer, d *accessData, uploadPath string) error {
+	z := zip.NewWriter(w)
+
+	f, err := z.Create("data.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return err
+	}
+
+	// files missing from the disk are listed in the summary instead
+	var missing []models.Attachment
+	for _, a := range d.Attachments {
+		src, err := os.Open(filepath.Join(uploadPath, fmt.Sprintf("%s-%s", a.Owner, a.ID)))
+		if os.IsNotExist(err) {
+			missing = append(missing, a)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// the attachment ID keeps the names of the files unique
+		f, err := z.Create(path.Join("files", fmt.Sprintf("%s-%s", a.ID, path.Base(a.Name))))
+		if err == nil {
+			_, err = io.Copy(f, src)
+		}
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	var tex bytes.Buffer
+	if err := accessSummary.Execute(&tex, summaryData{d, missing}); err != nil {
+		return err
+	}
+	pdf, err := contract.RenderPDF(ctx, &tex)
+	if err != nil {
+		return fmt.Errorf("generate summary: %w", err)
+	}
+	defer pdf.Close()
+
+	if f, err = z.Create("summary.pdf"); err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, pdf); err != nil {
+		return err
+	}
+
+	return z.Close()
+}
+
+var accessSummary = texDocument("summary", "11pt", "margin=2cm", template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("2006-01-02")
+	},
+}, `
+\section*{Personal data of << tex .Profile.Name >>}
+
+This document summarises the personal data kept about you. The complete data
+in machine-readable form is in the file data.json of this archive.
+
+\subsection*{Profile}
+\begin{tabular}{ll}
+Name & << tex .Profile.Name >> \\
+Email & << tex .Profile.Email >> \\
+Telephone & << tex .Profile.Telephone >> \\
+Address & << tex .Profile.Address >> \\
+Country & << tex (print .Profile.Country) >> \\
+Registered on & << date .Profile.CreatedAt >> \\
+\end{tabular}
+
+\subsection*{Roles}
+\begin{itemize}
+\item[] Organizations: << len .Profile.OrganizationRoles >>
+\item[] Projects: << len .Profile.ProjectRoles >>
+\item[] Countries: << len .Profile.CountryRoles >>
+\end{itemize}
+
+\subsection*{Organizations}
+<<- if .Organizations >>
+\begin{itemize}
+<<- range .Organizations >>
+\item << tex .Name >>
+<<- end >>
+\end{itemize}
+<<- else >>
+None.
+<<- end >>
+
+\subsection*{Projects}
+<<- if .Projects >>
+\begin{itemize}
+<<- range .Projects >>
+\item << tex .Name >>
+<<- end >>
+\end{itemize}
+<<- else >>
+None.
+<<- end >>
+
+\subsection*{Meetings attended as a guest}
+<<- if .Meetings >>
+\begin{itemize}
+<<- range .Meetings >>
+\item << date .Date >> << tex .Name >>, << tex .Location >>
+<<- end >>
+\end{itemize}
+<<- else >>
+None.
+<<- end >>
+
+\subsection*{Other records}
+\begin{itemize}
+\item[] Notifications: << len .Notifications >>
+\item[] Project comments: << len .ProjectComments >>
+\item[] Task comments: << len .TaskComments >>
+\item[] Tasks assigned: << len .Tasks >>
+\item[] Ballot votes: << len .Votes >>
+\item[] Uploaded files: << len .Attachments >>
+\end{itemize}
+<<- if .Missing >>
+
+\subsection*{Files no longer available}
+These files are recorded as uploaded for you but could not be found, so they
+are missing from this archive.
+\begin{itemize}
+<<- range .Missing >>
+\item << date .CreatedAt >> << tex .Name >>
+<<- end >>
+\end{itemize}
+<<- end >>
+`)
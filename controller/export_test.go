@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+)
+
+func TestCollectAccessData(t *testing.T) {
+	e := services.NewTestEnv(t)
+
+	u := *stores.NewTestUser(t, e.UserStore).Data.(*models.User)
+	other := stores.NewTestUser(t, e.UserStore)
+	prj := stores.NewTestProject(t, e.ProjectStore)
+
+	task := models.Task{Project: prj.ID, Title: "Read the meters", Assignee: &u.ID}
+	if err := e.DB.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	// notifications about the user sent to others are not the user's data
+	n := models.Notification{RecipientID: other.ID, UserID: other.ID, TargetID: u.ID, Action: models.UserActionAssign}
+	if err := e.DB.Create(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := collectAccessData(e.DB, u)
+	if err != nil {
+		t.Fatalf("collectAccessData: %v", err)
+	}
+	if d.Profile.Password != "" {
+		t.Error("Expected no password in the profile")
+	}
+	if len(d.Tasks) != 1 || d.Tasks[0].ID != task.ID {
+		t.Errorf("Expected the assigned task, got %v", d.Tasks)
+	}
+	if len(d.Notifications) != 0 {
+		t.Errorf("Expected no notifications, got %v", d.Notifications)
+	}
+}
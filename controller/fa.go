@@ -3,9 +3,10 @@ package controller
 import (
 	"context"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
+	"stageai.tech/sunshine/sunshine/bankaccount"
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/services"
 	"stageai.tech/sunshine/sunshine/stores"
@@ -47,12 +48,15 @@ func (f *ForfaitingAgreement) Create(ctx context.Context, fa models.ForfaitingAp
 		return nil, fmt.Errorf("%w: milestone is lower than 'forfaiting_payout'", ErrBadInput)
 	}
 
-	fa.Reviews = []models.FAReview{
-		{Approved: false, Type: models.FAReviewTypeExecutive},
-		{Approved: false, Type: models.FAReviewTypeFinancial},
-		{Approved: false, Type: models.FAReviewTypeGuidelines},
-		{Approved: false, Type: models.FAReviewTypeTechnical},
+	if err := validBankAccount(&fa.BankAccount); err != nil {
+		return nil, err
+	}
+
+	steps, err := f.chain(prj.Country)
+	if err != nil {
+		return nil, err
 	}
+	fa.Reviews = newReviews(steps, time.Now())
 
 	fms, err := f.pf.GetPortfolioRolesPerCountry(ctx, prj.Country, models.FundManagerRole)
 	if err == nil {
@@ -66,7 +70,7 @@ func (f *ForfaitingAgreement) Create(ctx context.Context, fa models.ForfaitingAp
 			TargetKey:  prj.Name,
 			Country:    prj.Country,
 		}
-		if !prj.Country.IsConsortium() {
+		if !prj.Country.Consortium() {
 			fms = []uuid.UUID{getANWManager(f.st)}
 		}
 		notifyAll(ctx, f.notifier, fms, n)
@@ -121,38 +125,104 @@ func (f *ForfaitingAgreement) Get(ctx context.Context, id uuid.UUID) (*models.Fo
 	return fa, nil
 }
 
+// Review decides on a step of the review chain of the forfaiting
+// application, given by the ID of the review or its type. A rejection sends
+// the application back to the sendBackTo step, if given.
 func (f *ForfaitingAgreement) Review(ctx context.Context,
-	id uuid.UUID, review models.FAReview) error {
+	id uuid.UUID, review models.FAReview, sendBackTo *models.FAReviewType) error {
 	fad, err := f.st.Get(ctx, id)
 	if err != nil {
 		return err
 	}
 	fa := fad.Data.(*models.ForfaitingApplication)
 
-	if !f.can(ctx, ReviewFA, fa.Project) {
+	prjd, err := f.st.FromKind("project").Get(ctx, fa.Project)
+	if err != nil {
+		return err
+	}
+	prj := prjd.Data.(*models.Project)
+
+	var reviews []models.FAReview
+	err = f.st.DB().
+		Where("forfaiting_application_id = ?", id).
+		Order("stage, type").
+		Find(&reviews).Error
+	if err != nil {
+		return err
+	}
+
+	i := -1
+	for k, r := range reviews {
+		if review.ID != uuid.Nil && r.ID == review.ID ||
+			review.ID == uuid.Nil && r.Type == review.Type {
+			i = k
+		}
+	}
+	if i < 0 {
+		if !Can(ctx, ReviewFA, prj.ID, prj.Country) {
+			return ErrUnauthorized
+		}
+		return ErrNotFound
+	}
+	if !Can(ctx, reviewAction(reviews[i]), prj.ID, prj.Country) {
 		return ErrUnauthorized
 	}
 
-	r := models.FAReview{
-		ForfaitingApplicationID: id,
-		Type:                    review.Type,
-		Approved:                review.Approved,
-		Comment:                 review.Comment,
-		Author:                  &services.FromContext(ctx).User.ID,
+	wasApproved := models.ForfaitingApplication{Reviews: reviews}.Approved()
+	before := make([]models.FAReview, len(reviews))
+	copy(before, reviews)
+
+	if err := decide(reviews, i, review.Approved, sendBackTo, time.Now()); err != nil {
+		return err
 	}
 
-	if review.ID != uuid.Nil {
-		r.ID = review.ID
+	cv := services.FromContext(ctx)
+	reviews[i].Comment = review.Comment
+	reviews[i].Author = &cv.User.ID
+
+	// start transaction block
+	tx := f.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
 	}
 
-	if r.Approved && r.Type == models.FAReviewTypeExecutive {
-		cv := services.FromContext(ctx)
-		prjd, err := f.st.FromKind("project").Get(ctx, fa.Project)
-		if err != nil {
+	for k := range reviews {
+		if k != i && sameState(before[k], reviews[k]) {
+			continue
+		}
+		if err := tx.Save(&reviews[k]).Error; err != nil {
+			tx.Rollback()
 			return err
 		}
-		prj := prjd.Data.(*models.Project)
+	}
+
+	d := models.FAReviewDecision{
+		ForfaitingApplicationID: id,
+		Review:                  reviews[i].ID,
+		Type:                    reviews[i].Type,
+		Stage:                   reviews[i].Stage,
+		Author:                  &cv.User.ID,
+		Approved:                review.Approved,
+		Comment:                 review.Comment,
+		SendBackTo:              sendBackTo,
+	}
+	if err := tx.Create(&d).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
 
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	// end transaction block
+
+	if !wasApproved && (models.ForfaitingApplication{Reviews: reviews}).Approved() {
 		n := models.Notification{
 			Action:     models.UserActionApproveForfaitingApplication,
 			UserID:     cv.User.ID,
@@ -177,7 +247,26 @@ func (f *ForfaitingAgreement) Review(ctx context.Context,
 		notifyAll(ctx, f.notifier, recs, n)
 	}
 
-	return f.st.DB().Save(&r).Error
+	return nil
+}
+
+// validBankAccount normalises the IBAN and the SWIFT code of the account and
+// checks them. An account without both is yet to be filled in.
+func validBankAccount(ba *models.BankAccount) error {
+	ba.IBAN = bankaccount.Normalize(ba.IBAN)
+	ba.SWIFT = bankaccount.Normalize(ba.SWIFT)
+	if ba.IBAN == "" && ba.SWIFT == "" {
+		return nil
+	}
+
+	if errs := bankaccount.Validate(ba.IBAN, ba.SWIFT); len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = err.Error()
+		}
+		return fmt.Errorf("%w: invalid bank account: %s", ErrBadInput, strings.Join(msgs, "; "))
+	}
+	return nil
 }
 
 func (f *ForfaitingAgreement) can(ctx context.Context, a Action, prjID uuid.UUID) bool {
@@ -196,7 +285,7 @@ func (f *ForfaitingAgreement) FetchReviews(ctx context.Context,
 	var result []models.FAReview
 	return result, f.st.DB().
 		Where("forfaiting_application_id IN (?)", ids).
-		Order("created_at DESC").Find(&result).Error
+		Order("stage, type").Find(&result).Error
 }
 
 func (f *ForfaitingAgreement) FetchAttachments(ctx context.Context, attType string, ids ...uuid.UUID) ([]models.Attachment, error) {
@@ -252,6 +341,16 @@ func (f *ForfaitingAgreement) Update(ctx context.Context, faID uuid.UUID, fa mod
 		foundFA.BankAccount.IBAN = fa.BankAccount.IBAN
 	}
 
+	if fa.BankAccount.SWIFT != "" {
+		foundFA.BankAccount.SWIFT = fa.BankAccount.SWIFT
+	}
+
+	if fa.BankAccount.IBAN != "" || fa.BankAccount.SWIFT != "" {
+		if err := validBankAccount(&foundFA.BankAccount); err != nil {
+			return nil, err
+		}
+	}
+
 	if fa.BankAccount.BeneficiaryName != "" {
 		foundFA.BankAccount.BeneficiaryName = fa.BankAccount.BeneficiaryName
 	}
@@ -329,7 +428,7 @@ func (f *ForfaitingAgreement) UploadFile(ctx context.Context, faid uuid.UUID, fo
 	return uploadFile(ctx, f.st, f.notifier, form, fadoc, f.uploadPath)
 }
 
-func (f *ForfaitingAgreement) GetFile(ctx context.Context, faid uuid.UUID, filename string) (*models.Attachment, *os.File, error) {
+func (f *ForfaitingAgreement) GetFile(ctx context.Context, faid uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
 	fadoc, err := f.st.Get(ctx, faid)
 	if err != nil {
 		return nil, nil, err
@@ -342,7 +441,7 @@ func (f *ForfaitingAgreement) GetFile(ctx context.Context, faid uuid.UUID, filen
 		return nil, nil, ErrUnauthorized
 	}
 
-	return getFile(ctx, f.st, faid, filename, f.uploadPath)
+	return getFile(ctx, f.st, faid, filename, f.uploadPath, fr)
 }
 
 func (f *ForfaitingAgreement) DeleteFile(ctx context.Context, faid uuid.UUID, filename string) error {
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -40,10 +41,10 @@ func createFA(t *testing.T) {
 		Finance:     models.FinanceBankFunding,
 		PrivateBond: true,
 		BankAccount: models.BankAccount{
-			IBAN:            "iban",
+			IBAN:            "LV80 BANK 0000 4351 9500 1",
 			BankNameAddress: "end of the world",
 			BeneficiaryName: "John Doe",
-			SWIFT:           "BC1234XX",
+			SWIFT:           "HABALV22",
 		},
 	}
 
@@ -160,7 +161,7 @@ func submitReview(t *testing.T) {
 			revid: revid,
 		},
 		{
-			name: "ok-additional-review",
+			name: "ok-review-by-type",
 			ctx:  services.NewTestContext(t, e, pm),
 			faid: fa.ID,
 		},
@@ -180,19 +181,18 @@ func submitReview(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			expectedRevNum := 5
+			expectedRevNum := 4
 			r := models.FAReview{
 				Type:    models.FAReviewTypeFinancial,
 				Comment: "Lorem Ipsum",
 			}
-			// If review.ID provided in ReviewWP request, controller
-			// should update, rather than create a new review.
+			// Without review.ID in the request, the controller
+			// decides on the step of the review type.
 			if c.revid != uuid.Nil {
 				r.ID = c.revid
-				expectedRevNum = 4
 			}
 
-			err := contr.Review(c.ctx, c.faid, r)
+			err := contr.Review(c.ctx, c.faid, r, nil)
 
 			if !isError(err, c.error) {
 				t.Fatalf("expected err: %v, but got: %v", c.error, err)
@@ -221,6 +221,12 @@ func submitReview(t *testing.T) {
 					}
 				}
 			}
+
+			var decisions int
+			e.FAStore.DB().Model(&models.FAReviewDecision{}).Where("forfaiting_application_id = ?", c.faid).Count(&decisions)
+			if decisions == 0 {
+				t.Fatalf("expected the decision to be recorded in the review history")
+			}
 		})
 	}
 }
@@ -272,7 +278,7 @@ func updateFA(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			res, err := contr.Update(c.ctx, c.faid, models.ForfaitingApplication{
 				PrivateBond: true,
-				BankAccount: models.BankAccount{IBAN: "BG1818UNCR0111"},
+				BankAccount: models.BankAccount{IBAN: "BG80BNBG96611020345678"},
 				Finance:     models.FinanceOther,
 			})
 
@@ -288,8 +294,8 @@ func updateFA(t *testing.T) {
 				t.Fatalf("expected privatebond to be %v, but got: %v", true, res.PrivateBond)
 			}
 
-			if res.BankAccount.IBAN != "BG1818UNCR0111" {
-				t.Fatalf("expected privatebond to be %v, but got: %v", "BG1818UNCR0111", res.BankAccount.IBAN)
+			if res.BankAccount.IBAN != "BG80BNBG96611020345678" {
+				t.Fatalf("expected iban to be %v, but got: %v", "BG80BNBG96611020345678", res.BankAccount.IBAN)
 			}
 
 			if res.Finance != models.FinanceOther {
@@ -469,3 +475,28 @@ func updateFP(t *testing.T) {
 		})
 	}
 }
+
+func TestValidBankAccount(t *testing.T) {
+	ba := models.BankAccount{IBAN: "lv80 bank 0000 4351 9500 1", SWIFT: "habalv22"}
+	if err := validBankAccount(&ba); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if ba.IBAN != "LV80BANK0000435195001" || ba.SWIFT != "HABALV22" {
+		t.Errorf("Expected the account normalised, got %+v", ba)
+	}
+
+	if err := validBankAccount(&models.BankAccount{}); err != nil {
+		t.Errorf("Expected an empty account to be valid, got %v", err)
+	}
+
+	for _, ba := range []models.BankAccount{
+		{IBAN: "iban"},
+		{IBAN: "LV80BANK0000435195002"},
+		{IBAN: "LV80BANK0000435195001", SWIFT: "DEUTDEFF"},
+		{SWIFT: "HABALV22"},
+	} {
+		if err := validBankAccount(&ba); !errors.Is(err, ErrBadInput) {
+			t.Errorf("%+v: expected bad input, got %v", ba, err)
+		}
+	}
+}
@@ -13,13 +13,16 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"stageai.tech/sunshine/sunshine/contract"
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/sentry"
 	"stageai.tech/sunshine/sunshine/services"
 	"stageai.tech/sunshine/sunshine/stores"
 
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -130,7 +133,34 @@ func notify(ctx context.Context, n stores.Notifier, form RequestForm, target *mo
 
 }
 
-func getFile(ctx context.Context, st stores.Store, id uuid.UUID, filename, upath string) (*models.Attachment, *os.File, error) {
+// File is a file to be downloaded.
+type File interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// FileRequest tells what of a file a download asks for.
+type FileRequest int
+
+const (
+	// WholeFile asks for all of the file. PDFs are stamped with their
+	// watermark and the download is logged.
+	WholeFile FileRequest = iota
+
+	// FileHeaders asks only about the file, as HEAD requests do. The file
+	// is neither opened nor logged.
+	FileHeaders
+
+	// FilePart asks for the rest of a download already logged, as range
+	// requests do. Watermarked files are generated per download and are
+	// served whole again instead.
+	FilePart
+)
+
+// getFile opens the attachment of the document with the id and logs the
+// download. PDFs are stamped with the watermark of the attachment or of its
+// project, if any, and who downloaded them and when.
+func getFile(ctx context.Context, st stores.Store, id uuid.UUID, filename, upath string, fr FileRequest) (*models.Attachment, File, error) {
 	doc, err := st.Get(ctx, id)
 	if err != nil {
 		return nil, nil, err
@@ -141,14 +171,125 @@ func getFile(ctx context.Context, st stores.Store, id uuid.UUID, filename, upath
 		return nil, nil, err
 	}
 
+	prj := projectOf(doc)
+	if att.Watermark, err = watermarkOf(st.DB(), *att, prj); err != nil {
+		return nil, nil, err
+	}
+
+	dl := models.Download{
+		Project:    prj,
+		Owner:      id,
+		Attachment: &att.ID,
+		Name:       att.Name,
+	}
 	fname := fmt.Sprintf("%s-%s", id, att.Value.ID)
-	f, err := os.Open(filepath.Join(upath, fname))
+	f, err := openFile(ctx, st.DB(), filepath.Join(upath, fname), *att, &dl, fr)
 	if err != nil {
 		return nil, nil, err
 	}
 	return att, f, nil
 }
 
+// watermarkOf returns the watermark stamped on the attachment when
+// downloaded: its own or else the one of the project. Only PDFs are
+// watermarked.
+func watermarkOf(db *gorm.DB, att models.Attachment, project *uuid.UUID) (models.Watermark, error) {
+	if !isPDF(att) {
+		return models.WatermarkNone, nil
+	}
+	if att.Watermark != models.WatermarkNone || project == nil {
+		return att.Watermark, nil
+	}
+
+	var wms []models.Watermark
+	err := db.Model(&models.Project{}).Where("id = ?", *project).Pluck("watermark", &wms).Error
+	if err != nil || len(wms) == 0 {
+		return models.WatermarkNone, err
+	}
+	return wms[0], nil
+}
+
+// openFile opens the file of the attachment for the request, stamped with
+// the watermark of the attachment, and logs whole downloads as dl. The file
+// is nil for FileHeaders.
+func openFile(ctx context.Context, db *gorm.DB, path string, att models.Attachment, dl *models.Download, fr FileRequest) (File, error) {
+	if fr == FilePart && att.Watermark != models.WatermarkNone {
+		fr = WholeFile
+	}
+	switch fr {
+	case FileHeaders:
+		return nil, nil
+	case FilePart:
+		return os.Open(path)
+	}
+
+	var (
+		f   File
+		err error
+	)
+	if att.Watermark == models.WatermarkNone {
+		f, err = os.Open(path)
+	} else {
+		f, err = contract.Watermark(ctx, path, att.Watermark.Stamp(), downloadMark(ctx, time.Now())...)
+		if err != nil {
+			err = fmt.Errorf("failed to watermark %s: %w", att.Name, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dl.Watermark = att.Watermark
+	if err := logDownload(ctx, db, dl); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// logDownload records the download by the user of the context.
+func logDownload(ctx context.Context, db *gorm.DB, dl *models.Download) error {
+	if cv := services.FromContext(ctx); cv.Authorized() {
+		dl.User = &cv.User.ID
+	}
+	return db.Create(dl).Error
+}
+
+// downloadMark returns the lines identifying the download of the user of the
+// context.
+func downloadMark(ctx context.Context, now time.Time) []string {
+	lines := []string{"Downloaded " + now.UTC().Format("2006-01-02 15:04 MST")}
+	if cv := services.FromContext(ctx); cv.Authorized() {
+		lines = append([]string{fmt.Sprintf("%s <%s>", cv.User.Name, cv.User.Email)}, lines...)
+	}
+	return lines
+}
+
+func isPDF(att models.Attachment) bool {
+	return att.ContentType == "application/pdf" ||
+		strings.EqualFold(filepath.Ext(att.Name), ".pdf")
+}
+
+// projectOf returns the project the document belongs to, if any.
+func projectOf(doc *models.Document) *uuid.UUID {
+	var id uuid.UUID
+	switch d := doc.Data.(type) {
+	case *models.Project:
+		id = doc.ID
+	case *models.WorkPhase:
+		id = d.Project
+	case *models.MonitoringPhase:
+		id = d.Project
+	case *models.ForfaitingApplication:
+		id = d.Project
+	case *models.Task:
+		id = d.Project
+	default:
+		return nil
+	}
+	return &id
+}
+
 func writeFile(path string, file multipart.File, fh *multipart.FileHeader, id uuid.UUID, uploadType string) (*models.Attachment, error) {
 	var att = &models.Attachment{
 		Value:       models.Value{ID: uuid.New()},
@@ -254,6 +395,7 @@ type Upload struct {
 	Filename    string
 	Size        int64
 	ContentType string
+	UploadType  models.UploadType
 }
 
 func uploadGQLFiles(st stores.Store, uploads []Upload, target uuid.UUID, path string) (err error) {
@@ -269,23 +411,46 @@ func uploadGQLFiles(st stores.Store, uploads []Upload, target uuid.UUID, path st
 }
 
 func uploadGQLfile(st stores.Store, u Upload, target uuid.UUID, path string) error {
+	_, err := saveUpload(st.DB(), u, target, path)
+	return err
+}
+
+// saveUpload writes the uploaded file and saves its attachment to target
+// with db, which may be a transaction. The file is removed if it fails.
+func saveUpload(db *gorm.DB, u Upload, target uuid.UUID, path string) (*models.Attachment, error) {
 	var att = &models.Attachment{
 		Value:       models.Value{ID: uuid.New()},
 		Name:        u.Filename,
 		Owner:       target,
 		ContentType: u.ContentType,
+		UploadType:  u.UploadType,
 		Size:        u.Size,
 	}
 
-	f, err := os.Create(filepath.Join(path, fmt.Sprintf("%s-%s", target, att.Value.ID)))
+	name := filepath.Join(path, fmt.Sprintf("%s-%s", target, att.Value.ID))
+	f, err := os.Create(name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer f.Close()
-	if _, err := io.Copy(f, u.File); err != nil {
-		return err
+	_, err = io.Copy(f, u.File)
+	if cerr := f.Close(); err == nil {
+		err = cerr
 	}
+	if err == nil {
+		err = db.Save(att).Error
+	}
+	if err != nil {
+		os.Remove(name)
+		return nil, err
+	}
+	return att, nil
+}
 
-	return st.DB().Save(att).Error
+// removeFiles removes the uploaded files written for a transaction rolled
+// back, or those of rows deleted by a committed one.
+func removeFiles(names []string) {
+	for _, name := range names {
+		os.Remove(name)
+	}
 }
@@ -1,9 +1,14 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/services"
@@ -63,7 +68,7 @@ func (g GDPR) SendRequest(ctx context.Context, req *models.GDPRRequest, u []Uplo
 	// find the target user with his email
 	var usr models.User
 	err = g.st.DB().Where("email = ?", req.Email).First(&usr).Error
-	if err != nil || usr.ID == uuid.Nil || !usr.Country.IsConsortium() {
+	if err != nil || usr.ID == uuid.Nil || !usr.Country.Consortium() {
 		// find the admin network manager
 		var anm models.User
 		err = g.st.DB().First(&anm).Where("admin_network_manager = ?", true).Error
@@ -127,3 +132,169 @@ func (g GDPR) Get(ctx context.Context, id uuid.UUID) (*models.Document, error) {
 	}
 	return g.st.Get(ctx, id)
 }
+
+// Approve completes the request. On access requests an archive of the data
+// linked to the data subject is attached to the request. On erasure requests
+// the data subject is anonymized and a certificate of completion is attached
+// to the request.
+func (g GDPR) Approve(ctx context.Context, id uuid.UUID) (*models.Document, error) {
+	doc, err := g.st.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	req := doc.Data.(*models.GDPRRequest)
+
+	if req.Status != models.GDPRStatusPending {
+		return nil, fmt.Errorf("%w: request is %s", ErrBadInput, req.Status)
+	}
+
+	usr, err := g.subject(*req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !Can(ctx, ReviewGDPRRequest, uuid.Nil, usr.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	cv := services.FromContext(ctx)
+	now := time.Now()
+	req.Status = models.GDPRStatusCompleted
+	req.Reviewer = &cv.User.ID
+	req.CompletedAt = &now
+	req.UserID = usr.ID
+
+	// start transaction block
+	tx := g.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	var (
+		up     Upload
+		erased []string
+	)
+	switch req.Action {
+	case models.GDPRTypeGet:
+		data, err := collectAccessData(tx, *usr)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := accessArchive(ctx, &buf, data, g.uppath); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("fail to export data: %w", err)
+		}
+		up = Upload{
+			File:        &buf,
+			Filename:    fmt.Sprintf("personal-data-%s.zip", now.Format("2006-01-02")),
+			Size:        int64(buf.Len()),
+			ContentType: "application/zip",
+		}
+
+	case models.GDPRTypeDelete:
+		e, err := anonymize(tx, g.uppath, *usr)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		erased = e.files
+
+		// the request itself holds personal data of the data subject
+		req.Name, req.Phone, req.Email, req.Address = erasedName, "", "", ""
+
+		cert := e.certificate(*req)
+		up = Upload{
+			File:        strings.NewReader(cert),
+			Filename:    "erasure-certificate.txt",
+			Size:        int64(len(cert)),
+			ContentType: "text/plain",
+		}
+
+	default:
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: cannot approve %s requests", ErrBadInput, req.Action)
+	}
+
+	if err := tx.Save(req).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	att, err := saveUpload(tx, up, req.ID, g.uppath)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("fail to upload %s: %w", up.Filename, err)
+	}
+	written := []string{filepath.Join(g.uppath, fmt.Sprintf("%s-%s", req.ID, att.ID))}
+
+	if err := tx.Commit().Error; err != nil {
+		removeFiles(written)
+		return nil, err
+	}
+	// end transaction block
+
+	removeFiles(erased)
+	return g.st.Get(ctx, id)
+}
+
+// Reject closes the request without acting on it. Requests about no known
+// user can only be rejected by reviewers of all countries.
+func (g GDPR) Reject(ctx context.Context, id uuid.UUID) (*models.Document, error) {
+	doc, err := g.st.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	req := doc.Data.(*models.GDPRRequest)
+
+	var country models.Country
+	usr, err := g.subject(*req)
+	switch {
+	case err == nil:
+		country = usr.Country
+	case !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+
+	if !Can(ctx, ReviewGDPRRequest, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+
+	if req.Status != models.GDPRStatusPending {
+		return nil, fmt.Errorf("%w: request is %s", ErrBadInput, req.Status)
+	}
+
+	cv := services.FromContext(ctx)
+	now := time.Now()
+	req.Status = models.GDPRStatusRejected
+	req.Reviewer = &cv.User.ID
+	req.CompletedAt = &now
+	if err := g.db.Save(req).Error; err != nil {
+		return nil, err
+	}
+	return g.st.Get(ctx, id)
+}
+
+// subject returns the user whose data the request is about.
+func (g GDPR) subject(req models.GDPRRequest) (*models.User, error) {
+	var usr models.User
+	q := g.db.Unscoped()
+	if req.UserID != uuid.Nil {
+		q = q.Where("id = ?", req.UserID)
+	} else {
+		q = q.Where("lower(email) = lower(?)", req.Email)
+	}
+	err := q.First(&usr).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, fmt.Errorf("%w: no user with the requested data", ErrNotFound)
+	}
+	return &usr, err
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -14,6 +15,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
 )
 
 var emptyCtx = context.Background()
@@ -152,3 +154,67 @@ func TestSingleFileUpload(t *testing.T) {
 		})
 	}
 }
+
+func TestGDPRErasure(t *testing.T) {
+	e := services.NewTestEnv(t)
+	gdpr := NewGDPR(e)
+
+	lear := stores.NewTestUser(t, e.UserStore)
+	leaa := stores.NewTestUser(t, e.UserStore)
+	org := stores.NewTestOrg(t, e.OrganizationStore, lear.ID, leaa.ID)
+
+	req := stores.NewTestGDPRRequest(t, e.DB, models.GDPRTypeDelete)
+	req.UserID = lear.ID
+	e.DB.Save(req)
+
+	prj := stores.NewTestProject(t, e.ProjectStore)
+	own := models.ProjectComment{ProjectID: prj.ID, UserID: lear.ID, Content: "Call me back"}
+	mention := models.ProjectComment{ProjectID: prj.ID, UserID: leaa.ID, Content: "Hi",
+		Mentions: pq.StringArray{lear.ID.String(), leaa.ID.String()}}
+	e.DB.Create(&own)
+	e.DB.Create(&mention)
+
+	uctx := services.NewTestContext(t, e, stores.NewTestUser(t, e.UserStore))
+	actx := services.NewTestContext(t, e, stores.NewTestAdmin(t, e.UserStore))
+
+	if _, err := gdpr.Approve(uctx, req.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized, got %v", err)
+	}
+
+	doc, err := gdpr.Approve(actx, req.ID)
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if r := doc.Data.(*models.GDPRRequest); r.Status != models.GDPRStatusCompleted || r.CompletedAt == nil {
+		t.Errorf("Expected a completed request, got %v", r)
+	}
+	if len(doc.Attachments) != 1 {
+		t.Errorf("Expected an erasure certificate, got %v", doc.Attachments)
+	}
+
+	var u models.User
+	e.DB.Unscoped().Where("id = ?", lear.ID).First(&u)
+	if u.Name != erasedName || u.Email == lear.Data.(*models.User).Email || u.IsActive {
+		t.Errorf("Expected the user to be anonymized, got %v", u)
+	}
+
+	e.DB.Where("id = ?", own.ID).First(&own)
+	if own.Content != erasedText {
+		t.Errorf("Expected the comment of the user erased, got %q", own.Content)
+	}
+	e.DB.Where("id = ?", mention.ID).First(&mention)
+	if len(mention.Mentions) != 1 || mention.Mentions[0] != leaa.ID.String() {
+		t.Errorf("Expected the mention of the user removed, got %v", mention.Mentions)
+	}
+
+	var pos string
+	e.DB.Table("organization_roles").Where("organization_id = ? AND user_id = ?", org.ID, leaa.ID).
+		Select("position").Row().Scan(&pos)
+	if pos != "lear" {
+		t.Errorf("Expected the LEAA to become LEAR, got %q", pos)
+	}
+
+	if _, err := gdpr.Approve(actx, req.ID); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected ErrBadInput, got %v", err)
+	}
+}
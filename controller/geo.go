@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"stageai.tech/sunshine/sunshine/geo"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// maxRadius is the maximum radius in kilometers of nearby assets search.
+const maxRadius = 500
+
+// InBox returns the assets within the box, optionally only the ones in
+// country.
+func (a *Asset) InBox(ctx context.Context, box geo.BBox, country models.Country) ([]models.Asset, error) {
+	if !Can(ctx, GetAsset, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+	if !box.Valid() {
+		return nil, fmt.Errorf("%w: invalid bounding box", ErrBadInput)
+	}
+
+	var assets []models.Asset
+	return assets, a.inBox(box, country).Find(&assets).Error
+}
+
+// InRadius returns the assets within km kilometers of center ordered by
+// distance, optionally only the ones in country.
+func (a *Asset) InRadius(ctx context.Context, center models.Coords, km float64, country models.Country) ([]models.Asset, error) {
+	if km <= 0 || km > maxRadius {
+		return nil, fmt.Errorf("%w: radius must be between 0 and %d km", ErrBadInput, maxRadius)
+	}
+
+	assets, err := a.InBox(ctx, geo.Around(center, km), country)
+	if err != nil {
+		return nil, err
+	}
+
+	dist := make(map[uuid.UUID]float64)
+	result := assets[:0]
+	for _, v := range assets {
+		if d := geo.Distance(center, v.Coordinates); d <= km {
+			dist[v.ID] = d
+			result = append(result, v)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return dist[result[i].ID] < dist[result[j].ID]
+	})
+
+	return result, nil
+}
+
+// Clusters groups the assets within the box into map markers for the zoom
+// level.
+func (a *Asset) Clusters(ctx context.Context, box geo.BBox, zoom int, country models.Country) ([]geo.Cluster, error) {
+	if !Can(ctx, GetAsset, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+	if !box.Valid() {
+		return nil, fmt.Errorf("%w: invalid bounding box", ErrBadInput)
+	}
+
+	var assets []models.Asset
+	err := a.inBox(box, country).Select("id, coords").Find(&assets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]geo.Point, len(assets))
+	for i, v := range assets {
+		points[i] = geo.Point{ID: v.ID, Coords: v.Coordinates}
+	}
+
+	return geo.Clusterize(points, zoom), nil
+}
+
+// GeoJSON exports the assets as point features along with their projects,
+// optionally only the ones within box, in country or with a project in
+// milestone. Projects the user is not allowed to get are left out.
+func (a *Asset) GeoJSON(ctx context.Context, box *geo.BBox, country models.Country, milestone *models.Milestone) (*geo.FeatureCollection, error) {
+	if !Can(ctx, GetAsset, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+
+	q := a.store.DB()
+	if box != nil {
+		if !box.Valid() {
+			return nil, fmt.Errorf("%w: invalid bounding box", ErrBadInput)
+		}
+		q = a.inBox(*box, country)
+	} else if country != "" {
+		q = q.Where("country = ?", country)
+	}
+
+	var assets []models.Asset
+	err := q.Preload("Projects", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).Order("created_at").Find(&assets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	fc := geo.NewFeatureCollection()
+	for _, v := range assets {
+		var (
+			projects []map[string]interface{}
+			current  *models.Project
+		)
+		for i, p := range v.Projects {
+			ids := append(projectIDs(&p), p.Owner)
+			if !canGetProject(ctx, GetProject, p.Country, ids...) {
+				continue
+			}
+			if milestone != nil && p.Milestone != *milestone {
+				continue
+			}
+
+			projects = append(projects, map[string]interface{}{
+				"id":        p.ID,
+				"name":      p.Name,
+				"milestone": p.Milestone,
+				"status":    p.Status,
+			})
+			current = &v.Projects[i]
+		}
+
+		if milestone != nil && current == nil {
+			continue
+		}
+
+		props := map[string]interface{}{
+			"address":       v.Address,
+			"cadastre":      v.Cadastre,
+			"country":       v.Country,
+			"building_type": v.BuildingType,
+			"status":        v.Valid.String(),
+			"projects":      projects,
+		}
+		if current != nil {
+			// the latest project is the one shown on the map
+			props["milestone"] = current.Milestone
+			props["project_status"] = current.Status
+		}
+
+		fc.Add(v.ID.String(), v.Coordinates, props)
+	}
+
+	return fc, nil
+}
+
+func (a *Asset) inBox(box geo.BBox, country models.Country) *gorm.DB {
+	q := a.store.DB().Where("coords[1] BETWEEN ? AND ? AND coords[2] BETWEEN ? AND ?",
+		box.South, box.North, box.West, box.East)
+	if country != "" {
+		q = q.Where("country = ?", country)
+	}
+	return q
+}
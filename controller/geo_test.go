@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/geo"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+)
+
+func TestAssetsGeo(t *testing.T) {
+	e := services.NewTestEnv(t)
+	c := NewAsset(e)
+
+	var (
+		riga   = models.Coords{Lat: 56.9496, Lng: 24.1052}
+		centre = models.Coords{Lat: 56.9510, Lng: 24.1130}
+		sofia  = models.Coords{Lat: 42.6977, Lng: 23.3219}
+		city   = geo.BBox{South: 56.9, West: 24.0, North: 57.0, East: 24.2}
+	)
+
+	a1 := stores.NewTestAsset(t, e.AssetStore, stores.TAWithCoords(riga))
+	a2 := stores.NewTestAsset(t, e.AssetStore, stores.TAWithCoords(centre))
+	stores.NewTestAsset(t, e.AssetStore, stores.TAWithCoords(sofia),
+		stores.TAWithCountry(models.CountryBulgaria))
+
+	pm := stores.NewTestUser(t, e.UserStore)
+	prj := stores.NewTestProject(t, e.ProjectStore,
+		stores.TPrjWithAsset(a1.ID),
+		stores.TPrjWithPm(pm.ID),
+		stores.TPrjWithMilestone(models.MilestoneWorkPhase),
+	)
+
+	pmctx := services.NewTestContext(t, e, pm)
+	rctx := services.NewTestContext(t, e, stores.NewTestUser(t, e.UserStore))
+
+	assets, err := c.InBox(rctx, city, models.CountryLatvia)
+	if err != nil {
+		t.Fatalf("InBox: %v", err)
+	}
+	if len(assets) != 2 {
+		t.Errorf("Expected 2 assets in Riga, got %d", len(assets))
+	}
+	if _, err := c.InBox(rctx, geo.BBox{South: 57, North: 56}, ""); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected ErrBadInput, got %v", err)
+	}
+
+	assets, err = c.InRadius(rctx, centre, 1, "")
+	if err != nil {
+		t.Fatalf("InRadius: %v", err)
+	}
+	if len(assets) != 2 || assets[0].ID != a2.ID {
+		t.Errorf("Expected the nearest asset first, got %v", assets)
+	}
+	if _, err := c.InRadius(rctx, centre, 0, ""); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected ErrBadInput, got %v", err)
+	}
+
+	clusters, err := c.Clusters(rctx, city, 6, "")
+	if err != nil {
+		t.Fatalf("Clusters: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].Count != 2 {
+		t.Errorf("Expected a single cluster of 2 assets, got %v", clusters)
+	}
+
+	milestone := models.MilestoneWorkPhase
+	fc, err := c.GeoJSON(pmctx, &city, "", &milestone)
+	if err != nil {
+		t.Fatalf("GeoJSON: %v", err)
+	}
+	if len(fc.Features) != 1 || fc.Features[0].ID != a1.ID.String() {
+		t.Fatalf("Expected the asset of the project, got %v", fc.Features)
+	}
+	if m := fc.Features[0].Properties["milestone"]; m != milestone {
+		t.Errorf("Expected %v milestone, got %v", milestone, m)
+	}
+	if p := fc.Features[0].Properties["projects"].([]map[string]interface{}); p[0]["id"] != prj.ID {
+		t.Errorf("Expected project %v, got %v", prj.ID, p)
+	}
+
+	// projects of other users are hidden
+	fc, err = c.GeoJSON(rctx, &city, "", &milestone)
+	if err != nil {
+		t.Fatalf("GeoJSON: %v", err)
+	}
+	if len(fc.Features) != 0 {
+		t.Errorf("Expected no features, got %v", fc.Features)
+	}
+}
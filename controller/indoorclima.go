@@ -8,7 +8,6 @@ import (
 	"io"
 	"log"
 	"regexp"
-	"strconv"
 
 	"stageai.tech/sunshine/sunshine/contract"
 	"stageai.tech/sunshine/sunshine/models"
@@ -27,13 +26,7 @@ func (c *Contract) GetIndoorClima(ctx context.Context, id uuid.UUID) (*models.Do
 		return nil, ErrUnauthorized
 	}
 
-	t := ctr.contract.Tables["calc_energy_fee"]
-	aapk, _ := strconv.ParseFloat(t.Row(t.Len()-1).Cell(4).String(), 64)
-	ctr.ic.Data.(*contract.IndoorClima).Calculate(
-		ctr.contract.Tables,
-		aapk,
-		float64(ctr.asset.Floors))
-
+	calculateIndoorClima(ctr)
 	return ctr.ic, nil
 }
 
@@ -61,11 +54,7 @@ func (c *Contract) UpdateIndoorClima(ctx context.Context, id uuid.UUID, d decode
 		return nil, err
 	}
 
-	t := ctr.contract.Tables["calc_energy_fee"]
-	Aapk, _ := strconv.ParseFloat(t.Row(t.Len()-1).Cell(4).String(), 64)
-	floors := ctr.asset.Floors
-	ctr.ic.Data.(*contract.IndoorClima).Calculate(ctr.contract.Tables, Aapk, float64(floors))
-	ctr.ic.Data.(*contract.IndoorClima).Project = id
+	calculateIndoorClima(ctr).Project = id
 
 	if err = c.cst.DB().Save(ctr.ic.Data.(*contract.IndoorClima)).Error; err != nil {
 		return nil, err
@@ -75,6 +64,61 @@ func (c *Contract) UpdateIndoorClima(ctx context.Context, id uuid.UUID, d decode
 
 }
 
+// DownloadIndoorClima generates the indoor clima calculation report of the
+// project in given language ("english" or "native") and format ("pdf" or
+// "tex").
+func (c *Contract) DownloadIndoorClima(ctx context.Context, id uuid.UUID, language, format string) (*contract.FileInTempDir, string, error) {
+	ctr, err := c.buildContext(ctx, id, nil, withIndoorClima)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !Can(ctx, GetProjectIndoorClima, ctr.project.ID, ctr.project.Country) {
+		return nil, "", ErrUnauthorized
+	}
+
+	var english bool
+	switch language {
+	case "native":
+	case "english":
+		english = true
+	default:
+		return nil, "", fmt.Errorf("%w: bad language: %v", ErrBadInput, language)
+	}
+	dir, texFile := contract.DocumentsOf(ctr.project.Country).Root("indoorclima", english)
+
+	ic := calculateIndoorClima(ctr)
+
+	tc := newTemplateContext(ctr, c.url)
+	tc.IndoorClima = *ic
+	tmpl, err := c.projectTemplate(ctr.project)
+	if err != nil {
+		return nil, "", err
+	}
+
+	doc, err := contract.NewDocumentFromTemplate(tc, dir, tmpl)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate indoor clima template: %w", err)
+	}
+
+	var op func(context.Context, string) (*contract.FileInTempDir, error)
+	switch format {
+	case "pdf":
+		op = doc.GeneratePDF
+	case "tex":
+		op = doc.GenerateTeX
+	default:
+		return nil, "", fmt.Errorf("%w: bad format: %v", ErrBadInput, format)
+	}
+
+	file, err := op(ctx, texFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate indoor clima report: %w", err)
+	}
+
+	return file, fmt.Sprintf("indoorclima_%s_%s.%s", language, ctr.id, format), nil
+}
+
 type decode func(models.Entity) error
 
 func MarshalJSON(r io.Reader) decode {
@@ -105,6 +149,14 @@ func withIndoorClima(ctx context.Context, ctr *contractCTX, s stores.Store, _ ma
 	return nil
 }
 
+// calculateIndoorClima calculates the indoor clima of the contract context out
+// of its contract tables and asset.
+func calculateIndoorClima(ctr contractCTX) *contract.IndoorClima {
+	ic := ctr.ic.Data.(*contract.IndoorClima)
+	ic.Calculate(ctr.contract.Tables, contract.HeatedArea(ctr.contract.Tables), float64(ctr.asset.Floors))
+	return ic
+}
+
 func validateZones(ic *contract.IndoorClima) error {
 	re := regexp.MustCompile(`(attic|basement_ceiling|ground|roof|basewall|external_door|window|external_wall)_[a-z]+[0-9]_zone[1-2]`)
 	for k := range ic.Zones {
@@ -2,7 +2,9 @@ package controller
 
 import (
 	"context"
+	"strings"
 
+	"stageai.tech/sunshine/sunshine/ical"
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/services"
 	"stageai.tech/sunshine/sunshine/stores"
@@ -13,14 +15,22 @@ import (
 type Meeting struct {
 	st         stores.Store
 	notifier   stores.Notifier
+	mailer     services.Mailer
+	tokens     stores.TokenStore
+	tasks      *Task
 	uploadPath string
+	url        string
 }
 
 func NewMeeting(e *services.Env) *Meeting {
 	return &Meeting{
 		st:         e.MeetingsStore,
 		notifier:   e.Notifier,
+		mailer:     e.Mailer,
+		tokens:     e.TokenStore,
+		tasks:      NewTask(e),
 		uploadPath: e.Paths.Uploads,
+		url:        e.General.URL,
 	}
 }
 
@@ -28,7 +38,14 @@ func (m *Meeting) Create(ctx context.Context, meet *models.Meeting) (*models.Doc
 	if !m.can(ctx, m.st, &meet.Host) && !m.can(ctx, m.st, meet.Project) {
 		return nil, ErrUnauthorized
 	}
-	return m.st.Create(ctx, meet)
+	meet.Sequence = 0
+	doc, err := m.st.Create(ctx, meet)
+	if err != nil {
+		return nil, err
+	}
+
+	go m.invite(*doc.Data.(*models.Meeting), ical.MethodRequest, meet.Guests)
+	return doc, nil
 }
 
 func (m *Meeting) Get(ctx context.Context, id uuid.UUID) (*models.Document, error) {
@@ -67,11 +84,24 @@ func (m *Meeting) Delete(ctx context.Context, id uuid.UUID) error {
 	if !m.can(ctx, m.st, &id) {
 		return ErrUnauthorized
 	}
-	meet, err := m.st.Get(ctx, id)
+	doc, err := m.st.Get(ctx, id)
 	if err != nil {
 		return err
 	}
-	return m.st.Delete(ctx, meet)
+
+	// the cancellation is a revision of the meeting for the calendars
+	meet := doc.Data.(*models.Meeting)
+	meet.Sequence++
+	if err := m.st.DB().Model(meet).UpdateColumn("sequence", meet.Sequence).Error; err != nil {
+		return err
+	}
+
+	if err := stores.Trash(m.st.DB(), "meeting", id); err != nil {
+		return err
+	}
+
+	go m.invite(*meet, ical.MethodCancel, meet.Guests)
+	return nil
 }
 
 func (m *Meeting) Update(ctx context.Context, upd models.Meeting) (*models.Document, error) {
@@ -85,20 +115,74 @@ func (m *Meeting) Update(ctx context.Context, upd models.Meeting) (*models.Docum
 	o := old.Data.(*models.Meeting)
 	newm := update(*o, upd)
 
-	if len(upd.Guests) > 0 {
-		err := m.st.DB().Unscoped().
-			Where("meeting_id = ?", upd.ID).
-			Delete(&models.MeetingGuest{}).Error
+	// the guests are sent updated invitations only if the meeting was
+	// rescheduled or moved or they were changed
+	rescheduled := newm.Name != o.Name || !newm.Date.Equal(o.Date) || newm.Location != o.Location
+	if rescheduled || len(upd.Guests) > 0 {
+		newm.Sequence++
+	}
 
-		if err != nil {
-			return nil, err
+	if len(upd.Guests) > 0 {
+		guests, removed := mergeGuests(o.Guests, upd.Guests)
+		if len(removed) > 0 {
+			err := m.st.DB().Unscoped().
+				Where("meeting_id = ? AND id IN (?)", upd.ID, removed).
+				Delete(&models.MeetingGuest{}).Error
+
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		newm.Guests = upd.Guests
+		newm.Guests = guests
 	}
 	res, err := m.st.Update(ctx, models.Wrap(&newm))
+	if err != nil {
+		return nil, err
+	}
+
+	if newm.Sequence != o.Sequence {
+		go m.invite(newm, ical.MethodCancel, removedGuests(o.Guests, newm.Guests))
+		go m.invite(newm, ical.MethodRequest, newm.Guests)
+	}
+	return res, nil
+}
+
+// mergeGuests returns the new guests of a meeting, those already invited
+// updated in place to keep their attendance and signature, and the IDs of the
+// old guests no longer invited. Guests are told apart by their email or by
+// their name if they have none.
+func mergeGuests(old, new []models.MeetingGuest) ([]models.MeetingGuest, []uuid.UUID) {
+	key := func(g models.MeetingGuest) string {
+		if g.Email != "" {
+			return "email:" + strings.ToLower(g.Email)
+		}
+		return "name:" + strings.ToLower(g.Name)
+	}
+
+	existing := make(map[string]models.MeetingGuest, len(old))
+	for _, g := range old {
+		existing[key(g)] = g
+	}
+
+	kept := make(map[uuid.UUID]bool, len(new))
+	guests := make([]models.MeetingGuest, len(new))
+	for i, g := range new {
+		if e, ok := existing[key(g)]; ok && !kept[e.ID] {
+			e.Name, e.Type, e.Email, e.Phone, e.Organization = g.Name, g.Type, g.Email, g.Phone, g.Organization
+			g = e
+			kept[e.ID] = true
+		}
+		guests[i] = g
+	}
 
-	return res, err
+	var removed []uuid.UUID
+	for _, g := range old {
+		if !kept[g.ID] {
+			removed = append(removed, g.ID)
+		}
+	}
+	return guests, removed
 }
 
 func (m *Meeting) List(ctx context.Context, id *uuid.UUID) ([]models.Document, error) {
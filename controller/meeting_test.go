@@ -208,3 +208,33 @@ func testUpdateMeeting(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeGuests(t *testing.T) {
+	present := models.AttendancePresent
+	signed := time.Now()
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	old := []models.MeetingGuest{
+		{Value: models.Value{ID: a}, Name: "A", Email: "a@example.com", Attendance: &present, Signature: "sig", SignedAt: &signed},
+		{Value: models.Value{ID: b}, Name: "B", Email: "b@example.com"},
+		{Value: models.Value{ID: c}, Name: "C"},
+	}
+	new := []models.MeetingGuest{
+		{Name: "A. Ozola", Email: "A@example.com", Phone: "+371"},
+		{Name: "c"},
+		{Name: "D", Email: "d@example.com"},
+	}
+
+	guests, removed := mergeGuests(old, new)
+	if len(guests) != 3 {
+		t.Fatalf("Expected 3 guests, got %v", guests)
+	}
+	if g := guests[0]; g.ID != a || g.Name != "A. Ozola" || g.Phone != "+371" || g.Attendance != &present || g.SignedAt != &signed {
+		t.Errorf("Expected A updated keeping the signature, got %+v", g)
+	}
+	if guests[1].ID != c || guests[2].ID != uuid.Nil {
+		t.Errorf("Expected C kept and D added, got %+v", guests)
+	}
+	if len(removed) != 1 || removed[0] != b {
+		t.Errorf("Expected B removed, got %v", removed)
+	}
+}
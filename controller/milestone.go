@@ -2,9 +2,9 @@ package controller
 
 import (
 	"context"
-	"os"
 	"time"
 
+	"stageai.tech/sunshine/sunshine/bankaccount"
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/services"
 	"stageai.tech/sunshine/sunshine/stores"
@@ -16,6 +16,7 @@ import (
 type WorkPhase struct {
 	store      stores.Store
 	notifier   stores.Notifier
+	screener   bankaccount.Screener
 	uploadPath string
 }
 
@@ -23,6 +24,7 @@ func NewWorkPhase(env *services.Env) *WorkPhase {
 	return &WorkPhase{
 		store:      env.WPStore,
 		notifier:   env.Notifier,
+		screener:   env.Screener,
 		uploadPath: env.Paths.Uploads,
 	}
 }
@@ -63,6 +65,14 @@ func (wp *WorkPhase) AdvanceToWorkPhase(ctx context.Context, pID uuid.UUID) (*mo
 		},
 	}
 
+	for i := range w.Reviews {
+		if w.Reviews[i].Type == models.WPReviewTypeBankAccount {
+			if err := wp.screenBankAccount(pID, &w.Reviews[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	wpdoc, err := wp.store.Create(ctx, &w)
 
 	if err != nil {
@@ -102,6 +112,17 @@ func (wp *WorkPhase) ReviewWP(ctx context.Context, id uuid.UUID, review models.W
 		r.ID = review.ID
 	}
 
+	if r.Type == models.WPReviewTypeBankAccount {
+		if err := wp.screenBankAccount(p.ID, &r); err != nil {
+			return err
+		}
+		if r.Approved {
+			if err := approvable(r); err != nil {
+				return err
+			}
+		}
+	}
+
 	if r.Approved && r.Type == models.WPReviewTypeExecutive {
 		cv := services.FromContext(ctx)
 
@@ -204,7 +225,7 @@ func (wp *WorkPhase) UploadWP(ctx context.Context, wpID uuid.UUID, form RequestF
 	return uploadFile(ctx, wp.store, wp.notifier, form, doc, wp.uploadPath)
 }
 
-func (wp *WorkPhase) GetFileWP(ctx context.Context, wpid uuid.UUID, filename string) (*models.Attachment, *os.File, error) {
+func (wp *WorkPhase) GetFileWP(ctx context.Context, wpid uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
 	doc, err := wp.store.FromKind("work_phase").Get(ctx, wpid)
 	if err != nil {
 		return nil, nil, err
@@ -217,7 +238,7 @@ func (wp *WorkPhase) GetFileWP(ctx context.Context, wpid uuid.UUID, filename str
 		return nil, nil, ErrUnauthorized
 	}
 
-	return getFile(ctx, wp.store, wpid, filename, wp.uploadPath)
+	return getFile(ctx, wp.store, wpid, filename, wp.uploadPath, fr)
 }
 
 func (wp *WorkPhase) DeleteFileWP(ctx context.Context, id uuid.UUID, filename string) error {
@@ -334,7 +355,7 @@ func (mp *MonitoringPhase) UploadMP(ctx context.Context, mpID uuid.UUID, form Re
 	return uploadFile(ctx, mp.store, mp.notifier, form, doc, mp.uploadPath)
 }
 
-func (mp *MonitoringPhase) GetFileMP(ctx context.Context, mpID uuid.UUID, filename string) (*models.Attachment, *os.File, error) {
+func (mp *MonitoringPhase) GetFileMP(ctx context.Context, mpID uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
 	doc, err := mp.store.FromKind("monitoring_phase").Get(ctx, mpID)
 	if err != nil {
 		return nil, nil, err
@@ -347,7 +368,7 @@ func (mp *MonitoringPhase) GetFileMP(ctx context.Context, mpID uuid.UUID, filena
 		return nil, nil, ErrUnauthorized
 	}
 
-	return getFile(ctx, mp.store, mpID, filename, mp.uploadPath)
+	return getFile(ctx, mp.store, mpID, filename, mp.uploadPath, fr)
 }
 
 func (mp *MonitoringPhase) DeleteFileMP(ctx context.Context, id uuid.UUID, filename string) error {
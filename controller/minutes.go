@@ -0,0 +1,518 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// Minutes are the structured minutes of a meeting.
+type Minutes struct {
+	Agenda      []models.AgendaItem
+	Decisions   []models.MeetingDecision
+	ActionItems []models.ActionItem
+}
+
+// MinutesTemplate is the agenda the minutes of the meetings of a topic start
+// with.
+type MinutesTemplate struct {
+	// Topic of the meetings; nil for the template of the meetings of the
+	// topics without template.
+	Topic  *models.MeetingType
+	Agenda []string
+}
+
+var defaultAgenda = []string{
+	"Opening and approval of the agenda",
+	"Discussion",
+	"Decisions",
+	"Any other business",
+}
+
+var minutesTemplates = map[models.MeetingType][]string{
+	models.MTypeAcquisition: {
+		"Introduction of the parties",
+		"Condition of the building",
+		"Energy efficiency measures",
+		"Next steps",
+	},
+	models.MTypeAcquisitionCommitment: {
+		"Results of the energy audit",
+		"Proposed renovation measures",
+		"Financing and energy savings guarantee",
+		"Commitment of the residents",
+	},
+	models.MTypeAcquisitionKickOff: {
+		"Introduction of the parties",
+		"Presentation of the renovation concept",
+		"Financing and forfaiting",
+		"Timeline of the project",
+		"Next steps",
+	},
+	models.MTypeWorksKickOff: {
+		"Introduction of the contractors",
+		"Schedule of the works",
+		"Site organization and safety",
+		"Communication with the residents",
+	},
+	models.MTypeWorksInitialInformation: {
+		"Scope of the works",
+		"Schedule of the works",
+		"Impact on the residents",
+		"Contact persons",
+	},
+	models.MTypeWorksWeeklyReport: {
+		"Progress since the last report",
+		"Works planned for the next week",
+		"Issues and deviations from the schedule",
+		"Health and safety",
+		"Complaints of the residents",
+	},
+	models.MTypeWorksRenovationInformative: {
+		"Progress of the renovation",
+		"Upcoming works affecting the residents",
+		"Questions of the residents",
+	},
+	models.MTypeWorksCommunication: {
+		"Open issues",
+		"Questions of the residents",
+	},
+	models.MTypeWorksConstructionManagersFinal: {
+		"Completion of the works",
+		"Defects and their remedy",
+		"Handover documentation",
+		"Acceptance of the works",
+	},
+	models.MTypeWorksFinalInformation: {
+		"Completed works",
+		"Operation and maintenance of the building",
+		"Monitoring of the energy savings",
+		"Questions of the residents",
+	},
+}
+
+// MinutesTemplates returns the templates of the minutes, the default one
+// first.
+func MinutesTemplates() []MinutesTemplate {
+	result := []MinutesTemplate{{Agenda: defaultAgenda}}
+	for _, topic := range meetingTopics {
+		if agenda, ok := minutesTemplates[topic]; ok {
+			topic := topic
+			result = append(result, MinutesTemplate{Topic: &topic, Agenda: agenda})
+		}
+	}
+	return result
+}
+
+// meetingTopics orders the topics of the minutes templates.
+var meetingTopics = []models.MeetingType{
+	models.MTypeAcquisition,
+	models.MTypeAcquisitionCommitment,
+	models.MTypeAcquisitionKickOff,
+	models.MTypeWorksKickOff,
+	models.MTypeWorksInitialInformation,
+	models.MTypeWorksWeeklyReport,
+	models.MTypeWorksRenovationInformative,
+	models.MTypeWorksCommunication,
+	models.MTypeWorksConstructionManagersFinal,
+	models.MTypeWorksFinalInformation,
+}
+
+// Minutes returns the minutes of the meeting.
+func (m *Meeting) Minutes(ctx context.Context, id uuid.UUID) (*Minutes, error) {
+	if _, err := m.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return m.minutes(m.st.DB(), id)
+}
+
+func (m *Meeting) minutes(db *gorm.DB, id uuid.UUID) (*Minutes, error) {
+	var min Minutes
+	for _, dst := range []interface{}{&min.Agenda, &min.Decisions, &min.ActionItems} {
+		if err := db.Where("meeting_id = ?", id).Order("position").Find(dst).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &min, nil
+}
+
+// ApplyMinutesTemplate sets the agenda of the meeting to the one of the
+// template of its topic. The agenda must be empty.
+func (m *Meeting) ApplyMinutesTemplate(ctx context.Context, id uuid.UUID) (*Minutes, error) {
+	if !m.can(ctx, m.st, &id) {
+		return nil, ErrUnauthorized
+	}
+
+	doc, err := m.st.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	meet := doc.Data.(*models.Meeting)
+
+	var n int
+	if err := m.st.DB().Model(&models.AgendaItem{}).Where("meeting_id = ?", id).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return nil, fmt.Errorf("%w: the meeting already has an agenda", ErrBadInput)
+	}
+
+	agenda := defaultAgenda
+	if meet.Topic != nil {
+		if a, ok := minutesTemplates[*meet.Topic]; ok {
+			agenda = a
+		}
+	}
+
+	min := Minutes{Agenda: make([]models.AgendaItem, len(agenda))}
+	for i, title := range agenda {
+		min.Agenda[i] = models.AgendaItem{Title: title}
+	}
+	return m.UpdateMinutes(ctx, id, min)
+}
+
+// UpdateMinutes replaces the agenda, the decisions and the action items of
+// the meeting with the given ones. Items with the IDs of existing ones are
+// updated; the action items keep the tasks following them up.
+func (m *Meeting) UpdateMinutes(ctx context.Context, id uuid.UUID, min Minutes) (*Minutes, error) {
+	if !m.can(ctx, m.st, &id) {
+		return nil, ErrUnauthorized
+	}
+	if _, err := m.st.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	for _, a := range min.Agenda {
+		if a.Title == "" {
+			return nil, fmt.Errorf("%w: agenda item without title", ErrBadInput)
+		}
+	}
+	for _, d := range min.Decisions {
+		if d.Text == "" {
+			return nil, fmt.Errorf("%w: empty decision", ErrBadInput)
+		}
+	}
+	for _, a := range min.ActionItems {
+		if a.Description == "" {
+			return nil, fmt.Errorf("%w: action item without description", ErrBadInput)
+		}
+	}
+
+	old, err := m.minutes(m.st.DB(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := m.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	var (
+		agenda    = make(map[uuid.UUID]bool)
+		decisions = make(map[uuid.UUID]bool)
+		tasks     = make(map[uuid.UUID]*uuid.UUID)
+	)
+	for _, a := range old.Agenda {
+		agenda[a.ID] = true
+	}
+	for _, d := range old.Decisions {
+		decisions[d.ID] = true
+	}
+	for _, a := range old.ActionItems {
+		tasks[a.ID] = a.Task
+	}
+
+	var items []interface{}
+	for i := range min.Agenda {
+		a := &min.Agenda[i]
+		a.MeetingID, a.Position = id, i
+		if !agenda[a.ID] {
+			a.Value = models.Value{}
+		}
+		items = append(items, a)
+	}
+	for i := range min.Decisions {
+		d := &min.Decisions[i]
+		d.MeetingID, d.Position = id, i
+		if !decisions[d.ID] {
+			d.Value = models.Value{}
+		}
+		items = append(items, d)
+	}
+	for i := range min.ActionItems {
+		a := &min.ActionItems[i]
+		a.MeetingID, a.Position = id, i
+		task, ok := tasks[a.ID]
+		if !ok {
+			a.Value = models.Value{}
+		}
+		a.Task = task
+		items = append(items, a)
+	}
+
+	// the items left out are removed
+	for _, table := range []string{"meeting_agenda_items", "meeting_decisions", "meeting_action_items"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE meeting_id = ?", id).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for _, item := range items {
+		if err := tx.Create(item).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return m.minutes(m.st.DB(), id)
+}
+
+// RecordAttendance records whether the guest attended the meeting along with
+// the signature of the guest, if any.
+func (m *Meeting) RecordAttendance(ctx context.Context, guestID uuid.UUID, status models.AttendanceStatus, signature string) (*models.MeetingGuest, error) {
+	var g models.MeetingGuest
+	err := m.st.DB().Where("id = ?", guestID).First(&g).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, fmt.Errorf("%w: no meeting guest %v", ErrNotFound, guestID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.can(ctx, m.st, &g.MeetingID) {
+		return nil, ErrUnauthorized
+	}
+
+	switch status {
+	case models.AttendancePresent, models.AttendanceAbsent, models.AttendanceExcused:
+	default:
+		return nil, fmt.Errorf("%w: bad attendance: %v", ErrBadInput, status)
+	}
+	if signature != "" && status != models.AttendancePresent {
+		return nil, fmt.Errorf("%w: only guests present can sign", ErrBadInput)
+	}
+
+	g.Attendance = &status
+	g.Signature = signature
+	g.SignedAt = nil
+	if signature != "" {
+		now := time.Now()
+		g.SignedAt = &now
+	}
+
+	err = m.st.DB().Model(&g).UpdateColumns(map[string]interface{}{
+		"attendance": g.Attendance,
+		"signature":  g.Signature,
+		"signed_at":  g.SignedAt,
+	}).Error
+	return &g, err
+}
+
+// CreateFollowUp creates a task of the meeting's project following up the
+// action item.
+func (m *Meeting) CreateFollowUp(ctx context.Context, actionItemID uuid.UUID) (*models.Document, error) {
+	var a models.ActionItem
+	err := m.st.DB().Where("id = ?", actionItemID).First(&a).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, fmt.Errorf("%w: no action item %v", ErrNotFound, actionItemID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.can(ctx, m.st, &a.MeetingID) {
+		return nil, ErrUnauthorized
+	}
+	if a.Task != nil {
+		return nil, fmt.Errorf("%w: the action item is already followed up", ErrBadInput)
+	}
+
+	doc, err := m.st.Get(ctx, a.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+	meet := doc.Data.(*models.Meeting)
+	if meet.Project == nil {
+		return nil, fmt.Errorf("%w: the meeting is not of a project", ErrBadInput)
+	}
+
+	task, err := m.tasks.Create(ctx, models.Task{
+		Project:     *meet.Project,
+		Title:       a.Description,
+		Description: fmt.Sprintf("Follow-up of %s on %s.", meet.Name, meet.Date.Format("2006-01-02")),
+		Assignee:    a.Assignee,
+		Deadline:    a.Deadline,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.st.DB().Model(&a).UpdateColumn("task_id", task.ID).Error; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// MinutesPDF generates the minutes of the meeting as PDF.
+func (m *Meeting) MinutesPDF(ctx context.Context, id uuid.UUID) (*contract.FileInTempDir, string, error) {
+	doc, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	meet := doc.Data.(*models.Meeting)
+
+	min, err := m.minutes(m.st.DB(), id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	assignees := make(map[uuid.UUID]string)
+	for _, a := range min.ActionItems {
+		if a.Assignee != nil {
+			assignees[*a.Assignee] = ""
+		}
+	}
+	if len(assignees) > 0 {
+		var users []models.User
+		ids := make([]uuid.UUID, 0, len(assignees))
+		for id := range assignees {
+			ids = append(ids, id)
+		}
+		if err := m.st.DB().Unscoped().Where("id IN (?)", ids).Find(&users).Error; err != nil {
+			return nil, "", err
+		}
+		for _, u := range users {
+			assignees[u.ID] = u.Name
+		}
+	}
+
+	var tex bytes.Buffer
+	err = minutesDocument.Execute(&tex, struct {
+		Meeting   *models.Meeting
+		Minutes   *Minutes
+		Assignees map[uuid.UUID]string
+	}{meet, min, assignees})
+	if err != nil {
+		return nil, "", err
+	}
+
+	file, err := contract.RenderPDF(ctx, &tex)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate minutes: %w", err)
+	}
+	return file, fmt.Sprintf("minutes_%s.pdf", id), nil
+}
+
+var minutesDocument = texDocument("minutes", "11pt", "margin=2cm", template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04")
+	},
+	"day": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	},
+	"attendance": func(s *models.AttendanceStatus) string {
+		if s == nil {
+			return "not recorded"
+		}
+		return string(*s)
+	},
+	"assignee": func(names map[uuid.UUID]string, id *uuid.UUID) string {
+		if id == nil {
+			return ""
+		}
+		return names[*id]
+	},
+	"inc": func(i int) int { return i + 1 },
+}, `
+\section*{Minutes of << tex .Meeting.Name >>}
+
+\begin{tabular}{ll}
+Date & << date .Meeting.Date >> \\
+Location & << tex .Meeting.Location >> \\
+<<- with .Meeting.HostEntity >>
+Host & << tex .Name >> \\
+<<- end >>
+\end{tabular}
+
+<<- with .Meeting.Objective >>
+
+\subsection*{Objective}
+<< tex . >>
+<<- end >>
+
+\subsection*{Attendance}
+<<- if .Meeting.Guests >>
+\begin{longtable}{p{4cm}p{4cm}p{3cm}p{4cm}}
+Name & Organization & Attendance & Signature \\
+\hline
+<<- range .Meeting.Guests >>
+<< tex .Name >> & << tex .Organization >> & << attendance .Attendance >> & << tex .Signature >> \\
+<<- end >>
+\end{longtable}
+<<- else >>
+No guests.
+<<- end >>
+
+\subsection*{Agenda}
+<<- if .Minutes.Agenda >>
+\begin{enumerate}
+<<- range .Minutes.Agenda >>
+\item \textbf{<< tex .Title >>}
+<<- with .Notes >>\\ << tex . >><< end >>
+<<- end >>
+\end{enumerate}
+<<- else >>
+No agenda.
+<<- end >>
+
+\subsection*{Decisions}
+<<- if .Minutes.Decisions >>
+\begin{enumerate}
+<<- range .Minutes.Decisions >>
+\item << tex .Text >>
+<<- end >>
+\end{enumerate}
+<<- else >>
+No decisions.
+<<- end >>
+
+\subsection*{Action items}
+<<- if .Minutes.ActionItems >>
+\begin{longtable}{p{1cm}p{8cm}p{4cm}p{2.5cm}}
+No. & Action & Responsible & Deadline \\
+\hline
+<<- range $i, $a := .Minutes.ActionItems >>
+<< inc $i >> & << tex $a.Description >> & << tex (assignee $.Assignees $a.Assignee) >> & << day $a.Deadline >> \\
+<<- end >>
+\end{longtable}
+<<- else >>
+No action items.
+<<- end >>
+
+\vspace{2cm}
+\begin{tabular}{p{7cm}p{7cm}}
+\hrulefill & \hrulefill \\
+Chair & Recorder \\
+\end{tabular}
+`)
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestMinutesTemplates(t *testing.T) {
+	templates := MinutesTemplates()
+	if templates[0].Topic != nil {
+		t.Errorf("Expected the default template first, got %v", *templates[0].Topic)
+	}
+	if len(templates) != len(minutesTemplates)+1 {
+		t.Errorf("Expected %d templates, got %d", len(minutesTemplates)+1, len(templates))
+	}
+	for _, tmpl := range templates {
+		if len(tmpl.Agenda) == 0 {
+			t.Errorf("Expected an agenda in the template of %v", tmpl.Topic)
+		}
+	}
+}
+
+func TestMinutesDocument(t *testing.T) {
+	var (
+		present  = models.AttendancePresent
+		assignee = uuid.New()
+		deadline = time.Date(2020, time.November, 2, 0, 0, 0, 0, time.UTC)
+	)
+	meet := models.Meeting{
+		Name:     "Kick-off",
+		Location: "Brīvības iela 10",
+		Date:     time.Date(2020, time.October, 19, 10, 0, 0, 0, time.UTC),
+		Guests: []models.MeetingGuest{
+			{Name: "Jānis", Organization: "Renesco & Co", Attendance: &present, Signature: "J. B."},
+			{Name: "Anna"},
+		},
+	}
+	min := Minutes{
+		Agenda:      []models.AgendaItem{{Title: "Financing", Notes: "50% grant"}},
+		ActionItems: []models.ActionItem{{Description: "Send the offer", Assignee: &assignee, Deadline: &deadline}},
+	}
+
+	var buf bytes.Buffer
+	err := minutesDocument.Execute(&buf, struct {
+		Meeting   *models.Meeting
+		Minutes   *Minutes
+		Assignees map[uuid.UUID]string
+	}{&meet, &min, map[uuid.UUID]string{assignee: "Jānis"}})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	tex := buf.String()
+
+	for _, want := range []string{
+		`Jānis & Renesco \& Co & present & J. B. \\`,
+		`Anna &  & not recorded &  \\`,
+		`\item \textbf{Financing}\\ 50\% grant`,
+		`1 & Send the offer & Jānis & 2020-11-02 \\`,
+	} {
+		if !strings.Contains(tex, want) {
+			t.Errorf("Expected %q in the minutes, got:\n%s", want, tex)
+		}
+	}
+	if !strings.Contains(tex, "Decisions}\nNo decisions.") {
+		t.Errorf("Expected no decisions in the minutes, got:\n%s", tex)
+	}
+}
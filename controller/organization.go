@@ -83,7 +83,7 @@ func (o *Organization) Create(ctx context.Context, r io.Reader) (*models.Documen
 				TargetKey:  org.Name,
 				Country:    org.Country,
 			}
-			if !org.Country.IsConsortium() {
+			if !org.Country.Consortium() {
 				admins = []uuid.UUID{getANWManager(o.store)}
 			}
 			notifyAll(ctx, o.notifier, admins, n)
@@ -194,7 +194,7 @@ func (o *Organization) UploadFile(ctx context.Context, oid uuid.UUID, form Reque
 	return uploadFile(ctx, o.store, o.notifier, form, doc, o.uploadPath)
 }
 
-func (o *Organization) GetFile(ctx context.Context, oid uuid.UUID, filename string) (*models.Attachment, *os.File, error) {
+func (o *Organization) GetFile(ctx context.Context, oid uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
 	doc, err := o.store.Get(ctx, oid)
 	if err != nil {
 		return nil, nil, err
@@ -207,7 +207,7 @@ func (o *Organization) GetFile(ctx context.Context, oid uuid.UUID, filename stri
 		return nil, nil, ErrUnauthorized
 	}
 
-	return getFile(ctx, o.store, oid, filename, o.uploadPath)
+	return getFile(ctx, o.store, oid, filename, o.uploadPath, fr)
 }
 
 func (o *Organization) DeleteFile(ctx context.Context, oid uuid.UUID, filename string) error {
@@ -406,47 +406,9 @@ func (o *Organization) GetReport(ctx context.Context, first, offset int) ([]mode
 		return nil, 0, err
 	}
 
-	// Own projects
-	// populate total count of projects
-	if err := organizations.CountTotalProjects(o.store.DB(), ids, true); err != nil {
-		sentry.Report(err, "GetOrganizationReports.populateOwnedTotalProjects fails")
-		return nil, 0, err
-	}
-	// populate total count of in progress projects
-	if err := organizations.CountOngoingProjects(o.store.DB(), ids, true); err != nil {
-		sentry.Report(err, "GetOrganizationReports.populateOwnedOngoingProjects fails")
-		return nil, 0, err
-	}
-	// total for forfaiting agreement
-	if err := organizations.CountFAProjects(o.store.DB(), ids, true); err != nil {
-		sentry.Report(err, "GetOrganizationReports.populateOwnedFAProjects fails")
-		return nil, 0, err
-	}
-
-	if err := organizations.CountMonitoringPhaseProjects(o.store.DB(), ids, true); err != nil {
-		sentry.Report(err, "GetOrganizationReports.populateRelatedMPProjects fails")
-		return nil, 0, err
-	}
-
-	// Related Projects
-	// populate total count of projects
-	if err := organizations.CountTotalProjects(o.store.DB(), ids, false); err != nil {
-		sentry.Report(err, "GetOrganizationReports.populateRelatedTotalProjects fails")
-		return nil, 0, err
-	}
-	// populate total count of in progress projects
-	if err := organizations.CountOngoingProjects(o.store.DB(), ids, false); err != nil {
-		sentry.Report(err, "GetOrganizationReports.populateRelatedOngoingProjects fails")
-		return nil, 0, err
-	}
-	// total for forfaiting agreement
-	if err := organizations.CountFAProjects(o.store.DB(), ids, false); err != nil {
-		sentry.Report(err, "GetOrganizationReports.populateRelatedFAProjects fails")
-		return nil, 0, err
-	}
-
-	if err := organizations.CountMonitoringPhaseProjects(o.store.DB(), ids, false); err != nil {
-		sentry.Report(err, "GetOrganizationReports.populateRelatedMPProjects fails")
+	// counts of own and related projects
+	if err := organizations.PopulateProjectCounts(o.store.DB(), ids); err != nil {
+		sentry.Report(err, "GetOrganizationReports.populateProjectCounts fails")
 		return nil, 0, err
 	}
 
@@ -605,7 +567,7 @@ func (o *Organization) AcceptLEARApplication(ctx context.Context, uid, oid uuid.
 		Old:        filename,
 		Country:    org.Country,
 	}
-	if !org.Country.IsConsortium() {
+	if !org.Country.Consortium() {
 		admins = []uuid.UUID{getANWManager(o.store)}
 	}
 	if !approved {
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"strings"
 	"testing"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/mocks"
 	"stageai.tech/sunshine/sunshine/models"
@@ -205,6 +206,10 @@ func TestGetOrganizationsReport(t *testing.T) {
 	u := stores.NewTestAdminNwManager(t, e.UserStore)
 
 	expTotal, targetOrg := generateDummyData(t, e)
+	// the counts of the projects are read from the reporting views
+	if _, err := NewReporting(e).RefreshStale(time.Now()); err != nil {
+		t.Fatalf("refresh reporting views: %v", err)
+	}
 	learID := targetOrg.OrganizationRoles[0].UserID
 	lear, _ := e.UserStore.Get(context.Background(), learID)
 
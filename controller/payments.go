@@ -0,0 +1,609 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"stageai.tech/sunshine/sunshine/bankaccount"
+	"stageai.tech/sunshine/sunshine/bankstatement"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// maxTranches limits the number of installments of a payment schedule.
+const maxTranches = 120
+
+// TrancheStatus is a tranche of a payment schedule with what was paid of it.
+type TrancheStatus struct {
+	models.ForfaitingTranche
+
+	Paid        float64
+	Outstanding float64
+	Overdue     bool
+}
+
+// PaymentSchedule is the payment plan of a forfaiting application along with
+// the transfers made for it.
+type PaymentSchedule struct {
+	ForfaitingApplication uuid.UUID
+
+	Tranches  []TrancheStatus
+	Transfers []models.ForfaitingTransfer
+
+	Total       float64
+	Paid        float64
+	Outstanding float64
+	Overdue     float64
+}
+
+// Reconciliation is the result of importing a bank statement.
+type Reconciliation struct {
+	// Matched are the transfers recorded out of the statement.
+	Matched []models.ForfaitingTransfer
+
+	// Unmatched are the outgoing transfers which could not be matched to
+	// any tranche.
+	Unmatched []bankstatement.Entry
+
+	// Duplicates is the count of entries already imported before, Ignored
+	// the count of incoming transfers.
+	Duplicates int
+	Ignored    int
+}
+
+// CreateSchedule splits the total of an approved forfaiting application in
+// given count of tranches, due every intervalMonths starting from firstDue.
+// It replaces the previous schedule as long as nothing was paid of it yet.
+func (f *ForfaitingAgreement) CreateSchedule(ctx context.Context, faID uuid.UUID,
+	total float64, currency models.Currency, count int, firstDue time.Time, intervalMonths int) (*PaymentSchedule, error) {
+	fa, err := f.application(ctx, faID, UpdateFP)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fa.Approved() {
+		return nil, fmt.Errorf("%w: forfaiting application is not approved", ErrBadInput)
+	}
+	if total <= 0 || count < 1 || count > maxTranches || intervalMonths < 1 {
+		return nil, fmt.Errorf("%w: total must be positive, tranches between 1 and %d and interval at least a month", ErrBadInput, maxTranches)
+	}
+
+	var transfers int
+	if err := f.st.DB().Model(&models.ForfaitingTransfer{}).
+		Where("forfaiting_application_id = ?", faID).
+		Count(&transfers).Error; err != nil {
+		return nil, err
+	}
+	if transfers > 0 {
+		return nil, fmt.Errorf("%w: transfers were already recorded", ErrBadInput)
+	}
+
+	tx := f.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("forfaiting_application_id = ?", faID).
+		Delete(&models.ForfaitingTranche{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, t := range splitSchedule(faID, total, currency, count, firstDue, intervalMonths) {
+		if err := tx.Create(&t).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return f.schedule(faID, time.Now())
+}
+
+// UpdateTranche changes the amount or the due date of a tranche.
+func (f *ForfaitingAgreement) UpdateTranche(ctx context.Context, id uuid.UUID,
+	amount *float64, dueDate *time.Time) (*PaymentSchedule, error) {
+	var t models.ForfaitingTranche
+	if err := f.st.DB().Where("id = ?", id).First(&t).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := f.application(ctx, t.ForfaitingApplicationID, UpdateFP); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	if amount != nil {
+		if *amount <= 0 {
+			return nil, fmt.Errorf("%w: amount must be positive", ErrBadInput)
+		}
+		changes["amount"] = math.Round(*amount*100) / 100
+	}
+	if dueDate != nil {
+		changes["due_date"] = *dueDate
+		changes["overdue_notified_at"] = gorm.Expr("NULL")
+	}
+
+	if len(changes) > 0 {
+		if err := f.st.DB().Model(&t).Updates(changes).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return f.schedule(t.ForfaitingApplicationID, time.Now())
+}
+
+// Schedule returns the payment schedule of a forfaiting application.
+func (f *ForfaitingAgreement) Schedule(ctx context.Context, faID uuid.UUID) (*PaymentSchedule, error) {
+	if _, err := f.application(ctx, faID, GetFP); err != nil {
+		return nil, err
+	}
+	return f.schedule(faID, time.Now())
+}
+
+// RecordTransfer records a transfer made for a forfaiting application. A
+// transfer not assigned to a tranche goes to the earliest outstanding one.
+func (f *ForfaitingAgreement) RecordTransfer(ctx context.Context, faID uuid.UUID,
+	t models.ForfaitingTransfer) (*models.ForfaitingTransfer, error) {
+	if _, err := f.application(ctx, faID, UpdateFP); err != nil {
+		return nil, err
+	}
+	if t.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrBadInput)
+	}
+
+	s, err := f.schedule(faID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Tranche == nil {
+		if ts := earliestOutstanding(s.Tranches, t.Currency); ts != nil {
+			t.Tranche = &ts.ID
+		}
+	} else if !hasTranche(s.Tranches, *t.Tranche) {
+		return nil, fmt.Errorf("%w: tranche of another forfaiting application", ErrBadInput)
+	}
+
+	t.ID = uuid.Nil
+	t.ForfaitingApplicationID = faID
+	t.Amount = math.Round(t.Amount*100) / 100
+	t.IBAN = bankaccount.Normalize(t.IBAN)
+	t.StatementRef = nil
+	t.RecordedBy = &services.FromContext(ctx).User.ID
+	if t.Date.IsZero() {
+		t.Date = time.Now()
+	}
+
+	return &t, f.st.DB().Create(&t).Error
+}
+
+// payee is a forfaiting application with a payment schedule, to which the
+// transfers of a bank statement are matched.
+type payee struct {
+	fa       uuid.UUID
+	iban     string
+	tranches []TrancheStatus
+}
+
+// ImportStatement reads a bank statement, CAMT.053 or CSV, and records its
+// outgoing transfers against the payment schedules they match. Transfers
+// are matched by the reference of a tranche in their remittance information
+// or else by the IBAN of the beneficiary, preferring a tranche with exactly
+// the transferred amount outstanding. Only the schedules the user may
+// update are considered.
+func (f *ForfaitingAgreement) ImportStatement(ctx context.Context, name string, r io.Reader) (*Reconciliation, error) {
+	if !services.FromContext(ctx).Authorized() {
+		return nil, ErrUnauthorized
+	}
+
+	entries, err := bankstatement.Parse(name, r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+
+	payees, err := f.payees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID())
+	}
+	var imported []string
+	if err := f.st.DB().Model(&models.ForfaitingTransfer{}).
+		Where("statement_ref IN (?)", ids).
+		Pluck("statement_ref", &imported).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(imported))
+	for _, ref := range imported {
+		seen[ref] = true
+	}
+
+	var (
+		result Reconciliation
+		user   = services.FromContext(ctx).User.ID
+	)
+	for _, e := range entries {
+		id := e.ID()
+		switch {
+		case seen[id]:
+			result.Duplicates++
+			continue
+		case !e.Debit:
+			result.Ignored++
+			continue
+		}
+		seen[id] = true
+
+		fa, tranche, ok := match(e, payees)
+		if !ok {
+			result.Unmatched = append(result.Unmatched, e)
+			continue
+		}
+		result.Matched = append(result.Matched, models.ForfaitingTransfer{
+			ForfaitingApplicationID: fa,
+			Tranche:                 &tranche.ID,
+			Amount:                  float64(e.Cents()) / 100,
+			Currency:                tranche.Currency,
+			Date:                    e.Date,
+			IBAN:                    e.IBAN,
+			Reference:               e.Reference,
+			StatementRef:            &id,
+			RecordedBy:              &user,
+		})
+	}
+
+	tx := f.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	for i := range result.Matched {
+		if err := tx.Create(&result.Matched[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return &result, tx.Commit().Error
+}
+
+// payees returns the forfaiting applications with a payment schedule the user
+// may record transfers for.
+func (f *ForfaitingAgreement) payees(ctx context.Context) ([]payee, error) {
+	var ids []uuid.UUID
+	if err := f.st.DB().Model(&models.ForfaitingTranche{}).
+		Pluck("DISTINCT forfaiting_application_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	var result []payee
+	for _, id := range ids {
+		fa, err := f.application(ctx, id, UpdateFP)
+		if err == ErrUnauthorized {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := f.schedule(id, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, payee{
+			fa:       id,
+			iban:     bankaccount.Normalize(fa.BankAccount.IBAN),
+			tranches: s.Tranches,
+		})
+	}
+	return result, nil
+}
+
+// referenceTokens splits the payment reference into its upper cased
+// alphanumeric words.
+func referenceTokens(ref string) []string {
+	return strings.FieldsFunc(strings.ToUpper(ref), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// hasTokens reports whether the tokens are a run of the words of ref.
+func hasTokens(ref, tokens []string) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+next:
+	for i := 0; i+len(tokens) <= len(ref); i++ {
+		for j, t := range tokens {
+			if ref[i+j] != t {
+				continue next
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// match finds the tranche the outgoing transfer of the entry pays. A matched
+// entry counts towards the outstanding amount of the tranche for the next
+// entries.
+func match(e bankstatement.Entry, payees []payee) (uuid.UUID, *models.ForfaitingTranche, bool) {
+	ref := referenceTokens(e.Reference)
+	currency := models.Currency(strings.ToUpper(e.Currency))
+
+	pay := func(p payee, i int) (uuid.UUID, *models.ForfaitingTranche, bool) {
+		t := &p.tranches[i]
+		t.Paid += float64(e.Cents()) / 100
+		t.Outstanding = math.Max(0, float64(cents(t.Amount)-cents(t.Paid))/100)
+		return p.fa, &t.ForfaitingTranche, true
+	}
+
+	if len(ref) > 0 {
+		for _, p := range payees {
+			for i, t := range p.tranches {
+				if hasTokens(ref, referenceTokens(t.Reference)) {
+					return pay(p, i)
+				}
+			}
+		}
+	}
+
+	var candidates []payee
+	for _, p := range payees {
+		if p.iban != "" && p.iban == e.IBAN {
+			candidates = append(candidates, p)
+		}
+	}
+
+	for _, p := range candidates {
+		for i, t := range p.tranches {
+			if (currency == "" || t.Currency == currency) && cents(t.Outstanding) == e.Cents() {
+				return pay(p, i)
+			}
+		}
+	}
+
+	// the beneficiary is unambiguous, so a partial or a bigger transfer
+	// still goes to its earliest outstanding tranche
+	if len(candidates) == 1 {
+		p := candidates[0]
+		for i, t := range p.tranches {
+			if (currency == "" || t.Currency == currency) && t.Outstanding > 0 {
+				return pay(p, i)
+			}
+		}
+	}
+
+	return uuid.Nil, nil, false
+}
+
+// NotifyOverdue notifies the fund managers of the projects about the
+// tranches which are overdue since the last check. It returns the count of
+// tranches notified about.
+func (f *ForfaitingAgreement) NotifyOverdue(now time.Time) (int, error) {
+	var faIDs []uuid.UUID
+	err := f.st.DB().Model(&models.ForfaitingTranche{}).
+		Where("due_date < ? AND overdue_notified_at IS NULL", now).
+		Pluck("DISTINCT forfaiting_application_id", &faIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		n   int
+		ctx = context.Background()
+	)
+	for _, id := range faIDs {
+		s, err := f.schedule(id, now)
+		if err != nil {
+			return n, err
+		}
+
+		var overdue []TrancheStatus
+		for _, t := range s.Tranches {
+			if t.Overdue && t.OverdueNotifiedAt == nil {
+				overdue = append(overdue, t)
+			}
+		}
+		if len(overdue) == 0 {
+			continue
+		}
+
+		fadoc, err := f.st.FromKind("forfaiting_application").Get(ctx, id)
+		if err != nil {
+			return n, err
+		}
+		prjdoc, err := f.st.FromKind("project").Get(ctx, fadoc.Data.(*models.ForfaitingApplication).Project)
+		if err != nil {
+			return n, err
+		}
+		prj := prjdoc.Data.(*models.Project)
+
+		var fms []uuid.UUID
+		if prj.FundManager != nil {
+			fms = []uuid.UUID{*prj.FundManager}
+		} else if fms, err = f.pf.GetPortfolioRolesPerCountry(ctx, prj.Country, models.FundManagerRole); err != nil {
+			return n, err
+		}
+
+		for _, t := range overdue {
+			notifyAll(ctx, f.notifier, fms, models.Notification{
+				Action:     models.UserActionForfaitingPaymentOverdue,
+				TargetID:   prj.ID,
+				TargetKey:  prj.Name,
+				TargetType: models.ProjectT,
+				New:        fmt.Sprintf("%s: %.2f %s due on %s", t.Reference, t.Outstanding, t.Currency, t.DueDate.Format("2006-01-02")),
+				Country:    prj.Country,
+			})
+
+			if err := f.st.DB().Model(&t.ForfaitingTranche).
+				UpdateColumn("overdue_notified_at", now).Error; err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// application returns the forfaiting application if the user may do the
+// action on its project.
+func (f *ForfaitingAgreement) application(ctx context.Context, id uuid.UUID, a Action) (*models.ForfaitingApplication, error) {
+	doc, err := f.st.FromKind("forfaiting_application").Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	fa := doc.Data.(*models.ForfaitingApplication)
+
+	if !f.can(ctx, a, fa.Project) {
+		return nil, ErrUnauthorized
+	}
+	return fa, nil
+}
+
+func (f *ForfaitingAgreement) schedule(faID uuid.UUID, now time.Time) (*PaymentSchedule, error) {
+	var tranches []models.ForfaitingTranche
+	if err := f.st.DB().
+		Where("forfaiting_application_id = ?", faID).
+		Order("position").Find(&tranches).Error; err != nil {
+		return nil, err
+	}
+
+	var transfers []models.ForfaitingTransfer
+	if err := f.st.DB().
+		Where("forfaiting_application_id = ?", faID).
+		Order("date, created_at").Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+
+	s := schedule(tranches, transfers, now)
+	s.ForfaitingApplication = faID
+	return &s, nil
+}
+
+// schedule sums up the transfers paid for each of the tranches.
+func schedule(tranches []models.ForfaitingTranche, transfers []models.ForfaitingTransfer, now time.Time) PaymentSchedule {
+	paid := make(map[uuid.UUID]int64)
+	for _, t := range transfers {
+		if t.Tranche != nil {
+			paid[*t.Tranche] += cents(t.Amount)
+		}
+	}
+
+	var (
+		s                                 = PaymentSchedule{Transfers: transfers}
+		total, allPaid, outstanding, late int64
+	)
+	for _, t := range tranches {
+		ts := TrancheStatus{ForfaitingTranche: t}
+		p, o := paid[t.ID], cents(t.Amount)-paid[t.ID]
+		if o < 0 {
+			o = 0
+		}
+		ts.Paid, ts.Outstanding = float64(p)/100, float64(o)/100
+		ts.Overdue = o > 0 && t.DueDate.Before(now)
+
+		total += cents(t.Amount)
+		allPaid += p
+		outstanding += o
+		if ts.Overdue {
+			late += o
+		}
+		s.Tranches = append(s.Tranches, ts)
+	}
+	for _, t := range transfers {
+		if t.Tranche == nil {
+			allPaid += cents(t.Amount)
+		}
+	}
+
+	s.Total = float64(total) / 100
+	s.Paid = float64(allPaid) / 100
+	s.Outstanding = float64(outstanding) / 100
+	s.Overdue = float64(late) / 100
+	return s
+}
+
+// splitSchedule splits the total in equal tranches, the last one taking the
+// cents left over.
+func splitSchedule(faID uuid.UUID, total float64, currency models.Currency,
+	count int, firstDue time.Time, intervalMonths int) []models.ForfaitingTranche {
+	var (
+		all    = cents(total)
+		each   = all / int64(count)
+		prefix = "FA" + strings.ToUpper(faID.String()[:8])
+		result = make([]models.ForfaitingTranche, count)
+	)
+	for i := range result {
+		amount := each
+		if i == count-1 {
+			amount = all - each*int64(count-1)
+		}
+		result[i] = models.ForfaitingTranche{
+			ForfaitingApplicationID: faID,
+			Position:                i + 1,
+			Amount:                  float64(amount) / 100,
+			Currency:                currency,
+			DueDate:                 firstDue.AddDate(0, i*intervalMonths, 0),
+			Reference:               fmt.Sprintf("%s-%02d", prefix, i+1),
+		}
+	}
+	return result
+}
+
+func earliestOutstanding(tranches []TrancheStatus, currency models.Currency) *TrancheStatus {
+	sorted := make([]TrancheStatus, len(tranches))
+	copy(sorted, tranches)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].DueDate.Before(sorted[j].DueDate) })
+
+	for _, t := range sorted {
+		if t.Outstanding > 0 && (currency == "" || t.Currency == currency) {
+			return &t
+		}
+	}
+	return nil
+}
+
+func hasTranche(tranches []TrancheStatus, id uuid.UUID) bool {
+	for _, t := range tranches {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
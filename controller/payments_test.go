@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/bankstatement"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSplitSchedule(t *testing.T) {
+	var (
+		fa    = uuid.MustParse("1234abcd-0000-0000-0000-000000000000")
+		first = time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)
+	)
+	tranches := splitSchedule(fa, 1000, models.CurrencyEUR, 3, first, 2)
+	if len(tranches) != 3 {
+		t.Fatalf("Expected 3 tranches, got %d", len(tranches))
+	}
+
+	var total int64
+	for _, tr := range tranches {
+		total += cents(tr.Amount)
+	}
+	if total != 100000 {
+		t.Errorf("Expected the tranches to sum up to 1000, got %v", float64(total)/100)
+	}
+	if tranches[0].Amount != 333.33 || tranches[2].Amount != 333.34 {
+		t.Errorf("Expected the last tranche to take the cents left over, got %v", tranches)
+	}
+	if tranches[1].Reference != "FA1234ABCD-02" || tranches[1].Position != 2 {
+		t.Errorf("Expected reference FA1234ABCD-02 at position 2, got %s at %d", tranches[1].Reference, tranches[1].Position)
+	}
+	if want := first.AddDate(0, 4, 0); !tranches[2].DueDate.Equal(want) {
+		t.Errorf("Expected the last tranche due on %v, got %v", want, tranches[2].DueDate)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	var (
+		now      = time.Date(2020, time.October, 19, 0, 0, 0, 0, time.UTC)
+		tranches = splitSchedule(uuid.New(), 300, models.CurrencyEUR, 3, now.AddDate(0, -2, 0), 1)
+	)
+	for i := range tranches {
+		tranches[i].ID = uuid.New()
+	}
+	transfers := []models.ForfaitingTransfer{
+		{Tranche: &tranches[0].ID, Amount: 100},
+		{Tranche: &tranches[1].ID, Amount: 40},
+		{Amount: 5},
+	}
+
+	s := schedule(tranches, transfers, now)
+	if s.Total != 300 || s.Paid != 145 || s.Outstanding != 160 {
+		t.Errorf("Expected 145 of 300 paid and 160 outstanding, got %v of %v and %v", s.Paid, s.Total, s.Outstanding)
+	}
+	if s.Tranches[0].Overdue || !s.Tranches[1].Overdue || s.Tranches[2].Overdue {
+		t.Errorf("Expected only the second tranche overdue, got %v", s.Tranches)
+	}
+	if s.Overdue != 60 {
+		t.Errorf("Expected 60 overdue, got %v", s.Overdue)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	newPayee := func(iban string, amounts ...float64) payee {
+		p := payee{fa: uuid.New(), iban: iban}
+		for i, a := range splitSchedule(p.fa, 300, models.CurrencyEUR, len(amounts), time.Now(), 1) {
+			a.ID = uuid.New()
+			p.tranches = append(p.tranches, TrancheStatus{ForfaitingTranche: a, Outstanding: amounts[i]})
+		}
+		return p
+	}
+	var (
+		a      = newPayee("LV80BANK0000435195001", 100, 100, 100)
+		b      = newPayee("LV10BANK0000000000001", 150, 150)
+		c      = newPayee("LV10BANK0000000000001", 300)
+		payees = []payee{a, b, c}
+	)
+
+	cases := []struct {
+		name    string
+		entry   bankstatement.Entry
+		fa      uuid.UUID
+		tranche uuid.UUID
+	}{
+		{
+			name:    "reference",
+			entry:   bankstatement.Entry{Amount: 10, Currency: "EUR", Reference: "Payout " + a.tranches[2].Reference},
+			fa:      a.fa,
+			tranche: a.tranches[2].ID,
+		},
+		{
+			name:  "reference of another tranche",
+			entry: bankstatement.Entry{Amount: 10, Currency: "EUR", Reference: "Payout " + a.tranches[0].Reference + "1"},
+		},
+		{
+			name:    "iban and amount",
+			entry:   bankstatement.Entry{Amount: 300, Currency: "EUR", IBAN: c.iban},
+			fa:      c.fa,
+			tranche: c.tranches[0].ID,
+		},
+		{
+			name:    "earliest outstanding",
+			entry:   bankstatement.Entry{Amount: 60, IBAN: a.iban},
+			fa:      a.fa,
+			tranche: a.tranches[0].ID,
+		},
+		{
+			name:    "paid in full",
+			entry:   bankstatement.Entry{Amount: 40, IBAN: a.iban},
+			fa:      a.fa,
+			tranche: a.tranches[0].ID,
+		},
+		{
+			name:    "next tranche",
+			entry:   bankstatement.Entry{Amount: 100, IBAN: a.iban},
+			fa:      a.fa,
+			tranche: a.tranches[1].ID,
+		},
+		{
+			name:  "ambiguous iban",
+			entry: bankstatement.Entry{Amount: 10, IBAN: b.iban},
+		},
+		{
+			name:  "other currency",
+			entry: bankstatement.Entry{Amount: 100, Currency: "GBP", IBAN: a.iban},
+		},
+		{
+			name:  "unknown iban",
+			entry: bankstatement.Entry{Amount: 100, IBAN: "DE89370400440532013000"},
+		},
+	}
+	for _, c := range cases {
+		fa, tranche, ok := match(c.entry, payees)
+		if c.fa == uuid.Nil {
+			if ok {
+				t.Errorf("%s: expected no match, got tranche %v", c.name, tranche.ID)
+			}
+			continue
+		}
+		if !ok || fa != c.fa || tranche.ID != c.tranche {
+			t.Errorf("%s: expected tranche %v, got %v", c.name, c.tranche, tranche)
+		}
+	}
+}
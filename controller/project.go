@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/contract"
 	"stageai.tech/sunshine/sunshine/models"
@@ -343,7 +344,7 @@ func (p *Project) UploadFile(ctx context.Context, pid uuid.UUID, form RequestFor
 	return uploadFile(ctx, p.st, p.notifier, form, doc, p.uploadPath)
 }
 
-func (p *Project) GetFile(ctx context.Context, pid uuid.UUID, filename string) (*models.Attachment, *os.File, error) {
+func (p *Project) GetFile(ctx context.Context, pid uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
 	doc, err := p.st.Get(ctx, pid)
 	if err != nil {
 		return nil, nil, err
@@ -355,7 +356,7 @@ func (p *Project) GetFile(ctx context.Context, pid uuid.UUID, filename string) (
 		return nil, nil, ErrUnauthorized
 	}
 
-	return getFile(ctx, p.st, pid, filename, p.uploadPath)
+	return getFile(ctx, p.st, pid, filename, p.uploadPath, fr)
 }
 
 func (p *Project) DeleteFile(ctx context.Context, pid uuid.UUID, filename string) error {
@@ -753,9 +754,10 @@ func (p *Project) CommentProject(ctx context.Context, pid uuid.UUID, content str
 	}
 
 	comment := models.ProjectComment{
-		UserID:    services.FromContext(ctx).User.ID,
-		ProjectID: pid,
-		Content:   content,
+		UserID:     services.FromContext(ctx).User.ID,
+		ProjectID:  pid,
+		Content:    content,
+		TargetKind: models.CommentOnProject,
 	}
 
 	if topic != nil {
@@ -791,6 +793,42 @@ func (p *Project) AdvanceToMilestone(ctx context.Context, id uuid.UUID, m models
 	return err
 }
 
+// Savings returns the expected and measured energy, primary energy and CO2
+// savings of a project. If there is no energy factor for the project's
+// country and heating type, only the final energy savings are populated.
+func (p *Project) Savings(ctx context.Context, pid uuid.UUID) (*models.ProjectSavings, error) {
+	doc, err := p.st.Get(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	prj := doc.Data.(*models.Project)
+	if !Can(ctx, GetProjectSavings, prj.ID, prj.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	var ctr contract.Contract
+	if err := p.st.DB().Where("project_id = ?", pid).First(&ctr).Error; err != nil {
+		if stores.IsRecordNotFound(err) {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+
+	f, err := energyFactor(p.st.DB(), prj.Country, prj.AssetSnapshot.HeatingType, time.Now())
+	if errors.Is(err, ErrNotFound) {
+		s := contract.Savings(ctr, models.EnergyFactor{})
+		s.Factor = nil
+		return &s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := contract.Savings(ctr, *f)
+	return &s, nil
+}
+
 func (p *Project) ExportMeetings(ctx context.Context, prjID uuid.UUID) (string, error) {
 	doc, err := p.st.Get(ctx, prjID)
 	if err != nil {
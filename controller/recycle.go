@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// RecycleBin lists, restores and permanently purges soft deleted records.
+type RecycleBin struct {
+	db         *gorm.DB
+	uploadPath string
+}
+
+func NewRecycleBin(env *services.Env) *RecycleBin {
+	return &RecycleBin{
+		db:         env.DB,
+		uploadPath: env.Paths.Uploads,
+	}
+}
+
+// Deleted is a record in the recycle bin.
+type Deleted struct {
+	ID        uuid.UUID
+	Kind      string
+	Name      string
+	Country   models.Country
+	DeletedAt time.Time
+}
+
+// purgeOrder lists the kinds referring to others first, so that a record
+// and the records it blocks from being purged go in the same run.
+var purgeOrder = []string{"task", "meeting", "project", "asset", "organization", "user"}
+
+func lookupBinKind(kind string) (stores.BinKind, error) {
+	k, ok := stores.LookupBinKind(kind)
+	if !ok {
+		return stores.BinKind{}, fmt.Errorf("%w: no recycle bin for %q", ErrBadInput, kind)
+	}
+	return k, nil
+}
+
+// List returns the deleted records of the kind, optionally only of the
+// country, the latest deleted first. Records merged into others are left
+// out as they cannot be restored.
+func (rb *RecycleBin) List(ctx context.Context, kind string, country models.Country) ([]Deleted, error) {
+	if !Can(ctx, ManageRecycleBin, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+
+	k, err := lookupBinKind(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	q := rb.db.Table(k.Table+" t").
+		Select(fmt.Sprintf("t.id, ? AS kind, %s AS name, COALESCE(%s, '') AS country, t.deleted_at", k.Name, k.Country), kind).
+		Where("t.deleted_at IS NOT NULL").
+		Where("NOT EXISTS (SELECT 1 FROM merges m WHERE m.source = t.id)")
+	if country != "" {
+		q = q.Where(k.Country+" = ?", country)
+	}
+
+	var result []Deleted
+	err = q.Order("t.deleted_at DESC").Scan(&result).Error
+	return result, err
+}
+
+// Delete moves the record to the recycle bin along with its attachments,
+// children and roles.
+func (rb *RecycleBin) Delete(ctx context.Context, kind string, id uuid.UUID) error {
+	k, err := lookupBinKind(kind)
+	if err != nil {
+		return err
+	}
+
+	var rec struct{ Country models.Country }
+	err = rb.db.Table(k.Table+" t").Select(fmt.Sprintf("COALESCE(%s, '') AS country", k.Country)).
+		Where("t.id = ? AND t.deleted_at IS NULL", id).Scan(&rec).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return fmt.Errorf("%w: no %s %v", ErrNotFound, kind, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !Can(ctx, ManageRecycleBin, uuid.Nil, rec.Country) {
+		return ErrUnauthorized
+	}
+
+	return stores.Trash(rb.db, kind, id)
+}
+
+// Restore undeletes the record along with its attachments, children and
+// roles deleted at the same time.
+func (rb *RecycleBin) Restore(ctx context.Context, kind string, id uuid.UUID) error {
+	k, err := lookupBinKind(kind)
+	if err != nil {
+		return err
+	}
+
+	var rec struct {
+		DeletedAt *time.Time
+		Country   models.Country
+	}
+	err = rb.db.Table(k.Table+" t").Select(fmt.Sprintf("t.deleted_at, COALESCE(%s, '') AS country", k.Country)).
+		Where("t.id = ?", id).Scan(&rec).Error
+	if gorm.IsRecordNotFoundError(err) || err == nil && rec.DeletedAt == nil {
+		return fmt.Errorf("%w: no deleted %s %v", ErrNotFound, kind, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !Can(ctx, ManageRecycleBin, uuid.Nil, rec.Country) {
+		return ErrUnauthorized
+	}
+
+	var merged int
+	if err := rb.db.Table("merges").Where("source = ?", id).Count(&merged).Error; err != nil {
+		return err
+	}
+	if merged > 0 {
+		return fmt.Errorf("%w: %s %v was merged into another one", ErrBadInput, kind, id)
+	}
+
+	tx := rb.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	for _, c := range append(k.Children, stores.BinChild{Table: "attachments", Column: "owner_id"}) {
+		err := tx.Exec(fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE %s = ? AND deleted_at = ?", c.Table, c.Column),
+			id, *rec.DeletedAt).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// roles whose other side was purged meanwhile are dropped by purge, and
+	// those granted again meanwhile are kept as they are
+	for _, r := range k.Roles {
+		err := tx.Exec(fmt.Sprintf(`INSERT INTO %[1]s
+			SELECT (jsonb_populate_record(NULL::%[1]s, role)).* FROM trashed_roles
+			WHERE owner_id = ? AND deleted_at = ? AND role_table = ?
+			ON CONFLICT DO NOTHING`, r.Table), id, *rec.DeletedAt, r.Table).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	err = tx.Exec("DELETE FROM trashed_roles WHERE owner_id = ? AND deleted_at = ?", id, *rec.DeletedAt).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec(fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = ?", k.Table), id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Purge permanently deletes the records and attachments deleted before the
+// given time along with their uploaded files and returns how many records
+// were purged. Records failing to be purged, e.g. because other rows still
+// refer to them, are skipped.
+func (rb *RecycleBin) Purge(before time.Time) (int, error) {
+	var n int
+	for _, kind := range purgeOrder {
+		k, _ := stores.LookupBinKind(kind)
+
+		q := rb.db.Table(k.Table+" t").Where("t.deleted_at < ?", before)
+		if k.Guard != "" {
+			q = q.Where("NOT " + k.Guard)
+		}
+
+		var ids []uuid.UUID
+		if err := q.Pluck("t.id", &ids).Error; err != nil {
+			return n, err
+		}
+
+		for _, id := range ids {
+			if err := rb.purge(k, id); err != nil {
+				log.Printf("purge %s %v: %v", kind, id, err)
+				continue
+			}
+			n++
+		}
+	}
+
+	// attachments deleted on their own, not those of records in the bin
+	// which were skipped or are kept for now
+	q := rb.db.Unscoped().Where("deleted_at < ?", before)
+	for _, kind := range purgeOrder {
+		k, _ := stores.LookupBinKind(kind)
+		q = q.Where(fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM %s o
+			WHERE o.id = attachments.owner_id AND o.deleted_at IS NOT NULL)`, k.Table))
+	}
+
+	var atts []models.Attachment
+	if err := q.Find(&atts).Error; err != nil {
+		return n, err
+	}
+	for _, a := range atts {
+		if err := rb.db.Unscoped().Delete(&a).Error; err != nil {
+			return n, err
+		}
+		rb.removeFile(a)
+	}
+
+	return n, nil
+}
+
+func (rb *RecycleBin) purge(k stores.BinKind, id uuid.UUID) error {
+	var atts []models.Attachment
+	if err := rb.db.Unscoped().Where("owner_id = ?", id).Find(&atts).Error; err != nil {
+		return err
+	}
+
+	tx := rb.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	for _, c := range append(k.Children, stores.BinChild{Table: "attachments", Column: "owner_id"}, stores.BinChild{Table: k.Table, Column: "id"}) {
+		if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", c.Table, c.Column), id).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// the trashed roles of the record and those of others granted on it
+	err := tx.Exec(`DELETE FROM trashed_roles WHERE owner_id = ?
+		OR ?::text IN (role->>'user_id', role->>'organization_id', role->>'project_id')`, id, id).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	for _, a := range atts {
+		rb.removeFile(a)
+	}
+	return nil
+}
+
+func (rb *RecycleBin) removeFile(a models.Attachment) {
+	err := os.Remove(filepath.Join(rb.uploadPath, fmt.Sprintf("%s-%s", a.Owner, a.ID)))
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("purge file of attachment %v: %v", a.ID, err)
+	}
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+)
+
+func TestRecycleBin(t *testing.T) {
+	e := services.NewTestEnv(t)
+	rb := NewRecycleBin(e)
+	m := NewMeeting(e)
+
+	org := stores.NewTestOrg(t, e.OrganizationStore)
+	meet := stores.NewTestMeeting(t, e.MeetingsStore, stores.TMeetingWithOrg(org.ID))
+	att := stores.NewTestAttachment(t, e.MeetingsStore, meet.ID)
+
+	actx := services.NewTestContext(t, e, stores.NewTestAdmin(t, e.UserStore))
+	rctx := services.NewTestContext(t, e, stores.NewTestUser(t, e.UserStore))
+
+	if err := m.Delete(actx, meet.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := rb.List(rctx, "meeting", ""); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := rb.List(actx, "contract", ""); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected ErrBadInput, got %v", err)
+	}
+
+	deleted, err := rb.List(actx, "meeting", models.CountryLatvia)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != meet.ID || deleted[0].Name != meet.Name {
+		t.Fatalf("Expected the deleted meeting, got %v", deleted)
+	}
+
+	if err := rb.Restore(actx, "meeting", meet.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, err := m.Get(actx, meet.ID); err != nil {
+		t.Errorf("Expected the meeting to be restored, got %v", err)
+	}
+
+	var guests int
+	e.DB.Model(&models.MeetingGuest{}).Where("meeting_id = ?", meet.ID).Count(&guests)
+	if guests != len(meet.Guests) {
+		t.Errorf("Expected %d guests, got %d", len(meet.Guests), guests)
+	}
+	if err := e.DB.Where("id = ?", att.ID).First(&models.Attachment{}).Error; err != nil {
+		t.Errorf("Expected the attachment to be restored, got %v", err)
+	}
+
+	if err := rb.Restore(actx, "meeting", meet.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := m.Delete(actx, meet.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := rb.Purge(time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+
+	var n int
+	e.DB.Unscoped().Model(&models.Meeting{}).Where("id = ?", meet.ID).Count(&n)
+	if n != 0 {
+		t.Errorf("Expected the meeting to be purged")
+	}
+	e.DB.Unscoped().Model(&models.Attachment{}).Where("id = ?", att.ID).Count(&n)
+	if n != 0 {
+		t.Errorf("Expected the attachment to be purged")
+	}
+}
+
+func TestRecycleBinRoles(t *testing.T) {
+	e := services.NewTestEnv(t)
+	rb := NewRecycleBin(e)
+
+	lear := stores.NewTestUser(t, e.UserStore)
+	org := stores.NewTestOrg(t, e.OrganizationStore, lear.ID)
+
+	actx := services.NewTestContext(t, e, stores.NewTestAdmin(t, e.UserStore))
+	rctx := services.NewTestContext(t, e, stores.NewTestUser(t, e.UserStore))
+
+	if err := rb.Delete(rctx, "organization", org.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if err := rb.Delete(actx, "organization", org.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	roles := func() int {
+		var n int
+		e.DB.Model(&models.OrganizationRole{}).Where("organization_id = ? AND user_id = ?", org.ID, lear.ID).Count(&n)
+		return n
+	}
+	if n := roles(); n != 0 {
+		t.Errorf("Expected the roles removed with the organization, got %d", n)
+	}
+
+	if err := rb.Restore(rctx, "organization", org.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if err := rb.Restore(actx, "organization", org.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if n := roles(); n != 1 {
+		t.Errorf("Expected the role restored, got %d", n)
+	}
+}
+
+func TestRecycleBinGuard(t *testing.T) {
+	e := services.NewTestEnv(t)
+	rb := NewRecycleBin(e)
+	ctx := context.Background()
+
+	fa := stores.NewTestFA(t, e.ProjectStore)
+	prj, err := e.ProjectStore.Get(ctx, fa.Project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	att := stores.NewTestAttachment(t, e.ProjectStore, prj.ID)
+
+	// deleting through the store keeps the roles for restoring
+	if err := e.ProjectStore.Delete(ctx, prj); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	var trashed int
+	e.DB.Table("trashed_roles").Where("owner_id = ?", prj.ID).Count(&trashed)
+	if trashed != len(prj.Data.(*models.Project).ProjectRoles) {
+		t.Errorf("Expected the project roles trashed, got %d", trashed)
+	}
+
+	if _, err := rb.Purge(time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+
+	var n int
+	e.DB.Unscoped().Model(&models.Project{}).Where("id = ?", prj.ID).Count(&n)
+	if n != 1 {
+		t.Errorf("Expected the project with a forfaiting application kept")
+	}
+	e.DB.Unscoped().Model(&models.Attachment{}).Where("id = ?", att.ID).Count(&n)
+	if n != 1 {
+		t.Errorf("Expected the attachment of the kept project kept")
+	}
+}
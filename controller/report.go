@@ -0,0 +1,578 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/mail"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+	"stageai.tech/sunshine/sunshine/xlsx"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/matcornic/hermes/v2"
+)
+
+// reportHour is the hour of the day, in UTC, the scheduled reports are
+// generated at.
+const reportHour = 6
+
+type Report struct {
+	st         stores.Store
+	org        *Organization
+	prj        *Project
+	asset      *Asset
+	mailer     services.Mailer
+	uploadPath string
+}
+
+func NewReport(env *services.Env) *Report {
+	return &Report{
+		st:         env.ProjectStore,
+		org:        NewOrganization(env),
+		prj:        NewProject(env),
+		asset:      NewAsset(env),
+		mailer:     env.Mailer,
+		uploadPath: env.Paths.Uploads,
+	}
+}
+
+// Save creates the report definition or, given its id, replaces it. New
+// definitions are generated as the user saving them.
+func (r *Report) Save(ctx context.Context, id *uuid.UUID, def models.ReportDefinition) (*models.ReportDefinition, error) {
+	if err := validReport(&def); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+	if !Can(ctx, ManageReports, uuid.Nil, def.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	now := time.Now()
+	if id == nil {
+		def.Value = models.Value{ID: uuid.New()}
+		def.Author = &services.FromContext(ctx).User.ID
+		def.NextRunAt = nextRun(def.Frequency, now)
+	} else {
+		old, err := r.definition(ctx, *id)
+		if err != nil {
+			return nil, err
+		}
+		def.Value = old.Value
+		def.Author = old.Author
+		def.LastRunAt = old.LastRunAt
+		def.NextRunAt = old.NextRunAt
+		if old.DisabledAt != nil {
+			def.Author = &services.FromContext(ctx).User.ID
+		}
+		if def.Frequency != old.Frequency || old.DisabledAt != nil {
+			def.NextRunAt = nextRun(def.Frequency, now)
+		}
+	}
+
+	return &def, r.st.DB().Save(&def).Error
+}
+
+// Delete deletes the report definition. Its archive is kept.
+func (r *Report) Delete(ctx context.Context, id uuid.UUID) error {
+	def, err := r.definition(ctx, id)
+	if err != nil {
+		return err
+	}
+	return r.st.DB().Delete(def).Error
+}
+
+// List returns the report definitions the user of the context manages.
+func (r *Report) List(ctx context.Context) ([]models.ReportDefinition, error) {
+	if !services.FromContext(ctx).Authorized() {
+		return nil, ErrUnauthorized
+	}
+
+	var defs []models.ReportDefinition
+	if err := r.st.DB().Order("name").Find(&defs).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]models.ReportDefinition, 0, len(defs))
+	for _, d := range defs {
+		if Can(ctx, ManageReports, uuid.Nil, d.Country) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// Get returns the report definition with the id.
+func (r *Report) Get(ctx context.Context, id uuid.UUID) (*models.ReportDefinition, error) {
+	return r.definition(ctx, id)
+}
+
+// Runs returns the archive of the report definition, the latest first.
+func (r *Report) Runs(ctx context.Context, id uuid.UUID, first, offset int) ([]models.ReportRun, error) {
+	if _, err := r.definition(ctx, id); err != nil {
+		return nil, err
+	}
+
+	db := r.st.DB().Where("definition_id = ?", id)
+	if offset != 0 {
+		db = db.Offset(offset)
+	}
+	if first != 0 {
+		db = db.Limit(first)
+	}
+
+	var runs []models.ReportRun
+	return runs, db.Order("created_at DESC").Find(&runs).Error
+}
+
+// File returns the attachment of the report run, if it was generated.
+func (r *Report) File(ctx context.Context, run models.ReportRun) (*models.Attachment, error) {
+	var att models.Attachment
+	err := r.st.DB().Where("owner_id = ?", run.ID).First(&att).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	return &att, err
+}
+
+// Generate generates the report now as the user of the context and emails it
+// to the subscribers. The schedule of the report is left as it is.
+func (r *Report) Generate(ctx context.Context, id uuid.UUID) (*models.ReportRun, error) {
+	def, err := r.definition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.run(ctx, *def, time.Now())
+}
+
+// Download opens the file of the report run and logs the download.
+func (r *Report) Download(ctx context.Context, runID uuid.UUID, fr FileRequest) (*models.Attachment, File, error) {
+	var run models.ReportRun
+	if err := r.st.DB().Where("id = ?", runID).First(&run).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	if _, err := r.definition(ctx, run.DefinitionID); err != nil {
+		return nil, nil, err
+	}
+
+	att, err := r.File(ctx, run)
+	if err != nil {
+		return nil, nil, err
+	}
+	if att == nil {
+		return nil, nil, ErrNotFound
+	}
+
+	dl := models.Download{
+		Owner:      run.ID,
+		Attachment: &att.ID,
+		Name:       att.Name,
+	}
+	f, err := openFile(ctx, r.st.DB(), filepath.Join(r.uploadPath, fmt.Sprintf("%s-%s", run.ID, att.ID)), *att, &dl, fr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return att, f, nil
+}
+
+// errNoAuthor is returned for report definitions whose author is gone.
+var errNoAuthor = errors.New("the author of the report is gone")
+
+// RunDue generates the reports due at now, each as its author, and returns
+// how many were generated. Reports failing are logged and tried again on
+// their next run. Reports whose author is gone are disabled.
+func (r *Report) RunDue(now time.Time) (int, error) {
+	var defs []models.ReportDefinition
+	err := r.st.DB().Where("next_run_at <= ? AND disabled_at IS NULL", now).Find(&defs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, def := range defs {
+		ctx, err := r.authorContext(def)
+		if errors.Is(err, errNoAuthor) {
+			log.Printf("report %v: %v, disabling it", def.ID, err)
+			if err := r.st.DB().Model(&def).UpdateColumn("disabled_at", now).Error; err != nil {
+				log.Printf("report %v: %v", def.ID, err)
+			}
+			continue
+		}
+
+		cols := map[string]interface{}{"next_run_at": nextRun(def.Frequency, now)}
+		if err == nil {
+			_, err = r.run(ctx, def, now)
+			cols["last_run_at"] = now
+		}
+		if err != nil {
+			log.Printf("report %v: %v", def.ID, err)
+		} else {
+			n++
+		}
+
+		if err := r.st.DB().Model(&def).UpdateColumns(cols).Error; err != nil {
+			log.Printf("report %v: %v", def.ID, err)
+		}
+	}
+	return n, nil
+}
+
+// authorContext returns the context of the author of the report definition,
+// or errNoAuthor if the author is gone.
+func (r *Report) authorContext(def models.ReportDefinition) (context.Context, error) {
+	ctx := context.Background()
+	if def.Author == nil {
+		return nil, errNoAuthor
+	}
+
+	doc, err := r.st.FromKind("user").Get(ctx, *def.Author)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errNoAuthor
+	}
+	if err != nil {
+		return nil, err
+	}
+	return services.WithContext(ctx, &models.Token{User: *doc.Data.(*models.User)}), nil
+}
+
+// run generates the report, attaches it to a new run in the archive and
+// emails it to the subscribers. A report failing to be generated or
+// delivered is archived along with the error.
+func (r *Report) run(ctx context.Context, def models.ReportDefinition, now time.Time) (*models.ReportRun, error) {
+	run := models.ReportRun{Value: models.Value{ID: uuid.New(), CreatedAt: now}, DefinitionID: def.ID}
+
+	if err := r.deliver(ctx, def, &run); err != nil {
+		run.Error = err.Error()
+		log.Printf("report %v: %v", def.ID, err)
+	}
+	return &run, r.st.DB().Create(&run).Error
+}
+
+func (r *Report) deliver(ctx context.Context, def models.ReportDefinition, run *models.ReportRun) error {
+	if !Can(ctx, ManageReports, uuid.Nil, def.Country) {
+		return errors.New("the author may no longer manage the report")
+	}
+
+	sheet, err := r.sheet(ctx, def)
+	if err != nil {
+		return err
+	}
+	run.Rows = len(sheet.Rows)
+
+	var (
+		content     bytes.Buffer
+		contentType = xlsx.ContentType
+	)
+	if def.Format == models.ReportPDF {
+		contentType = "application/pdf"
+		err = renderReportPDF(ctx, &content, def, *run, sheet)
+	} else {
+		err = xlsx.Write(&content, sheet)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render the report: %w", err)
+	}
+
+	up := Upload{
+		File:        bytes.NewReader(content.Bytes()),
+		Filename:    fmt.Sprintf("%s-report-%s.%s", def.Kind, run.CreatedAt.UTC().Format("2006-01-02"), def.Format),
+		Size:        int64(content.Len()),
+		ContentType: contentType,
+		UploadType:  "scheduled report",
+	}
+	if err := uploadGQLfile(r.st, up, run.ID, r.uploadPath); err != nil {
+		return fmt.Errorf("fail to upload %s: %w", up.Filename, err)
+	}
+
+	if len(def.Subscribers) == 0 {
+		return nil
+	}
+	to := make([]mail.Address, len(def.Subscribers))
+	for i, s := range def.Subscribers {
+		to[i] = mail.Address{Address: s}
+	}
+	err = r.mailer.Send(to, "Report: "+def.Name,
+		hermes.Email{Body: hermes.Body{Intros: []string{
+			fmt.Sprintf("Attached is the report %s as of %s.", def.Name, run.CreatedAt.UTC().Format("2006-01-02")),
+		}}},
+		services.Attachment{Filename: up.Filename, ContentType: contentType, Content: content.Bytes()},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to email the report: %w", err)
+	}
+	run.Recipients = len(to)
+	return nil
+}
+
+// sheet lists the records of the report the user of the context may see.
+func (r *Report) sheet(ctx context.Context, def models.ReportDefinition) (xlsx.Sheet, error) {
+	switch def.Kind {
+	case models.ReportOrganizations:
+		orgs, _, err := r.org.GetReport(ctx, 0, 0)
+		if err != nil {
+			return xlsx.Sheet{}, err
+		}
+		return organizationSheet(def, orgs), nil
+
+	case models.ReportProjects:
+		docs, _, _, err := r.prj.Reports(ctx, stores.Filter{})
+		if err != nil {
+			return xlsx.Sheet{}, err
+		}
+		prjs := make([]models.Project, len(docs))
+		ids := make([]uuid.UUID, len(docs))
+		for i, d := range docs {
+			prjs[i] = *d.Data.(*models.Project)
+			ids[i] = d.ID
+		}
+
+		var sums []models.ProjectReportSummary
+		if len(ids) > 0 {
+			if err := r.st.DB().Where("project_id IN (?)", ids).Find(&sums).Error; err != nil {
+				return xlsx.Sheet{}, err
+			}
+		}
+		return projectSheet(def, prjs, sums), nil
+
+	default:
+		docs, _, err := r.asset.Reports(ctx, stores.Filter{})
+		if err != nil {
+			return xlsx.Sheet{}, err
+		}
+		assets := make([]models.Asset, len(docs))
+		for i, d := range docs {
+			assets[i] = *d.Data.(*models.Asset)
+		}
+		return assetSheet(def, assets), nil
+	}
+}
+
+// definition returns the report definition with the id if the user of the
+// context manages it.
+func (r *Report) definition(ctx context.Context, id uuid.UUID) (*models.ReportDefinition, error) {
+	var def models.ReportDefinition
+	if err := r.st.DB().Where("id = ?", id).First(&def).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !Can(ctx, ManageReports, uuid.Nil, def.Country) {
+		return nil, ErrUnauthorized
+	}
+	return &def, nil
+}
+
+// validReport checks the report definition and cleans up its name and
+// subscribers.
+func validReport(def *models.ReportDefinition) error {
+	def.Name = strings.TrimSpace(def.Name)
+	if def.Name == "" {
+		return errors.New("empty report name")
+	}
+	for _, err := range []error{def.Kind.Valid(), def.Format.Valid(), def.Frequency.Valid()} {
+		if err != nil {
+			return err
+		}
+	}
+	if def.Country != "" {
+		if err := def.Country.Valid(); err != nil {
+			return err
+		}
+	}
+	if def.ProjectStatus != nil && def.Kind != models.ReportProjects {
+		return errors.New("only project reports are filtered by project status")
+	}
+	if def.BuildingType != nil && def.Kind == models.ReportOrganizations {
+		return errors.New("organization reports are not filtered by building type")
+	}
+
+	for i, s := range def.Subscribers {
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return fmt.Errorf("invalid subscriber %q: %v", s, err)
+		}
+		def.Subscribers[i] = addr.Address
+	}
+	return nil
+}
+
+// nextRun returns when the report is due after now: on Monday for the weekly
+// reports and on the first day of the month for the monthly ones.
+func nextRun(f models.ReportFrequency, now time.Time) time.Time {
+	now = now.UTC()
+	if f == models.ReportWeekly {
+		next := time.Date(now.Year(), now.Month(), now.Day(), reportHour, 0, 0, 0, time.UTC)
+		next = next.AddDate(0, 0, (int(time.Monday)-int(next.Weekday())+7)%7)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+
+	next := time.Date(now.Year(), now.Month(), 1, reportHour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 1, 0)
+	}
+	return next
+}
+
+func organizationSheet(def models.ReportDefinition, orgs []models.OrganizationReport) xlsx.Sheet {
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+
+	s := xlsx.Sheet{
+		Name: def.Name,
+		Header: []string{
+			"Name", "VAT", "Registration number", "Legal form", "Country",
+			"Registered", "Status", "Email", "Telephone", "LEAR", "LEAR email", "Users",
+			"Own projects", "Own ongoing", "Own in monitoring", "Own forfaiting",
+			"Related projects", "Related ongoing", "Related in monitoring", "Related forfaiting",
+		},
+	}
+	for _, o := range orgs {
+		if !sameCountry(def.Country, o.Country) {
+			continue
+		}
+		s.Rows = append(s.Rows, []interface{}{
+			o.Name, o.VAT, o.RegistrationNumber, o.LegalForm.String(), o.Country.String(),
+			o.Registered, o.Valid.String(), o.Email, o.Telephone, o.LearName, o.LearEmail, o.UsersCount,
+			o.OwnProjects.TotalCount, o.OwnProjects.OngoingCount,
+			o.OwnProjects.MonitoringPhaseCount, o.OwnProjects.ApprovedForfaitingCount,
+			o.RelatedProjects.TotalCount, o.RelatedProjects.OngoingCount,
+			o.RelatedProjects.MonitoringPhaseCount, o.RelatedProjects.ApprovedForfaitingCount,
+		})
+	}
+	return s
+}
+
+// projectSheet lists the projects along with their figures precomputed in
+// the reporting views.
+func projectSheet(def models.ReportDefinition, prjs []models.Project, sums []models.ProjectReportSummary) xlsx.Sheet {
+	byID := make(map[uuid.UUID]models.ProjectReportSummary, len(sums))
+	for _, s := range sums {
+		byID[s.ProjectID] = s
+	}
+
+	s := xlsx.Sheet{
+		Name: def.Name,
+		Header: []string{
+			"Name", "Owner", "Address", "Country", "Status", "Milestone", "Building type", "Flats", "Area",
+			"Guaranteed savings", "Construction from", "Construction to", "In monitoring", "Forfaiting approved", "Created",
+		},
+	}
+	for _, p := range prjs {
+		if !sameCountry(def.Country, p.Country) ||
+			def.ProjectStatus != nil && *def.ProjectStatus != p.Status ||
+			def.BuildingType != nil && *def.BuildingType != p.AssetSnapshot.BuildingType {
+			continue
+		}
+		sum := byID[p.ID]
+		s.Rows = append(s.Rows, []interface{}{
+			p.Name, sum.OwnerName, sum.AssetAddress, p.Country.String(), p.Status.String(), string(p.Milestone),
+			p.AssetSnapshot.BuildingType.String(), p.AssetSnapshot.Flats, p.AssetSnapshot.Area,
+			p.GuaranteedSavings, p.ConstructionFrom, p.ConstructionTo, sum.Monitoring, sum.ForfaitingApproved, p.CreatedAt,
+		})
+	}
+	return s
+}
+
+func assetSheet(def models.ReportDefinition, assets []models.Asset) xlsx.Sheet {
+	s := xlsx.Sheet{
+		Name: def.Name,
+		Header: []string{
+			"Address", "Cadastre", "Country", "Building type", "Area", "Heated area",
+			"Flats", "Floors", "Status", "Created",
+		},
+	}
+	for _, a := range assets {
+		if !sameCountry(def.Country, a.Country) ||
+			def.BuildingType != nil && *def.BuildingType != a.BuildingType {
+			continue
+		}
+		s.Rows = append(s.Rows, []interface{}{
+			a.Address, a.Cadastre, a.Country.String(), a.BuildingType.String(), a.Area, a.HeatedArea,
+			a.Flats, a.Floors, a.Valid.String(), a.CreatedAt,
+		})
+	}
+	return s
+}
+
+// sameCountry reports whether the record of the country is listed by a
+// report of the filter, if any.
+func sameCountry(filter, country models.Country) bool {
+	return filter == "" || strings.EqualFold(string(filter), string(country))
+}
+
+func renderReportPDF(ctx context.Context, w *bytes.Buffer, def models.ReportDefinition, run models.ReportRun, sheet xlsx.Sheet) error {
+	var tex bytes.Buffer
+	err := reportTeX.Execute(&tex, struct {
+		Definition models.ReportDefinition
+		Date       string
+		Sheet      xlsx.Sheet
+		Width      float64
+	}{def, run.CreatedAt.UTC().Format("2006-01-02"), sheet, 0.95 / float64(len(sheet.Header))})
+	if err != nil {
+		return err
+	}
+
+	pdf, err := contract.RenderPDF(ctx, &tex)
+	if err != nil {
+		return err
+	}
+	defer pdf.Close()
+
+	b, err := ioutil.ReadAll(pdf)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// reportCell formats the cell of a report for TeX.
+func reportCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format("2006-01-02")
+	}
+	return contract.TexEscape(fmt.Sprint(v))
+}
+
+var reportTeX = texDocument("report", "10pt", "landscape,margin=1cm", template.FuncMap{
+	"cell": reportCell,
+}, `
+\section*{<< tex .Definition.Name >>}
+Report of the << tex (print .Definition.Kind) >> as of << tex .Date >>
+<<- with .Definition.Country >>, << tex .String >><< end >>.
+
+\scriptsize
+\begin{longtable}{<< range .Sheet.Header >>p{<< printf "%.3f" $.Width >>\linewidth}<< end >>}
+<<- range $i, $h := .Sheet.Header >><< if $i >> & << end >>\textbf{<< tex $h >>}<< end >> \\
+\hline
+\endhead
+<<- range .Sheet.Rows >>
+<< range $i, $c := . >><< if $i >> & << end >><< cell $c >><< end >> \\
+<<- end >>
+\end{longtable}
+`)
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/xlsx"
+
+	"github.com/google/uuid"
+)
+
+func TestNextRun(t *testing.T) {
+	cases := []struct {
+		freq models.ReportFrequency
+		now  time.Time
+		want time.Time
+	}{
+		// Wednesday
+		{models.ReportWeekly, time.Date(2020, 10, 14, 12, 0, 0, 0, time.UTC), time.Date(2020, 10, 19, 6, 0, 0, 0, time.UTC)},
+		// Monday before and after the hour
+		{models.ReportWeekly, time.Date(2020, 10, 19, 5, 0, 0, 0, time.UTC), time.Date(2020, 10, 19, 6, 0, 0, 0, time.UTC)},
+		{models.ReportWeekly, time.Date(2020, 10, 19, 6, 0, 0, 0, time.UTC), time.Date(2020, 10, 26, 6, 0, 0, 0, time.UTC)},
+		// Sunday
+		{models.ReportWeekly, time.Date(2020, 10, 25, 23, 0, 0, 0, time.UTC), time.Date(2020, 10, 26, 6, 0, 0, 0, time.UTC)},
+		{models.ReportMonthly, time.Date(2020, 10, 14, 12, 0, 0, 0, time.UTC), time.Date(2020, 11, 1, 6, 0, 0, 0, time.UTC)},
+		{models.ReportMonthly, time.Date(2020, 12, 1, 7, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 6, 0, 0, 0, time.UTC)},
+		{models.ReportMonthly, time.Date(2020, 11, 1, 5, 0, 0, 0, time.UTC), time.Date(2020, 11, 1, 6, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		if got := nextRun(c.freq, c.now); !got.Equal(c.want) {
+			t.Errorf("%s at %v: expected %v, got %v", c.freq, c.now, c.want, got)
+		}
+	}
+}
+
+func TestValidReport(t *testing.T) {
+	status := models.ProjectStatusInProgress
+	building := models.BuildingType103
+	valid := func() models.ReportDefinition {
+		return models.ReportDefinition{
+			Name:        " Monthly projects ",
+			Kind:        models.ReportProjects,
+			Format:      models.ReportXLSX,
+			Frequency:   models.ReportMonthly,
+			Country:     models.CountryLatvia,
+			Subscribers: []string{"Agency <reports@agency.lv>"},
+		}
+	}
+
+	def := valid()
+	if err := validReport(&def); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if def.Name != "Monthly projects" || def.Subscribers[0] != "reports@agency.lv" {
+		t.Errorf("Expected the name and subscribers cleaned up, got %q %v", def.Name, def.Subscribers)
+	}
+
+	cases := map[string]func(*models.ReportDefinition){
+		"empty name":        func(d *models.ReportDefinition) { d.Name = " " },
+		"kind":              func(d *models.ReportDefinition) { d.Kind = "meetings" },
+		"format":            func(d *models.ReportDefinition) { d.Format = "csv" },
+		"frequency":         func(d *models.ReportDefinition) { d.Frequency = "daily" },
+		"country":           func(d *models.ReportDefinition) { d.Country = "Atlantis" },
+		"subscriber":        func(d *models.ReportDefinition) { d.Subscribers = []string{"nobody"} },
+		"asset status":      func(d *models.ReportDefinition) { d.Kind, d.ProjectStatus = models.ReportAssets, &status },
+		"org building type": func(d *models.ReportDefinition) { d.Kind, d.BuildingType = models.ReportOrganizations, &building },
+	}
+	for name, change := range cases {
+		def := valid()
+		change(&def)
+		if err := validReport(&def); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestProjectSheet(t *testing.T) {
+	status := models.ProjectStatusInProgress
+	building := models.BuildingType103
+	match := uuid.New()
+	prjs := []models.Project{
+		{Value: models.Value{ID: match}, Name: "Match", Country: models.CountryLatvia, Status: status, AssetSnapshot: models.AssetSnapshot{BuildingType: building, Flats: 40}},
+		{Name: "Other country", Country: models.CountryBulgaria, Status: status, AssetSnapshot: models.AssetSnapshot{BuildingType: building}},
+		{Name: "Other status", Country: models.CountryLatvia, Status: models.ProjectStatusPlanning, AssetSnapshot: models.AssetSnapshot{BuildingType: building}},
+		{Name: "Other building", Country: models.CountryLatvia, Status: status, AssetSnapshot: models.AssetSnapshot{BuildingType: models.BuildingType104}},
+	}
+
+	sums := []models.ProjectReportSummary{{ProjectID: match, OwnerName: "ESCO", Monitoring: true}}
+	s := projectSheet(models.ReportDefinition{Name: "Projects", Country: "latvia", ProjectStatus: &status, BuildingType: &building}, prjs, sums)
+	if len(s.Rows) != 1 || s.Rows[0][0] != "Match" {
+		t.Fatalf("Expected only the matching project, got %v", s.Rows)
+	}
+	if len(s.Rows[0]) != len(s.Header) {
+		t.Errorf("Expected %d cells, got %d", len(s.Header), len(s.Rows[0]))
+	}
+	if s.Rows[0][1] != "ESCO" || s.Rows[0][4] != "in progress" || s.Rows[0][6] != "103" || s.Rows[0][7] != 40 || s.Rows[0][12] != true {
+		t.Errorf("Unexpected row %v", s.Rows[0])
+	}
+
+	if s := projectSheet(models.ReportDefinition{}, prjs, nil); len(s.Rows) != len(prjs) {
+		t.Errorf("Expected all projects without filters, got %d", len(s.Rows))
+	}
+}
+
+func TestOrganizationSheet(t *testing.T) {
+	orgs := []models.OrganizationReport{
+		{Name: "Zeta", Country: models.CountryLatvia, OwnProjects: models.OrganizationProjectsReport{TotalCount: 3}},
+		{Name: "Alpha", Country: models.CountryLatvia},
+		{Name: "Beta", Country: models.CountryAustria},
+	}
+
+	s := organizationSheet(models.ReportDefinition{Country: models.CountryLatvia}, orgs)
+	if len(s.Rows) != 2 || s.Rows[0][0] != "Alpha" || s.Rows[1][0] != "Zeta" {
+		t.Fatalf("Expected the Latvian organizations by name, got %v", s.Rows)
+	}
+	if len(s.Rows[1]) != len(s.Header) || s.Rows[1][12] != 3 {
+		t.Errorf("Unexpected row %v", s.Rows[1])
+	}
+}
+
+func TestReportTeX(t *testing.T) {
+	var tex bytes.Buffer
+	err := reportTeX.Execute(&tex, struct {
+		Definition models.ReportDefinition
+		Date       string
+		Sheet      xlsx.Sheet
+		Width      float64
+	}{
+		models.ReportDefinition{Name: "Assets & co", Kind: models.ReportAssets, Country: models.CountryLatvia},
+		"2020-10-19",
+		xlsx.Sheet{Header: []string{"Address", "Area"}, Rows: [][]interface{}{{"Main_st 1", 12.5}}},
+		0.475,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`\section*{Assets \& co}`,
+		`as of 2020-10-19, Latvia.`,
+		`\begin{longtable}{p{0.475\linewidth}p{0.475\linewidth}}`,
+		`\textbf{Address} & \textbf{Area} \\`,
+		`Main\_st 1 & 12.50 \\`,
+	} {
+		if !strings.Contains(tex.String(), want) {
+			t.Errorf("Expected %s in\n%s", want, tex.String())
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+)
+
+// reportingMaxAge is how long a reporting view is kept before it is
+// refreshed even if it is not marked stale, e.g. to catch up with changes
+// missed by the triggers.
+const reportingMaxAge = 24 * time.Hour
+
+// Reporting keeps the materialized views precomputing the figures of the
+// reports fresh.
+type Reporting struct {
+	st stores.Store
+}
+
+func NewReporting(env *services.Env) *Reporting {
+	return &Reporting{st: env.ProjectStore}
+}
+
+// Freshness returns when each reporting view was refreshed and since when it
+// is stale, if it is.
+func (r *Reporting) Freshness(ctx context.Context) ([]models.ReportingView, error) {
+	if !services.FromContext(ctx).Authorized() {
+		return nil, ErrUnauthorized
+	}
+	return r.views()
+}
+
+// Refresh refreshes all the reporting views now.
+func (r *Reporting) Refresh(ctx context.Context) ([]models.ReportingView, error) {
+	if !Can(ctx, RefreshReporting, uuid.Nil, "") {
+		return nil, ErrUnauthorized
+	}
+
+	views, err := r.views()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range views {
+		if err := r.refresh(v.View); err != nil {
+			return nil, err
+		}
+	}
+	return r.views()
+}
+
+// RefreshStale refreshes the reporting views marked stale or refreshed
+// longer than reportingMaxAge before now and returns how many were.
+func (r *Reporting) RefreshStale(now time.Time) (int, error) {
+	views, err := r.views()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, v := range views {
+		if !refreshDue(v, now) {
+			continue
+		}
+		if err := r.refresh(v.View); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (r *Reporting) views() ([]models.ReportingView, error) {
+	var views []models.ReportingView
+	return views, r.st.DB().Order("view").Find(&views).Error
+}
+
+// refresh refreshes the view without blocking the reports reading it. The
+// view stays stale if it was changed after the refresh started, as told by
+// the clock of the database the triggers marking the changes use too.
+func (r *Reporting) refresh(view string) error {
+	var start struct{ At time.Time }
+	if err := r.st.DB().Raw("SELECT clock_timestamp() AS at").Scan(&start).Error; err != nil {
+		return err
+	}
+
+	// the view names come from reporting_refreshes, not from the users
+	if err := r.st.DB().Exec(fmt.Sprintf("REFRESH MATERIALIZED VIEW CONCURRENTLY %q", view)).Error; err != nil {
+		return fmt.Errorf("failed to refresh %s: %w", view, err)
+	}
+
+	return r.st.DB().Exec(`UPDATE reporting_refreshes
+		SET refreshed_at = ?, stale_since = CASE WHEN changed_at <= ? THEN NULL ELSE stale_since END
+		WHERE view = ?`, start.At, start.At, view).Error
+}
+
+// refreshDue reports whether the view is to be refreshed at now.
+func refreshDue(v models.ReportingView, now time.Time) bool {
+	return v.StaleSince != nil || now.Sub(v.RefreshedAt) >= reportingMaxAge
+}
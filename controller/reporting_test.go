@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+func TestRefreshDue(t *testing.T) {
+	now := time.Date(2020, 10, 19, 12, 0, 0, 0, time.UTC)
+	stale := now.Add(-time.Minute)
+	cases := []struct {
+		name string
+		view models.ReportingView
+		want bool
+	}{
+		{"fresh", models.ReportingView{RefreshedAt: now.Add(-time.Hour)}, false},
+		{"stale", models.ReportingView{RefreshedAt: now.Add(-time.Hour), StaleSince: &stale}, true},
+		{"old", models.ReportingView{RefreshedAt: now.Add(-reportingMaxAge)}, true},
+	}
+	for _, c := range cases {
+		if got := refreshDue(c.view, now); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// DefaultReviewChain is the review chain of the forfaiting applications of
+// the countries without one of their own: the financial, technical and
+// guidelines reviews in parallel, followed by the executive one.
+var DefaultReviewChain = []models.FAReviewStep{
+	{Stage: 1, Type: models.FAReviewTypeFinancial},
+	{Stage: 1, Type: models.FAReviewTypeTechnical},
+	{Stage: 1, Type: models.FAReviewTypeGuidelines},
+	{Stage: 2, Type: models.FAReviewTypeExecutive},
+}
+
+// ReviewChain returns the steps of the review chain of the forfaiting
+// applications of the country.
+func (f *ForfaitingAgreement) ReviewChain(ctx context.Context, country models.Country) ([]models.FAReviewStep, error) {
+	if !Can(ctx, ReviewFA|ManageReviewChains, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+	return f.chain(country)
+}
+
+// SetReviewChain replaces the review chain of the forfaiting applications of
+// the country. The applications already under review keep their chain. No
+// steps restore the default chain.
+func (f *ForfaitingAgreement) SetReviewChain(ctx context.Context, country models.Country, steps []models.FAReviewStep) ([]models.FAReviewStep, error) {
+	if err := country.Valid(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+	if !Can(ctx, ManageReviewChains, uuid.Nil, country) {
+		return nil, ErrUnauthorized
+	}
+	if len(steps) > 0 {
+		if err := validChain(steps); err != nil {
+			return nil, err
+		}
+	}
+
+	// start transaction block
+	tx := f.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("country = ?", country).Delete(&models.FAReviewStep{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for i := range steps {
+		steps[i].ID = uuid.Nil
+		steps[i].Country = country
+		if err := tx.Create(&steps[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	// end transaction block
+
+	return f.chain(country)
+}
+
+// ReviewHistory returns the decisions taken on the review chain of the
+// forfaiting application, oldest first.
+func (f *ForfaitingAgreement) ReviewHistory(ctx context.Context, id uuid.UUID) ([]models.FAReviewDecision, error) {
+	fa, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.FAReviewDecision
+	return result, f.st.DB().
+		Where("forfaiting_application_id = ?", fa.ID).
+		Order("created_at").
+		Find(&result).Error
+}
+
+// chain returns the review chain of the country, or the default one.
+func (f *ForfaitingAgreement) chain(country models.Country) ([]models.FAReviewStep, error) {
+	var steps []models.FAReviewStep
+	err := f.st.DB().
+		Where("country = ?", country).
+		Order("stage, type").
+		Find(&steps).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		steps = make([]models.FAReviewStep, len(DefaultReviewChain))
+		copy(steps, DefaultReviewChain)
+		for i := range steps {
+			steps[i].Country = country
+		}
+	}
+	return steps, nil
+}
+
+// validChain checks that the steps make up a review chain: each review type
+// at most once, positive stages and SLAs, known roles and rejections sending
+// back to a step of the same or an earlier stage.
+func validChain(steps []models.FAReviewStep) error {
+	stages := make(map[models.FAReviewType]int, len(steps))
+	for _, s := range steps {
+		if s.Type < models.FAReviewTypeFinancial || s.Type > models.FAReviewTypeExecutive {
+			return fmt.Errorf("%w: unknown review type %d", ErrBadInput, s.Type)
+		}
+		if _, ok := stages[s.Type]; ok {
+			return fmt.Errorf("%w: review type %d is in the chain twice", ErrBadInput, s.Type)
+		}
+		if s.Stage < 1 {
+			return fmt.Errorf("%w: stages start at 1", ErrBadInput)
+		}
+		if s.SLADays < 0 {
+			return fmt.Errorf("%w: negative SLA", ErrBadInput)
+		}
+		for _, r := range s.Roles {
+			if roleBit(r) == 0 {
+				return fmt.Errorf("%w: unknown role %q", ErrBadInput, r)
+			}
+		}
+		stages[s.Type] = s.Stage
+	}
+
+	for _, s := range steps {
+		if s.RejectTo == nil {
+			continue
+		}
+		stage, ok := stages[*s.RejectTo]
+		if !ok || stage > s.Stage {
+			return fmt.Errorf("%w: step %d may only send back to a step of its stage or an earlier one",
+				ErrBadInput, s.Type)
+		}
+	}
+	return nil
+}
+
+// newReviews returns the reviews of a forfaiting application going through
+// the chain, with its first stage open.
+func newReviews(steps []models.FAReviewStep, now time.Time) []models.FAReview {
+	reviews := make([]models.FAReview, len(steps))
+	for i, s := range steps {
+		reviews[i] = models.FAReview{
+			Type:     s.Type,
+			Stage:    s.Stage,
+			Status:   models.FAReviewPending,
+			Roles:    s.Roles,
+			SLADays:  s.SLADays,
+			RejectTo: s.RejectTo,
+		}
+	}
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].Stage < reviews[j].Stage })
+
+	if len(reviews) > 0 {
+		open(reviews, reviews[0].Stage, now)
+	}
+	return reviews
+}
+
+// decide applies the decision on the i-th review of the chain. Approving the
+// last step of a stage opens the next one. Rejecting sends the application
+// back to the step given, the one configured for the step, or the step
+// itself, and everything from there is reviewed again.
+func decide(reviews []models.FAReview, i int, approved bool, sendBackTo *models.FAReviewType, now time.Time) error {
+	r := &reviews[i]
+	switch {
+	case r.Status == models.FAReviewPending:
+		return fmt.Errorf("%w: the review waits for the previous steps of the chain", ErrBadInput)
+	case r.Status == models.FAReviewApproved && approved:
+		// only the comment changes
+		return nil
+	case r.Status == models.FAReviewApproved && started(reviews, r.Stage+1):
+		return fmt.Errorf("%w: the chain moved past the review, a later step must send it back", ErrBadInput)
+	}
+
+	if approved {
+		r.Approved, r.Status = true, models.FAReviewApproved
+		if next, ok := nextStage(reviews, r.Stage); ok && stageApproved(reviews, r.Stage) {
+			open(reviews, next, now)
+		}
+		return nil
+	}
+
+	to := sendBackTo
+	if to == nil {
+		to = r.RejectTo
+	}
+	if to == nil || *to == r.Type {
+		reopen(r, now)
+		return nil
+	}
+
+	j := -1
+	for k := range reviews {
+		if reviews[k].Type == *to {
+			j = k
+		}
+	}
+	if j < 0 || reviews[j].Stage > r.Stage {
+		return fmt.Errorf("%w: may only send back to a step of the same or an earlier stage", ErrBadInput)
+	}
+
+	for k := range reviews {
+		switch {
+		case k == j:
+			reopen(&reviews[k], now)
+		case reviews[k].Stage > reviews[j].Stage:
+			reviews[k].Approved, reviews[k].Status = false, models.FAReviewPending
+			reviews[k].OpenedAt, reviews[k].DueAt = nil, nil
+		case k == i:
+			reopen(&reviews[k], now)
+		}
+	}
+	return nil
+}
+
+// open opens the pending reviews of the stage.
+func open(reviews []models.FAReview, stage int, now time.Time) {
+	for k := range reviews {
+		if reviews[k].Stage == stage && reviews[k].Status == models.FAReviewPending {
+			reopen(&reviews[k], now)
+		}
+	}
+}
+
+func reopen(r *models.FAReview, now time.Time) {
+	r.Approved, r.Status = false, models.FAReviewOpen
+	r.OpenedAt, r.DueAt = &now, nil
+	if r.SLADays > 0 {
+		due := now.AddDate(0, 0, r.SLADays)
+		r.DueAt = &due
+	}
+}
+
+// started reports whether any review from the stage on is no longer pending.
+func started(reviews []models.FAReview, stage int) bool {
+	for _, r := range reviews {
+		if r.Stage >= stage && r.Status != models.FAReviewPending {
+			return true
+		}
+	}
+	return false
+}
+
+func stageApproved(reviews []models.FAReview, stage int) bool {
+	for _, r := range reviews {
+		if r.Stage == stage && !r.Approved {
+			return false
+		}
+	}
+	return true
+}
+
+func nextStage(reviews []models.FAReview, stage int) (int, bool) {
+	next, ok := 0, false
+	for _, r := range reviews {
+		if r.Stage > stage && (!ok || r.Stage < next) {
+			next, ok = r.Stage, true
+		}
+	}
+	return next, ok
+}
+
+// reviewAction is who may decide on the review: the administrators and the
+// roles of the step, or anyone allowed to review forfaiting applications if
+// the step has none.
+func reviewAction(r models.FAReview) Action {
+	if len(r.Roles) == 0 {
+		return ReviewFA
+	}
+	a := Action(superuser | pfm | anm)
+	for _, role := range r.Roles {
+		a |= Action(roleBit(role))
+	}
+	return a
+}
+
+// Overdue reports whether the review is open past its due date.
+func Overdue(r models.FAReview, now time.Time) bool {
+	return r.Status == models.FAReviewOpen && r.DueAt != nil && now.After(*r.DueAt)
+}
+
+// sameState reports whether the review is in the same state after a
+// decision.
+func sameState(a, b models.FAReview) bool {
+	return a.Approved == b.Approved && a.Status == b.Status && a.OpenedAt == b.OpenedAt
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+func TestValidChain(t *testing.T) {
+	financial := models.FAReviewTypeFinancial
+	executive := models.FAReviewTypeExecutive
+
+	cases := []struct {
+		name  string
+		steps []models.FAReviewStep
+		err   error
+	}{
+		{
+			name:  "default",
+			steps: DefaultReviewChain,
+		},
+		{
+			name: "sequential",
+			steps: []models.FAReviewStep{
+				{Stage: 1, Type: models.FAReviewTypeTechnical, Roles: []string{"portfolio_director"}},
+				{Stage: 2, Type: models.FAReviewTypeFinancial, SLADays: 5},
+				{Stage: 3, Type: models.FAReviewTypeExecutive, Roles: []string{"fund_manager"}, RejectTo: &financial},
+			},
+		},
+		{
+			name: "twice",
+			steps: []models.FAReviewStep{
+				{Stage: 1, Type: models.FAReviewTypeFinancial},
+				{Stage: 2, Type: models.FAReviewTypeFinancial},
+			},
+			err: ErrBadInput,
+		},
+		{
+			name:  "stage",
+			steps: []models.FAReviewStep{{Stage: 0, Type: models.FAReviewTypeFinancial}},
+			err:   ErrBadInput,
+		},
+		{
+			name:  "role",
+			steps: []models.FAReviewStep{{Stage: 1, Type: models.FAReviewTypeFinancial, Roles: []string{"boss"}}},
+			err:   ErrBadInput,
+		},
+		{
+			name: "send forward",
+			steps: []models.FAReviewStep{
+				{Stage: 1, Type: models.FAReviewTypeFinancial, RejectTo: &executive},
+				{Stage: 2, Type: models.FAReviewTypeExecutive},
+			},
+			err: ErrBadInput,
+		},
+	}
+	for _, c := range cases {
+		if err := validChain(c.steps); !errors.Is(err, c.err) {
+			t.Errorf("%s: expected error %v, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	var (
+		now       = time.Date(2020, time.October, 19, 0, 0, 0, 0, time.UTC)
+		financial = models.FAReviewTypeFinancial
+		technical = models.FAReviewTypeTechnical
+	)
+	steps := []models.FAReviewStep{
+		{Stage: 2, Type: models.FAReviewTypeExecutive, SLADays: 3},
+		{Stage: 1, Type: models.FAReviewTypeFinancial},
+		{Stage: 1, Type: models.FAReviewTypeTechnical},
+	}
+	reviews := newReviews(steps, now)
+
+	index := func(typ models.FAReviewType) int {
+		for i, r := range reviews {
+			if r.Type == typ {
+				return i
+			}
+		}
+		t.Fatalf("no review of type %d", typ)
+		return -1
+	}
+	status := func(typ models.FAReviewType) models.FAReviewStatus {
+		return reviews[index(typ)].Status
+	}
+	approved := func() bool {
+		return models.ForfaitingApplication{Reviews: reviews}.Approved()
+	}
+
+	if status(financial) != models.FAReviewOpen || status(models.FAReviewTypeExecutive) != models.FAReviewPending {
+		t.Fatalf("Expected the first stage open and the executive review pending, got %v", reviews)
+	}
+	if err := decide(reviews, index(models.FAReviewTypeExecutive), true, nil, now); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected the executive review locked, got %v", err)
+	}
+
+	if err := decide(reviews, index(financial), true, nil, now); err != nil {
+		t.Fatal(err)
+	}
+	if status(models.FAReviewTypeExecutive) != models.FAReviewPending {
+		t.Errorf("Expected the executive review locked until the technical one is approved")
+	}
+	if err := decide(reviews, index(financial), true, nil, now); err != nil {
+		t.Errorf("Expected to comment on the approved review again, got %v", err)
+	}
+
+	if err := decide(reviews, index(technical), true, nil, now); err != nil {
+		t.Fatal(err)
+	}
+	exec := reviews[index(models.FAReviewTypeExecutive)]
+	if exec.Status != models.FAReviewOpen || exec.DueAt == nil || !exec.DueAt.Equal(now.AddDate(0, 0, 3)) {
+		t.Errorf("Expected the executive review open and due in 3 days, got %v due %v", exec.Status, exec.DueAt)
+	}
+	if Overdue(exec, now) || !Overdue(exec, now.AddDate(0, 0, 4)) {
+		t.Errorf("Expected the executive review overdue after 3 days only")
+	}
+	if err := decide(reviews, index(financial), false, nil, now); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected the approved financial review locked once the chain moved on, got %v", err)
+	}
+
+	if err := decide(reviews, index(models.FAReviewTypeExecutive), false, &financial, now); err != nil {
+		t.Fatal(err)
+	}
+	if status(financial) != models.FAReviewOpen || status(technical) != models.FAReviewApproved ||
+		status(models.FAReviewTypeExecutive) != models.FAReviewPending {
+		t.Errorf("Expected only the financial review sent back, got %v", reviews)
+	}
+
+	if err := decide(reviews, index(financial), true, nil, now); err != nil {
+		t.Fatal(err)
+	}
+	if approved() {
+		t.Errorf("Expected the application not approved before the executive review")
+	}
+	if err := decide(reviews, index(models.FAReviewTypeExecutive), true, nil, now); err != nil {
+		t.Fatal(err)
+	}
+	if !approved() {
+		t.Errorf("Expected the application approved, got %v", reviews)
+	}
+}
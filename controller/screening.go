@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/bankaccount"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// ScreenBankAccount checks again the bank account of the project of the work
+// phase and records the result on its latest bank account review.
+func (wp *WorkPhase) ScreenBankAccount(ctx context.Context, wpID uuid.UUID) (*models.WPReview, error) {
+	doc, err := wp.store.Get(ctx, wpID)
+	if err != nil {
+		return nil, err
+	}
+	pdoc, err := wp.store.FromKind("project").Get(ctx, doc.Data.(*models.WorkPhase).Project)
+	if err != nil {
+		return nil, err
+	}
+	p := pdoc.Data.(*models.Project)
+	if !wp.canReview(ctx, *p, models.WPReviewTypeBankAccount) {
+		return nil, ErrUnauthorized
+	}
+
+	var r models.WPReview
+	err = wp.store.DB().
+		Where("wp_id = ? AND type = ?", wpID, models.WPReviewTypeBankAccount).
+		Order("created_at DESC").
+		First(&r).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wp.screenBankAccount(p.ID, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, wp.store.DB().Model(&r).UpdateColumns(map[string]interface{}{
+		"screened_at":       r.ScreenedAt,
+		"screening_errors":  r.ScreeningErrors,
+		"screening_matches": r.ScreeningMatches,
+	}).Error
+}
+
+// screenBankAccount validates the IBAN and the SWIFT of the bank account of
+// the forfaiting application of the project and screens its beneficiary
+// against the sanctions list, setting the result on the review.
+func (wp *WorkPhase) screenBankAccount(projectID uuid.UUID, r *models.WPReview) error {
+	var ba models.BankAccount
+	err := wp.store.DB().
+		Joins("JOIN forfaiting_applications fa ON fa.id = bank_accounts.fa_id").
+		Where("fa.project_id = ? AND fa.deleted_at IS NULL", projectID).
+		Order("bank_accounts.created_at DESC").
+		First(&ba).Error
+	switch {
+	case gorm.IsRecordNotFoundError(err):
+		r.ScreeningErrors, r.ScreeningMatches = pq.StringArray{"no bank account"}, nil
+	case err != nil:
+		return err
+	default:
+		r.ScreeningErrors, r.ScreeningMatches = screen(ba, wp.screener)
+	}
+
+	now := time.Now()
+	r.ScreenedAt = &now
+	return nil
+}
+
+// approvable returns why the bank account review may not be approved, if
+// so. A beneficiary on the sanctions list, which may be a namesake, needs a
+// comment of the reviewer.
+func approvable(r models.WPReview) error {
+	if len(r.ScreeningErrors) > 0 {
+		return fmt.Errorf("%w: invalid bank account: %s", ErrBadInput, strings.Join(r.ScreeningErrors, "; "))
+	}
+	if len(r.ScreeningMatches) > 0 && strings.TrimSpace(r.Comment) == "" {
+		return fmt.Errorf("%w: beneficiary matches the sanctions list (%s), explain the approval in a comment",
+			ErrBadInput, strings.Join(r.ScreeningMatches, "; "))
+	}
+	return nil
+}
+
+// screen returns the problems with the IBAN and the SWIFT of the bank
+// account and the sanctions list entries its beneficiary matches.
+func screen(ba models.BankAccount, s bankaccount.Screener) (errs, matches pq.StringArray) {
+	for _, err := range bankaccount.Validate(ba.IBAN, ba.SWIFT) {
+		errs = append(errs, err.Error())
+	}
+	if strings.TrimSpace(ba.BeneficiaryName) == "" {
+		errs = append(errs, "no beneficiary name")
+	}
+
+	if s != nil {
+		for _, m := range s.Screen(ba.BeneficiaryName) {
+			matches = append(matches, m.String())
+		}
+	}
+	return errs, matches
+}
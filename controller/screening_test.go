@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/bankaccount"
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+func TestScreen(t *testing.T) {
+	list, err := bankaccount.ParseText(strings.NewReader("Acme Holdings Ltd\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		account models.BankAccount
+		errors  int
+		matches int
+	}{
+		{
+			name:    "valid",
+			account: models.BankAccount{BeneficiaryName: "Renesco SIA", IBAN: "LV80 BANK 0000 4351 9500 1", SWIFT: "HABALV22"},
+		},
+		{
+			name:    "mistyped iban",
+			account: models.BankAccount{BeneficiaryName: "Renesco SIA", IBAN: "LV80BANK0000435195010"},
+			errors:  1,
+		},
+		{
+			name:    "no beneficiary and swift of another country",
+			account: models.BankAccount{IBAN: "LV80BANK0000435195001", SWIFT: "DEUTDEFF"},
+			errors:  2,
+		},
+		{
+			name:    "sanctioned",
+			account: models.BankAccount{BeneficiaryName: "ACME Holdings", IBAN: "LV80BANK0000435195001"},
+			matches: 1,
+		},
+	}
+	for _, c := range cases {
+		errs, matches := screen(c.account, list)
+		if len(errs) != c.errors || len(matches) != c.matches {
+			t.Errorf("%s: expected %d errors and %d matches, got %v and %v", c.name, c.errors, c.matches, errs, matches)
+		}
+	}
+
+	if _, matches := screen(cases[3].account, nil); len(matches) != 0 {
+		t.Errorf("Expected no matches without sanctions list, got %v", matches)
+	}
+}
+
+func TestApprovable(t *testing.T) {
+	cases := []struct {
+		name   string
+		review models.WPReview
+		err    error
+	}{
+		{"clear", models.WPReview{}, nil},
+		{"invalid", models.WPReview{ScreeningErrors: []string{"wrong IBAN check digits"}, Comment: "ok"}, ErrBadInput},
+		{"match without comment", models.WPReview{ScreeningMatches: []string{"Acme Holdings Ltd"}}, ErrBadInput},
+		{"match with comment", models.WPReview{ScreeningMatches: []string{"Acme Holdings Ltd"}, Comment: "A namesake."}, nil},
+	}
+	for _, c := range cases {
+		if err := approvable(c.review); !errors.Is(err, c.err) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.err, err)
+		}
+	}
+}
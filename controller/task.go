@@ -0,0 +1,400 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+type Task struct {
+	st         stores.Store
+	notifier   stores.Notifier
+	uploadPath string
+}
+
+func NewTask(env *services.Env) *Task {
+	return &Task{
+		st:         env.TaskStore,
+		notifier:   env.Notifier,
+		uploadPath: env.Paths.Uploads,
+	}
+}
+
+// TaskUpdate holds the fields of a task to be updated. Nil fields are left
+// as they are.
+type TaskUpdate struct {
+	Title       *string
+	Description *string
+	Status      *models.TaskStatus
+	Deadline    *time.Time
+	Milestone   *models.Milestone
+}
+
+// Create adds a new task to its project. The task is open unless other status
+// is given.
+func (t *Task) Create(ctx context.Context, task models.Task) (*models.Document, error) {
+	prj, err := t.project(ctx, task.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canGetProject(ctx, CreateTask, prj.Country, projectIDs(prj)...) {
+		return nil, ErrUnauthorized
+	}
+
+	if task.Assignee != nil && !isProjectMember(prj, *task.Assignee) {
+		return nil, fmt.Errorf("%w: assignee is not a project member", ErrBadInput)
+	}
+	if task.Status == "" {
+		task.Status = models.TaskStatusTodo
+	}
+	if !validTaskStatus(task.Status) {
+		return nil, fmt.Errorf("%w: bad status: %v", ErrBadInput, task.Status)
+	}
+
+	cv := services.FromContext(ctx)
+	task.Author = &cv.User.ID
+	task.Comments = nil
+
+	doc, err := t.st.Create(ctx, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	go t.notifier.Broadcast(ctx, models.UserActionCreate,
+		*cv.User, *doc, "", task.Title, cv.User.ID, nil)
+	if task.Assignee != nil {
+		t.notifyAssignee(ctx, prj, &task)
+	}
+
+	return doc, nil
+}
+
+// Get returns the task along with its comments and attachments.
+func (t *Task) Get(ctx context.Context, id uuid.UUID) (*models.Document, error) {
+	doc, prj, err := t.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canGetProject(ctx, GetTask, prj.Country, projectIDs(prj)...) {
+		return nil, ErrUnauthorized
+	}
+	return doc, nil
+}
+
+// Update changes the non-nil fields of upd in the task.
+func (t *Task) Update(ctx context.Context, id uuid.UUID, upd TaskUpdate) (*models.Document, error) {
+	doc, prj, err := t.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canGetProject(ctx, UpdateTask, prj.Country, projectIDs(prj)...) {
+		return nil, ErrUnauthorized
+	}
+
+	task := doc.Data.(*models.Task)
+	old := task.Status
+
+	if upd.Title != nil {
+		if *upd.Title == "" {
+			return nil, fmt.Errorf("%w: empty title", ErrBadInput)
+		}
+		task.Title = *upd.Title
+	}
+	if upd.Description != nil {
+		task.Description = *upd.Description
+	}
+	if upd.Status != nil {
+		if !validTaskStatus(*upd.Status) {
+			return nil, fmt.Errorf("%w: bad status: %v", ErrBadInput, *upd.Status)
+		}
+		task.Status = *upd.Status
+	}
+	if upd.Deadline != nil {
+		task.Deadline = upd.Deadline
+	}
+	if upd.Milestone != nil {
+		task.Milestone = upd.Milestone
+	}
+
+	// Comments are managed by Comment only.
+	comments := task.Comments
+	task.Comments = nil
+	doc, err = t.st.Update(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	doc.Data.(*models.Task).Comments = comments
+
+	if old != task.Status {
+		cv := services.FromContext(ctx)
+		go t.notifier.Broadcast(ctx, models.UserActionUpdate,
+			*cv.User, *doc, string(old), string(task.Status), cv.User.ID, nil)
+	}
+
+	return doc, nil
+}
+
+// Assign makes the project member responsible for the task. A nil assignee
+// leaves the task unassigned.
+func (t *Task) Assign(ctx context.Context, id uuid.UUID, assignee *uuid.UUID) (*models.Document, error) {
+	doc, prj, err := t.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canGetProject(ctx, UpdateTask, prj.Country, projectIDs(prj)...) {
+		return nil, ErrUnauthorized
+	}
+
+	if assignee != nil && !isProjectMember(prj, *assignee) {
+		return nil, fmt.Errorf("%w: assignee is not a project member", ErrBadInput)
+	}
+
+	task := doc.Data.(*models.Task)
+	err = t.st.DB().Model(task).UpdateColumn("assignee", assignee).Error
+	if err != nil {
+		return nil, err
+	}
+	task.Assignee = assignee
+
+	if assignee != nil {
+		t.notifyAssignee(ctx, prj, task)
+	}
+
+	return doc, nil
+}
+
+// Delete moves the task to the recycle bin.
+func (t *Task) Delete(ctx context.Context, id uuid.UUID) error {
+	_, prj, err := t.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !canGetProject(ctx, DeleteTask, prj.Country, projectIDs(prj)...) {
+		return ErrUnauthorized
+	}
+
+	return stores.Trash(t.st.DB(), "task", id)
+}
+
+// Comment adds a comment to the task and notifies its author and assignee.
+func (t *Task) Comment(ctx context.Context, id uuid.UUID, content string) (*models.Document, error) {
+	doc, prj, err := t.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canGetProject(ctx, CommentTask, prj.Country, projectIDs(prj)...) {
+		return nil, ErrUnauthorized
+	}
+
+	if content == "" {
+		return nil, fmt.Errorf("%w: empty comment", ErrBadInput)
+	}
+
+	cv := services.FromContext(ctx)
+	comment := models.TaskComment{
+		TaskID:  id,
+		UserID:  cv.User.ID,
+		Content: content,
+	}
+	if err := t.st.DB().Create(&comment).Error; err != nil {
+		return nil, err
+	}
+
+	task := doc.Data.(*models.Task)
+	task.Comments = append(task.Comments, comment)
+
+	var recs []uuid.UUID
+	for _, r := range []*uuid.UUID{task.Author, task.Assignee} {
+		if r != nil && *r != cv.User.ID {
+			recs = append(recs, *r)
+		}
+	}
+	notifyAll(ctx, t.notifier, recs, models.Notification{
+		Action:     models.UserActionComment,
+		UserID:     cv.User.ID,
+		UserKey:    cv.User.Name,
+		TargetID:   task.ID,
+		TargetKey:  task.Title,
+		TargetType: models.TaskT,
+		New:        content,
+		Country:    prj.Country,
+	})
+
+	return doc, nil
+}
+
+// ListByProject returns the tasks of the project, optionally only the ones in
+// status or linked to milestone, ordered by deadline.
+func (t *Task) ListByProject(ctx context.Context, pid uuid.UUID, status *models.TaskStatus, milestone *models.Milestone) ([]models.Task, error) {
+	prj, err := t.project(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canGetProject(ctx, GetTask, prj.Country, projectIDs(prj)...) {
+		return nil, ErrUnauthorized
+	}
+
+	q := t.st.DB().Where("project_id = ?", pid)
+	if milestone != nil {
+		q = q.Where("milestone = ?", *milestone)
+	}
+	return t.list(q, status)
+}
+
+// ListByUser returns the tasks assigned to the user, optionally only the ones
+// in status, ordered by deadline. Without user the tasks of the current user
+// are returned.
+func (t *Task) ListByUser(ctx context.Context, uid *uuid.UUID, status *models.TaskStatus) ([]models.Task, error) {
+	cv := services.FromContext(ctx)
+	if !cv.Authorized() {
+		return nil, ErrUnauthorized
+	}
+
+	id := cv.User.ID
+	if uid != nil {
+		id = *uid
+	}
+
+	u := cv.User
+	if id != u.ID && !u.SuperUser && !u.PlatformManager && !u.AdminNwManager {
+		return nil, ErrUnauthorized
+	}
+
+	return t.list(t.st.DB().Where("assignee = ?", id), status)
+}
+
+func (t *Task) list(q *gorm.DB, status *models.TaskStatus) ([]models.Task, error) {
+	if status != nil {
+		q = q.Where("status = ?", *status)
+	}
+
+	var result []models.Task
+	return result, q.Preload("Attachments").
+		Order("deadline ASC NULLS LAST, created_at").
+		Find(&result).Error
+}
+
+func (t *Task) UploadFile(ctx context.Context, id uuid.UUID, form RequestForm) error {
+	doc, prj, err := t.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !canGetProject(ctx, UpdateTask, prj.Country, projectIDs(prj)...) {
+		return ErrUnauthorized
+	}
+
+	return uploadFile(ctx, t.st, t.notifier, form, doc, t.uploadPath)
+}
+
+func (t *Task) GetFile(ctx context.Context, id uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
+	_, prj, err := t.get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !canGetProject(ctx, GetTask, prj.Country, projectIDs(prj)...) {
+		return nil, nil, ErrUnauthorized
+	}
+
+	return getFile(ctx, t.st, id, filename, t.uploadPath, fr)
+}
+
+func (t *Task) DeleteFile(ctx context.Context, id uuid.UUID, filename string) error {
+	doc, prj, err := t.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !canGetProject(ctx, UpdateTask, prj.Country, projectIDs(prj)...) {
+		return ErrUnauthorized
+	}
+
+	return t.st.DeleteAttachment(ctx, doc, filename)
+}
+
+func (t *Task) notifyAssignee(ctx context.Context, prj *models.Project, task *models.Task) {
+	cv := services.FromContext(ctx)
+	if *task.Assignee == cv.User.ID {
+		return
+	}
+
+	n := models.Notification{
+		Action:      models.UserActionAssign,
+		RecipientID: *task.Assignee,
+		UserID:      cv.User.ID,
+		UserKey:     cv.User.Name,
+		TargetID:    task.ID,
+		TargetKey:   task.Title,
+		TargetType:  models.TaskT,
+		New:         task.Assignee.String(),
+		Country:     prj.Country,
+	}
+	go t.notifier.Notify(ctx, &n)
+}
+
+func (t *Task) get(ctx context.Context, id uuid.UUID) (*models.Document, *models.Project, error) {
+	doc, err := t.st.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prj, err := t.project(ctx, doc.Data.(*models.Task).Project)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, prj, nil
+}
+
+func (t *Task) project(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	doc, err := t.st.FromKind("project").Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Data.(*models.Project), nil
+}
+
+// projectIDs returns the IDs the roles of which are checked for actions on
+// the project, i.e. the project and its consortium organizations.
+func projectIDs(prj *models.Project) []uuid.UUID {
+	ids := []uuid.UUID{prj.ID}
+	for _, id := range prj.ConsortiumOrgs {
+		ids = append(ids, uuid.MustParse(id))
+	}
+	return ids
+}
+
+func isProjectMember(prj *models.Project, uid uuid.UUID) bool {
+	for _, r := range prj.ProjectRoles {
+		if r.UserID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+func validTaskStatus(s models.TaskStatus) bool {
+	switch s {
+	case models.TaskStatusTodo,
+		models.TaskStatusInProgress,
+		models.TaskStatusBlocked,
+		models.TaskStatusDone:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+)
+
+func TestTasks(t *testing.T) {
+	e := services.NewTestEnv(t)
+	c := NewTask(e)
+
+	pm := stores.NewTestUser(t, e.UserStore)
+	tama := stores.NewTestUser(t, e.UserStore)
+	random := stores.NewTestUser(t, e.UserStore)
+	prj := stores.NewTestProject(t, e.ProjectStore,
+		stores.TPrjWithPm(pm.ID),
+		stores.TPrjWithRole(map[string][]uuid.UUID{"tama": {tama.ID}}),
+	)
+
+	pmctx := services.NewTestContext(t, e, pm)
+	tamactx := services.NewTestContext(t, e, tama)
+	rctx := services.NewTestContext(t, e, random)
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		task models.Task
+		err  error
+	}{
+		{
+			name: "ok pm",
+			ctx:  pmctx,
+			task: models.Task{Project: prj.ID, Title: "Insulate roof", Assignee: &tama.ID},
+		},
+		{
+			name: "ok task manager",
+			ctx:  tamactx,
+			task: models.Task{Project: prj.ID, Title: "Replace windows"},
+		},
+		{
+			name: "assignee not member",
+			ctx:  pmctx,
+			task: models.Task{Project: prj.ID, Title: "Paint", Assignee: &random.ID},
+			err:  ErrBadInput,
+		},
+		{
+			name: "bad status",
+			ctx:  pmctx,
+			task: models.Task{Project: prj.ID, Title: "Paint", Status: "later"},
+			err:  ErrBadInput,
+		},
+		{
+			name: "fail random guy",
+			ctx:  rctx,
+			task: models.Task{Project: prj.ID, Title: "Paint"},
+			err:  ErrUnauthorized,
+		},
+	}
+
+	var ids []uuid.UUID
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := c.Create(tc.ctx, tc.task)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Got unexpected err; exp: %v, got: %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if s := doc.Data.(*models.Task).Status; s != models.TaskStatusTodo {
+				t.Errorf("Expected new task to be todo, got %v", s)
+			}
+			ids = append(ids, doc.ID)
+		})
+	}
+	if len(ids) != 2 {
+		t.Fatalf("Expected 2 created tasks, got %d", len(ids))
+	}
+
+	done := models.TaskStatusDone
+	if _, err := c.Update(tamactx, ids[1], TaskUpdate{Status: &done}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := c.Update(rctx, ids[1], TaskUpdate{Status: &done}); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+
+	if _, err := c.Comment(pmctx, ids[0], "Mind the chimney"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	doc, err := c.Get(tamactx, ids[0])
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if n := len(doc.Data.(*models.Task).Comments); n != 1 {
+		t.Errorf("Expected 1 comment, got %d", n)
+	}
+
+	list, err := c.ListByProject(pmctx, prj.ID, &done, nil)
+	if err != nil {
+		t.Fatalf("ListByProject: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != ids[1] {
+		t.Errorf("Expected only the done task, got %v", list)
+	}
+	if _, err := c.ListByProject(rctx, prj.ID, nil, nil); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+
+	list, err = c.ListByUser(tamactx, nil, nil)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != ids[0] {
+		t.Errorf("Expected the assigned task, got %v", list)
+	}
+	if _, err := c.ListByUser(rctx, &tama.ID, nil); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+
+	if _, err := c.Assign(pmctx, ids[0], nil); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	list, err = c.ListByUser(tamactx, nil, nil)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("Expected no assigned tasks, got %d", len(list))
+	}
+
+	if err := c.Delete(tamactx, ids[0]); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if err := c.Delete(pmctx, ids[0]); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"text/template"
+
+	"stageai.tech/sunshine/sunshine/contract"
+)
+
+// texDocument parses the body of an A4 LaTeX article typeset with xelatex.
+// The template uses << and >> as delimiters, which don't clash with the
+// braces of LaTeX, and can escape text with tex besides the given funcs.
+// Size is the font size and geometry the page layout of the article.
+func texDocument(name, size, geometry string, funcs template.FuncMap, body string) *template.Template {
+	fm := template.FuncMap{"tex": contract.TexEscape}
+	for k, f := range funcs {
+		fm[k] = f
+	}
+
+	return template.Must(template.New(name).
+		Funcs(fm).
+		Delims("<<", ">>").
+		Parse(`\documentclass[a4paper,` + size + `]{article}
+\usepackage{fontspec}
+\usepackage[` + geometry + `]{geometry}
+\usepackage{longtable}
+\begin{document}
+` + body + `
+\end{document}
+`))
+}
@@ -210,18 +210,19 @@ func (u *User) UploadFile(ctx context.Context, uid uuid.UUID, form RequestForm)
 	return uploadFile(ctx, u.st, u.n, form, udoc, u.uploadPath)
 }
 
-func (u *User) GetFile(ctx context.Context, uid uuid.UUID, filename string) (*models.Attachment, *os.File, error) {
+func (u *User) GetFile(ctx context.Context, uid uuid.UUID, filename string, fr FileRequest) (*models.Attachment, File, error) {
 	doc, err := u.st.Get(ctx, uid)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// special case for becoming a lear - that organization's lear
-	// should be able to see your application
-	att, file, err := getFile(ctx, u.st, uid, filename, u.uploadPath)
+	att, err := u.st.GetAttachment(ctx, doc, filename)
 	if err != nil {
 		return nil, nil, err
 	}
+
+	// special case for becoming a lear - that organization's lear
+	// should be able to see your application
 	if att.UploadType == "lear apply" {
 		cv := services.FromContext(ctx)
 		cmnt := &RequestFormComment{}
@@ -242,7 +243,12 @@ func (u *User) GetFile(ctx context.Context, uid uuid.UUID, filename string) (*mo
 		}
 	}
 
-	return att, file, nil
+	// avatars are shown everywhere, so their views are not logged
+	if filename == avatar {
+		f, err := os.Open(filepath.Join(u.uploadPath, fmt.Sprintf("%s-%s", uid, att.ID)))
+		return att, f, err
+	}
+	return getFile(ctx, u.st, uid, filename, u.uploadPath, fr)
 }
 
 func (u *User) DeleteFile(ctx context.Context, uid uuid.UUID, filename string) error {
@@ -0,0 +1,380 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/geo"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+	"stageai.tech/sunshine/sunshine/weather"
+
+	"github.com/google/uuid"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// maxStationDistance is the maximum distance in kilometers between an asset
+// and the weather station whose temperatures are used for it.
+const maxStationDistance = 50
+
+// temperatureBatch is the count of days inserted by a single statement when
+// importing temperatures.
+const temperatureBatch = 1000
+
+type Weather struct {
+	st        stores.Store
+	validator *validator.Validate
+}
+
+func NewWeather(env *services.Env) *Weather {
+	return &Weather{st: env.WeatherStore, validator: env.Validator}
+}
+
+// DegreeDays holds heating degree days of a period measured by a station.
+type DegreeDays struct {
+	weather.Summary
+
+	Station  models.WeatherStation
+	Distance float64
+}
+
+func (w *Weather) CreateStation(ctx context.Context, ws models.WeatherStation) (*models.WeatherStation, error) {
+	if !Can(ctx, ManageWeatherData, uuid.Nil, ws.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	doc, err := w.st.Create(ctx, &ws)
+	if err != nil {
+		if stores.IsDuplicatedRecord(err) {
+			err = ErrDuplicate
+		}
+		return nil, err
+	}
+	return doc.Data.(*models.WeatherStation), nil
+}
+
+func (w *Weather) ListStations(ctx context.Context, country models.Country) ([]models.WeatherStation, error) {
+	cv := services.FromContext(ctx)
+	if !cv.Authorized() {
+		return nil, ErrUnauthorized
+	}
+
+	var result []models.WeatherStation
+	db := w.st.DB()
+	if country != "" {
+		db = db.Where("country = ?", country)
+	}
+	return result, db.Order("name").Find(&result).Error
+}
+
+// ImportTemperatures reads daily temperatures in CSV format from r and stores
+// them for given station. Already imported days are overwritten. It returns
+// the count of imported days.
+func (w *Weather) ImportTemperatures(ctx context.Context, station uuid.UUID, r io.Reader) (int, error) {
+	doc, err := w.st.Get(ctx, station)
+	if err != nil {
+		return 0, err
+	}
+	ws := doc.Data.(*models.WeatherStation)
+
+	if !Can(ctx, ManageWeatherData, uuid.Nil, ws.Country) {
+		return 0, ErrUnauthorized
+	}
+
+	days, err := weather.ParseCSV(r)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrBadInput, err)
+	}
+
+	tx := w.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, err
+	}
+
+	// a single statement must not update a day twice
+	days = uniqueDays(days)
+	for i := 0; i < len(days); i += temperatureBatch {
+		batch := days[i:]
+		if len(batch) > temperatureBatch {
+			batch = batch[:temperatureBatch]
+		}
+
+		values := make([]string, len(batch))
+		args := make([]interface{}, 0, 3*len(batch))
+		for j, d := range batch {
+			values[j] = "(?, ?, ?)"
+			args = append(args, ws.ID, d.Date, d.Temperature)
+		}
+
+		err := tx.Exec(`INSERT INTO daily_temperatures (station_id, date, temperature)
+			VALUES `+strings.Join(values, ", ")+`
+			ON CONFLICT (station_id, date) DO UPDATE SET temperature = EXCLUDED.temperature`,
+			args...).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	return len(days), tx.Commit().Error
+}
+
+// uniqueDays drops the days of the sorted days which have the same date as
+// the day after them.
+func uniqueDays(days []weather.Day) []weather.Day {
+	result := days[:0]
+	for i, d := range days {
+		if i+1 < len(days) && days[i+1].Date.Equal(d.Date) {
+			continue
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// DegreeDays computes heating degree days for the asset location between from
+// and to, both inclusive, using the nearest weather station. If base is nil,
+// weather.DefaultBaseTemperature is used.
+func (w *Weather) DegreeDays(ctx context.Context, asset uuid.UUID, from, to time.Time, base *float64) (*DegreeDays, error) {
+	adoc, err := w.st.FromKind("asset").Get(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+	a := adoc.Data.(*models.Asset)
+
+	if !Can(ctx, GetAsset, a.ID, a.Country) {
+		return nil, ErrUnauthorized
+	}
+
+	ws, dist, err := w.nearestStation(a.Country, a.Coordinates)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := w.temperatures(ws.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DegreeDays{
+		Summary:  weather.Summarize(days, baseTemperature(base)),
+		Station:  *ws,
+		Distance: dist,
+	}, nil
+}
+
+// FillProjectTemperatures fills the heating days and their average outdoor
+// temperature of the base years year, year-1 and year-2 in the project's
+// contract base year and baseconditions tables, and the heating period
+// temperature of the indoor clima, using the weather station nearest to the
+// project's asset. The monitoring years, from the project's first year on,
+// are filled the same way in the monitoring conditions table. The days colder
+// than base, weather.DefaultBaseTemperature if nil, are heating days. Months
+// and years without imported temperatures are left untouched.
+func (w *Weather) FillProjectTemperatures(ctx context.Context, pid uuid.UUID, year int, base *float64) error {
+	pdoc, err := w.st.FromKind("project").Get(ctx, pid)
+	if err != nil {
+		return err
+	}
+	prj := pdoc.Data.(*models.Project)
+
+	if !Can(ctx, FillProjectWeatherData, prj.ID, prj.Country) {
+		return ErrUnauthorized
+	}
+	bt := baseTemperature(base)
+
+	adoc, err := w.st.FromKind("asset").Get(ctx, prj.Asset)
+	if err != nil {
+		return err
+	}
+	a := adoc.Data.(*models.Asset)
+
+	ws, _, err := w.nearestStation(a.Country, a.Coordinates)
+	if err != nil {
+		return err
+	}
+
+	days, err := w.temperatures(ws.ID,
+		time.Date(year-2, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return err
+	}
+
+	cdoc, err := w.st.FromKind("contract").GetByIndex(ctx, pid.String())
+	if stores.IsRecordNotFound(err) {
+		return fmt.Errorf("%w: project has no contract", ErrNotFound)
+	} else if err != nil {
+		return err
+	}
+	ctr := cdoc.Data.(*contract.Contract)
+
+	icdoc, err := w.st.FromKind("indoorclima").GetByIndex(ctx, pid.String())
+	if stores.IsRecordNotFound(err) {
+		icdoc = &models.Document{Data: contract.NewIndoorClima(pid)}
+	} else if err != nil {
+		return err
+	}
+	ic := icdoc.Data.(*contract.IndoorClima)
+
+	for offset := 0; offset < 3; offset++ {
+		y := year - offset
+		for m := time.January; m <= time.December; m++ {
+			md := weather.Month(days, y, m)
+			if len(md) == 0 {
+				continue
+			}
+			s := weather.Summarize(md, bt)
+			if err := ctr.SetHeatingPeriod(offset, m, s.HeatingDays, s.HeatingAverageTemperature); err != nil {
+				return err
+			}
+		}
+
+		yd := weather.Year(days, y)
+		if len(yd) == 0 {
+			continue
+		}
+		t := weather.Summarize(yd, bt).HeatingAverageTemperature
+		switch offset {
+		case 0:
+			ic.OutdoorAirTemp.N = t
+		case 1:
+			ic.OutdoorAirTemp.N1 = t
+		case 2:
+			ic.OutdoorAirTemp.N2 = t
+		}
+	}
+
+	if err := w.fillMonitoringYears(ctr, ws.ID, prj.FirstYear, bt); err != nil {
+		return err
+	}
+
+	tx := w.st.DB().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	if _, err := stores.NewContractStore(tx, w.validator).Update(ctx, cdoc); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	icst := stores.NewIndoorClimaStore(tx, w.validator)
+	if icdoc.ID == uuid.Nil {
+		_, err = icst.Create(ctx, ic)
+	} else {
+		_, err = icst.Update(ctx, icdoc)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// fillMonitoringYears sets the heating periods of the monitoring years of
+// ctr, the first being the calendar year first, from the temperatures of
+// station. It does nothing if there are no temperatures for those years yet.
+func (w *Weather) fillMonitoringYears(ctr *contract.Contract, station uuid.UUID, first int, base float64) error {
+	if first <= 0 {
+		return nil
+	}
+
+	days, err := w.temperatures(station,
+		time.Date(first, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(first+contract.MonitoringYears-1, time.December, 31, 0, 0, 0, 0, time.UTC))
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for y := 1; y <= contract.MonitoringYears; y++ {
+		for m := time.January; m <= time.December; m++ {
+			md := weather.Month(days, first+y-1, m)
+			if len(md) == 0 {
+				continue
+			}
+			s := weather.Summarize(md, base)
+			if err := ctr.SetMonitoringPeriod(y, m, s.HeatingDays, s.HeatingAverageTemperature); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// baseTemperature returns base or weather.DefaultBaseTemperature if it is
+// nil.
+func baseTemperature(base *float64) float64 {
+	if base == nil {
+		return weather.DefaultBaseTemperature
+	}
+	return *base
+}
+
+// nearestStation returns the station in country c nearest to coords and its
+// distance in kilometers.
+func (w *Weather) nearestStation(c models.Country, coords models.Coords) (*models.WeatherStation, float64, error) {
+	var stations []models.WeatherStation
+	if err := w.st.DB().Where("country = ?", c).Find(&stations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		result *models.WeatherStation
+		dist   float64
+	)
+	for i := range stations {
+		d := geo.Distance(coords, stations[i].Coordinates)
+		if result == nil || d < dist {
+			result, dist = &stations[i], d
+		}
+	}
+
+	if result == nil || dist > maxStationDistance {
+		return nil, 0, fmt.Errorf("%w: no weather station within %d km", ErrNotFound, maxStationDistance)
+	}
+	return result, dist, nil
+}
+
+func (w *Weather) temperatures(station uuid.UUID, from, to time.Time) ([]weather.Day, error) {
+	var temps []models.DailyTemperature
+	err := w.st.DB().
+		Where("station_id = ?", station).
+		Where("date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("date").
+		Find(&temps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(temps) == 0 {
+		return nil, fmt.Errorf("%w: no temperatures for the period", ErrNotFound)
+	}
+
+	days := make([]weather.Day, len(temps))
+	for i, t := range temps {
+		days[i] = weather.Day{Date: t.Date, Temperature: t.Temperature}
+	}
+	return days, nil
+}
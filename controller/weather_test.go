@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/services"
+	"stageai.tech/sunshine/sunshine/stores"
+
+	"github.com/google/uuid"
+)
+
+func TestWeather(t *testing.T) {
+	e := services.NewTestEnv(t)
+	w := NewWeather(e)
+
+	admin := stores.NewTestAdmin(t, e.UserStore)
+	random := stores.NewTestUser(t, e.UserStore)
+	actx := services.NewTestContext(t, e, admin)
+
+	asset := stores.NewTestAsset(t, e.AssetStore).Data.(*models.Asset)
+	name := "Station " + uuid.New().String()
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		err  error
+	}{
+		{name: "ok admin", ctx: actx},
+		{name: "duplicate", ctx: actx, err: ErrDuplicate},
+		{name: "fail random guy", ctx: services.NewTestContext(t, e, random), err: ErrUnauthorized},
+	}
+
+	var station *models.WeatherStation
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ws, err := w.CreateStation(c.ctx, models.WeatherStation{
+				Name:        name,
+				Country:     asset.Country,
+				Coordinates: asset.Coordinates,
+			})
+			if !errors.Is(err, c.err) {
+				t.Fatalf("Got unexpected err; exp: %v, got: %v", c.err, err)
+			}
+			if err == nil {
+				station = ws
+			}
+		})
+	}
+	if station == nil {
+		t.Fatal("No station created")
+	}
+
+	csv := "date;temperature\n2020-01-01;-2\n2020-01-02;8,0\n2020-01-03;20\n"
+	if _, err := w.ImportTemperatures(services.NewTestContext(t, e, random), station.ID, strings.NewReader(csv)); err != ErrUnauthorized {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := w.ImportTemperatures(actx, station.ID, strings.NewReader("foo")); !errors.Is(err, ErrBadInput) {
+		t.Errorf("Expected ErrBadInput, got %v", err)
+	}
+	n, err := w.ImportTemperatures(actx, station.ID, strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ImportTemperatures: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 imported days, got %d", n)
+	}
+	// days repeated in the file are imported once
+	n, err = w.ImportTemperatures(actx, station.ID, strings.NewReader(csv+"2020-01-03;20\n"))
+	if err != nil {
+		t.Fatalf("ImportTemperatures: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 imported days, got %d", n)
+	}
+
+	dd, err := w.DegreeDays(actx, asset.ID,
+		time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC), nil)
+	if err != nil {
+		t.Fatalf("DegreeDays: %v", err)
+	}
+	if dd.Station.ID != station.ID {
+		t.Errorf("Expected station %v, got %v", station.ID, dd.Station.ID)
+	}
+	if dd.HeatingDays != 2 || dd.DegreeDays != 30 {
+		t.Errorf("Unexpected degree days: %+v", dd.Summary)
+	}
+
+	_, err = w.DegreeDays(actx, asset.ID,
+		time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2010, time.January, 31, 0, 0, 0, 0, time.UTC), nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
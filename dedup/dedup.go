@@ -0,0 +1,265 @@
+// Package dedup scores how likely two assets or two organizations are the
+// same entity entered twice, e.g. a building with slightly different
+// coordinates or a company with reformatted VAT.
+package dedup
+
+import (
+	"math"
+	"sort"
+
+	"stageai.tech/sunshine/sunshine/geo"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+// Threshold is the score from which two records are considered duplicates.
+const Threshold = 0.7
+
+// MaxDistance is the distance in kilometers from which the coordinates of
+// two assets don't count as a sign of them being the same building.
+const MaxDistance = 0.2
+
+// Reason is a sign of two records being duplicates.
+type Reason string
+
+const (
+	ReasonCadastre           Reason = "cadastre"
+	ReasonAddress            Reason = "address"
+	ReasonDistance           Reason = "distance"
+	ReasonVAT                Reason = "vat"
+	ReasonRegistrationNumber Reason = "registration_number"
+	ReasonName               Reason = "name"
+)
+
+// Match is a pair of likely duplicates.
+type Match struct {
+	A       uuid.UUID
+	B       uuid.UUID
+	Score   float64
+	Reasons []Reason
+}
+
+// AssetScore returns a score between 0 and 1 of a and b being the same
+// building along with the reasons for it.
+func AssetScore(a, b models.Asset) (float64, []Reason) {
+	var reasons []Reason
+
+	cadastre := Digits(a.Cadastre) != "" && Digits(a.Cadastre) == Digits(b.Cadastre)
+	if cadastre {
+		reasons = append(reasons, ReasonCadastre)
+	}
+
+	addr := AddressSimilarity(a.Address, b.Address)
+	if addr >= Threshold {
+		reasons = append(reasons, ReasonAddress)
+	}
+
+	near := math.Max(0, 1-geo.Distance(a.Coordinates, b.Coordinates)/MaxDistance)
+	if near > 0 {
+		reasons = append(reasons, ReasonDistance)
+	}
+
+	if cadastre {
+		return 1, reasons
+	}
+	return 0.6*addr + 0.4*near, reasons
+}
+
+// OrganizationScore returns a score between 0 and 1 of a and b being the
+// same organization along with the reasons for it.
+func OrganizationScore(a, b models.Organization) (float64, []Reason) {
+	var (
+		reasons []Reason
+		score   float64
+	)
+
+	if s := idSimilarity(a.VAT, b.VAT); s > 0 {
+		reasons = append(reasons, ReasonVAT)
+		score = s
+	}
+	if s := idSimilarity(a.RegistrationNumber, b.RegistrationNumber); s > 0 {
+		reasons = append(reasons, ReasonRegistrationNumber)
+		score = math.Max(score, s)
+	}
+
+	name := Similarity(Name(a.Name), Name(b.Name))
+	if name >= Threshold {
+		reasons = append(reasons, ReasonName)
+	}
+	addr := AddressSimilarity(a.Address, b.Address)
+	if addr >= Threshold {
+		reasons = append(reasons, ReasonAddress)
+	}
+
+	return math.Max(score, 0.7*name+0.3*addr), reasons
+}
+
+// idSimilarity scores VAT and registration numbers. Numbers equal once
+// normalized score 1 and numbers with a single typo score 0.8.
+func idSimilarity(a, b string) float64 {
+	a, b = ID(a), ID(b)
+	switch {
+	case a == "" || b == "":
+		return 0
+	case a == b:
+		return 1
+	case len(a) >= 8 && levenshtein([]rune(a), []rune(b)) == 1:
+		return 0.8
+	default:
+		return 0
+	}
+}
+
+// Assets finds the likely duplicates among assets. Only assets sharing a
+// cadastre number or lying close to each other are compared.
+func Assets(assets []models.Asset) []Match {
+	type cell struct {
+		country models.Country
+		lat     int64
+		lng     int64
+	}
+	// The cells are at least MaxDistance wide up to 70° latitude, where a
+	// degree of longitude is a third of a degree of latitude, so comparing
+	// with the neighbouring cells is enough.
+	const (
+		latSize = MaxDistance / 111 // a degree of latitude is 111 km
+		lngSize = 3 * latSize
+	)
+
+	var (
+		cadastres = make(map[string][]int)
+		cells     = make(map[cell][]int)
+		keys      = make([]cell, len(assets))
+	)
+	for i, a := range assets {
+		if c := Digits(a.Cadastre); c != "" {
+			k := string(a.Country) + ":" + c
+			cadastres[k] = append(cadastres[k], i)
+		}
+
+		keys[i] = cell{
+			country: a.Country,
+			lat:     int64(math.Floor(float64(a.Coordinates.Lat) / latSize)),
+			lng:     int64(math.Floor(float64(a.Coordinates.Lng) / lngSize)),
+		}
+		cells[keys[i]] = append(cells[keys[i]], i)
+	}
+
+	p := newPairs()
+	for _, block := range cadastres {
+		p.addBlock(block)
+	}
+	for i, k := range keys {
+		for dlat := int64(-1); dlat <= 1; dlat++ {
+			for dlng := int64(-1); dlng <= 1; dlng++ {
+				n := cell{country: k.country, lat: k.lat + dlat, lng: k.lng + dlng}
+				for _, j := range cells[n] {
+					p.add(i, j)
+				}
+			}
+		}
+	}
+
+	return p.matches(func(i, j int) (uuid.UUID, uuid.UUID, float64, []Reason) {
+		score, reasons := AssetScore(assets[i], assets[j])
+		return assets[i].ID, assets[j].ID, score, reasons
+	})
+}
+
+// Organizations finds the likely duplicates among organizations. Only
+// organizations of the same country sharing VAT, registration number or
+// name are compared.
+func Organizations(orgs []models.Organization) []Match {
+	blocks := make(map[string][]int)
+	for i, o := range orgs {
+		for _, k := range []string{"v" + ID(o.VAT), "r" + ID(o.RegistrationNumber), "n" + Name(o.Name)} {
+			if len(k) > 1 {
+				k = string(o.Country) + ":" + k
+				blocks[k] = append(blocks[k], i)
+			}
+		}
+	}
+
+	p := newPairs()
+	for _, block := range blocks {
+		p.addBlock(block)
+	}
+
+	return p.matches(func(i, j int) (uuid.UUID, uuid.UUID, float64, []Reason) {
+		score, reasons := OrganizationScore(orgs[i], orgs[j])
+		return orgs[i].ID, orgs[j].ID, score, reasons
+	})
+}
+
+// AssetMatches returns the candidates that are likely duplicates of a.
+func AssetMatches(a models.Asset, candidates []models.Asset) []Match {
+	p := newPairs()
+	for i := range candidates {
+		p.add(-1, i)
+	}
+
+	return p.matches(func(_, j int) (uuid.UUID, uuid.UUID, float64, []Reason) {
+		score, reasons := AssetScore(a, candidates[j])
+		return a.ID, candidates[j].ID, score, reasons
+	})
+}
+
+// OrganizationMatches returns the candidates that are likely duplicates of o.
+func OrganizationMatches(o models.Organization, candidates []models.Organization) []Match {
+	p := newPairs()
+	for i := range candidates {
+		p.add(-1, i)
+	}
+
+	return p.matches(func(_, j int) (uuid.UUID, uuid.UUID, float64, []Reason) {
+		score, reasons := OrganizationScore(o, candidates[j])
+		return o.ID, candidates[j].ID, score, reasons
+	})
+}
+
+// pairs holds the candidate pairs of indexes to be scored.
+type pairs map[[2]int]struct{}
+
+func newPairs() pairs { return make(pairs) }
+
+func (p pairs) add(i, j int) {
+	if i == j {
+		return
+	}
+	if i > j {
+		i, j = j, i
+	}
+	p[[2]int{i, j}] = struct{}{}
+}
+
+func (p pairs) addBlock(block []int) {
+	for x := range block {
+		for y := x + 1; y < len(block); y++ {
+			p.add(block[x], block[y])
+		}
+	}
+}
+
+// matches scores the pairs and returns the ones above the threshold, the
+// most likely first.
+func (p pairs) matches(score func(i, j int) (uuid.UUID, uuid.UUID, float64, []Reason)) []Match {
+	var result []Match
+	for k := range p {
+		a, b, s, reasons := score(k[0], k[1])
+		if s >= Threshold {
+			result = append(result, Match{A: a, B: b, Score: s, Reasons: reasons})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		if result[i].A != result[j].A {
+			return result[i].A.String() < result[j].A.String()
+		}
+		return result[i].B.String() < result[j].B.String()
+	})
+	return result
+}
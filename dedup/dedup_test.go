@@ -0,0 +1,190 @@
+package dedup
+
+import (
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		f    func(string) string
+		in   string
+		want string
+	}{
+		{Address, "Brīvības iela 10, Rīga, LV-1010", "brivibas 10 riga"},
+		{Address, "ul. Vitosha 15, Sofia 1000", "vitosha 15 sofia"},
+		{Address, "brivibas st. 10 riga", "brivibas 10 riga"},
+		{Name, `SIA "Renesco"`, "renesco"},
+		{Name, "Renesco, SIA", "renesco"},
+		{ID, "LV 4000-324 5752", "40003245752"},
+		{ID, "40003245752", "40003245752"},
+		{Digits, "0100-012-0034", "01000120034"},
+	}
+
+	for _, c := range cases {
+		if got := c.f(c.in); got != c.want {
+			t.Errorf("%q: want %q, got %q", c.in, c.want, got)
+		}
+	}
+}
+
+func TestAddressSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"Brīvības iela 10, Rīga", "Brivibas 10, Riga", 1, 1},
+		{"Brīvības iela 10, Rīga", "Brivibas 10, Riga, LV-1010", 1, 1},
+		{"Brīvības iela 10, Rīga", "Brivibas 10", 0.5, 0.7},
+		{"Brīvības iela 10, Rīga", "Brīvības iela 12, Rīga", 0, 0},
+		{"Brīvības iela 10, Rīga", "Lāčplēša iela 10, Rīga", 0, 0.7},
+	}
+
+	for _, c := range cases {
+		if s := AddressSimilarity(c.a, c.b); s < c.min || s > c.max {
+			t.Errorf("%q and %q: want similarity in [%v, %v], got %v", c.a, c.b, c.min, c.max, s)
+		}
+	}
+}
+
+func TestAssetScore(t *testing.T) {
+	base := models.Asset{
+		Address:     "Brīvības iela 10, Rīga",
+		Cadastre:    "0100-012-0034",
+		Coordinates: models.Coords{Lat: 56.9566, Lng: 24.1191},
+	}
+
+	cases := []struct {
+		name    string
+		other   models.Asset
+		dup     bool
+		reasons []Reason
+	}{
+		{
+			name: "same cadastre",
+			other: models.Asset{
+				Address:     "Somewhere else 1",
+				Cadastre:    "01000120034",
+				Coordinates: models.Coords{Lat: 42.6977, Lng: 23.3219},
+			},
+			dup:     true,
+			reasons: []Reason{ReasonCadastre},
+		},
+		{
+			name: "moved coordinates",
+			other: models.Asset{
+				Address:     "Brivibas 10, Riga",
+				Cadastre:    "1",
+				Coordinates: models.Coords{Lat: 56.9570, Lng: 24.1200},
+			},
+			dup:     true,
+			reasons: []Reason{ReasonAddress, ReasonDistance},
+		},
+		{
+			name: "next door",
+			other: models.Asset{
+				Address:     "Brīvības iela 12, Rīga",
+				Cadastre:    "2",
+				Coordinates: models.Coords{Lat: 56.9568, Lng: 24.1194},
+			},
+			reasons: []Reason{ReasonDistance},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			score, reasons := AssetScore(base, c.other)
+			if (score >= Threshold) != c.dup {
+				t.Errorf("want duplicate %v, got score %v", c.dup, score)
+			}
+			if !equalReasons(reasons, c.reasons) {
+				t.Errorf("want reasons %v, got %v", c.reasons, reasons)
+			}
+		})
+	}
+}
+
+func TestOrganizationScore(t *testing.T) {
+	base := models.Organization{
+		Name:               "SIA Renesco",
+		VAT:                "LV40003245752",
+		RegistrationNumber: "40003245752",
+		Address:            "Brīvības iela 10, Rīga",
+	}
+
+	cases := []struct {
+		name  string
+		other models.Organization
+		dup   bool
+	}{
+		{"reformatted vat", models.Organization{Name: "Other", VAT: "LV 40003245752"}, true},
+		{"vat typo", models.Organization{Name: "Other", VAT: "LV40003245753"}, true},
+		{"same name and address", models.Organization{Name: "Renesco, SIA", Address: "Brivibas 10, Riga"}, true},
+		{"different", models.Organization{Name: "Latvenergo", VAT: "LV40003032949", Address: "Pulkveža Brieža iela 12"}, false},
+	}
+
+	for _, c := range cases {
+		if score, _ := OrganizationScore(base, c.other); (score >= Threshold) != c.dup {
+			t.Errorf("%s: want duplicate %v, got score %v", c.name, c.dup, score)
+		}
+	}
+}
+
+func TestAssets(t *testing.T) {
+	var (
+		a = models.Asset{Address: "Brīvības iela 10, Rīga", Cadastre: "1", Coordinates: models.Coords{Lat: 56.9566, Lng: 24.1191}}
+		b = models.Asset{Address: "Brivibas 10, Riga", Cadastre: "2", Coordinates: models.Coords{Lat: 56.9570, Lng: 24.1200}}
+		c = models.Asset{Address: "Brīvības iela 12, Rīga", Cadastre: "3", Coordinates: models.Coords{Lat: 56.9568, Lng: 24.1194}}
+		d = models.Asset{Address: "Vitosha 1, Sofia", Cadastre: "1", Coordinates: models.Coords{Lat: 42.6977, Lng: 23.3219}}
+	)
+	assets := []models.Asset{a, b, c, d}
+	for i := range assets {
+		assets[i].ID = uuid.New()
+		assets[i].Country = models.CountryLatvia
+	}
+	assets[3].Country = models.CountryBulgaria
+
+	matches := Assets(assets)
+	if len(matches) != 1 {
+		t.Fatalf("want 1 match, got %v", matches)
+	}
+	if m := matches[0]; m.A != assets[0].ID || m.B != assets[1].ID {
+		t.Errorf("want the first two assets, got %v", m)
+	}
+}
+
+func TestOrganizations(t *testing.T) {
+	orgs := []models.Organization{
+		{Name: "SIA Renesco", VAT: "LV40003245752", Country: models.CountryLatvia},
+		{Name: "Renesco", VAT: "40003245752", Country: models.CountryLatvia},
+		{Name: "Renesco", Country: models.CountryBulgaria},
+		{Name: "Latvenergo", VAT: "LV40003032949", Country: models.CountryLatvia},
+	}
+	for i := range orgs {
+		orgs[i].ID = uuid.New()
+	}
+
+	matches := Organizations(orgs)
+	if len(matches) != 1 {
+		t.Fatalf("want 1 match, got %v", matches)
+	}
+	if m := matches[0]; m.Score != 1 || !equalReasons(m.Reasons, []Reason{ReasonVAT, ReasonName}) {
+		t.Errorf("unexpected match %v", m)
+	}
+}
+
+func equalReasons(a, b []Reason) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
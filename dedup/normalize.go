@@ -0,0 +1,181 @@
+package dedup
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fold replaces the letters with diacritics of the countries in the platform
+// by their base letters, since these are often left out when typing.
+var fold = strings.NewReplacer(
+	"ā", "a", "ă", "a", "â", "a", "ä", "a", "á", "a", "ą", "a",
+	"č", "c", "ć", "c",
+	"ď", "d", "đ", "d",
+	"ē", "e", "ė", "e", "ę", "e", "ě", "e", "é", "e",
+	"ģ", "g",
+	"ī", "i", "î", "i", "í", "i", "į", "i",
+	"ķ", "k",
+	"ļ", "l", "ľ", "l", "ĺ", "l",
+	"ņ", "n", "ň", "n",
+	"ó", "o", "ö", "o", "ő", "o", "ô", "o",
+	"ř", "r", "ŕ", "r",
+	"š", "s", "ș", "s", "ş", "s",
+	"ť", "t", "ț", "t", "ţ", "t",
+	"ū", "u", "ú", "u", "ü", "u", "ű", "u", "ů", "u", "ų", "u",
+	"ý", "y",
+	"ž", "z",
+)
+
+// streetWords are left out of addresses as they are abbreviated in many ways.
+var streetWords = set(
+	"iela", "st", "str", "street", "ul", "ulica", "ulitsa", "ulice",
+	"gatve", "g", "bulv", "bulvaris", "blvd", "bul", "pr", "prospekt",
+	"road", "rd", "avenue", "ave", "nr", "no",
+)
+
+// legalForms are left out of organization names.
+var legalForms = set(
+	"sia", "as", "ltd", "llc", "plc", "inc", "gmbh", "ag", "uab", "ab",
+	"ood", "eood", "ead", "ad", "et", "sro", "spol", "doo", "srl", "sa",
+	"biedriba", "dzivoklu", "ipasnieku",
+)
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+// words splits the lower case, folded s into words of letters and digits.
+func words(s string) []string {
+	s = fold.Replace(strings.ToLower(s))
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Address normalizes an address for comparison. Postal codes, e.g. LV-1010
+// or 1000, are left out as they are given only sometimes.
+func Address(s string) string {
+	var result []string
+	for _, w := range words(s) {
+		switch {
+		case streetWords[w]:
+		case len(w) >= 4 && Digits(w) == w:
+			if n := len(result); n > 0 && len(result[n-1]) == 2 && Digits(result[n-1]) == "" {
+				result = result[:n-1]
+			}
+		default:
+			result = append(result, w)
+		}
+	}
+	return strings.Join(result, " ")
+}
+
+// Name normalizes an organization name for comparison.
+func Name(s string) string {
+	var result []string
+	for _, w := range words(s) {
+		if !legalForms[w] {
+			result = append(result, w)
+		}
+	}
+	return strings.Join(result, " ")
+}
+
+// ID normalizes VAT and registration numbers, i.e. drops separators and the
+// country prefix.
+func ID(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	id := b.String()
+	if len(id) > 2 && isUpper(id[0]) && isUpper(id[1]) && !isUpper(id[2]) {
+		id = id[2:]
+	}
+	return id
+}
+
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
+
+// Digits returns only the digits of s, e.g. of a cadastre number.
+func Digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// Similarity returns a score between 0 and 1 of the strings being equal
+// based on their edit distance.
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	n := len(ra)
+	if len(rb) > n {
+		n = len(rb)
+	}
+	if n == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(n)
+}
+
+// AddressSimilarity is Similarity of normalized addresses. Addresses with
+// different house numbers are not similar at all.
+func AddressSimilarity(a, b string) float64 {
+	a, b = Address(a), Address(b)
+	if na, nb := houseNumber(a), houseNumber(b); na != "" && nb != "" && na != nb {
+		return 0
+	}
+	return Similarity(a, b)
+}
+
+// houseNumber returns the first word of the normalized address with digits,
+// which comes before the city and postal code.
+func houseNumber(s string) string {
+	for _, w := range strings.Fields(s) {
+		if strings.IndexFunc(w, unicode.IsDigit) >= 0 {
+			return w
+		}
+	}
+	return ""
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min(v ...int) int {
+	m := v[0]
+	for _, x := range v[1:] {
+		if x < m {
+			m = x
+		}
+	}
+	return m
+}
@@ -0,0 +1,138 @@
+// Package geo provides bounding boxes, distances, marker clustering and
+// GeoJSON encoding for coordinates of assets.
+package geo
+
+import (
+	"math"
+	"sort"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+const earthRadius = 6371.0 // km
+
+// MaxZoom is the zoom level of the map from which assets are not clustered.
+const MaxZoom = 20
+
+// cellsPerTile is the number of clustering grid cells per map tile side, i.e.
+// markers closer than about 64 pixels of a 256 pixels tile are clustered.
+const cellsPerTile = 4
+
+// BBox is a bounding box in degrees.
+type BBox struct {
+	South float64
+	West  float64
+	North float64
+	East  float64
+}
+
+// Valid reports whether the box is within the coordinate ranges and its
+// south-west corner is not past its north-east one.
+func (b BBox) Valid() bool {
+	return b.South >= -90 && b.North <= 90 && b.South <= b.North &&
+		b.West >= -180 && b.East <= 180 && b.West <= b.East
+}
+
+// Contains reports whether c is within the box.
+func (b BBox) Contains(c models.Coords) bool {
+	lat, lng := float64(c.Lat), float64(c.Lng)
+	return lat >= b.South && lat <= b.North && lng >= b.West && lng <= b.East
+}
+
+// Around returns the smallest box that contains the circle of radius km
+// around c.
+func Around(c models.Coords, km float64) BBox {
+	dlat := km / earthRadius * 180 / math.Pi
+	dlng := 180.0
+	if cos := math.Cos(float64(c.Lat) * math.Pi / 180); cos > 0 {
+		dlng = math.Min(dlat/cos, 180)
+	}
+
+	return BBox{
+		South: math.Max(float64(c.Lat)-dlat, -90),
+		West:  math.Max(float64(c.Lng)-dlng, -180),
+		North: math.Min(float64(c.Lat)+dlat, 90),
+		East:  math.Min(float64(c.Lng)+dlng, 180),
+	}
+}
+
+// Distance returns the great-circle distance in kilometers between a and b.
+func Distance(a, b models.Coords) float64 {
+	rad := func(deg float32) float64 { return float64(deg) * math.Pi / 180 }
+
+	dlat := rad(b.Lat - a.Lat)
+	dlng := rad(b.Lng - a.Lng)
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(rad(a.Lat))*math.Cos(rad(b.Lat))*math.Sin(dlng/2)*math.Sin(dlng/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// Point is a located entity, e.g. an asset.
+type Point struct {
+	ID     uuid.UUID
+	Coords models.Coords
+}
+
+// Cluster is a group of points shown as a single marker on the map.
+type Cluster struct {
+	// Center is the mean of the coordinates of the points.
+	Center models.Coords
+	Count  int
+	IDs    []uuid.UUID
+}
+
+// Clusterize groups the points by the cells of a grid, the size of which
+// depends on the zoom level of the map. The biggest clusters come first.
+// From MaxZoom on each point is a cluster of its own.
+func Clusterize(points []Point, zoom int) []Cluster {
+	if zoom < 0 {
+		zoom = 0
+	}
+
+	type cell struct{ lat, lng int64 }
+	size := 360 / math.Exp2(float64(zoom)) / cellsPerTile
+
+	var (
+		result []Cluster
+		index  = make(map[cell]int)
+		sums   [][2]float64
+	)
+	for i, p := range points {
+		c := cell{lat: int64(i), lng: -1}
+		if zoom < MaxZoom {
+			c = cell{
+				lat: int64(math.Floor(float64(p.Coords.Lat) / size)),
+				lng: int64(math.Floor(float64(p.Coords.Lng) / size)),
+			}
+		}
+
+		j, ok := index[c]
+		if !ok {
+			j = len(result)
+			index[c] = j
+			result = append(result, Cluster{})
+			sums = append(sums, [2]float64{})
+		}
+
+		result[j].Count++
+		result[j].IDs = append(result[j].IDs, p.ID)
+		sums[j][0] += float64(p.Coords.Lat)
+		sums[j][1] += float64(p.Coords.Lng)
+	}
+
+	for i := range result {
+		n := float64(result[i].Count)
+		result[i].Center = models.Coords{
+			Lat: float32(sums[i][0] / n),
+			Lng: float32(sums[i][1] / n),
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Count > result[j].Count
+	})
+	return result
+}
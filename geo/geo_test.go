@@ -0,0 +1,118 @@
+package geo
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	riga  = models.Coords{Lat: 56.9496, Lng: 24.1052}
+	sofia = models.Coords{Lat: 42.6977, Lng: 23.3219}
+)
+
+func TestDistance(t *testing.T) {
+	if d := Distance(riga, riga); d != 0 {
+		t.Errorf("expected 0 distance to itself; got %v", d)
+	}
+	if d := Distance(riga, sofia); math.Abs(d-1586) > 5 {
+		t.Errorf("expected about 1586 km between Riga and Sofia; got %v", d)
+	}
+}
+
+func TestAround(t *testing.T) {
+	box := Around(riga, 10)
+	if !box.Valid() {
+		t.Fatalf("invalid box %+v", box)
+	}
+
+	corners := []models.Coords{
+		{Lat: float32(box.South), Lng: riga.Lng},
+		{Lat: float32(box.North), Lng: riga.Lng},
+		{Lat: riga.Lat, Lng: float32(box.West)},
+		{Lat: riga.Lat, Lng: float32(box.East)},
+	}
+	for _, c := range corners {
+		if d := Distance(riga, c); math.Abs(d-10) > 0.1 {
+			t.Errorf("expected box edge %v 10 km away; got %v", c, d)
+		}
+	}
+
+	if box.Contains(sofia) || !box.Contains(riga) {
+		t.Errorf("box %+v contains wrong points", box)
+	}
+}
+
+func TestBBoxValid(t *testing.T) {
+	cases := []struct {
+		box   BBox
+		valid bool
+	}{
+		{BBox{South: 56, West: 24, North: 57, East: 25}, true},
+		{BBox{South: 57, West: 24, North: 56, East: 25}, false},
+		{BBox{South: 56, West: 25, North: 57, East: 24}, false},
+		{BBox{South: -91, West: 24, North: 57, East: 25}, false},
+		{BBox{South: 56, West: 24, North: 57, East: 181}, false},
+	}
+
+	for _, c := range cases {
+		if v := c.box.Valid(); v != c.valid {
+			t.Errorf("%+v: expected valid %v; got %v", c.box, c.valid, v)
+		}
+	}
+}
+
+func TestClusterize(t *testing.T) {
+	points := []Point{
+		{ID: uuid.New(), Coords: riga},
+		{ID: uuid.New(), Coords: models.Coords{Lat: 56.9510, Lng: 24.1130}},
+		{ID: uuid.New(), Coords: sofia},
+	}
+
+	cases := []struct {
+		zoom  int
+		sizes []int
+	}{
+		{0, []int{3}},
+		{6, []int{2, 1}},
+		{MaxZoom, []int{1, 1, 1}},
+	}
+
+	for _, c := range cases {
+		clusters := Clusterize(points, c.zoom)
+		if len(clusters) != len(c.sizes) {
+			t.Fatalf("zoom %d: expected %d clusters; got %d", c.zoom, len(c.sizes), len(clusters))
+		}
+		for i, n := range c.sizes {
+			if clusters[i].Count != n || len(clusters[i].IDs) != n {
+				t.Errorf("zoom %d: expected cluster %d of %d points; got %+v", c.zoom, i, n, clusters[i])
+			}
+		}
+	}
+
+	city := Clusterize(points, 6)[0]
+	if Distance(city.Center, riga) > 1 {
+		t.Errorf("expected cluster in Riga; got %v", city.Center)
+	}
+}
+
+func TestFeatureCollection(t *testing.T) {
+	fc := NewFeatureCollection()
+	fc.Add("a", riga, map[string]interface{}{"milestone": models.MilestoneWorkPhase})
+
+	b, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `{"type":"FeatureCollection","features":[{"type":"Feature","id":"a",` +
+		`"geometry":{"type":"Point","coordinates":[24.1052,56.9496]},` +
+		`"properties":{"milestone":"work_phase"}}]}`
+	if string(b) != exp {
+		t.Errorf("expected %s; got %s", exp, b)
+	}
+}
@@ -0,0 +1,56 @@
+package geo
+
+import (
+	"math"
+
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+// FeatureCollection is a GeoJSON (RFC 7946) feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON feature.
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry is a GeoJSON point geometry. Note that coordinates are ordered
+// longitude first.
+type Geometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// NewFeatureCollection returns an empty feature collection.
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+// Add adds a point feature at c to the collection.
+func (fc *FeatureCollection) Add(id string, c models.Coords, props map[string]interface{}) {
+	if props == nil {
+		props = make(map[string]interface{})
+	}
+
+	fc.Features = append(fc.Features, Feature{
+		Type: "Feature",
+		ID:   id,
+		Geometry: Geometry{
+			Type:        "Point",
+			Coordinates: [2]float64{round(c.Lng), round(c.Lat)},
+		},
+		Properties: props,
+	})
+}
+
+// round drops the noise of float32 to float64 conversion; 6 decimals are
+// about 10 cm precision.
+func round(deg float32) float64 {
+	return math.Round(float64(deg)*1e6) / 1e6
+}
@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"context"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func (r *queryResolver) ListFlats(ctx context.Context, assetID uuid.UUID) ([]models.Flat, error) {
+	return r.ballot.Flats(ctx, assetID)
+}
+
+func (r *mutationResolver) SaveFlat(ctx context.Context, flat models.Flat) (*models.Flat, error) {
+	return r.ballot.SaveFlat(ctx, flat)
+}
+
+func (r *mutationResolver) DeleteFlat(ctx context.Context, id uuid.UUID) (*Message, error) {
+	return messageResult(r.ballot.DeleteFlat(ctx, id))
+}
+
+func (r *mutationResolver) VerifyFlatResident(ctx context.Context, flatID uuid.UUID, userID *uuid.UUID) (*models.Flat, error) {
+	return r.ballot.VerifyResident(ctx, flatID, userID)
+}
+
+func (r *queryResolver) GetBallot(ctx context.Context, id uuid.UUID) (*models.Ballot, error) {
+	return r.ballot.Get(ctx, id)
+}
+
+func (r *queryResolver) ListBallots(ctx context.Context, assetID uuid.UUID) ([]models.Ballot, error) {
+	return r.ballot.List(ctx, assetID)
+}
+
+func (r *mutationResolver) CreateBallot(ctx context.Context, ballot models.Ballot) (*models.Ballot, error) {
+	return r.ballot.Create(ctx, ballot)
+}
+
+func (r *mutationResolver) Vote(ctx context.Context, ballotID, flatID uuid.UUID, option int) (*models.Vote, error) {
+	return r.ballot.Vote(ctx, ballotID, flatID, option)
+}
+
+func (r *mutationResolver) CloseBallot(ctx context.Context, id uuid.UUID) (*models.Ballot, error) {
+	return r.ballot.Close(ctx, id)
+}
+
+func (r *ballotResolver) Tally(ctx context.Context, obj *models.Ballot) (*controller.Tally, error) {
+	return r.ballot.Tally(ctx, obj.ID)
+}
+
+func (r *ballotResolver) Options(ctx context.Context, obj *models.Ballot) ([]string, error) {
+	return obj.Options, nil
+}
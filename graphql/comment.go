@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/sentry"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+)
+
+func (r *mutationResolver) PostComment(ctx context.Context, projectID uuid.UUID, comment models.ProjectComment,
+	mentions []uuid.UUID, files []graphql.Upload) (*models.ProjectComment, error) {
+	ups := make([]controller.Upload, len(files))
+	for i := range files {
+		ups[i] = controller.Upload{
+			File:        files[i].File,
+			Filename:    files[i].Filename,
+			Size:        files[i].Size,
+			ContentType: files[i].ContentType,
+		}
+	}
+	return r.cmt.Post(ctx, projectID, comment, mentions, ups)
+}
+
+func (r *mutationResolver) EditComment(ctx context.Context, id uuid.UUID, content string, mentions []uuid.UUID) (*models.ProjectComment, error) {
+	return r.cmt.Edit(ctx, id, content, mentions)
+}
+
+func (r *mutationResolver) DeleteComment(ctx context.Context, id uuid.UUID) (*Message, error) {
+	return messageResult(r.cmt.Delete(ctx, id))
+}
+
+func (r *mutationResolver) ResolveComment(ctx context.Context, id uuid.UUID, resolved bool) (*models.ProjectComment, error) {
+	return r.cmt.Resolve(ctx, id, resolved)
+}
+
+func (r *mutationResolver) AcceptSuggestion(ctx context.Context, id uuid.UUID) (*models.ProjectComment, error) {
+	return r.cmt.AcceptSuggestion(ctx, id)
+}
+
+func (r *mutationResolver) RejectSuggestion(ctx context.Context, id uuid.UUID) (*models.ProjectComment, error) {
+	return r.cmt.RejectSuggestion(ctx, id)
+}
+
+func (r *queryResolver) ListCommentThreads(ctx context.Context, projectID uuid.UUID, target *models.CommentTarget,
+	targetID *uuid.UUID, targetName *string, resolved *bool) ([]models.ProjectComment, error) {
+	return r.cmt.Threads(ctx, projectID, target, targetID, targetName, resolved)
+}
+
+func (r *prjCommentResolver) Mentions(ctx context.Context, obj *models.ProjectComment) ([]models.User, error) {
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	users := make([]models.User, 0, len(obj.Mentions))
+	for _, m := range obj.Mentions {
+		id, err := uuid.Parse(m)
+		if err != nil {
+			return nil, err
+		}
+		user, err := cv.User.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *prjCommentResolver) ResolvedBy(ctx context.Context, obj *models.ProjectComment) (*models.User, error) {
+	if obj.ResolvedBy == nil {
+		return nil, nil
+	}
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	user, err := cv.User.Load(*obj.ResolvedBy)
+	return &user, err
+}
+
+func (r *prjCommentResolver) SuggestionStatus(ctx context.Context, obj *models.ProjectComment) (*models.SuggestionStatus, error) {
+	if obj.SuggestionStatus == "" {
+		return nil, nil
+	}
+	return &obj.SuggestionStatus, nil
+}
+
+func (r *prjCommentResolver) DecidedBy(ctx context.Context, obj *models.ProjectComment) (*models.User, error) {
+	if obj.DecidedBy == nil {
+		return nil, nil
+	}
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	user, err := cv.User.Load(*obj.DecidedBy)
+	return &user, err
+}
+
+func (r *prjCommentResolver) Replies(ctx context.Context, obj *models.ProjectComment) ([]models.ProjectComment, error) {
+	return r.cmt.Replies(ctx, *obj)
+}
+
+func (r *prjCommentResolver) Attachments(ctx context.Context, obj *models.ProjectComment) ([]models.Attachment, error) {
+	return r.cmt.Attachments(ctx, *obj)
+}
+
+func (r *prjCommentResolver) History(ctx context.Context, obj *models.ProjectComment) ([]models.ProjectCommentRevision, error) {
+	return r.cmt.History(ctx, *obj)
+}
+
+func (r *prjCommentRevResolver) EditedBy(ctx context.Context, obj *models.ProjectCommentRevision) (*models.User, error) {
+	if obj.EditedBy == nil {
+		return nil, nil
+	}
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	user, err := cv.User.Load(*obj.EditedBy)
+	return &user, err
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/gob"
 	"errors"
+	"sort"
 	"strconv"
 
 	"stageai.tech/sunshine/sunshine/contract"
@@ -15,7 +16,15 @@ import (
 	"github.com/google/uuid"
 )
 
-func (r *queryResolver) GetTable(ctx context.Context, projectID uuid.UUID, annexN *int, tableName string) (*contract.Table, error) {
+// ContractTable is a contract table along with the changes suggested for its
+// cells and still open.
+type ContractTable struct {
+	contract.Table
+
+	Suggestions []models.ProjectComment
+}
+
+func (r *queryResolver) GetTable(ctx context.Context, projectID uuid.UUID, annexN *int, tableName string) (*ContractTable, error) {
 	vars := map[string]string{
 		"annexn": "",
 		"table":  tableName,
@@ -25,10 +34,13 @@ func (r *queryResolver) GetTable(ctx context.Context, projectID uuid.UUID, annex
 	}
 
 	t, err := r.ctr.GetTable(ctx, projectID, vars)
-	return t, err
+	if err != nil {
+		return nil, err
+	}
+	return r.contractTable(ctx, projectID, tableName, t)
 }
 
-func (r *mutationResolver) UpdateTable(ctx context.Context, projectID uuid.UUID, annexN *int, tableName string, table *UpdateTable) (*contract.Table, error) {
+func (r *mutationResolver) UpdateTable(ctx context.Context, projectID uuid.UUID, annexN *int, tableName string, table *UpdateTable) (*ContractTable, error) {
 	vars := map[string]string{
 		"annexn": "",
 		"table":  tableName,
@@ -42,8 +54,42 @@ func (r *mutationResolver) UpdateTable(ctx context.Context, projectID uuid.UUID,
 	}
 
 	c, err := r.ctr.UpdateTable(ctx, projectID, *t, vars)
+	if err != nil {
+		return nil, err
+	}
+	return r.contractTable(ctx, projectID, tableName, c)
+}
+
+func (r *Resolver) contractTable(ctx context.Context, projectID uuid.UUID, name string, t *contract.Table) (*ContractTable, error) {
+	sugs, err := r.cmt.Suggestions(ctx, projectID, models.CommentOnContractTable, &name)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractTable{Table: *t, Suggestions: sugs}, nil
+}
+
+func (r *queryResolver) GetFields(ctx context.Context, projectID uuid.UUID) ([]ContractField, error) {
+	fields, err := r.ctr.GetFields(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	sugs, err := r.cmt.Suggestions(ctx, projectID, models.CommentOnContractField, nil)
+	if err != nil {
+		return nil, err
+	}
 
-	return c, err
+	res := make([]ContractField, 0, len(fields))
+	for k, v := range fields {
+		f := ContractField{Key: k, Value: v, Suggestions: []models.ProjectComment{}}
+		for _, s := range sugs {
+			if s.TargetName == k {
+				f.Suggestions = append(f.Suggestions, s)
+			}
+		}
+		res = append(res, f)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res, nil
 }
 
 func (r *queryResolver) GetIndoorClima(ctx context.Context, projectID uuid.UUID) (*contract.IndoorClima, error) {
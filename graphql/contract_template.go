@@ -0,0 +1,46 @@
+package graphql
+
+import (
+	"context"
+
+	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+)
+
+func (r *mutationResolver) UploadContractTemplate(ctx context.Context, country string, description *string, file graphql.Upload) (*contract.Template, error) {
+	var desc string
+	if description != nil {
+		desc = *description
+	}
+
+	return r.ctr.UploadTemplate(ctx, models.Country(country), desc, controller.Upload{
+		File:        file.File,
+		Filename:    file.Filename,
+		Size:        file.Size,
+		ContentType: file.ContentType,
+	})
+}
+
+func (r *mutationResolver) ActivateContractTemplate(ctx context.Context, id uuid.UUID) (*contract.Template, error) {
+	return r.ctr.ActivateTemplate(ctx, id)
+}
+
+func (r *mutationResolver) SetProjectContractTemplate(ctx context.Context, projectID uuid.UUID, templateID *uuid.UUID) (*Message, error) {
+	return messageResult(r.ctr.SetProjectTemplate(ctx, projectID, templateID))
+}
+
+func (r *queryResolver) ListContractTemplates(ctx context.Context, country *string) ([]contract.Template, error) {
+	var c models.Country
+	if country != nil {
+		c = models.Country(*country)
+	}
+	return r.ctr.ListTemplates(ctx, c)
+}
+
+func (r *ctResolver) Country(ctx context.Context, obj *contract.Template) (string, error) {
+	return string(obj.Country), nil
+}
@@ -2,6 +2,7 @@ package graphql
 
 import (
 	"context"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/models"
 )
@@ -13,3 +14,20 @@ func (r *mutationResolver) SetVat(ctx context.Context, country string, vat int)
 func (r *queryResolver) GetCountry(ctx context.Context, country string) (*models.CountryVat, error) {
 	return r.ctry.GetCountry(ctx, models.Country(country))
 }
+
+func (r *mutationResolver) SetEnergyFactor(ctx context.Context, country string, heatingType models.Heating, co2 float64, primaryEnergy float64, validFrom *time.Time) (*models.EnergyFactor, error) {
+	f := models.EnergyFactor{
+		Country:       models.Country(country),
+		HeatingType:   heatingType,
+		CO2:           co2,
+		PrimaryEnergy: primaryEnergy,
+	}
+	if validFrom != nil {
+		f.ValidFrom = *validFrom
+	}
+	return r.ctry.SetEnergyFactor(ctx, f)
+}
+
+func (r *queryResolver) ListEnergyFactors(ctx context.Context, country string) ([]models.EnergyFactor, error) {
+	return r.ctry.ListEnergyFactors(ctx, models.Country(country))
+}
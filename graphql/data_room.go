@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/sentry"
+
+	"github.com/google/uuid"
+)
+
+func (r *queryResolver) GetDataRoom(ctx context.Context, projectID uuid.UUID) (*models.DataRoom, error) {
+	return r.room.Get(ctx, projectID)
+}
+
+func (r *mutationResolver) SaveDataRoom(ctx context.Context, projectID uuid.UUID, title, description *string, includeContract, watermark *bool) (*models.DataRoom, error) {
+	return r.room.Save(ctx, projectID, title, description, includeContract, watermark)
+}
+
+func (r *mutationResolver) SetDataRoomDocuments(ctx context.Context, projectID uuid.UUID, documents []models.DataRoomDocument) (*models.DataRoom, error) {
+	return r.room.SetDocuments(ctx, projectID, documents)
+}
+
+func (r *mutationResolver) GrantDataRoom(ctx context.Context, projectID, investorID uuid.UUID, expiresAt *time.Time) (*models.DataRoomGrant, error) {
+	return r.room.Grant(ctx, projectID, investorID, expiresAt)
+}
+
+func (r *mutationResolver) RevokeDataRoom(ctx context.Context, grantID uuid.UUID) (*Message, error) {
+	return messageResult(r.room.Revoke(ctx, grantID))
+}
+
+func (r *dataRoomResolver) Documents(ctx context.Context, obj *models.DataRoom) ([]controller.DataRoomItem, error) {
+	return r.room.Items(ctx, obj)
+}
+
+func (r *dataRoomResolver) Financials(ctx context.Context, obj *models.DataRoom) (*controller.DataRoomFinancials, error) {
+	return r.room.Financials(ctx, obj)
+}
+
+func (r *dataRoomResolver) Monitoring(ctx context.Context, obj *models.DataRoom) ([]models.MPReview, error) {
+	return r.room.Monitoring(ctx, obj)
+}
+
+func (r *dataRoomResolver) Grants(ctx context.Context, obj *models.DataRoom) ([]models.DataRoomGrant, error) {
+	return r.room.Grants(ctx, obj)
+}
+
+func (r *dataRoomResolver) Downloads(ctx context.Context, obj *models.DataRoom) ([]models.Download, error) {
+	return r.room.Downloads(ctx, obj)
+}
+
+func (r *roomGrantResolver) Investor(ctx context.Context, obj *models.DataRoomGrant) (*models.User, error) {
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	user, err := cv.User.Load(obj.Investor)
+	return &user, err
+}
+
+func (r *roomGrantResolver) Active(ctx context.Context, obj *models.DataRoomGrant) (bool, error) {
+	return obj.ActiveAt(time.Now()), nil
+}
@@ -0,0 +1,72 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/sentry"
+
+	"github.com/google/uuid"
+)
+
+func (r *queryResolver) ListProjectDownloads(ctx context.Context, projectID uuid.UUID, first, offset *int) ([]models.Download, error) {
+	if first == nil {
+		first = new(int)
+	}
+	if offset == nil {
+		offset = new(int)
+	}
+	return r.project.Downloads(ctx, projectID, *first, *offset)
+}
+
+func (r *queryResolver) ListUserDownloads(ctx context.Context, userID uuid.UUID, first, offset *int) ([]models.Download, error) {
+	if first == nil {
+		first = new(int)
+	}
+	if offset == nil {
+		offset = new(int)
+	}
+	return r.user.Downloads(ctx, userID, *first, *offset)
+}
+
+func (r *downloadResolver) User(ctx context.Context, obj *models.Download) (*models.User, error) {
+	if obj.User == nil {
+		return nil, nil
+	}
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	user, err := cv.User.Load(*obj.User)
+	return &user, err
+}
+
+func (r *downloadResolver) Watermark(ctx context.Context, obj *models.Download) (*models.Watermark, error) {
+	return watermark(obj.Watermark), nil
+}
+
+func (r *projectResolver) Watermark(ctx context.Context, obj *models.Project) (*models.Watermark, error) {
+	return watermark(obj.Watermark), nil
+}
+
+func (r *attachmentResolver) Watermark(ctx context.Context, obj *models.Attachment) (*models.Watermark, error) {
+	return watermark(obj.Watermark), nil
+}
+
+func (r *mutationResolver) SetWatermark(ctx context.Context, projectID uuid.UUID, attachmentID *uuid.UUID, wm *models.Watermark) (*Message, error) {
+	w := models.WatermarkNone
+	if wm != nil {
+		w = *wm
+	}
+	return messageResult(r.project.SetWatermark(ctx, projectID, attachmentID, w))
+}
+
+// watermark returns the watermark, nil if none.
+func watermark(w models.Watermark) *models.Watermark {
+	if w == models.WatermarkNone {
+		return nil
+	}
+	return &w
+}
@@ -0,0 +1,42 @@
+package graphql
+
+import (
+	"context"
+
+	"stageai.tech/sunshine/sunshine/dedup"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/google/uuid"
+)
+
+func (r *mutationResolver) MergeAssets(ctx context.Context, keepID uuid.UUID, duplicateID uuid.UUID) (*Message, error) {
+	return messageResult(r.dup.MergeAssets(ctx, keepID, duplicateID))
+}
+
+func (r *mutationResolver) MergeOrganizations(ctx context.Context, keepID uuid.UUID, duplicateID uuid.UUID) (*Message, error) {
+	return messageResult(r.dup.MergeOrganizations(ctx, keepID, duplicateID))
+}
+
+func (r *mutationResolver) MergeUsers(ctx context.Context, keepID uuid.UUID, duplicateID uuid.UUID) (*Message, error) {
+	return messageResult(r.dup.MergeUsers(ctx, keepID, duplicateID))
+}
+
+func (r *queryResolver) ListAssetDuplicates(ctx context.Context, assetID uuid.UUID) ([]dedup.Match, error) {
+	return r.dup.Asset(ctx, assetID)
+}
+
+func (r *queryResolver) ListOrganizationDuplicates(ctx context.Context, organizationID uuid.UUID) ([]dedup.Match, error) {
+	return r.dup.Organization(ctx, organizationID)
+}
+
+func (r *queryResolver) AssetDuplicatesReport(ctx context.Context, country *string) ([]dedup.Match, error) {
+	return r.dup.AssetReport(ctx, optCountry(country))
+}
+
+func (r *queryResolver) OrganizationDuplicatesReport(ctx context.Context, country *string) ([]dedup.Match, error) {
+	return r.dup.OrganizationReport(ctx, optCountry(country))
+}
+
+func (r *queryResolver) ListMerges(ctx context.Context, targetID uuid.UUID) ([]models.Merge, error) {
+	return r.dup.Merges(ctx, targetID)
+}
@@ -7,7 +7,9 @@ import (
 
 	"stageai.tech/sunshine/sunshine/controller"
 	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/sentry"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/google/uuid"
 )
 
@@ -34,9 +36,46 @@ func (r *queryResolver) ListForfaitingApplications(ctx context.Context,
 }
 
 func (r *mutationResolver) ReviewForfaitingApplication(ctx context.Context,
-	id uuid.UUID, review models.FAReview) (*Message, error) {
+	id uuid.UUID, review models.FAReview, sendBackTo *models.FAReviewType) (*Message, error) {
 
-	return messageResult(r.fa.Review(ctx, id, review))
+	return messageResult(r.fa.Review(ctx, id, review, sendBackTo))
+}
+
+func (r *mutationResolver) SetReviewChain(ctx context.Context, country string, steps []models.FAReviewStep) ([]models.FAReviewStep, error) {
+	return r.fa.SetReviewChain(ctx, models.Country(country), steps)
+}
+
+func (r *queryResolver) GetReviewChain(ctx context.Context, country string) ([]models.FAReviewStep, error) {
+	return r.fa.ReviewChain(ctx, models.Country(country))
+}
+
+func (r *faResolver) ReviewHistory(ctx context.Context, fa *models.ForfaitingApplication) ([]models.FAReviewDecision, error) {
+	return r.fa.ReviewHistory(ctx, fa.ID)
+}
+
+func (r *fareviewResolver) Roles(ctx context.Context, obj *models.FAReview) ([]string, error) {
+	return obj.Roles, nil
+}
+
+func (r *fareviewResolver) Overdue(ctx context.Context, obj *models.FAReview) (bool, error) {
+	return controller.Overdue(*obj, time.Now()), nil
+}
+
+func (r *fareviewDecisionResolver) Author(ctx context.Context, obj *models.FAReviewDecision) (*models.User, error) {
+	if obj.Author == nil {
+		return nil, nil
+	}
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	user, err := cv.User.Load(*obj.Author)
+	return &user, err
+}
+
+func (r *fareviewStepResolver) Roles(ctx context.Context, obj *models.FAReviewStep) ([]string, error) {
+	return obj.Roles, nil
 }
 
 func (r *mutationResolver) UpdateForfaitingApplication(ctx context.Context, id uuid.UUID, fa models.ForfaitingApplication) (*models.ForfaitingApplication, error) {
@@ -65,3 +104,43 @@ func (r *queryResolver) GetForfaitingPayment(ctx context.Context, fpid uuid.UUID
 func (r *mutationResolver) UpdateForfaitingPayment(ctx context.Context, faid, pid uuid.UUID, transferValue *int, c *models.Currency, td *time.Time) (*models.ForfaitingPayment, error) {
 	return r.fa.UpdateFP(ctx, faid, pid, transferValue, c, td)
 }
+
+func (r *mutationResolver) CreatePaymentSchedule(ctx context.Context, faid uuid.UUID, total float64, c models.Currency, tranches int, firstDueDate time.Time, intervalMonths int) (*controller.PaymentSchedule, error) {
+	return r.fa.CreateSchedule(ctx, faid, total, c, tranches, firstDueDate, intervalMonths)
+}
+
+func (r *mutationResolver) UpdateForfaitingTranche(ctx context.Context, id uuid.UUID, amount *float64, dueDate *time.Time) (*controller.PaymentSchedule, error) {
+	return r.fa.UpdateTranche(ctx, id, amount, dueDate)
+}
+
+func (r *mutationResolver) RecordForfaitingTransfer(ctx context.Context, faid uuid.UUID, transfer models.ForfaitingTransfer) (*models.ForfaitingTransfer, error) {
+	return r.fa.RecordTransfer(ctx, faid, transfer)
+}
+
+func (r *mutationResolver) ImportBankStatement(ctx context.Context, file graphql.Upload) (*controller.Reconciliation, error) {
+	return r.fa.ImportStatement(ctx, file.Filename, file.File)
+}
+
+func (r *queryResolver) GetPaymentSchedule(ctx context.Context, faid uuid.UUID) (*controller.PaymentSchedule, error) {
+	return r.fa.Schedule(ctx, faid)
+}
+
+func (r *mutationResolver) SetFAChecklist(ctx context.Context, country string, items []models.FAChecklistItem) ([]models.FAChecklistItem, error) {
+	return r.fa.SetChecklist(ctx, models.Country(country), items)
+}
+
+func (r *queryResolver) GetFAChecklist(ctx context.Context, country string) ([]models.FAChecklistItem, error) {
+	return r.fa.ChecklistItems(ctx, models.Country(country))
+}
+
+func (r *faResolver) Checklist(ctx context.Context, fa *models.ForfaitingApplication) (*controller.Checklist, error) {
+	return r.fa.Checklist(ctx, fa.ID)
+}
+
+func (r *faclStatusResolver) UploadTypes(ctx context.Context, obj *controller.ChecklistStatus) ([]string, error) {
+	return obj.UploadTypes, nil
+}
+
+func (r *faclItemResolver) UploadTypes(ctx context.Context, obj *models.FAChecklistItem) ([]string, error) {
+	return obj.UploadTypes, nil
+}
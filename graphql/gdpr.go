@@ -34,6 +34,16 @@ func (r *mutationResolver) SendGDPRRequest(ctx context.Context, req GDPRRequest)
 	return &req, r.gdpr.SendRequest(ctx, &req.GDPRRequest, ups)
 }
 
+func (r *mutationResolver) ApproveGDPRRequest(ctx context.Context, rID uuid.UUID) (*GDPRRequest, error) {
+	doc, err := r.gdpr.Approve(ctx, rID)
+	return newGDPRRequest(doc), err
+}
+
+func (r *mutationResolver) RejectGDPRRequest(ctx context.Context, rID uuid.UUID) (*GDPRRequest, error) {
+	doc, err := r.gdpr.Reject(ctx, rID)
+	return newGDPRRequest(doc), err
+}
+
 func (r *queryResolver) ListGDPRRequests(ctx context.Context,
 	first, offset *int) (*PaginatedList, error) {
 	if first == nil {
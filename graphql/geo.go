@@ -0,0 +1,36 @@
+package graphql
+
+import (
+	"context"
+
+	"stageai.tech/sunshine/sunshine/geo"
+	"stageai.tech/sunshine/sunshine/models"
+)
+
+func (r *queryResolver) ListAssetsInBox(ctx context.Context, box geo.BBox, country *string) ([]models.Asset, error) {
+	return r.asset.InBox(ctx, box, optCountry(country))
+}
+
+func (r *queryResolver) ListAssetsNearby(ctx context.Context, lat float64, lng float64, radius float64, country *string) ([]models.Asset, error) {
+	center := models.Coords{Lat: float32(lat), Lng: float32(lng)}
+	return r.asset.InRadius(ctx, center, radius, optCountry(country))
+}
+
+func (r *queryResolver) ListAssetClusters(ctx context.Context, box geo.BBox, zoom int, country *string) ([]geo.Cluster, error) {
+	return r.asset.Clusters(ctx, box, zoom, optCountry(country))
+}
+
+func (r *clusterResolver) Lat(ctx context.Context, obj *geo.Cluster) (float64, error) {
+	return float64(obj.Center.Lat), nil
+}
+
+func (r *clusterResolver) Lng(ctx context.Context, obj *geo.Cluster) (float64, error) {
+	return float64(obj.Center.Lng), nil
+}
+
+func optCountry(country *string) models.Country {
+	if country == nil {
+		return ""
+	}
+	return models.Country(*country)
+}
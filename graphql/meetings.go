@@ -3,6 +3,7 @@ package graphql
 import (
 	"context"
 
+	"stageai.tech/sunshine/sunshine/controller"
 	"stageai.tech/sunshine/sunshine/models"
 
 	"github.com/google/uuid"
@@ -45,6 +46,10 @@ func (r *mutationResolver) DeleteMeeting(ctx context.Context, id uuid.UUID) (*Me
 	return msgOK, nil
 }
 
+func (r *mutationResolver) CreateCalendarFeed(ctx context.Context) (string, error) {
+	return r.meet.CalendarFeed(ctx)
+}
+
 func (r *mutationResolver) UpdateMeeting(ctx context.Context, meeting Meeting) (*Meeting, error) {
 	doc, err := r.meet.Update(ctx, meeting.Meeting)
 	return newMeeting(doc), err
@@ -61,3 +66,35 @@ func (r *queryResolver) ListMeetings(ctx context.Context, id *uuid.UUID) ([]*Mee
 	}
 	return res, err
 }
+
+func (r *meetingsResolver) Minutes(ctx context.Context, obj *Meeting) (*controller.Minutes, error) {
+	return r.meet.Minutes(ctx, obj.ID)
+}
+
+func (r *queryResolver) ListMinutesTemplates(ctx context.Context) ([]controller.MinutesTemplate, error) {
+	return controller.MinutesTemplates(), nil
+}
+
+func (r *mutationResolver) ApplyMinutesTemplate(ctx context.Context, meetingID uuid.UUID) (*controller.Minutes, error) {
+	return r.meet.ApplyMinutesTemplate(ctx, meetingID)
+}
+
+func (r *mutationResolver) UpdateMeetingMinutes(ctx context.Context, meetingID uuid.UUID, minutes controller.Minutes) (*controller.Minutes, error) {
+	return r.meet.UpdateMinutes(ctx, meetingID, minutes)
+}
+
+func (r *mutationResolver) RecordAttendance(ctx context.Context, guestID uuid.UUID, attendance models.AttendanceStatus, signature *string) (*models.MeetingGuest, error) {
+	var sig string
+	if signature != nil {
+		sig = *signature
+	}
+	return r.meet.RecordAttendance(ctx, guestID, attendance, sig)
+}
+
+func (r *mutationResolver) CreateFollowUpTask(ctx context.Context, actionItemID uuid.UUID) (*Task, error) {
+	doc, err := r.meet.CreateFollowUp(ctx, actionItemID)
+	if err != nil {
+		return nil, err
+	}
+	return newTask(doc), nil
+}
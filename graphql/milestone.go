@@ -82,6 +82,10 @@ func (r *mutationResolver) ReviewWorkPhase(ctx context.Context, id uuid.UUID, re
 	return messageResult(r.wp.ReviewWP(ctx, id, review))
 }
 
+func (r *mutationResolver) ScreenBankAccount(ctx context.Context, wpID uuid.UUID) (*models.WPReview, error) {
+	return r.wp.ScreenBankAccount(ctx, wpID)
+}
+
 func (r *mutationResolver) ReviewMonitoringPhase(ctx context.Context, id uuid.UUID, review models.MPReview) (*Message, error) {
 	return messageResult(r.mp.ReviewMP(ctx, id, review))
 }
@@ -73,3 +73,7 @@ func (r *mutationResolver) AdvanceToMilestone(ctx context.Context, projectID uui
 	}
 	return msgOK, nil
 }
+
+func (r *queryResolver) GetProjectSavings(ctx context.Context, projectID uuid.UUID) (*models.ProjectSavings, error) {
+	return r.project.Savings(ctx, projectID)
+}
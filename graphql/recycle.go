@@ -0,0 +1,25 @@
+package graphql
+
+import (
+	"context"
+
+	"stageai.tech/sunshine/sunshine/controller"
+
+	"github.com/google/uuid"
+)
+
+func (r *mutationResolver) RestoreDeleted(ctx context.Context, kind string, id uuid.UUID) (*Message, error) {
+	return messageResult(r.bin.Restore(ctx, kind, id))
+}
+
+func (r *mutationResolver) DeleteRecord(ctx context.Context, kind string, id uuid.UUID) (*Message, error) {
+	return messageResult(r.bin.Delete(ctx, kind, id))
+}
+
+func (r *queryResolver) ListDeleted(ctx context.Context, kind string, country *string) ([]controller.Deleted, error) {
+	return r.bin.List(ctx, kind, optCountry(country))
+}
+
+func (r *deletedResolver) Country(ctx context.Context, obj *controller.Deleted) (string, error) {
+	return string(obj.Country), nil
+}
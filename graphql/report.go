@@ -0,0 +1,84 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/sentry"
+
+	"github.com/google/uuid"
+)
+
+func (r *queryResolver) ListReportDefinitions(ctx context.Context) ([]models.ReportDefinition, error) {
+	return r.report.List(ctx)
+}
+
+func (r *queryResolver) ListReportRuns(ctx context.Context, definitionID uuid.UUID, first, offset *int) ([]models.ReportRun, error) {
+	if first == nil {
+		first = new(int)
+	}
+	if offset == nil {
+		offset = new(int)
+	}
+	return r.report.Runs(ctx, definitionID, *first, *offset)
+}
+
+func (r *mutationResolver) SaveReportDefinition(ctx context.Context, id *uuid.UUID, country *string, report models.ReportDefinition) (*models.ReportDefinition, error) {
+	if country != nil {
+		report.Country = models.Country(*country)
+	}
+	return r.report.Save(ctx, id, report)
+}
+
+func (r *mutationResolver) DeleteReportDefinition(ctx context.Context, id uuid.UUID) (*Message, error) {
+	return messageResult(r.report.Delete(ctx, id))
+}
+
+func (r *mutationResolver) GenerateReport(ctx context.Context, id uuid.UUID) (*models.ReportRun, error) {
+	return r.report.Generate(ctx, id)
+}
+
+func (r *queryResolver) GetReportingFreshness(ctx context.Context) ([]models.ReportingView, error) {
+	return r.rptg.Freshness(ctx)
+}
+
+func (r *mutationResolver) RefreshReporting(ctx context.Context) ([]models.ReportingView, error) {
+	return r.rptg.Refresh(ctx)
+}
+
+func (r *reportDefResolver) Country(ctx context.Context, obj *models.ReportDefinition) (*string, error) {
+	if obj.Country == "" {
+		return nil, nil
+	}
+	c := obj.Country.String()
+	return &c, nil
+}
+
+func (r *reportDefResolver) Author(ctx context.Context, obj *models.ReportDefinition) (*models.User, error) {
+	if obj.Author == nil {
+		return nil, nil
+	}
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	user, err := cv.User.Load(*obj.Author)
+	return &user, err
+}
+
+func (r *reportDefResolver) Subscribers(ctx context.Context, obj *models.ReportDefinition) ([]string, error) {
+	return obj.Subscribers, nil
+}
+
+func (r *reportRunResolver) Error(ctx context.Context, obj *models.ReportRun) (*string, error) {
+	if obj.Error == "" {
+		return nil, nil
+	}
+	return &obj.Error, nil
+}
+
+func (r *reportRunResolver) File(ctx context.Context, obj *models.ReportRun) (*models.Attachment, error) {
+	return r.report.File(ctx, *obj)
+}
@@ -36,6 +36,15 @@ type Resolver struct {
 	cr      *controller.User
 	gl      *controller.Global
 	ctry    *controller.Country
+	weather *controller.Weather
+	task    *controller.Task
+	dup     *controller.Duplicate
+	bin     *controller.RecycleBin
+	ballot  *controller.Ballot
+	room    *controller.DataRoom
+	report  *controller.Report
+	rptg    *controller.Reporting
+	cmt     *controller.Comment
 }
 
 func NewResolver(e *services.Env) *Resolver {
@@ -55,6 +64,15 @@ func NewResolver(e *services.Env) *Resolver {
 		cr:      controller.NewUser(e),
 		gl:      controller.NewGlobal(e),
 		ctry:    controller.NewCountry(e),
+		weather: controller.NewWeather(e),
+		task:    controller.NewTask(e),
+		dup:     controller.NewDuplicate(e),
+		bin:     controller.NewRecycleBin(e),
+		ballot:  controller.NewBallot(e),
+		room:    controller.NewDataRoom(e),
+		report:  controller.NewReport(e),
+		rptg:    controller.NewReporting(e),
+		cmt:     controller.NewComment(e),
 	}
 }
 
@@ -99,7 +117,7 @@ func (r *meetingsResolver) Project(ctx context.Context, obj *Meeting) (*models.P
 	return nil, nil
 }
 
-func (r *tableResolver) Rows(ctx context.Context, t *contract.Table) ([][]*string, error) {
+func (r *tableResolver) Rows(ctx context.Context, t *ContractTable) ([][]*string, error) {
 	result := make([][]*string, t.Len())
 	for i, row := range t.Rows() {
 		cells := make([]*string, len(row))
@@ -112,7 +130,7 @@ func (r *tableResolver) Rows(ctx context.Context, t *contract.Table) ([][]*strin
 	return result, nil
 }
 
-func (r *tableResolver) Columns(ctx context.Context, t *contract.Table) ([]*contract.Column, error) {
+func (r *tableResolver) Columns(ctx context.Context, t *ContractTable) ([]*contract.Column, error) {
 	result := make([]*contract.Column, t.ColumnLen())
 	for i, c := range t.Columns() {
 		column := c
@@ -305,6 +323,14 @@ func (r *fareviewResolver) Author(ctx context.Context, obj *models.FAReview) (*m
 	return &user, err
 }
 
+func (r *wpreviewResolver) ScreeningErrors(ctx context.Context, obj *models.WPReview) ([]string, error) {
+	return obj.ScreeningErrors, nil
+}
+
+func (r *wpreviewResolver) ScreeningMatches(ctx context.Context, obj *models.WPReview) ([]string, error) {
+	return obj.ScreeningMatches, nil
+}
+
 func (r *wpreviewResolver) Author(ctx context.Context, obj *models.WPReview) (*models.User, error) {
 	cv, ok := ctx.Value(ctxkey).(dataloader)
 	if !ok {
@@ -365,6 +391,10 @@ func (r *ctryResolver) Country(ctx context.Context, obj *models.CountryVat) (str
 	return string(obj.Country), nil
 }
 
+func (r *energyFactorResolver) Country(ctx context.Context, obj *models.EnergyFactor) (string, error) {
+	return string(obj.Country), nil
+}
+
 func messageResult(err error) (*Message, error) {
 	if err != nil {
 		return msgErr, err
@@ -373,24 +403,43 @@ func messageResult(err error) (*Message, error) {
 }
 
 type (
-	queryResolver      struct{ *Resolver }
-	mutationResolver   struct{ *Resolver }
-	meetingsResolver   struct{ *Resolver }
-	faResolver         struct{ *Resolver }
-	tableResolver      struct{ *Resolver }
-	assetResolver      struct{ *Resolver }
-	projectResolver    struct{ *Resolver }
-	userResolver       struct{ *Resolver }
-	fareviewResolver   struct{ *Resolver }
-	orgReportResolver  struct{ *Resolver }
-	wpreviewResolver   struct{ *Resolver }
-	mpreviewResolver   struct{ *Resolver }
-	prjCommentResolver struct{ *Resolver }
-	orgResolver        struct{ *Resolver }
-	notifResolver      struct{ *Resolver }
-	fpResolver         struct{ *Resolver }
-	crResolver         struct{ *Resolver }
-	ctryResolver       struct{ *Resolver }
+	queryResolver            struct{ *Resolver }
+	mutationResolver         struct{ *Resolver }
+	meetingsResolver         struct{ *Resolver }
+	faResolver               struct{ *Resolver }
+	tableResolver            struct{ *Resolver }
+	assetResolver            struct{ *Resolver }
+	projectResolver          struct{ *Resolver }
+	userResolver             struct{ *Resolver }
+	fareviewResolver         struct{ *Resolver }
+	orgReportResolver        struct{ *Resolver }
+	wpreviewResolver         struct{ *Resolver }
+	mpreviewResolver         struct{ *Resolver }
+	prjCommentResolver       struct{ *Resolver }
+	prjCommentRevResolver    struct{ *Resolver }
+	orgResolver              struct{ *Resolver }
+	notifResolver            struct{ *Resolver }
+	fpResolver               struct{ *Resolver }
+	crResolver               struct{ *Resolver }
+	ctryResolver             struct{ *Resolver }
+	energyFactorResolver     struct{ *Resolver }
+	wsResolver               struct{ *Resolver }
+	ctResolver               struct{ *Resolver }
+	taskResolver             struct{ *Resolver }
+	taskCommentResolver      struct{ *Resolver }
+	clusterResolver          struct{ *Resolver }
+	deletedResolver          struct{ *Resolver }
+	ballotResolver           struct{ *Resolver }
+	fareviewDecisionResolver struct{ *Resolver }
+	fareviewStepResolver     struct{ *Resolver }
+	faclStatusResolver       struct{ *Resolver }
+	faclItemResolver         struct{ *Resolver }
+	dataRoomResolver         struct{ *Resolver }
+	roomGrantResolver        struct{ *Resolver }
+	attachmentResolver       struct{ *Resolver }
+	downloadResolver         struct{ *Resolver }
+	reportDefResolver        struct{ *Resolver }
+	reportRunResolver        struct{ *Resolver }
 )
 
 func (r *Resolver) Query() QueryResolver                                 { return &queryResolver{r} }
@@ -411,3 +460,25 @@ func (r *Resolver) Notification() NotificationResolver                   { retur
 func (r *Resolver) ForfaitingPayment() ForfaitingPaymentResolver         { return &fpResolver{r} }
 func (r *Resolver) CountryRole() CountryRoleResolver                     { return &crResolver{r} }
 func (r *Resolver) Country() CountryResolver                             { return &ctryResolver{r} }
+func (r *Resolver) EnergyFactor() EnergyFactorResolver                   { return &energyFactorResolver{r} }
+func (r *Resolver) WeatherStation() WeatherStationResolver               { return &wsResolver{r} }
+func (r *Resolver) ContractTemplate() ContractTemplateResolver           { return &ctResolver{r} }
+func (r *Resolver) Task() TaskResolver                                   { return &taskResolver{r} }
+func (r *Resolver) TaskComment() TaskCommentResolver                     { return &taskCommentResolver{r} }
+func (r *Resolver) AssetCluster() AssetClusterResolver                   { return &clusterResolver{r} }
+func (r *Resolver) DeletedRecord() DeletedRecordResolver                 { return &deletedResolver{r} }
+func (r *Resolver) Ballot() BallotResolver                               { return &ballotResolver{r} }
+func (r *Resolver) FAReviewDecision() FAReviewDecisionResolver           { return &fareviewDecisionResolver{r} }
+func (r *Resolver) FAReviewStep() FAReviewStepResolver                   { return &fareviewStepResolver{r} }
+func (r *Resolver) FAChecklistStatus() FAChecklistStatusResolver         { return &faclStatusResolver{r} }
+func (r *Resolver) FAChecklistItem() FAChecklistItemResolver             { return &faclItemResolver{r} }
+func (r *Resolver) DataRoom() DataRoomResolver                           { return &dataRoomResolver{r} }
+func (r *Resolver) DataRoomGrant() DataRoomGrantResolver                 { return &roomGrantResolver{r} }
+func (r *Resolver) Attachment() AttachmentResolver                       { return &attachmentResolver{r} }
+func (r *Resolver) Download() DownloadResolver                           { return &downloadResolver{r} }
+func (r *Resolver) ReportDefinition() ReportDefinitionResolver           { return &reportDefResolver{r} }
+func (r *Resolver) ReportRun() ReportRunResolver                         { return &reportRunResolver{r} }
+
+func (r *Resolver) ProjectCommentRevision() ProjectCommentRevisionResolver {
+	return &prjCommentRevResolver{r}
+}
@@ -2,8 +2,10 @@ package graphql
 
 import (
 	"fmt"
+	"strings"
 
 	"stageai.tech/sunshine/sunshine/contract"
+	"stageai.tech/sunshine/sunshine/dedup"
 	"stageai.tech/sunshine/sunshine/models"
 
 	"github.com/99designs/gqlgen/graphql"
@@ -40,6 +42,8 @@ func UnmarshalEntityType(v interface{}) (models.EntityType, error) {
 		return models.IndoorClimaT, nil
 	case "MEETING":
 		return models.MeetingT, nil
+	case "TASK":
+		return models.TaskT, nil
 	default:
 		return "", fmt.Errorf("%[1]T(%[1]v)  is not valid entity type", v)
 	}
@@ -84,6 +88,16 @@ func UnmarshalUserAction(v interface{}) (models.UserAction, error) {
 		return models.UserActionApproveForfaitingApplication, nil
 	case "APPROVE_FORFAITING_PAYMENT":
 		return models.UserActionApproveForfaitingPayment, nil
+	case "COMMENT":
+		return models.UserActionComment, nil
+	case "FORFAITING_PAYMENT_OVERDUE":
+		return models.UserActionForfaitingPaymentOverdue, nil
+	case "MENTION":
+		return models.UserActionMention, nil
+	case "ACCEPT_SUGGESTION":
+		return models.UserActionAcceptSuggestion, nil
+	case "REJECT_SUGGESTION":
+		return models.UserActionRejectSuggestion, nil
 	default:
 		return "", fmt.Errorf("%[1]T(%[1]v) is not user action", v)
 	}
@@ -267,6 +281,44 @@ func MarshalFAReviewType(f models.FAReviewType) graphql.Marshaler {
 	return graphql.MarshalString(faReviewTypeMap[f])
 }
 
+func MarshalFADataPoint(d models.FADataPoint) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(d)))
+}
+
+func UnmarshalFADataPoint(v interface{}) (models.FADataPoint, error) {
+	s, _ := v.(string)
+	switch s {
+	case "BANK_ACCOUNT":
+		return models.FADataBankAccount, nil
+	case "BENEFICIARY":
+		return models.FADataBeneficiary, nil
+	case "MANAGER":
+		return models.FADataManager, nil
+	case "FUND_MANAGER":
+		return models.FADataFundManager, nil
+	default:
+		return "", fmt.Errorf("%[1]T(%[1]v) is not data point", v)
+	}
+}
+
+func MarshalFAReviewStatus(s models.FAReviewStatus) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(s)))
+}
+
+func UnmarshalFAReviewStatus(v interface{}) (models.FAReviewStatus, error) {
+	s, _ := v.(string)
+	switch s {
+	case "PENDING":
+		return models.FAReviewPending, nil
+	case "OPEN":
+		return models.FAReviewOpen, nil
+	case "APPROVED":
+		return models.FAReviewApproved, nil
+	default:
+		return "", fmt.Errorf("%[1]T(%[1]v) is not review status", v)
+	}
+}
+
 func MarshalWPReviewType(f models.WPReviewType) graphql.Marshaler {
 	return graphql.MarshalString(wpReviewTypeMap[f])
 }
@@ -485,6 +537,70 @@ func MarshalStakeholdersType(t models.StakeholdersType) graphql.Marshaler {
 	return graphql.MarshalString(meetingStakeholdersMap[t])
 }
 
+func MarshalTaskStatus(s models.TaskStatus) graphql.Marshaler {
+	return graphql.MarshalString(taskStatusMap[s])
+}
+
+func UnmarshalTaskStatus(v interface{}) (models.TaskStatus, error) {
+	s, _ := v.(string)
+	switch s {
+	case "TODO":
+		return models.TaskStatusTodo, nil
+	case "IN_PROGRESS":
+		return models.TaskStatusInProgress, nil
+	case "BLOCKED":
+		return models.TaskStatusBlocked, nil
+	case "DONE":
+		return models.TaskStatusDone, nil
+	default:
+		return "", fmt.Errorf("%[1]T(%[1]v) is not task status", v)
+	}
+}
+
+func MarshalAttendanceStatus(s models.AttendanceStatus) graphql.Marshaler {
+	return graphql.MarshalString(attendanceStatusMap[s])
+}
+
+func UnmarshalAttendanceStatus(v interface{}) (models.AttendanceStatus, error) {
+	s, _ := v.(string)
+	for a, name := range attendanceStatusMap {
+		if name == s {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("%[1]T(%[1]v) is not attendance status", v)
+}
+
+func MarshalBallotBasis(b models.BallotBasis) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(b)))
+}
+
+func UnmarshalBallotBasis(v interface{}) (models.BallotBasis, error) {
+	s, _ := v.(string)
+	switch s {
+	case "FLATS":
+		return models.BallotBasisFlats, nil
+	case "AREA":
+		return models.BallotBasisArea, nil
+	default:
+		return "", fmt.Errorf("%[1]T(%[1]v) is not ballot basis", v)
+	}
+}
+
+func MarshalDuplicateReason(r dedup.Reason) graphql.Marshaler {
+	return graphql.MarshalString(duplicateReasonMap[r])
+}
+
+func UnmarshalDuplicateReason(v interface{}) (dedup.Reason, error) {
+	s, _ := v.(string)
+	for r, name := range duplicateReasonMap {
+		if name == s {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("%[1]T(%[1]v) is not duplicate reason", v)
+}
+
 func MarshalMilestone(m models.Milestone) graphql.Marshaler {
 	return graphql.MarshalString(milestoneMap[m])
 }
@@ -578,6 +694,24 @@ func UnmarshalGDPRType(v interface{}) (models.GDPRType, error) {
 	}
 }
 
+func MarshalGDPRStatus(s models.GDPRStatus) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(s)))
+}
+
+func UnmarshalGDPRStatus(v interface{}) (models.GDPRStatus, error) {
+	s, _ := v.(string)
+	switch s {
+	case "PENDING":
+		return models.GDPRStatusPending, nil
+	case "COMPLETED":
+		return models.GDPRStatusCompleted, nil
+	case "REJECTED":
+		return models.GDPRStatusRejected, nil
+	default:
+		return "", fmt.Errorf("%[1]T(%[1]v) is not gdpr status", v)
+	}
+}
+
 func MarshalPortfolioRole(pr models.PortfolioRole) graphql.Marshaler {
 	return graphql.MarshalString(string(pr))
 }
@@ -666,6 +800,28 @@ var (
 		models.MilestoneForfaitingPayment:  "FORFAITING_PAYMENT",
 	}
 
+	taskStatusMap = map[models.TaskStatus]string{
+		models.TaskStatusTodo:       "TODO",
+		models.TaskStatusInProgress: "IN_PROGRESS",
+		models.TaskStatusBlocked:    "BLOCKED",
+		models.TaskStatusDone:       "DONE",
+	}
+
+	attendanceStatusMap = map[models.AttendanceStatus]string{
+		models.AttendancePresent: "PRESENT",
+		models.AttendanceAbsent:  "ABSENT",
+		models.AttendanceExcused: "EXCUSED",
+	}
+
+	duplicateReasonMap = map[dedup.Reason]string{
+		dedup.ReasonCadastre:           "CADASTRE",
+		dedup.ReasonAddress:            "ADDRESS",
+		dedup.ReasonDistance:           "DISTANCE",
+		dedup.ReasonVAT:                "VAT",
+		dedup.ReasonRegistrationNumber: "REGISTRATION_NUMBER",
+		dedup.ReasonName:               "NAME",
+	}
+
 	meetingTopicMap = map[models.MeetingType]string{
 		models.MTypeInternalMeeting:   "INTERNAL_MEETING",
 		models.MTypeConference:        "CONFERENCE",
@@ -739,3 +895,84 @@ var (
 		1: "FORFAITING",
 	}
 )
+
+func MarshalWatermark(w models.Watermark) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(w)))
+}
+
+func UnmarshalWatermark(v interface{}) (models.Watermark, error) {
+	s, _ := v.(string)
+	switch s {
+	case "DRAFT":
+		return models.WatermarkDraft, nil
+	case "CONFIDENTIAL":
+		return models.WatermarkConfidential, nil
+	default:
+		return "", fmt.Errorf("%[1]T(%[1]v) is not watermark", v)
+	}
+}
+
+func MarshalReportKind(k models.ReportKind) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(k)))
+}
+
+func UnmarshalReportKind(v interface{}) (models.ReportKind, error) {
+	s, _ := v.(string)
+	k := models.ReportKind(strings.ToLower(s))
+	if k.Valid() != nil {
+		return "", fmt.Errorf("%[1]T(%[1]v) is not report kind", v)
+	}
+	return k, nil
+}
+
+func MarshalReportFormat(f models.ReportFormat) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(f)))
+}
+
+func UnmarshalReportFormat(v interface{}) (models.ReportFormat, error) {
+	s, _ := v.(string)
+	f := models.ReportFormat(strings.ToLower(s))
+	if f.Valid() != nil {
+		return "", fmt.Errorf("%[1]T(%[1]v) is not report format", v)
+	}
+	return f, nil
+}
+
+func MarshalReportFrequency(f models.ReportFrequency) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(f)))
+}
+
+func UnmarshalReportFrequency(v interface{}) (models.ReportFrequency, error) {
+	s, _ := v.(string)
+	f := models.ReportFrequency(strings.ToLower(s))
+	if f.Valid() != nil {
+		return "", fmt.Errorf("%[1]T(%[1]v) is not report frequency", v)
+	}
+	return f, nil
+}
+
+func MarshalCommentTarget(t models.CommentTarget) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(t)))
+}
+
+func UnmarshalCommentTarget(v interface{}) (models.CommentTarget, error) {
+	s, _ := v.(string)
+	t := models.CommentTarget(strings.ToLower(s))
+	if t.Valid() != nil {
+		return "", fmt.Errorf("%[1]T(%[1]v) is not comment target", v)
+	}
+	return t, nil
+}
+
+func MarshalSuggestionStatus(st models.SuggestionStatus) graphql.Marshaler {
+	return graphql.MarshalString(strings.ToUpper(string(st)))
+}
+
+func UnmarshalSuggestionStatus(v interface{}) (models.SuggestionStatus, error) {
+	s, _ := v.(string)
+	switch st := models.SuggestionStatus(strings.ToLower(s)); st {
+	case models.SuggestionOpen, models.SuggestionAccepted, models.SuggestionRejected:
+		return st, nil
+	}
+	return "", fmt.Errorf("%[1]T(%[1]v) is not suggestion status", v)
+}
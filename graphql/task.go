@@ -0,0 +1,121 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/models"
+	"stageai.tech/sunshine/sunshine/sentry"
+
+	"github.com/google/uuid"
+)
+
+// Task encapsulates models.Task with its attachments.
+type Task struct {
+	models.Task
+	Attachments []models.Attachment
+}
+
+func newTask(doc *models.Document) *Task {
+	if doc == nil || doc.Kind != "task" {
+		return nil
+	}
+	t := doc.Data.(*models.Task)
+	atts := make([]models.Attachment, 0, len(doc.Attachments))
+	for _, v := range doc.Attachments {
+		atts = append(atts, v)
+	}
+
+	return &Task{Task: *t, Attachments: atts}
+}
+
+func (r *mutationResolver) CreateTask(ctx context.Context, task models.Task) (*Task, error) {
+	doc, err := r.task.Create(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	return newTask(doc), nil
+}
+
+func (r *mutationResolver) UpdateTask(ctx context.Context, id uuid.UUID, task controller.TaskUpdate) (*Task, error) {
+	doc, err := r.task.Update(ctx, id, task)
+	if err != nil {
+		return nil, err
+	}
+	return newTask(doc), nil
+}
+
+func (r *mutationResolver) AssignTask(ctx context.Context, id uuid.UUID, assigneeID *uuid.UUID) (*Task, error) {
+	doc, err := r.task.Assign(ctx, id, assigneeID)
+	if err != nil {
+		return nil, err
+	}
+	return newTask(doc), nil
+}
+
+func (r *mutationResolver) DeleteTask(ctx context.Context, id uuid.UUID) (*Message, error) {
+	return messageResult(r.task.Delete(ctx, id))
+}
+
+func (r *mutationResolver) CommentTask(ctx context.Context, id uuid.UUID, comment string) (*Task, error) {
+	doc, err := r.task.Comment(ctx, id, comment)
+	if err != nil {
+		return nil, err
+	}
+	return newTask(doc), nil
+}
+
+func (r *queryResolver) GetTask(ctx context.Context, id uuid.UUID) (*Task, error) {
+	doc, err := r.task.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newTask(doc), nil
+}
+
+func (r *queryResolver) ListProjectTasks(ctx context.Context, projectID uuid.UUID, status *models.TaskStatus, milestone *models.Milestone) ([]Task, error) {
+	tasks, err := r.task.ListByProject(ctx, projectID, status, milestone)
+	return wrapTasks(tasks), err
+}
+
+func (r *queryResolver) ListUserTasks(ctx context.Context, userID *uuid.UUID, status *models.TaskStatus) ([]Task, error) {
+	tasks, err := r.task.ListByUser(ctx, userID, status)
+	return wrapTasks(tasks), err
+}
+
+func wrapTasks(tasks []models.Task) []Task {
+	result := make([]Task, len(tasks))
+	for i, t := range tasks {
+		result[i] = Task{Task: t, Attachments: t.Attachments}
+	}
+	return result
+}
+
+func (r *taskResolver) Assignee(ctx context.Context, obj *Task) (*models.User, error) {
+	if obj.Assignee == nil {
+		return nil, nil
+	}
+	return taskUser(ctx, *obj.Assignee)
+}
+
+func (r *taskResolver) Author(ctx context.Context, obj *Task) (*models.User, error) {
+	if obj.Author == nil {
+		return nil, nil
+	}
+	return taskUser(ctx, *obj.Author)
+}
+
+func (r *taskCommentResolver) Author(ctx context.Context, obj *models.TaskComment) (*models.User, error) {
+	return taskUser(ctx, obj.UserID)
+}
+
+func taskUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	cv, ok := ctx.Value(ctxkey).(dataloader)
+	if !ok {
+		return nil, sentry.Report(errors.New("dataloader is missing"))
+	}
+
+	user, err := cv.User.Load(id)
+	return &user, err
+}
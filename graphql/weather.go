@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/models"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+)
+
+func (r *mutationResolver) CreateWeatherStation(ctx context.Context, name string, country string, lat float64, lng float64) (*models.WeatherStation, error) {
+	return r.weather.CreateStation(ctx, models.WeatherStation{
+		Name:        name,
+		Country:     models.Country(country),
+		Coordinates: models.Coords{Lat: float32(lat), Lng: float32(lng)},
+	})
+}
+
+func (r *mutationResolver) ImportTemperatures(ctx context.Context, stationID uuid.UUID, file graphql.Upload) (int, error) {
+	return r.weather.ImportTemperatures(ctx, stationID, file.File)
+}
+
+func (r *mutationResolver) FillProjectTemperatures(ctx context.Context, projectID uuid.UUID, year int, baseTemperature *float64) (*Message, error) {
+	return messageResult(r.weather.FillProjectTemperatures(ctx, projectID, year, baseTemperature))
+}
+
+func (r *queryResolver) ListWeatherStations(ctx context.Context, country *string) ([]models.WeatherStation, error) {
+	var c models.Country
+	if country != nil {
+		c = models.Country(*country)
+	}
+	return r.weather.ListStations(ctx, c)
+}
+
+func (r *queryResolver) GetDegreeDays(ctx context.Context, assetID uuid.UUID, from time.Time, to time.Time, baseTemperature *float64) (*controller.DegreeDays, error) {
+	return r.weather.DegreeDays(ctx, assetID, from, to, baseTemperature)
+}
+
+func (r *wsResolver) Country(ctx context.Context, obj *models.WeatherStation) (string, error) {
+	return string(obj.Country), nil
+}
+
+func (r *wsResolver) Lat(ctx context.Context, obj *models.WeatherStation) (float64, error) {
+	return float64(obj.Coordinates.Lat), nil
+}
+
+func (r *wsResolver) Lng(ctx context.Context, obj *models.WeatherStation) (float64, error) {
+	return float64(obj.Coordinates.Lng), nil
+}
@@ -1,17 +1,18 @@
 package http
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"mime"
 	"net/http"
 	"net/url"
 	"path"
-	"path/filepath"
 	"strconv"
 	"strings"
 
 	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/geo"
+	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/services"
 
 	"github.com/gorilla/mux"
@@ -19,14 +20,16 @@ import (
 )
 
 type asset struct {
-	s sessions.Store
-	c *controller.Asset
+	s   sessions.Store
+	c   *controller.Asset
+	dup *controller.Duplicate
 }
 
 func newAsset(env *services.Env) *asset {
 	return &asset{
-		s: env.SessionStore,
-		c: controller.NewAsset(env),
+		s:   env.SessionStore,
+		c:   controller.NewAsset(env),
+		dup: controller.NewDuplicate(env),
 	}
 }
 
@@ -39,13 +42,18 @@ func (a *asset) create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Add("Location", path.Join(r.URL.String(), doc.ID.String()))
+	setDuplicates(w, r, doc.ID, a.dup.Asset)
 	encode(w, doc, deps, err)
 }
 
 // Get existing asset.
 func (a *asset) get(w http.ResponseWriter, r *http.Request) {
-	doc, deps, err := a.c.Get(r.Context(), mustExtractUUID(r))
+	id := mustExtractUUID(r)
+	doc, deps, err := a.c.Get(r.Context(), id)
 	if err != nil {
+		if redirectMerged(w, r, id, a.dup) {
+			return
+		}
 		w.WriteHeader(http.StatusNotFound)
 	}
 
@@ -70,6 +78,55 @@ func (a *asset) list(w http.ResponseWriter, r *http.Request) {
 	encode(w, docs, deps, err)
 }
 
+// Export assets as GeoJSON feature collection.
+func (a *asset) geojson(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var box *geo.BBox
+	if v := q.Get("bbox"); v != "" {
+		b, err := parseBBox(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		box = &b
+	}
+
+	var milestone *models.Milestone
+	if v := q.Get("milestone"); v != "" {
+		m := models.Milestone(v)
+		milestone = &m
+	}
+
+	fc, err := a.c.GeoJSON(r.Context(), box, models.Country(q.Get("country")), milestone)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	json.NewEncoder(w).Encode(fc)
+}
+
+// parseBBox parses bounding box in south,west,north,east format.
+func parseBBox(s string) (geo.BBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return geo.BBox{}, errors.New("bbox must be south,west,north,east")
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return geo.BBox{}, fmt.Errorf("bad bbox: %v", err)
+		}
+		v[i] = f
+	}
+
+	return geo.BBox{South: v[0], West: v[1], North: v[2], East: v[3]}, nil
+}
+
 func (a *asset) upload(w http.ResponseWriter, r *http.Request) {
 	id := mustExtractUUID(r)
 
@@ -109,23 +166,12 @@ func (a *asset) getFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	att, f, err := a.c.GetFile(r.Context(), id, fname)
+	att, f, err := a.c.GetFile(r.Context(), id, fname, fileRequest(r))
 	if err != nil {
 		writeError(w, r, err)
 		return
 	}
-	defer f.Close()
-
-	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(fname)), "image/") {
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fname))
-
-	}
-	w.Header().Set("Content-Type", att.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
-
-	if r.Method != "HEAD" {
-		io.Copy(w, f)
-	}
+	serveAttachment(w, r, att, f, fname)
 }
 
 func (a *asset) delFile(w http.ResponseWriter, r *http.Request) {
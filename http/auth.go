@@ -83,6 +83,11 @@ func (a *Auth) login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Inactive users are anonymized after a retention period.
+	if err := a.us.DB().Exec("UPDATE users SET last_login = now() WHERE id = ?", user.ID).Error; err != nil {
+		sentry.Report(err, "Failed to update last login")
+	}
+
 	// Remove any left-over cookie.
 	w.Header().Del("Set-Cookie")
 
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/services"
+
+	"github.com/gorilla/mux"
+)
+
+type comment struct {
+	c *controller.Comment
+}
+
+func newComment(env *services.Env) *comment {
+	return &comment{c: controller.NewComment(env)}
+}
+
+// getFile serves a file attached to a project comment.
+func (cmt *comment) getFile(w http.ResponseWriter, r *http.Request) {
+	fname, err := url.PathUnescape(mux.Vars(r)["filename"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	att, f, err := cmt.c.Download(r.Context(), mustExtractUUID(r), fname, fileRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	serveAttachment(w, r, att, f, fname)
+}
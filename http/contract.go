@@ -49,8 +49,11 @@ func (ch *contractHandler) downloadNativeTeX(w http.ResponseWriter, r *http.Requ
 	ch.download(w, r, "native", "tex")
 }
 
+// download serves the contract of the project. It is generated per request,
+// so it is always served whole.
 func (ch *contractHandler) download(w http.ResponseWriter, r *http.Request, language, format string) {
 	id := mustExtractUUID(r)
+	r.Header.Del("Range")
 
 	file, name, err := ch.c.DownloadContract(r.Context(), id, language, format)
 	if err != nil {
@@ -95,6 +98,36 @@ func (ch *contractHandler) downloadAgreement(w http.ResponseWriter, r *http.Requ
 	http.ServeContent(w, r, name, time.Now(), file)
 }
 
+func (ch *contractHandler) previewTemplatePDF(w http.ResponseWriter, r *http.Request) {
+	ch.previewTemplate(w, r, "pdf")
+}
+
+func (ch *contractHandler) previewTemplateTeX(w http.ResponseWriter, r *http.Request) {
+	ch.previewTemplate(w, r, "tex")
+}
+
+// previewTemplate renders a contract template version with a sample contract.
+// The rendered file is given by the "file" query parameter and defaults to
+// the contract.
+func (ch *contractHandler) previewTemplate(w http.ResponseWriter, r *http.Request, format string) {
+	id := mustExtractUUID(r)
+	root := r.FormValue("file")
+	if root == "" {
+		root = "contract.tex"
+	}
+
+	file, name, err := ch.c.PreviewTemplate(r.Context(), id, root, format)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Del("Content-Type")
+	w.Header().Set("Content-Disposition", "attachment; filename="+name)
+	http.ServeContent(w, r, name, time.Now(), file)
+}
+
 func (ch *contractHandler) updateTable(w http.ResponseWriter, r *http.Request) {
 	id := mustExtractUUID(r)
 
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/services"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type dataRoom struct {
+	c *controller.DataRoom
+}
+
+func newDataRoom(env *services.Env) *dataRoom {
+	return &dataRoom{c: controller.NewDataRoom(env)}
+}
+
+// download serves a document of the data room of the project.
+func (d *dataRoom) download(w http.ResponseWriter, r *http.Request) {
+	attID, err := uuid.Parse(mux.Vars(r)["attachment"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	att, file, err := d.c.Download(r.Context(), mustExtractUUID(r), attID, fileRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	serveAttachment(w, r, att, file, att.Name)
+}
+
+// downloadContract serves the contract of the project of the data room as
+// PDF. It is generated per request, so it is always served whole.
+func (d *dataRoom) downloadContract(w http.ResponseWriter, r *http.Request) {
+	r.Header.Del("Range")
+	file, name, err := d.c.DownloadContract(r.Context(), mustExtractUUID(r), mux.Vars(r)["language"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Del("Content-Type")
+	w.Header().Set("Content-Disposition", "attachment; filename="+name)
+	http.ServeContent(w, r, name, time.Now(), file)
+}
@@ -1,15 +1,9 @@
 package http
 
 import (
-	"fmt"
-	"io"
-	"mime"
 	"net/http"
 	"net/url"
 	"path"
-	"path/filepath"
-	"strconv"
-	"strings"
 
 	"stageai.tech/sunshine/sunshine/controller"
 	"stageai.tech/sunshine/sunshine/services"
@@ -64,23 +58,12 @@ func (f *fa) getFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	att, file, err := f.c.GetFile(r.Context(), id, fname)
+	att, file, err := f.c.GetFile(r.Context(), id, fname, fileRequest(r))
 	if err != nil {
 		writeError(w, r, err)
 		return
 	}
-	defer file.Close()
-
-	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(fname)), "image/") {
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fname))
-
-	}
-	w.Header().Set("Content-Type", att.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
-
-	if r.Method != "HEAD" {
-		io.Copy(w, file)
-	}
+	serveAttachment(w, r, att, file, fname)
 }
 
 func (f *fa) delFile(w http.ResponseWriter, r *http.Request) {
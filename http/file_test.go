@@ -2,9 +2,11 @@ package http
 
 import (
 	"fmt"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 
+	"stageai.tech/sunshine/sunshine/controller"
 	"stageai.tech/sunshine/sunshine/models"
 )
 
@@ -42,3 +44,31 @@ func TestGenerateFilename(t *testing.T) {
 	}
 
 }
+
+func TestFileRequest(t *testing.T) {
+	cases := []struct {
+		method, rng string
+		want        controller.FileRequest
+	}{
+		{"GET", "", controller.WholeFile},
+		{"GET", "bytes=0-", controller.WholeFile},
+		{"GET", "bytes=0-1023", controller.WholeFile},
+		{"GET", "bytes=-1024", controller.WholeFile},
+		{"GET", "bytes=1024-2047, 0-1023", controller.WholeFile},
+		{"GET", "items=1024-", controller.WholeFile},
+		{"GET", "bytes=1024-", controller.FilePart},
+		{"GET", "bytes=1024-2047, 4096-", controller.FilePart},
+		{"HEAD", "", controller.FileHeaders},
+		{"HEAD", "bytes=1024-", controller.FileHeaders},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, "/project/x/file.pdf", nil)
+		if c.rng != "" {
+			r.Header.Set("Range", c.rng)
+		}
+		if got := fileRequest(r); got != c.want {
+			t.Errorf("fileRequest(%s %q) = %v; want %v", c.method, c.rng, got, c.want)
+		}
+	}
+}
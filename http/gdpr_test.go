@@ -65,7 +65,7 @@ func TestGDPRUpload(t *testing.T) {
 			}
 
 			// head to make sure we can access the file even without downloading it
-			r = loginAs(t, e, nil,
+			r = loginAs(t, e, stores.NewTestAdmin(t, e.UserStore),
 				httptest.NewRequest("HEAD", "/gdpr/"+c.gdID.String()+"/gg.jpg", nil))
 			w = httptest.NewRecorder()
 			router.ServeHTTP(w, r)
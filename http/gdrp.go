@@ -31,6 +31,11 @@ func (g *gdpr) upload(w http.ResponseWriter, r *http.Request) {
 }
 
 func (g *gdpr) getFile(w http.ResponseWriter, r *http.Request) {
+	// the files of the requests, e.g. the exported data, are personal data
+	if _, err := g.c.Get(r.Context(), extractUUID(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
 	getFile(w, r, g.store, g.uploadPath)
 }
 
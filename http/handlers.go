@@ -26,6 +26,9 @@ const (
 	uuidRe      = "{id:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}"
 	countHeader = "X-Documents-Count"
 	filenameRe  = `{filename:.+}`
+
+	// duplicatesHeader lists the likely duplicates of a created record.
+	duplicatesHeader = "X-Possible-Duplicates"
 )
 
 var (
@@ -51,6 +54,10 @@ func New(env *services.Env) http.Handler {
 		gd     = newGDPR(env)
 		gqlh   = graphql.Handler(env)
 		fa     = newForfaitingApplication(env)
+		tsk    = newTask(env)
+		room   = newDataRoom(env)
+		rep    = newReport(env)
+		cmt    = newComment(env)
 		mux    = mux.NewRouter().StrictSlash(true).UseEncodedPath()
 	)
 
@@ -141,6 +148,9 @@ func New(env *services.Env) http.Handler {
 		"GET":  http.HandlerFunc(asset.list),
 		"POST": http.HandlerFunc(asset.create),
 	})
+	mux.Handle("/asset/geojson", handlers.MethodHandler{
+		"GET": http.HandlerFunc(asset.geojson),
+	})
 	mux.Handle("/asset/"+uuidRe, handlers.MethodHandler{
 		"GET": http.HandlerFunc(asset.get),
 		"PUT": http.HandlerFunc(asset.update),
@@ -231,6 +241,36 @@ func New(env *services.Env) http.Handler {
 		"GET": http.HandlerFunc(contr.getIndoorClima),
 		"PUT": http.HandlerFunc(contr.updateIndoorClima),
 	})
+	mux.Handle("/contract/template/"+uuidRe+"/preview/pdf",
+		handlers.MethodHandler{
+			"GET": http.HandlerFunc(contr.previewTemplatePDF),
+		},
+	)
+	mux.Handle("/contract/template/"+uuidRe+"/preview/tex",
+		handlers.MethodHandler{
+			"GET": http.HandlerFunc(contr.previewTemplateTeX),
+		},
+	)
+	mux.Handle("/project/"+uuidRe+"/indoorclima/download/english",
+		handlers.MethodHandler{
+			"GET": http.HandlerFunc(contr.downloadIndoorClimaEnglishPDF),
+		},
+	)
+	mux.Handle("/project/"+uuidRe+"/indoorclima/download/native",
+		handlers.MethodHandler{
+			"GET": http.HandlerFunc(contr.downloadIndoorClimaNativePDF),
+		},
+	)
+	mux.Handle("/project/"+uuidRe+"/indoorclima/tex/english",
+		handlers.MethodHandler{
+			"GET": http.HandlerFunc(contr.downloadIndoorClimaEnglishTeX),
+		},
+	)
+	mux.Handle("/project/"+uuidRe+"/indoorclima/tex/native",
+		handlers.MethodHandler{
+			"GET": http.HandlerFunc(contr.downloadIndoorClimaNativeTeX),
+		},
+	)
 	mux.Handle("/project/"+uuidRe+`/markdown`,
 		handlers.MethodHandler{
 			"GET": http.HandlerFunc(contr.getMarkdown),
@@ -272,6 +312,15 @@ func New(env *services.Env) http.Handler {
 		"GET": http.HandlerFunc(stats.getCountryStats),
 	})
 
+	mux.Handle("/meeting/"+uuidRe+".ics", handlers.MethodHandler{
+		"GET": http.HandlerFunc(meet.calendar),
+	})
+	mux.Handle("/calendar/"+uuidRe+".ics", handlers.MethodHandler{
+		"GET": http.HandlerFunc(meet.feed),
+	})
+	mux.Handle("/meeting/"+uuidRe+"/minutes/pdf", handlers.MethodHandler{
+		"GET": http.HandlerFunc(meet.minutesPDF),
+	})
 	mux.Handle("/meeting/"+uuidRe+"/upload", handlers.MethodHandler{
 		"POST": http.HandlerFunc(meet.upload),
 	})
@@ -281,6 +330,22 @@ func New(env *services.Env) http.Handler {
 		"HEAD":   http.HandlerFunc(meet.getFile),
 	})
 
+	mux.Handle("/dataroom/"+uuidRe+"/contract/{language:english|native}", handlers.MethodHandler{
+		"GET": http.HandlerFunc(room.downloadContract),
+	})
+	mux.Handle("/dataroom/"+uuidRe+"/{attachment:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", handlers.MethodHandler{
+		"GET": http.HandlerFunc(room.download),
+	})
+
+	mux.Handle("/report/"+uuidRe, handlers.MethodHandler{
+		"GET": http.HandlerFunc(rep.download),
+	})
+
+	mux.Handle("/comment/"+uuidRe+"/"+filenameRe, handlers.MethodHandler{
+		"GET":  http.HandlerFunc(cmt.getFile),
+		"HEAD": http.HandlerFunc(cmt.getFile),
+	})
+
 	mux.Handle("/forfaitinga/"+uuidRe+"/upload", handlers.MethodHandler{
 		"POST": http.HandlerFunc(fa.upload),
 	})
@@ -290,6 +355,15 @@ func New(env *services.Env) http.Handler {
 		"HEAD":   http.HandlerFunc(fa.getFile),
 	})
 
+	mux.Handle("/task/"+uuidRe+"/upload", handlers.MethodHandler{
+		"POST": http.HandlerFunc(tsk.upload),
+	})
+	mux.Handle("/task/"+uuidRe+"/"+filenameRe, handlers.MethodHandler{
+		"DELETE": http.HandlerFunc(tsk.delFile),
+		"GET":    http.HandlerFunc(tsk.getFile),
+		"HEAD":   http.HandlerFunc(tsk.getFile),
+	})
+
 	mux.Handle("/workphase/"+uuidRe, handlers.MethodHandler{
 		"GET": http.HandlerFunc(wp.getWP),
 	})
@@ -3,6 +3,7 @@ package http
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/controller"
 )
@@ -31,3 +32,34 @@ func (ch *contractHandler) updateIndoorClima(w http.ResponseWriter, r *http.Requ
 
 	json.NewEncoder(w).Encode(doc)
 }
+
+func (ch *contractHandler) downloadIndoorClimaEnglishPDF(w http.ResponseWriter, r *http.Request) {
+	ch.downloadIndoorClima(w, r, "english", "pdf")
+}
+
+func (ch *contractHandler) downloadIndoorClimaNativePDF(w http.ResponseWriter, r *http.Request) {
+	ch.downloadIndoorClima(w, r, "native", "pdf")
+}
+
+func (ch *contractHandler) downloadIndoorClimaEnglishTeX(w http.ResponseWriter, r *http.Request) {
+	ch.downloadIndoorClima(w, r, "english", "tex")
+}
+
+func (ch *contractHandler) downloadIndoorClimaNativeTeX(w http.ResponseWriter, r *http.Request) {
+	ch.downloadIndoorClima(w, r, "native", "tex")
+}
+
+func (ch *contractHandler) downloadIndoorClima(w http.ResponseWriter, r *http.Request, language, format string) {
+	id := mustExtractUUID(r)
+
+	file, name, err := ch.c.DownloadIndoorClima(r.Context(), id, language, format)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Del("Content-Type")
+	w.Header().Set("Content-Disposition", "attachment; filename="+name)
+	http.ServeContent(w, r, name, time.Now(), file)
+}
@@ -258,3 +258,51 @@ func testUpdateIndoorClimaHiddenColumn(t *testing.T) {
 		t.Fatalf("The user shouldn't be able to change Created_At column ; got %v", createdAt)
 	}
 }
+
+func TestDownloadIndoorClima(t *testing.T) {
+	env, cleanup := newTestEnv(t)
+	defer cleanup()
+
+	admin := stores.NewTestAdmin(t, env.UserStore)
+	prj := stores.NewTestProject(t, env.ProjectStore)
+
+	router := New(env)
+
+	cases := []struct {
+		name   string
+		target string
+	}{
+		{
+			name:   "download/native/pdf",
+			target: fmt.Sprintf("/project/%v/indoorclima/download/native", prj.ID),
+		},
+		{
+			name:   "download/english/pdf",
+			target: fmt.Sprintf("/project/%v/indoorclima/download/english", prj.ID),
+		},
+		{
+			name:   "download/native/tex",
+			target: fmt.Sprintf("/project/%v/indoorclima/tex/native", prj.ID),
+		},
+		{
+			name:   "download/english/tex",
+			target: fmt.Sprintf("/project/%v/indoorclima/tex/english", prj.ID),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := loginAs(
+				t,
+				env,
+				admin,
+				httptest.NewRequest("GET", c.target, nil),
+			)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			compareRespCode(t, http.StatusOK, w.Code, w.Body.String())
+		})
+	}
+}
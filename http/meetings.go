@@ -2,9 +2,13 @@ package http
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/ical"
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/services"
 	"stageai.tech/sunshine/sunshine/stores"
@@ -16,6 +20,7 @@ import (
 type meeting struct {
 	store   stores.Store
 	session sessions.Store
+	c       *controller.Meeting
 
 	uploadPath string
 }
@@ -24,6 +29,7 @@ func newMeeting(env *services.Env) *meeting {
 	return &meeting{
 		session: env.SessionStore,
 		store:   env.MeetingsStore,
+		c:       controller.NewMeeting(env),
 
 		uploadPath: env.Paths.Uploads,
 	}
@@ -95,3 +101,43 @@ func (m *meeting) delFile(w http.ResponseWriter, r *http.Request) {
 		delFile(w, r, m.store)
 	}
 }
+
+func (m *meeting) calendar(w http.ResponseWriter, r *http.Request) {
+	cal, err := m.c.Calendar(r.Context(), mustExtractUUID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeCalendar(w, cal, "meeting.ics")
+}
+
+// feed serves the calendar feed of the user with the token in the URL, as
+// calendar applications subscribe without a session.
+func (m *meeting) feed(w http.ResponseWriter, r *http.Request) {
+	cal, err := m.c.Feed(r.Context(), mustExtractUUID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeCalendar(w, cal, "meetings.ics")
+}
+
+// minutesPDF serves the minutes of the meeting as PDF.
+func (m *meeting) minutesPDF(w http.ResponseWriter, r *http.Request) {
+	file, name, err := m.c.MinutesPDF(r.Context(), mustExtractUUID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Del("Content-Type")
+	w.Header().Set("Content-Disposition", "attachment; filename="+name)
+	http.ServeContent(w, r, name, time.Now(), file)
+}
+
+func writeCalendar(w http.ResponseWriter, cal *ical.Calendar, filename string) {
+	w.Header().Set("Content-Type", cal.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%s", filename))
+	io.WriteString(w, cal.String())
+}
@@ -1,15 +1,9 @@
 package http
 
 import (
-	"fmt"
-	"io"
-	"mime"
 	"net/http"
 	"net/url"
 	"path"
-	"path/filepath"
-	"strconv"
-	"strings"
 
 	"stageai.tech/sunshine/sunshine/controller"
 	"stageai.tech/sunshine/sunshine/services"
@@ -79,23 +73,12 @@ func (wp *workphase) getFileWP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	att, f, err := wp.c.GetFileWP(r.Context(), id, fname)
+	att, f, err := wp.c.GetFileWP(r.Context(), id, fname, fileRequest(r))
 	if err != nil {
 		writeError(w, r, err)
 		return
 	}
-	defer f.Close()
-
-	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(fname)), "image/") {
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fname))
-
-	}
-	w.Header().Set("Content-Type", att.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
-
-	if r.Method != "HEAD" {
-		io.Copy(w, f)
-	}
+	serveAttachment(w, r, att, f, fname)
 }
 
 func (wp *workphase) delFileWP(w http.ResponseWriter, r *http.Request) {
@@ -172,23 +155,12 @@ func (mp *monitoringphase) getFileMP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	att, f, err := mp.c.GetFileMP(r.Context(), id, fname)
+	att, f, err := mp.c.GetFileMP(r.Context(), id, fname, fileRequest(r))
 	if err != nil {
 		writeError(w, r, err)
 		return
 	}
-	defer f.Close()
-
-	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(fname)), "image/") {
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fname))
-
-	}
-	w.Header().Set("Content-Type", att.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
-
-	if r.Method != "HEAD" {
-		io.Copy(w, f)
-	}
+	serveAttachment(w, r, att, f, fname)
 }
 
 func (mp *monitoringphase) delFileMP(w http.ResponseWriter, r *http.Request) {
@@ -1,12 +1,19 @@
 package http
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"mime"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/dedup"
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/sentry"
 	"stageai.tech/sunshine/sunshine/stores"
@@ -55,6 +62,62 @@ func mustExtractUUID(r *http.Request) uuid.UUID {
 	return uuid.Must(uuid.Parse(mux.Vars(r)["id"]))
 }
 
+// fileRequest returns what of a file the request asks for: only its headers
+// on HEAD, the rest of a download on ranges all starting after its
+// beginning, else all of it.
+func fileRequest(r *http.Request) controller.FileRequest {
+	switch {
+	case r.Method == http.MethodHead:
+		return controller.FileHeaders
+	case laterRanges(r.Header.Get("Range")):
+		return controller.FilePart
+	}
+	return controller.WholeFile
+}
+
+// laterRanges tells whether the byte ranges of the Range header all start
+// after the beginning of the file. Suffix ranges, which may cover all of the
+// file, and malformed ranges do not.
+func laterRanges(rng string) bool {
+	specs := strings.TrimPrefix(rng, "bytes=")
+	if specs == rng {
+		return false
+	}
+	for _, spec := range strings.Split(specs, ",") {
+		start := strings.TrimSpace(strings.SplitN(spec, "-", 2)[0])
+		if n, err := strconv.ParseInt(start, 10, 64); err != nil || n <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// serveAttachment writes and closes the file of the attachment, as a
+// download unless it is an image. Watermarked files are generated per
+// download, so they are served whole and their size is unknown until then.
+// The file is nil on HEAD requests.
+func serveAttachment(w http.ResponseWriter, r *http.Request, att *models.Attachment, f controller.File, fname string) {
+	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(fname)), "image/") {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fname))
+	}
+	w.Header().Set("Content-Type", att.ContentType)
+
+	if f == nil {
+		if att.Watermark == models.WatermarkNone {
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	defer f.Close()
+
+	if att.Watermark != models.WatermarkNone {
+		r.Header.Del("Range")
+	}
+	http.ServeContent(w, r, fname, time.Time{}, f)
+}
+
 // writeError writes given error to w via http.Error with status code deduced
 // by inspecting err. If deducing fails uses http.StatusInternalServerError as
 // status.
@@ -127,3 +190,36 @@ func parseNullableUUID(q *stores.Filter, k, v string) {
 	}
 
 }
+
+// setDuplicates sets the header listing the likely duplicates of the created
+// record id. Failing to find them does not fail the request.
+func setDuplicates(w http.ResponseWriter, r *http.Request, id uuid.UUID,
+	find func(context.Context, uuid.UUID) ([]dedup.Match, error)) {
+	matches, err := find(r.Context(), id)
+	if err != nil {
+		sentry.Report(err, sentry.CaptureRequest(r))
+		return
+	}
+
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.B.String()
+	}
+	if len(ids) > 0 {
+		w.Header().Set(duplicatesHeader, strings.Join(ids, ","))
+	}
+}
+
+// redirectMerged redirects requests for a record merged into another one to
+// the latter and reports whether it did.
+func redirectMerged(w http.ResponseWriter, r *http.Request, id uuid.UUID, dup *controller.Duplicate) bool {
+	target, err := dup.Redirect(r.Context(), id)
+	if err != nil {
+		return false
+	}
+
+	u := *r.URL
+	u.Path = strings.Replace(u.Path, id.String(), target.String(), 1)
+	http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	return true
+}
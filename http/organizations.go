@@ -2,16 +2,12 @@ package http
 
 import (
 	"encoding/json"
-	"fmt"
 	"io"
-	"mime"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
-	"path/filepath"
 	"strconv"
-	"strings"
 
 	"stageai.tech/sunshine/sunshine/controller"
 	"stageai.tech/sunshine/sunshine/services"
@@ -21,14 +17,16 @@ import (
 )
 
 type org struct {
-	s sessions.Store
-	c *controller.Organization
+	s   sessions.Store
+	c   *controller.Organization
+	dup *controller.Duplicate
 }
 
 func newOrg(env *services.Env) *org {
 	return &org{
-		s: env.SessionStore,
-		c: controller.NewOrganization(env),
+		s:   env.SessionStore,
+		c:   controller.NewOrganization(env),
+		dup: controller.NewDuplicate(env),
 	}
 }
 
@@ -41,13 +39,18 @@ func (o *org) create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Add("Location", path.Join(r.URL.String(), doc.ID.String()))
+	setDuplicates(w, r, doc.ID, o.dup.Organization)
 	json.NewEncoder(w).Encode(doc)
 }
 
 // Get existing organization.
 func (o *org) get(w http.ResponseWriter, r *http.Request) {
-	doc, deps, err := o.c.Get(r.Context(), mustExtractUUID(r))
+	id := mustExtractUUID(r)
+	doc, deps, err := o.c.Get(r.Context(), id)
 	if err != nil {
+		if redirectMerged(w, r, id, o.dup) {
+			return
+		}
 		writeError(w, r, err)
 		return
 	}
@@ -112,23 +115,12 @@ func (o *org) getFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	att, f, err := o.c.GetFile(r.Context(), id, fname)
+	att, f, err := o.c.GetFile(r.Context(), id, fname, fileRequest(r))
 	if err != nil {
 		writeError(w, r, err)
 		return
 	}
-	defer f.Close()
-
-	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(fname)), "image/") {
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fname))
-
-	}
-	w.Header().Set("Content-Type", att.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
-
-	if r.Method != "HEAD" {
-		io.Copy(w, f)
-	}
+	serveAttachment(w, r, att, f, fname)
 }
 
 func (o *org) delFile(w http.ResponseWriter, r *http.Request) {
@@ -1,16 +1,12 @@
 package http
 
 import (
-	"fmt"
 	"io"
-	"mime"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
-	"path/filepath"
 	"strconv"
-	"strings"
 
 	"stageai.tech/sunshine/sunshine/controller"
 	"stageai.tech/sunshine/sunshine/services"
@@ -123,23 +119,12 @@ func (p *proj) getFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	att, f, err := p.c.GetFile(r.Context(), id, fname)
+	att, f, err := p.c.GetFile(r.Context(), id, fname, fileRequest(r))
 	if err != nil {
 		writeError(w, r, err)
 		return
 	}
-	defer f.Close()
-
-	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(fname)), "image/") {
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fname))
-
-	}
-	w.Header().Set("Content-Type", att.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
-
-	if r.Method != "HEAD" {
-		io.Copy(w, f)
-	}
+	serveAttachment(w, r, att, f, fname)
 }
 
 func (p *proj) delFile(w http.ResponseWriter, r *http.Request) {
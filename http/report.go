@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/services"
+)
+
+type report struct {
+	c *controller.Report
+}
+
+func newReport(env *services.Env) *report {
+	return &report{c: controller.NewReport(env)}
+}
+
+// download serves the file of a run of a scheduled report.
+func (rep *report) download(w http.ResponseWriter, r *http.Request) {
+	att, file, err := rep.c.Download(r.Context(), mustExtractUUID(r), fileRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	serveAttachment(w, r, att, file, att.Name)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+
+	"stageai.tech/sunshine/sunshine/controller"
+	"stageai.tech/sunshine/sunshine/services"
+
+	"github.com/gorilla/mux"
+)
+
+type task struct {
+	c *controller.Task
+}
+
+func newTask(e *services.Env) *task {
+	return &task{c: controller.NewTask(e)}
+}
+
+func (t *task) upload(w http.ResponseWriter, r *http.Request) {
+	id := mustExtractUUID(r)
+
+	file, fheader, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	form := controller.RequestForm{
+		FileHeader: fheader,
+		File:       file,
+		UploadType: r.FormValue("upload-type"),
+		Comment:    r.FormValue("comment"),
+	}
+
+	if err := t.c.UploadFile(r.Context(), id, form); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	location := *r.URL
+	base, _ := path.Split(location.Path) // removes '/upload' from the end
+	location.Path = path.Join(base, fheader.Filename)
+	w.Header().Set("Location", location.String())
+}
+
+func (t *task) getFile(w http.ResponseWriter, r *http.Request) {
+	fname, err := url.PathUnescape(mux.Vars(r)["filename"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	att, f, err := t.c.GetFile(r.Context(), mustExtractUUID(r), fname, fileRequest(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	serveAttachment(w, r, att, f, fname)
+}
+
+func (t *task) delFile(w http.ResponseWriter, r *http.Request) {
+	fname, err := url.PathUnescape(mux.Vars(r)["filename"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := t.c.DeleteFile(r.Context(), mustExtractUUID(r), fname); err != nil {
+		writeError(w, r, err)
+		return
+	}
+}
@@ -2,15 +2,10 @@ package http
 
 import (
 	"encoding/json"
-	"fmt"
-	"io"
-	"mime"
 	"net/http"
 	"net/url"
 	"path"
-	"path/filepath"
 	"strconv"
-	"strings"
 
 	"stageai.tech/sunshine/sunshine/controller"
 	"stageai.tech/sunshine/sunshine/services"
@@ -20,16 +15,18 @@ import (
 )
 
 type user struct {
-	ss sessions.Store
-	c  *controller.User
+	ss  sessions.Store
+	c   *controller.User
+	dup *controller.Duplicate
 }
 
 func newUser(env *services.Env) *user {
 	uc := controller.NewUser(env)
 
 	return &user{
-		ss: env.SessionStore,
-		c:  uc,
+		ss:  env.SessionStore,
+		c:   uc,
+		dup: controller.NewDuplicate(env),
 	}
 }
 
@@ -59,8 +56,12 @@ func (h *user) create(w http.ResponseWriter, r *http.Request) {
 
 // Get existing user.
 func (h *user) get(w http.ResponseWriter, r *http.Request) {
-	doc, deps, err := h.c.Get(r.Context(), mustExtractUUID(r))
+	id := mustExtractUUID(r)
+	doc, deps, err := h.c.Get(r.Context(), id)
 	if err != nil {
+		if redirectMerged(w, r, id, h.dup) {
+			return
+		}
 		w.WriteHeader(http.StatusNotFound)
 	}
 
@@ -114,23 +115,12 @@ func (h *user) getFile(w http.ResponseWriter, r *http.Request) {
 	}
 	id := mustExtractUUID(r)
 
-	att, f, err := h.c.GetFile(r.Context(), id, filename)
+	att, f, err := h.c.GetFile(r.Context(), id, filename, fileRequest(r))
 	if err != nil {
 		writeError(w, r, err)
 		return
 	}
-	defer f.Close()
-
-	if !strings.HasPrefix(mime.TypeByExtension(filepath.Ext(filename)), "image/") {
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-
-	}
-	w.Header().Set("Content-Type", att.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
-
-	if r.Method != "HEAD" {
-		io.Copy(w, f)
-	}
+	serveAttachment(w, r, att, f, filename)
 }
 
 func (h *user) delFile(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,187 @@
+// Package ical encodes meetings as iCalendar (RFC 5545) events, e.g. to be
+// sent as invitations or subscribed to as a feed.
+package ical
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// prodID identifies the product which created the calendar.
+const prodID = "-//SunSHiNE//Sunshine//EN"
+
+// lineLength is the maximum length of a content line in octets, excluding
+// the line break.
+const lineLength = 75
+
+// Method is the iTIP method of a calendar, i.e. what the recipient is asked
+// to do with its events.
+type Method string
+
+const (
+	// MethodPublish is for calendars only to be looked at, e.g. feeds.
+	MethodPublish Method = "PUBLISH"
+
+	// MethodRequest invites the attendees to an event or updates it.
+	MethodRequest Method = "REQUEST"
+
+	// MethodCancel cancels an event.
+	MethodCancel Method = "CANCEL"
+)
+
+// Status is the status of an event.
+type Status string
+
+const (
+	StatusConfirmed Status = "CONFIRMED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// Event is a scheduled event, i.e. a VEVENT component.
+type Event struct {
+	// UID identifies the event across its updates.
+	UID string
+
+	// Sequence is the revision of the event. It must be increased on each
+	// update sent to the attendees.
+	Sequence int
+
+	// Stamp is when the event was last sent; now if not set.
+	Stamp time.Time
+
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Location    string
+	Description string
+	URL         string
+	Status      Status
+
+	Organizer *mail.Address
+	Attendees []mail.Address
+}
+
+// Calendar is a set of events, i.e. a VCALENDAR object.
+type Calendar struct {
+	// Name of the calendar shown by the calendar applications.
+	Name   string
+	Method Method
+	Events []Event
+}
+
+// String returns the calendar encoded as iCalendar.
+func (c Calendar) String() string {
+	var w writer
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", prodID)
+	w.line("CALSCALE", "GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD", string(c.Method))
+	}
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", Escape(c.Name))
+	}
+
+	now := time.Now()
+	for _, e := range c.Events {
+		stamp := e.Stamp
+		if stamp.IsZero() {
+			stamp = now
+		}
+
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", Escape(e.UID))
+		w.line("SEQUENCE", fmt.Sprint(e.Sequence))
+		w.line("DTSTAMP", timestamp(stamp))
+		w.line("DTSTART", timestamp(e.Start))
+		if !e.End.IsZero() {
+			w.line("DTEND", timestamp(e.End))
+		}
+		w.line("SUMMARY", Escape(e.Summary))
+		if e.Location != "" {
+			w.line("LOCATION", Escape(e.Location))
+		}
+		if e.Description != "" {
+			w.line("DESCRIPTION", Escape(e.Description))
+		}
+		if e.URL != "" {
+			w.line("URL", e.URL)
+		}
+		if e.Status != "" {
+			w.line("STATUS", string(e.Status))
+		}
+		if e.Organizer != nil {
+			w.line("ORGANIZER"+commonName(e.Organizer.Name), "mailto:"+e.Organizer.Address)
+		}
+		for _, a := range e.Attendees {
+			w.line("ATTENDEE"+commonName(a.Name)+";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE",
+				"mailto:"+a.Address)
+		}
+		w.line("END", "VEVENT")
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.String()
+}
+
+// ContentType returns the MIME type of the calendar.
+func (c Calendar) ContentType() string {
+	if c.Method == "" {
+		return "text/calendar; charset=utf-8"
+	}
+	return fmt.Sprintf("text/calendar; charset=utf-8; method=%s", c.Method)
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// Escape escapes a TEXT value.
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// commonName returns the CN parameter for the name, if any. Parameter values
+// cannot contain double quotes, so these are left out.
+func commonName(name string) string {
+	name = strings.ReplaceAll(name, `"`, "")
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf(`;CN="%s"`, name)
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// writer writes content lines folded to lineLength octets and terminated by
+// CRLF.
+type writer struct {
+	strings.Builder
+}
+
+func (w *writer) line(name, value string) {
+	s := name + ":" + value
+	for n := lineLength; len(s) > n; n = lineLength - 1 {
+		// don't split multi-octet characters
+		i := n
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		w.WriteString(s[:i])
+		w.WriteString("\r\n ")
+		s = s[i:]
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
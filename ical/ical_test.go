@@ -0,0 +1,73 @@
+package ical
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCalendar(t *testing.T) {
+	start := time.Date(2020, time.October, 19, 10, 0, 0, 0, time.FixedZone("EEST", 3*60*60))
+	c := Calendar{
+		Method: MethodRequest,
+		Events: []Event{{
+			UID:         "42@sunshine",
+			Sequence:    2,
+			Stamp:       start.Add(-time.Hour),
+			Start:       start,
+			End:         start.Add(time.Hour),
+			Summary:     "Kick-off; residents, owners",
+			Location:    "Brīvības iela 10",
+			Description: "Agenda:\n1. Renovation",
+			Status:      StatusConfirmed,
+			Organizer:   &mail.Address{Name: "SIA \"Renesco\"", Address: "info@renesco.example"},
+			Attendees:   []mail.Address{{Name: "Jānis", Address: "janis@example.com"}},
+		}},
+	}
+	s := c.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"METHOD:REQUEST\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTAMP:20201019T060000Z\r\n",
+		"DTSTART:20201019T070000Z\r\n",
+		"DTEND:20201019T080000Z\r\n",
+		`SUMMARY:Kick-off\; residents\, owners` + "\r\n",
+		`DESCRIPTION:Agenda:\n1. Renovation` + "\r\n",
+		`ORGANIZER;CN="SIA Renesco":mailto:info@renesco.example` + "\r\n",
+		"END:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Expected %q in:\n%s", want, s)
+		}
+	}
+	if got, want := c.ContentType(), "text/calendar; charset=utf-8; method=REQUEST"; got != want {
+		t.Errorf("Expected content type %q, got %q", want, got)
+	}
+}
+
+func TestFolding(t *testing.T) {
+	c := Calendar{Events: []Event{{Summary: strings.Repeat("ā", 100)}}}
+
+	var unfolded strings.Builder
+	for i, l := range strings.Split(strings.TrimSuffix(c.String(), "\r\n"), "\r\n") {
+		if len(l) > lineLength {
+			t.Errorf("Line %d is %d octets long: %q", i, len(l), l)
+		}
+		if !utf8.ValidString(l) {
+			t.Errorf("Line %d splits a character: %q", i, l)
+		}
+		if strings.HasPrefix(l, " ") {
+			unfolded.WriteString(l[1:])
+		} else {
+			unfolded.WriteString("\n" + l)
+		}
+	}
+
+	if !strings.Contains(unfolded.String(), "\nSUMMARY:"+strings.Repeat("ā", 100)+"\n") {
+		t.Errorf("Expected the summary to be unfolded intact, got %q", unfolded.String())
+	}
+}
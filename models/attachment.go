@@ -30,6 +30,10 @@ type Attachment struct {
 	Size int64 `json:"length"`
 
 	Comment string `json:"comment"`
+
+	// Watermark is stamped on the PDF when downloaded, instead of the one
+	// of its project.
+	Watermark Watermark `json:"watermark"`
 }
 
 func (Attachment) TableName() string {
@@ -120,6 +124,9 @@ var UploadTypes = map[string]struct{}{
 	"lear apply":                                                   struct{}{},
 	"proof of transfer":                                            struct{}{},
 	"epc contracts":                                                struct{}{},
+	"residents vote result":                                        struct{}{},
+	"scheduled report":                                             struct{}{},
+	"comment attachment":                                           struct{}{},
 }
 
 // Scan implements the database/sql.Scanner interface.
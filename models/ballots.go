@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Flat is an apartment of a residential building. Its verified resident
+// votes for it in the residents' ballots.
+type Flat struct {
+	Value
+
+	Asset  uuid.UUID `json:"asset" gorm:"column:asset_id"`
+	Number string    `json:"number" validate:"required"`
+	Area   float64   `json:"area"`
+
+	// Resident is the user voting for the flat, once verified by the
+	// managers of the building.
+	Resident   *uuid.UUID `json:"resident" gorm:"column:resident_id; type:uuid; null"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	VerifiedBy *uuid.UUID `json:"verified_by" gorm:"type:uuid; null"`
+}
+
+func (Flat) TableName() string { return "flats" }
+
+// Verified reports whether the flat has a verified resident.
+func (f Flat) Verified() bool {
+	return f.Resident != nil && f.VerifiedAt != nil
+}
+
+// BallotBasis is what the votes of the flats are weighted by.
+type BallotBasis string
+
+const (
+	// BallotBasisFlats gives each flat one vote.
+	BallotBasisFlats BallotBasis = "flats"
+
+	// BallotBasisArea weights the vote of each flat by its area.
+	BallotBasisArea BallotBasis = "area"
+)
+
+func (b *BallotBasis) Scan(value interface{}) error {
+	var v, ok = value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid ballot basis: %v", v)
+	}
+
+	*b = BallotBasis(v)
+	return nil
+}
+
+func (b BallotBasis) Value() (driver.Value, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return string(b), nil
+}
+
+// Ballot is a vote of the residents of a building, e.g. to approve its
+// renovation.
+type Ballot struct {
+	Value
+
+	Asset   uuid.UUID  `json:"asset" gorm:"column:asset_id"`
+	Project *uuid.UUID `json:"project" gorm:"column:project_id; type:uuid; null"`
+
+	Question string         `json:"question" validate:"required"`
+	Options  pq.StringArray `json:"options" gorm:"type:text[]"`
+	Basis    BallotBasis    `json:"basis"`
+
+	// Quorum is the share of the flats, or their area, which must vote for
+	// the ballot to be valid.
+	Quorum float64 `json:"quorum"`
+
+	// Majority is the share of the votes cast, or of their area, an option
+	// must exceed to win.
+	Majority float64 `json:"majority"`
+
+	Deadline time.Time  `json:"deadline"`
+	Author   *uuid.UUID `json:"author" gorm:"type:uuid; null"`
+
+	// ClosedAt is when the result was counted. Digest is the SHA-256 hash of
+	// the ballot and its votes at that time, printed on the result report.
+	ClosedAt *time.Time `json:"closed_at"`
+	Digest   string     `json:"digest"`
+}
+
+func (Ballot) TableName() string { return "ballots" }
+
+// Open reports whether the ballot accepts votes at the time.
+func (b Ballot) Open(now time.Time) bool {
+	return b.ClosedAt == nil && now.Before(b.Deadline)
+}
+
+// Vote is the choice of a flat in a ballot.
+type Vote struct {
+	Value
+
+	Ballot uuid.UUID `json:"ballot" gorm:"column:ballot_id"`
+	Flat   uuid.UUID `json:"flat" gorm:"column:flat_id"`
+
+	// User is the resident who voted for the flat.
+	User *uuid.UUID `json:"user" gorm:"column:user_id; type:uuid; null"`
+
+	// Option is the index of the chosen option of the ballot.
+	Option int `json:"option"`
+}
+
+func (Vote) TableName() string { return "votes" }
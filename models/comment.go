@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// CommentTarget is what in a project a comment thread is about.
+type CommentTarget string
+
+const (
+	CommentOnProject       CommentTarget = "project"
+	CommentOnWorkPhase     CommentTarget = "work_phase"
+	CommentOnFAReview      CommentTarget = "fa_review"
+	CommentOnContractTable CommentTarget = "contract_table"
+	CommentOnContractField CommentTarget = "contract_field"
+)
+
+// Valid reports an error for unknown targets.
+func (t CommentTarget) Valid() error {
+	switch t {
+	case CommentOnProject, CommentOnWorkPhase, CommentOnFAReview, CommentOnContractTable, CommentOnContractField:
+		return nil
+	}
+	return fmt.Errorf("invalid comment target %q", string(t))
+}
+
+// SuggestionStatus is the state of a change suggested in a comment on a
+// contract.
+type SuggestionStatus string
+
+const (
+	SuggestionOpen     SuggestionStatus = "open"
+	SuggestionAccepted SuggestionStatus = "accepted"
+	SuggestionRejected SuggestionStatus = "rejected"
+)
+
+// ProjectCommentRevision is the content of a project comment before it was
+// edited.
+type ProjectCommentRevision struct {
+	Value
+
+	CommentID uuid.UUID  `json:"comment"`
+	Content   string     `json:"content"`
+	EditedBy  *uuid.UUID `json:"edited_by" gorm:"type:uuid; null"`
+}
+
+func (ProjectCommentRevision) TableName() string { return "project_comment_revisions" }
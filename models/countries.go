@@ -2,9 +2,13 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"stageai.tech/sunshine/sunshine/config"
 )
@@ -76,31 +80,70 @@ func Countries() []Country {
 		CountryTurkey, CountryUkraine, CountryUK, CountryVatican}
 }
 
-func (c Country) IsConsortium() bool {
-	switch c {
-	case CountryAustria, CountryBulgaria, CountryLatvia, CountryPoland, CountryRomania, CountrySlovakia:
-		return true
-	default:
-		return false
+func (c Country) Valid() error {
+	for _, co := range Countries() {
+		if co == c {
+			return nil
+		}
 	}
+	return errors.New("invalid country")
 }
 
-func (c Country) HasAdaptedContract() bool {
-	switch c {
-	case CountryBulgaria, CountryRomania:
-		return true
-	default:
-		return false
+// countriesCatalog is the file in the LaTeX path with the properties of the
+// countries. The contract templates of the countries are registered in it
+// too.
+const countriesCatalog = "countries.json"
+
+var (
+	countriesOnce sync.Once
+	consortium    map[Country]bool
+)
+
+// Consortium reports whether c is a country of the consortium, whose staff
+// handle the projects in it. Projects elsewhere are handled by the admin
+// network manager.
+func (c Country) Consortium() bool {
+	countriesOnce.Do(loadCountries)
+	return consortium[c]
+}
+
+// ConsortiumCountries returns the countries of the consortium in the order of
+// Countries.
+func ConsortiumCountries() []Country {
+	var result []Country
+	for _, c := range Countries() {
+		if c.Consortium() {
+			result = append(result, c)
+		}
 	}
+	return result
 }
 
-func (c Country) Valid() error {
-	for _, co := range Countries() {
-		if co == c {
-			return nil
+// loadCountries reads the properties of the countries out of the countries
+// catalog. It panics if the catalog can't be read, as does loading the
+// contract templates registered in it.
+func loadCountries() {
+	path := filepath.Join(config.Load().Paths.LaTeX, countriesCatalog)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(fmt.Sprintf("load countries: %v", err))
+	}
+
+	var catalog struct {
+		Countries map[Country]struct {
+			Consortium bool `json:"consortium"`
+		} `json:"countries"`
+	}
+	if err := json.Unmarshal(b, &catalog); err != nil {
+		panic(fmt.Sprintf("load countries: %s: %v", countriesCatalog, err))
+	}
+
+	consortium = make(map[Country]bool)
+	for c, p := range catalog.Countries {
+		if p.Consortium {
+			consortium[c] = true
 		}
 	}
-	return errors.New("invalid country")
 }
 
 // Scan implements the database/sql.Scanner interface.
@@ -124,17 +167,6 @@ func (c *Country) Scan(value interface{}) error {
 func (c Country) String() string               { return strings.Title(strings.ToLower(string(c))) }
 func (c Country) Value() (driver.Value, error) { return c.String(), nil }
 
-// LegalCountry returns the country of the project. If the project's
-// country is not within the consortium, fallback to latvian. All
-// results are formatted with toLower case.
-func (c Country) LegalCountry() string {
-	if c.IsConsortium() {
-		return c.String()
-	}
-
-	return CountryLatvia.String()
-}
-
 // TODO: With the completion of #522 issue the above enum should become obsolete or somewhat changed.
 // Also the below type CountryVat should become the only 'type Country'. It is done this way here
 // not to introduce too many refactoring in addition to adding the new functionality and not make
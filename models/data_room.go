@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataRoom is the read-only package of documents and figures of a project
+// shared with its investors.
+type DataRoom struct {
+	Value
+
+	Project     uuid.UUID `json:"project" gorm:"column:project_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+
+	// IncludeContract offers the contract of the project as PDF.
+	IncludeContract bool `json:"include_contract"`
+
+	// Watermark stamps the PDFs downloaded by the investors with their
+	// name and the time of the download.
+	Watermark bool `json:"watermark"`
+
+	Documents []DataRoomDocument `json:"documents" gorm:"foreignkey:DataRoomID;PRELOAD:true"`
+	Grants    []DataRoomGrant    `json:"grants" gorm:"foreignkey:DataRoomID;PRELOAD:true"`
+}
+
+func (DataRoom) TableName() string { return "data_rooms" }
+
+// DataRoomDocument is an attachment of the project, its work or monitoring
+// phase or its forfaiting application selected for the data room.
+type DataRoomDocument struct {
+	Value
+
+	DataRoomID uuid.UUID `json:"data_room"`
+	Attachment uuid.UUID `json:"attachment" gorm:"column:attachment_id"`
+	Title      string    `json:"title"`
+}
+
+func (DataRoomDocument) TableName() string { return "data_room_documents" }
+
+// DataRoomGrant gives an investor access to the data room until it expires,
+// if ever.
+type DataRoomGrant struct {
+	Value
+
+	DataRoomID uuid.UUID  `json:"data_room"`
+	Investor   uuid.UUID  `json:"investor" gorm:"column:investor_id"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	GrantedBy  *uuid.UUID `json:"granted_by" gorm:"type:uuid; null"`
+}
+
+func (DataRoomGrant) TableName() string { return "data_room_grants" }
+
+// ActiveAt reports whether the grant has not expired at the time.
+func (g DataRoomGrant) ActiveAt(now time.Time) bool {
+	return g.ExpiresAt == nil || now.Before(*g.ExpiresAt)
+}
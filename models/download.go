@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Watermark is stamped across the pages of a downloaded PDF along with who
+// downloaded it and when.
+type Watermark string
+
+const (
+	WatermarkNone         Watermark = ""
+	WatermarkDraft        Watermark = "draft"
+	WatermarkConfidential Watermark = "confidential"
+)
+
+// Valid reports an error for unknown watermarks.
+func (w Watermark) Valid() error {
+	switch w {
+	case WatermarkNone, WatermarkDraft, WatermarkConfidential:
+		return nil
+	}
+	return fmt.Errorf("invalid watermark %q", string(w))
+}
+
+// Stamp returns the text stamped across the pages.
+func (w Watermark) Stamp() string {
+	return strings.ToUpper(string(w))
+}
+
+// Download records who downloaded an attachment or a contract and when.
+type Download struct {
+	Value
+
+	User *uuid.UUID `json:"user" gorm:"column:user_id; type:uuid; null"`
+
+	// Project is the project the file belongs to, if any.
+	Project *uuid.UUID `json:"project" gorm:"column:project_id; type:uuid; null"`
+
+	// Owner is the entity the file is attached to, or the project of a
+	// contract.
+	Owner      uuid.UUID  `json:"owner" gorm:"column:owner_id"`
+	Attachment *uuid.UUID `json:"attachment" gorm:"column:attachment_id; type:uuid; null"`
+	Name       string     `json:"name"`
+
+	// TemplateVersion and ContractUpdatedAt identify the version of a
+	// downloaded contract.
+	TemplateVersion   *int       `json:"template_version"`
+	ContractUpdatedAt *time.Time `json:"contract_updated_at"`
+
+	// Watermark is the one stamped on the PDF, if any.
+	Watermark Watermark `json:"watermark"`
+
+	// DataRoom is the data room the file was downloaded from, if any.
+	DataRoom *uuid.UUID `json:"data_room" gorm:"column:data_room_id; type:uuid; null"`
+}
+
+func (Download) TableName() string { return "downloads" }
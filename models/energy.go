@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"stageai.tech/sunshine/sunshine/config"
+
+	"github.com/google/uuid"
+)
+
+// EnergyFactor holds the conversion factors used to turn saved final energy
+// into avoided CO2 emissions and primary energy for given country and heating
+// type. Factors are effective-dated, so older calculations stay reproducible
+// when national values change.
+type EnergyFactor struct {
+	Value
+
+	Country     Country `json:"country" validate:"required"`
+	HeatingType Heating `json:"heating_type" validate:"required"`
+
+	// CO2 is the emission factor in tonnes CO2 per MWh of final energy.
+	CO2 float64 `json:"co2" gorm:"column:co2"`
+
+	// PrimaryEnergy is the non-dimensional primary energy factor.
+	PrimaryEnergy float64 `json:"primary_energy"`
+
+	// ValidFrom is the date from which the factor is in effect.
+	ValidFrom time.Time `json:"valid_from"`
+}
+
+func (EnergyFactor) Kind() string                      { return "energy_factor" }
+func (ef EnergyFactor) Key() string                    { return ef.ID.String() }
+func (EnergyFactor) TableName() string                 { return "energy_factors" }
+func (EnergyFactor) Dependencies() []config.Dependency { return nil }
+func (EnergyFactor) IsEntity()                         {}
+
+// Savings holds the yearly avoided final energy (MWh), primary energy (MWh)
+// and CO2 emissions (t).
+type Savings struct {
+	Energy        float64 `json:"energy"`
+	PrimaryEnergy float64 `json:"primary_energy"`
+	CO2           float64 `json:"co2"`
+}
+
+// NewSavings converts saved final energy in MWh to Savings using f.
+func NewSavings(energy float64, f EnergyFactor) Savings {
+	return Savings{
+		Energy:        energy,
+		PrimaryEnergy: energy * f.PrimaryEnergy,
+		CO2:           energy * f.CO2,
+	}
+}
+
+// ProjectSavings holds the expected and measured savings of a project.
+type ProjectSavings struct {
+	Project uuid.UUID     `json:"project"`
+	Factor  *EnergyFactor `json:"factor"`
+
+	// Expected savings come from the guaranteed savings of the project
+	// applied to the baseline of the contract.
+	Expected Savings `json:"expected"`
+
+	// Measured savings compare the baseline with the last complete
+	// monitoring year, normalised to the degree days of the baseline. It
+	// is nil until such a year and its degree days are reported.
+	Measured *Savings `json:"measured"`
+}
@@ -1,11 +1,14 @@
 package models
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"time"
 
 	"stageai.tech/sunshine/sunshine/config"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type ForfaitingApplication struct {
@@ -30,6 +33,26 @@ func (fa ForfaitingApplication) Dependencies() []Dependency {
 	return dep
 }
 
+// Approved reports whether the steps of the last stage of the review chain
+// of the application are approved.
+func (fa ForfaitingApplication) Approved() bool {
+	var last int
+	for _, r := range fa.Reviews {
+		if r.Stage > last {
+			last = r.Stage
+		}
+	}
+
+	approved := len(fa.Reviews) > 0
+	for _, r := range fa.Reviews {
+		if r.Stage == last && !r.Approved {
+			approved = false
+		}
+	}
+	return approved
+}
+
+// FAReview is a step of the review chain of a forfaiting application.
 type FAReview struct {
 	Value
 
@@ -38,6 +61,24 @@ type FAReview struct {
 	Approved                bool
 	Comment                 string
 	Type                    FAReviewType
+
+	// Stage orders the steps of the chain. The steps of a stage are reviewed
+	// in parallel and open once all the steps of the previous stage are
+	// approved.
+	Stage  int
+	Status FAReviewStatus `gorm:"default:'open'"`
+
+	// Roles are the positions allowed to decide on the step, e.g.
+	// fund_manager. Anyone allowed to review forfaiting applications may,
+	// if none.
+	Roles pq.StringArray `gorm:"type:text[]"`
+
+	// SLADays is how long the step may stay open, if set, and RejectTo is
+	// the step a rejection sends the application back to by default.
+	SLADays  int           `gorm:"column:sla_days"`
+	RejectTo *FAReviewType `gorm:"column:reject_to"`
+	OpenedAt *time.Time
+	DueAt    *time.Time
 }
 
 func (FAReview) TableName() string          { return "fa_reviews" }
@@ -80,6 +121,69 @@ const (
 	FAReviewTypeExecutive
 )
 
+// FAReviewStatus is the state of a step of the review chain.
+type FAReviewStatus string
+
+const (
+	// FAReviewPending waits for the previous stages to be approved.
+	FAReviewPending FAReviewStatus = "pending"
+
+	// FAReviewOpen waits for the decision of a reviewer.
+	FAReviewOpen FAReviewStatus = "open"
+
+	// FAReviewApproved is approved.
+	FAReviewApproved FAReviewStatus = "approved"
+)
+
+func (s *FAReviewStatus) Scan(value interface{}) error {
+	var v, ok = value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid review status: %v", v)
+	}
+
+	*s = FAReviewStatus(v)
+	return nil
+}
+
+func (s FAReviewStatus) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return string(s), nil
+}
+
+// FAReviewStep is a step of the review chain the forfaiting applications of
+// a country go through.
+type FAReviewStep struct {
+	Value
+
+	Country  Country        `json:"country"`
+	Stage    int            `json:"stage"`
+	Type     FAReviewType   `json:"type"`
+	Roles    pq.StringArray `json:"roles" gorm:"type:text[]"`
+	SLADays  int            `json:"sla_days" gorm:"column:sla_days"`
+	RejectTo *FAReviewType  `json:"reject_to" gorm:"column:reject_to"`
+}
+
+func (FAReviewStep) TableName() string { return "fa_review_steps" }
+
+// FAReviewDecision is a decision taken on a step of the review chain of a
+// forfaiting application.
+type FAReviewDecision struct {
+	Value
+
+	ForfaitingApplicationID uuid.UUID     `json:"forfaiting_application"`
+	Review                  uuid.UUID     `json:"review" gorm:"column:review_id"`
+	Type                    FAReviewType  `json:"type"`
+	Stage                   int           `json:"stage"`
+	Author                  *uuid.UUID    `json:"author" gorm:"type:uuid; null"`
+	Approved                bool          `json:"approved"`
+	Comment                 string        `json:"comment"`
+	SendBackTo              *FAReviewType `json:"send_back_to" gorm:"column:send_back_to"`
+}
+
+func (FAReviewDecision) TableName() string { return "fa_review_decisions" }
+
 type ForfaitingPayment struct {
 	Value
 
@@ -95,6 +199,49 @@ func (fp ForfaitingPayment) Key() string             { return fp.ID.String() }
 func (ForfaitingPayment) Dependencies() []Dependency { return nil }
 func (ForfaitingPayment) IsEntity()                  {}
 
+// ForfaitingTranche is an installment of the payment schedule of an approved
+// forfaiting application.
+type ForfaitingTranche struct {
+	Value
+
+	ForfaitingApplicationID uuid.UUID `json:"forfaiting_application"`
+	Position                int       `json:"position"`
+	Amount                  float64   `json:"amount"`
+	Currency                Currency  `json:"currency"`
+	DueDate                 time.Time `json:"due_date"`
+
+	// Reference is the remittance information the transfers of the tranche
+	// are expected to carry.
+	Reference string `json:"reference"`
+
+	// OverdueNotifiedAt is when the fund managers were notified that the
+	// tranche is overdue.
+	OverdueNotifiedAt *time.Time `json:"overdue_notified_at"`
+}
+
+func (ForfaitingTranche) TableName() string { return "forfaiting_tranches" }
+
+// ForfaitingTransfer is a transfer paid out for a forfaiting application,
+// either recorded by hand or imported from a bank statement.
+type ForfaitingTransfer struct {
+	Value
+
+	ForfaitingApplicationID uuid.UUID  `json:"forfaiting_application"`
+	Tranche                 *uuid.UUID `json:"tranche" gorm:"column:tranche_id; type:uuid; null"`
+	Amount                  float64    `json:"amount"`
+	Currency                Currency   `json:"currency"`
+	Date                    time.Time  `json:"date"`
+	IBAN                    string     `json:"iban" gorm:"column:iban"`
+	Reference               string     `json:"reference"`
+
+	// StatementRef identifies the entry of the bank statement the transfer
+	// was imported from.
+	StatementRef *string    `json:"statement_ref"`
+	RecordedBy   *uuid.UUID `json:"recorded_by" gorm:"type:uuid; null"`
+}
+
+func (ForfaitingTransfer) TableName() string { return "forfaiting_transfers" }
+
 type Currency string
 
 const (
@@ -123,3 +270,59 @@ const (
 	CurrencyUAH Currency = "UAH"
 	CurrencyGBP Currency = "GBP"
 )
+
+// FADataPoint is a data point of a forfaiting application the due-diligence
+// checklist may require.
+type FADataPoint string
+
+const (
+	// FADataBankAccount is a bank account with a valid IBAN and SWIFT.
+	FADataBankAccount FADataPoint = "bank_account"
+
+	// FADataBeneficiary is the name and the bank of the beneficiary.
+	FADataBeneficiary FADataPoint = "beneficiary"
+
+	// FADataManager is the forfaiting manager of the application.
+	FADataManager FADataPoint = "manager"
+
+	// FADataFundManager is the fund manager of the project.
+	FADataFundManager FADataPoint = "fund_manager"
+)
+
+func (d *FADataPoint) Scan(value interface{}) error {
+	var v, ok = value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid data point: %v", v)
+	}
+
+	*d = FADataPoint(v)
+	return nil
+}
+
+func (d FADataPoint) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	return string(d), nil
+}
+
+// FAChecklistItem is an item of the due-diligence checklist of the
+// forfaiting applications of a country. It is either a document, complete
+// once a file of any of its upload types is attached to the project, its
+// work phase or the application, or a data point of the application.
+type FAChecklistItem struct {
+	Value
+
+	Country     Country        `json:"country"`
+	Position    int            `json:"position"`
+	Name        string         `json:"name"`
+	UploadTypes pq.StringArray `json:"upload_types" gorm:"type:text[]"`
+	DataPoint   *FADataPoint   `json:"data_point"`
+
+	// Required items must be complete for the dossier to be complete. The
+	// weight of an item is its share in the completeness score.
+	Required bool `json:"required"`
+	Weight   int  `json:"weight"`
+}
+
+func (FAChecklistItem) TableName() string { return "fa_checklist_items" }
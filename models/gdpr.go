@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql/driver"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"stageai.tech/sunshine/sunshine/config"
@@ -22,6 +23,36 @@ type GDPRRequest struct {
 	Action           GDPRType  `json:"action"`
 	Reason           string    `json:"reason"`
 	Information      string    `json:"information"`
+
+	Status      GDPRStatus `json:"status" gorm:"default:'pending'"`
+	Reviewer    *uuid.UUID `json:"reviewer" gorm:"type:uuid; null"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// GDPRStatus is the stage of handling a GDPR request.
+type GDPRStatus string
+
+const (
+	GDPRStatusPending   GDPRStatus = "pending"
+	GDPRStatusCompleted GDPRStatus = "completed"
+	GDPRStatusRejected  GDPRStatus = "rejected"
+)
+
+func (s *GDPRStatus) Scan(value interface{}) error {
+	var v, ok = value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid GDPR status: %v", v)
+	}
+
+	*s = GDPRStatus(v)
+	return nil
+}
+
+func (s GDPRStatus) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return string(s), nil
 }
 
 func (GDPRRequest) TableName() string {
@@ -36,6 +36,13 @@ type Meeting struct {
 
 	// InternalProject is Related Organization's internal project
 	InternalProject string
+
+	// Sequence is the revision of the meeting sent to the guests in
+	// calendar invitations.
+	Sequence int `json:"sequence"`
+
+	// RemindedAt is when the host was reminded of the next contact.
+	RemindedAt *time.Time `json:"reminded_at"`
 }
 
 func (Meeting) Kind() string {
@@ -65,6 +72,11 @@ type MeetingGuest struct {
 
 	// Organization name
 	Organization string
+
+	// Attendance of the guest recorded in the minutes.
+	Attendance *AttendanceStatus `json:"attendance"`
+	Signature  string            `json:"signature"`
+	SignedAt   *time.Time        `json:"signed_at"`
 }
 
 func (mg *MeetingGuest) String() string {
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Merge records that a duplicate record was merged into another one. It is
+// kept as an audit trail and to redirect the ID of the deleted duplicate.
+type Merge struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid; primary_key"`
+
+	// Kind of the merged records, e.g. user or organization.
+	Kind string `json:"kind"`
+
+	// Source is the deleted duplicate and Target the record it was merged
+	// into.
+	Source uuid.UUID `json:"source"`
+	Target uuid.UUID `json:"target"`
+
+	Author *uuid.UUID `json:"author" gorm:"type:uuid; null"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Merge) TableName() string { return "merges" }
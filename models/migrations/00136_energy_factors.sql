@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE energy_factors (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	country country NOT NULL,
+	heating_type INTEGER NOT NULL,
+	co2 DOUBLE PRECISION NOT NULL DEFAULT 0,
+	primary_energy DOUBLE PRECISION NOT NULL DEFAULT 0,
+	valid_from TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE,
+
+	UNIQUE (country, heating_type, valid_from)
+);
+
+UPDATE contracts SET fields = fields || (
+       SELECT jsonb_set(
+		fields,
+		'{"calculations_qsaved"}',
+		'""',
+		true
+       )
+);
+
+-- +goose Down
+UPDATE contracts SET fields = fields #- '{calculations_qsaved}';
+
+DROP TABLE energy_factors;
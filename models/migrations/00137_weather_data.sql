@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE weather_stations (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	name TEXT NOT NULL UNIQUE,
+	country country NOT NULL,
+	coords REAL[] NOT NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE daily_temperatures (
+	station_id UUID NOT NULL REFERENCES weather_stations (id) ON DELETE CASCADE,
+	date DATE NOT NULL,
+	temperature DOUBLE PRECISION NOT NULL,
+
+	PRIMARY KEY (station_id, date)
+);
+
+-- +goose Down
+DROP TABLE daily_temperatures;
+DROP TABLE weather_stations;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE contract_templates (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	country country NOT NULL,
+	version INTEGER NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	files JSONB NOT NULL DEFAULT '{}',
+	active BOOLEAN NOT NULL DEFAULT false,
+	author UUID REFERENCES users(id) ON DELETE SET NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE,
+
+	UNIQUE (country, version)
+);
+
+CREATE UNIQUE INDEX contract_templates_active ON contract_templates (country) WHERE active;
+
+ALTER TABLE projects ADD COLUMN contract_template UUID REFERENCES contract_templates(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN contract_template;
+
+DROP TABLE contract_templates;
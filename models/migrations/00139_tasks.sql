@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TYPE task_status AS ENUM ('todo', 'in_progress', 'blocked', 'done');
+
+CREATE TABLE tasks (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	project_id UUID REFERENCES projects NOT NULL,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	status task_status NOT NULL DEFAULT 'todo',
+	assignee UUID REFERENCES users(id) ON DELETE SET NULL,
+	author UUID REFERENCES users(id) ON DELETE SET NULL,
+	deadline TIMESTAMP WITH TIME ZONE,
+	milestone milestone,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX tasks_project_id ON tasks (project_id);
+CREATE INDEX tasks_assignee ON tasks (assignee);
+
+CREATE TABLE task_comments (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	task_id UUID REFERENCES tasks NOT NULL,
+	author UUID REFERENCES users NOT NULL,
+	content TEXT NOT NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- +goose Down
+DROP TABLE task_comments;
+DROP TABLE tasks;
+DROP TYPE task_status;
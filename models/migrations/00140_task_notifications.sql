@@ -0,0 +1,8 @@
+-- +goose Up
+-- +goose NO TRANSACTION
+
+ALTER TYPE entity_type ADD VALUE IF NOT EXISTS 'task';
+ALTER TYPE user_action ADD VALUE IF NOT EXISTS 'comment';
+
+-- +goose Down
+SELECT 1;
@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX assets_coords_idx ON assets ((coords[1]), (coords[2]));
+
+-- +goose Down
+DROP INDEX assets_coords_idx;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE merges (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	kind TEXT NOT NULL,
+	source UUID NOT NULL UNIQUE,
+	target UUID NOT NULL,
+	author UUID REFERENCES users(id) ON DELETE SET NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX merges_target ON merges (target);
+
+-- +goose Down
+DROP TABLE merges;
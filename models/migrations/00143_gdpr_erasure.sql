@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TYPE gdpr_status AS ENUM ('pending', 'completed', 'rejected');
+
+ALTER TABLE gdpr_requests
+   ADD COLUMN status gdpr_status NOT NULL DEFAULT 'pending',
+   ADD COLUMN reviewer UUID REFERENCES users(id) ON DELETE SET NULL,
+   ADD COLUMN completed_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE users
+   ADD COLUMN last_login TIMESTAMP WITH TIME ZONE,
+   ADD COLUMN anonymized_at TIMESTAMP WITH TIME ZONE;
+
+-- +goose Down
+ALTER TABLE users
+   DROP COLUMN last_login,
+   DROP COLUMN anonymized_at;
+
+ALTER TABLE gdpr_requests
+   DROP COLUMN status,
+   DROP COLUMN reviewer,
+   DROP COLUMN completed_at;
+
+DROP TYPE gdpr_status;
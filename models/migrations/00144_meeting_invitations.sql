@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TYPE token_purpose RENAME TO old_token_purpose;
+CREATE TYPE token_purpose AS ENUM ('session', 'create', 'resetpwd', 'createprj', 'calendar');
+ALTER TABLE tokens ALTER COLUMN purpose TYPE token_purpose using purpose::TEXT::token_purpose;
+
+DROP TYPE old_token_purpose;
+
+ALTER TABLE meetings
+	ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN reminded_at TIMESTAMP WITH TIME ZONE;
+
+-- +goose Down
+ALTER TABLE meetings
+	DROP COLUMN sequence,
+	DROP COLUMN reminded_at;
+
+DELETE FROM tokens WHERE purpose = 'calendar';
+
+ALTER TYPE token_purpose RENAME TO old_token_purpose;
+CREATE TYPE token_purpose AS ENUM ('session', 'create', 'resetpwd', 'createprj');
+ALTER TABLE tokens ALTER COLUMN purpose TYPE token_purpose using purpose::TEXT::token_purpose;
+
+DROP TYPE old_token_purpose;
@@ -0,0 +1,62 @@
+-- +goose Up
+CREATE TYPE attendance_status AS ENUM ('present', 'absent', 'excused');
+
+ALTER TABLE meeting_guests
+	ADD COLUMN attendance attendance_status,
+	ADD COLUMN signature TEXT NOT NULL DEFAULT '',
+	ADD COLUMN signed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE meeting_agenda_items (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	meeting_id UUID REFERENCES meetings NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0,
+	title TEXT NOT NULL,
+	notes TEXT NOT NULL DEFAULT '',
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX meeting_agenda_items_meeting_id ON meeting_agenda_items (meeting_id);
+
+CREATE TABLE meeting_decisions (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	meeting_id UUID REFERENCES meetings NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0,
+	text TEXT NOT NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX meeting_decisions_meeting_id ON meeting_decisions (meeting_id);
+
+CREATE TABLE meeting_action_items (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	meeting_id UUID REFERENCES meetings NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0,
+	description TEXT NOT NULL,
+	assignee UUID REFERENCES users(id) ON DELETE SET NULL,
+	deadline TIMESTAMP WITH TIME ZONE,
+	task_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX meeting_action_items_meeting_id ON meeting_action_items (meeting_id);
+
+-- +goose Down
+DROP TABLE meeting_action_items;
+DROP TABLE meeting_decisions;
+DROP TABLE meeting_agenda_items;
+
+ALTER TABLE meeting_guests
+	DROP COLUMN attendance,
+	DROP COLUMN signature,
+	DROP COLUMN signed_at;
+
+DROP TYPE attendance_status;
@@ -0,0 +1,60 @@
+-- +goose Up
+CREATE TABLE flats (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	asset_id UUID REFERENCES assets(id) ON DELETE CASCADE NOT NULL,
+	number TEXT NOT NULL,
+	area DOUBLE PRECISION NOT NULL DEFAULT 0,
+	resident_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	verified_at TIMESTAMP WITH TIME ZONE,
+	verified_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX flats_asset_number ON flats (asset_id, number) WHERE deleted_at IS NULL;
+CREATE INDEX flats_resident_id ON flats (resident_id);
+
+CREATE TYPE ballot_basis AS ENUM ('flats', 'area');
+
+CREATE TABLE ballots (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	asset_id UUID REFERENCES assets(id) ON DELETE CASCADE NOT NULL,
+	project_id UUID REFERENCES projects(id) ON DELETE SET NULL,
+	question TEXT NOT NULL,
+	options TEXT[] NOT NULL,
+	basis ballot_basis NOT NULL DEFAULT 'flats',
+	quorum DOUBLE PRECISION NOT NULL,
+	majority DOUBLE PRECISION NOT NULL,
+	deadline TIMESTAMP WITH TIME ZONE NOT NULL,
+	author UUID REFERENCES users(id) ON DELETE SET NULL,
+	closed_at TIMESTAMP WITH TIME ZONE,
+	digest TEXT NOT NULL DEFAULT '',
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX ballots_asset_id ON ballots (asset_id);
+
+CREATE TABLE votes (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	ballot_id UUID REFERENCES ballots(id) ON DELETE CASCADE NOT NULL,
+	flat_id UUID REFERENCES flats(id) ON DELETE CASCADE NOT NULL,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	option INTEGER NOT NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE,
+
+	UNIQUE (ballot_id, flat_id)
+);
+
+-- +goose Down
+DROP TABLE votes;
+DROP TABLE ballots;
+DROP TYPE ballot_basis;
+DROP TABLE flats;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- +goose NO TRANSACTION
+
+ALTER TYPE upload_type ADD VALUE IF NOT EXISTS 'residents vote result';
+
+-- +goose Down
+SELECT 1;
@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE forfaiting_tranches (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	forfaiting_application_id UUID REFERENCES forfaiting_applications(id) ON DELETE CASCADE NOT NULL,
+	position INTEGER NOT NULL,
+	amount NUMERIC(14, 2) NOT NULL,
+	currency currency NOT NULL,
+	due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+	reference TEXT NOT NULL,
+	overdue_notified_at TIMESTAMP WITH TIME ZONE,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX forfaiting_tranches_reference ON forfaiting_tranches (reference) WHERE deleted_at IS NULL;
+CREATE INDEX forfaiting_tranches_forfaiting_application_id ON forfaiting_tranches (forfaiting_application_id);
+
+CREATE TABLE forfaiting_transfers (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	forfaiting_application_id UUID REFERENCES forfaiting_applications(id) ON DELETE CASCADE NOT NULL,
+	tranche_id UUID REFERENCES forfaiting_tranches(id) ON DELETE SET NULL,
+	amount NUMERIC(14, 2) NOT NULL,
+	currency currency NOT NULL,
+	date TIMESTAMP WITH TIME ZONE NOT NULL,
+	iban TEXT NOT NULL DEFAULT '',
+	reference TEXT NOT NULL DEFAULT '',
+	statement_ref TEXT,
+	recorded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX forfaiting_transfers_statement_ref ON forfaiting_transfers (statement_ref)
+	WHERE statement_ref IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX forfaiting_transfers_forfaiting_application_id ON forfaiting_transfers (forfaiting_application_id);
+
+-- +goose Down
+DROP TABLE forfaiting_transfers;
+DROP TABLE forfaiting_tranches;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- +goose NO TRANSACTION
+
+ALTER TYPE user_action ADD VALUE IF NOT EXISTS 'forfaiting_payment_overdue';
+
+-- +goose Down
+SELECT 1;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE wp_reviews
+	ADD COLUMN screened_at TIMESTAMP WITH TIME ZONE,
+	ADD COLUMN screening_errors TEXT[],
+	ADD COLUMN screening_matches TEXT[];
+
+-- +goose Down
+ALTER TABLE wp_reviews
+	DROP COLUMN screened_at,
+	DROP COLUMN screening_errors,
+	DROP COLUMN screening_matches;
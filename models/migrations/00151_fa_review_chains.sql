@@ -0,0 +1,82 @@
+-- +goose Up
+CREATE TYPE fa_review_status AS ENUM ('pending', 'open', 'approved');
+
+ALTER TABLE fa_reviews
+	ADD COLUMN stage INTEGER NOT NULL DEFAULT 1,
+	ADD COLUMN status fa_review_status NOT NULL DEFAULT 'open',
+	ADD COLUMN roles TEXT[],
+	ADD COLUMN sla_days INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN reject_to INTEGER,
+	ADD COLUMN opened_at TIMESTAMP WITH TIME ZONE,
+	ADD COLUMN due_at TIMESTAMP WITH TIME ZONE;
+
+-- applications may have several reviews of a type, of which only the latest
+-- one is kept in the chain
+UPDATE fa_reviews r SET deleted_at = now()
+	WHERE r.deleted_at IS NULL AND EXISTS (
+		SELECT 1 FROM fa_reviews l
+		WHERE l.forfaiting_application_id = r.forfaiting_application_id
+			AND l.type = r.type AND l.deleted_at IS NULL
+			AND (l.created_at, l.id) > (r.created_at, r.id)
+	);
+
+-- the executive review of the existing applications unlocks after the other
+-- ones, as in the default chain
+UPDATE fa_reviews SET stage = 2 WHERE type = 4;
+UPDATE fa_reviews SET status = 'approved' WHERE approved;
+UPDATE fa_reviews e SET status = 'pending'
+	WHERE e.stage = 2 AND NOT e.approved AND EXISTS (
+		SELECT 1 FROM fa_reviews r
+		WHERE r.forfaiting_application_id = e.forfaiting_application_id
+			AND r.stage = 1 AND NOT r.approved AND r.deleted_at IS NULL
+	);
+UPDATE fa_reviews SET opened_at = created_at WHERE status <> 'pending';
+
+CREATE TABLE fa_review_steps (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	country country NOT NULL,
+	stage INTEGER NOT NULL,
+	type INTEGER NOT NULL,
+	roles TEXT[],
+	sla_days INTEGER NOT NULL DEFAULT 0,
+	reject_to INTEGER,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX fa_review_steps_country_type ON fa_review_steps (country, type) WHERE deleted_at IS NULL;
+
+CREATE TABLE fa_review_decisions (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	forfaiting_application_id UUID REFERENCES forfaiting_applications(id) ON DELETE CASCADE NOT NULL,
+	review_id UUID REFERENCES fa_reviews(id) ON DELETE CASCADE NOT NULL,
+	type INTEGER NOT NULL,
+	stage INTEGER NOT NULL,
+	author UUID REFERENCES users(id) ON DELETE SET NULL,
+	approved BOOLEAN NOT NULL,
+	comment TEXT NOT NULL DEFAULT '',
+	send_back_to INTEGER,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX fa_review_decisions_forfaiting_application_id ON fa_review_decisions (forfaiting_application_id);
+
+-- +goose Down
+DROP TABLE fa_review_decisions;
+DROP TABLE fa_review_steps;
+
+ALTER TABLE fa_reviews
+	DROP COLUMN stage,
+	DROP COLUMN status,
+	DROP COLUMN roles,
+	DROP COLUMN sla_days,
+	DROP COLUMN reject_to,
+	DROP COLUMN opened_at,
+	DROP COLUMN due_at;
+
+DROP TYPE fa_review_status;
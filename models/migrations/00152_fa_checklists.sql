@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE fa_checklist_items (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	country country NOT NULL,
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	upload_types TEXT[],
+	data_point TEXT,
+	required BOOLEAN NOT NULL DEFAULT true,
+	weight INTEGER NOT NULL DEFAULT 1,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX fa_checklist_items_country ON fa_checklist_items (country);
+
+-- +goose Down
+DROP TABLE fa_checklist_items;
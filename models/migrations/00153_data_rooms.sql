@@ -0,0 +1,63 @@
+-- +goose Up
+CREATE TABLE data_rooms (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	project_id UUID REFERENCES projects(id) ON DELETE CASCADE NOT NULL,
+	title TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	include_contract BOOLEAN NOT NULL DEFAULT false,
+	watermark BOOLEAN NOT NULL DEFAULT true,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX data_rooms_project_id ON data_rooms (project_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE data_room_documents (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	data_room_id UUID REFERENCES data_rooms(id) ON DELETE CASCADE NOT NULL,
+	attachment_id UUID REFERENCES attachments(id) ON DELETE CASCADE NOT NULL,
+	title TEXT NOT NULL DEFAULT '',
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX data_room_documents_data_room_id ON data_room_documents (data_room_id);
+
+CREATE TABLE data_room_grants (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	data_room_id UUID REFERENCES data_rooms(id) ON DELETE CASCADE NOT NULL,
+	investor_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+	expires_at TIMESTAMP WITH TIME ZONE,
+	granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX data_room_grants_investor ON data_room_grants (data_room_id, investor_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE data_room_downloads (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	data_room_id UUID REFERENCES data_rooms(id) ON DELETE CASCADE NOT NULL,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	attachment_id UUID REFERENCES attachments(id) ON DELETE SET NULL,
+	name TEXT NOT NULL,
+	watermarked BOOLEAN NOT NULL DEFAULT false,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX data_room_downloads_data_room_id ON data_room_downloads (data_room_id);
+
+-- +goose Down
+DROP TABLE data_room_downloads;
+DROP TABLE data_room_grants;
+DROP TABLE data_room_documents;
+DROP TABLE data_rooms;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE downloads (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+	owner_id UUID NOT NULL,
+	attachment_id UUID REFERENCES attachments(id) ON DELETE SET NULL,
+	name TEXT NOT NULL,
+	template_version INTEGER,
+	contract_updated_at TIMESTAMP WITH TIME ZONE,
+	watermark TEXT NOT NULL DEFAULT '',
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX downloads_project_id ON downloads (project_id);
+CREATE INDEX downloads_user_id ON downloads (user_id);
+
+-- +goose Down
+DROP TABLE downloads;
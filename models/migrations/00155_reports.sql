@@ -0,0 +1,43 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE upload_type ADD VALUE IF NOT EXISTS 'scheduled report';
+
+CREATE TABLE report_definitions (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	name TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	format TEXT NOT NULL,
+	frequency TEXT NOT NULL,
+	country TEXT NOT NULL DEFAULT '',
+	project_status NUMERIC,
+	building_type NUMERIC,
+	author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	subscribers TEXT[],
+	next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	last_run_at TIMESTAMP WITH TIME ZONE,
+	disabled_at TIMESTAMP WITH TIME ZONE,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX report_definitions_next_run_at ON report_definitions (next_run_at);
+
+CREATE TABLE report_runs (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	definition_id UUID NOT NULL REFERENCES report_definitions(id) ON DELETE CASCADE,
+	rows INTEGER NOT NULL DEFAULT 0,
+	recipients INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX report_runs_definition_id ON report_runs (definition_id);
+
+-- +goose Down
+DROP TABLE report_runs;
+DROP TABLE report_definitions;
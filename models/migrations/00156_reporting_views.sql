@@ -0,0 +1,117 @@
+-- +goose Up
+CREATE MATERIALIZED VIEW organization_project_counts AS
+SELECT p.organization_id,
+	count(*) FILTER (WHERE p.own) AS own_total,
+	count(*) FILTER (WHERE p.own AND p.status = 2) AS own_ongoing,
+	coalesce(sum(p.monitoring) FILTER (WHERE p.own), 0) AS own_monitoring,
+	coalesce(sum(p.forfaiting) FILTER (WHERE p.own), 0) AS own_forfaiting,
+	count(*) FILTER (WHERE NOT p.own) AS related_total,
+	count(*) FILTER (WHERE NOT p.own AND p.status = 2) AS related_ongoing,
+	coalesce(sum(p.monitoring) FILTER (WHERE NOT p.own), 0) AS related_monitoring,
+	coalesce(sum(p.forfaiting) FILTER (WHERE NOT p.own), 0) AS related_forfaiting
+FROM (
+	-- Projects count for the owner of the project and for the owner of its
+	-- asset. They are their own when the organization owns both, and related
+	-- when it owns either but not both.
+	SELECT o.organization_id, projects.status,
+		o.organization_id = projects.owner AND o.organization_id = assets.owner_id AS own,
+		(SELECT count(*) FROM monitoring_phase WHERE monitoring_phase.project_id = projects.id) AS monitoring,
+		(SELECT count(*) FROM forfaiting_applications
+			WHERE forfaiting_applications.project_id = projects.id AND forfaiting_applications.deleted_at IS NULL
+				AND EXISTS (
+					SELECT 1 FROM fa_reviews r
+					WHERE r.forfaiting_application_id = forfaiting_applications.id AND r.deleted_at IS NULL
+				) AND NOT EXISTS (
+					SELECT 1 FROM fa_reviews r
+					WHERE r.forfaiting_application_id = forfaiting_applications.id AND r.deleted_at IS NULL
+						AND NOT r.approved AND r.stage = (
+							SELECT max(l.stage) FROM fa_reviews l
+							WHERE l.forfaiting_application_id = r.forfaiting_application_id AND l.deleted_at IS NULL
+						)
+				)
+		) AS forfaiting
+	FROM projects
+	JOIN assets ON assets.id = projects.asset
+	CROSS JOIN LATERAL (
+		SELECT DISTINCT organization_id FROM unnest(ARRAY[projects.owner, assets.owner_id]) AS organization_id
+	) o
+	WHERE projects.deleted_at IS NULL AND o.organization_id IS NOT NULL
+) p
+GROUP BY p.organization_id;
+
+CREATE UNIQUE INDEX organization_project_counts_organization_id ON organization_project_counts (organization_id);
+
+CREATE MATERIALIZED VIEW project_report_summaries AS
+SELECT projects.id AS project_id,
+	coalesce(organizations.name, '') AS owner_name,
+	coalesce(assets.address, '') AS asset_address,
+	EXISTS (SELECT 1 FROM monitoring_phase WHERE monitoring_phase.project_id = projects.id) AS monitoring,
+	EXISTS (
+		SELECT 1 FROM forfaiting_applications
+		WHERE forfaiting_applications.project_id = projects.id AND forfaiting_applications.deleted_at IS NULL
+			AND EXISTS (
+				SELECT 1 FROM fa_reviews r
+				WHERE r.forfaiting_application_id = forfaiting_applications.id AND r.deleted_at IS NULL
+			) AND NOT EXISTS (
+				SELECT 1 FROM fa_reviews r
+				WHERE r.forfaiting_application_id = forfaiting_applications.id AND r.deleted_at IS NULL
+					AND NOT r.approved AND r.stage = (
+						SELECT max(l.stage) FROM fa_reviews l
+						WHERE l.forfaiting_application_id = r.forfaiting_application_id AND l.deleted_at IS NULL
+					)
+			)
+	) AS forfaiting_approved
+FROM projects
+LEFT JOIN organizations ON organizations.id = projects.owner
+LEFT JOIN assets ON assets.id = projects.asset
+WHERE projects.deleted_at IS NULL;
+
+CREATE UNIQUE INDEX project_report_summaries_project_id ON project_report_summaries (project_id);
+
+-- reporting_refreshes records when each view was refreshed, since when it
+-- is stale and when it was last changed.
+CREATE TABLE reporting_refreshes (
+	view TEXT PRIMARY KEY,
+	refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	stale_since TIMESTAMP WITH TIME ZONE,
+	changed_at TIMESTAMP WITH TIME ZONE
+);
+
+INSERT INTO reporting_refreshes (view) VALUES
+	('organization_project_counts'),
+	('project_report_summaries');
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION reporting_mark_stale() RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE reporting_refreshes
+		SET changed_at = clock_timestamp(), stale_since = coalesce(stale_since, clock_timestamp());
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER projects_reporting AFTER INSERT OR UPDATE OR DELETE ON projects
+	FOR EACH STATEMENT EXECUTE PROCEDURE reporting_mark_stale();
+CREATE TRIGGER assets_reporting AFTER INSERT OR UPDATE OR DELETE ON assets
+	FOR EACH STATEMENT EXECUTE PROCEDURE reporting_mark_stale();
+CREATE TRIGGER organizations_reporting AFTER INSERT OR UPDATE OR DELETE ON organizations
+	FOR EACH STATEMENT EXECUTE PROCEDURE reporting_mark_stale();
+CREATE TRIGGER monitoring_phase_reporting AFTER INSERT OR UPDATE OR DELETE ON monitoring_phase
+	FOR EACH STATEMENT EXECUTE PROCEDURE reporting_mark_stale();
+CREATE TRIGGER forfaiting_applications_reporting AFTER INSERT OR UPDATE OR DELETE ON forfaiting_applications
+	FOR EACH STATEMENT EXECUTE PROCEDURE reporting_mark_stale();
+CREATE TRIGGER fa_reviews_reporting AFTER INSERT OR UPDATE OR DELETE ON fa_reviews
+	FOR EACH STATEMENT EXECUTE PROCEDURE reporting_mark_stale();
+
+-- +goose Down
+DROP TRIGGER fa_reviews_reporting ON fa_reviews;
+DROP TRIGGER forfaiting_applications_reporting ON forfaiting_applications;
+DROP TRIGGER monitoring_phase_reporting ON monitoring_phase;
+DROP TRIGGER organizations_reporting ON organizations;
+DROP TRIGGER assets_reporting ON assets;
+DROP TRIGGER projects_reporting ON projects;
+DROP FUNCTION reporting_mark_stale();
+DROP TABLE reporting_refreshes;
+DROP MATERIALIZED VIEW project_report_summaries;
+DROP MATERIALIZED VIEW organization_project_counts;
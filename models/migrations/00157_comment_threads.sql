@@ -0,0 +1,46 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE upload_type ADD VALUE IF NOT EXISTS 'comment attachment';
+ALTER TYPE user_action ADD VALUE IF NOT EXISTS 'mention';
+
+ALTER TABLE project_comments
+	ADD COLUMN parent_id UUID REFERENCES project_comments(id),
+	ADD COLUMN target_kind TEXT NOT NULL DEFAULT 'project',
+	ADD COLUMN target_id UUID,
+	ADD COLUMN target_name TEXT NOT NULL DEFAULT '',
+	ADD COLUMN mentions UUID[],
+	ADD COLUMN edited_at TIMESTAMP WITH TIME ZONE,
+	ADD COLUMN resolved_at TIMESTAMP WITH TIME ZONE,
+	ADD COLUMN resolved_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX project_comments_project_id ON project_comments (project_id, target_kind);
+CREATE INDEX project_comments_parent_id ON project_comments (parent_id);
+
+CREATE TABLE project_comment_revisions (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	comment_id UUID NOT NULL REFERENCES project_comments(id) ON DELETE CASCADE,
+	content TEXT NOT NULL,
+	edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX project_comment_revisions_comment_id ON project_comment_revisions (comment_id);
+
+-- +goose Down
+DROP TABLE project_comment_revisions;
+
+DROP INDEX project_comments_parent_id;
+DROP INDEX project_comments_project_id;
+
+ALTER TABLE project_comments
+	DROP COLUMN parent_id,
+	DROP COLUMN target_kind,
+	DROP COLUMN target_id,
+	DROP COLUMN target_name,
+	DROP COLUMN mentions,
+	DROP COLUMN edited_at,
+	DROP COLUMN resolved_at,
+	DROP COLUMN resolved_by;
@@ -0,0 +1,28 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE user_action ADD VALUE IF NOT EXISTS 'accept_suggestion';
+ALTER TYPE user_action ADD VALUE IF NOT EXISTS 'reject_suggestion';
+
+ALTER TABLE project_comments
+	ADD COLUMN target_row INTEGER,
+	ADD COLUMN target_column INTEGER,
+	ADD COLUMN suggestion TEXT,
+	ADD COLUMN suggestion_original TEXT,
+	ADD COLUMN suggestion_status TEXT NOT NULL DEFAULT '',
+	ADD COLUMN decided_at TIMESTAMP WITH TIME ZONE,
+	ADD COLUMN decided_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX project_comments_open_suggestions ON project_comments (project_id, target_kind, target_name)
+	WHERE suggestion_status = 'open' AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX project_comments_open_suggestions;
+
+ALTER TABLE project_comments
+	DROP COLUMN target_row,
+	DROP COLUMN target_column,
+	DROP COLUMN suggestion,
+	DROP COLUMN suggestion_original,
+	DROP COLUMN suggestion_status,
+	DROP COLUMN decided_at,
+	DROP COLUMN decided_by;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE trashed_roles (
+	owner_id UUID NOT NULL,
+	deleted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	role_table TEXT NOT NULL,
+	role JSONB NOT NULL
+);
+
+CREATE INDEX trashed_roles_owner ON trashed_roles (owner_id, deleted_at);
+
+-- +goose Down
+DROP TABLE trashed_roles;
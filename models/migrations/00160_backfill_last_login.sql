@@ -0,0 +1,6 @@
+-- +goose Up
+-- Logins were not tracked before, so the data retention period of the users
+-- who haven't logged in since starts with this migration.
+UPDATE users SET last_login = now() WHERE last_login IS NULL;
+
+-- +goose Down
@@ -0,0 +1,43 @@
+-- +goose Up
+ALTER TABLE attachments ADD COLUMN watermark TEXT NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN watermark TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE downloads ADD COLUMN data_room_id UUID REFERENCES data_rooms(id) ON DELETE SET NULL;
+CREATE INDEX downloads_data_room_id ON downloads (data_room_id);
+
+INSERT INTO downloads (user_id, project_id, owner_id, attachment_id, name, watermark, data_room_id, created_at, updated_at)
+SELECT d.user_id, r.project_id, COALESCE(a.owner_id, r.project_id), d.attachment_id, d.name,
+	CASE WHEN d.watermarked THEN 'confidential' ELSE '' END, d.data_room_id, d.created_at, d.updated_at
+FROM data_room_downloads d
+JOIN data_rooms r ON r.id = d.data_room_id
+LEFT JOIN attachments a ON a.id = d.attachment_id;
+
+DROP TABLE data_room_downloads;
+
+-- +goose Down
+CREATE TABLE data_room_downloads (
+	id UUID PRIMARY KEY DEFAULT PUBLIC.gen_random_uuid(),
+	data_room_id UUID REFERENCES data_rooms(id) ON DELETE CASCADE NOT NULL,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	attachment_id UUID REFERENCES attachments(id) ON DELETE SET NULL,
+	name TEXT NOT NULL,
+	watermarked BOOLEAN NOT NULL DEFAULT false,
+
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX data_room_downloads_data_room_id ON data_room_downloads (data_room_id);
+
+INSERT INTO data_room_downloads (data_room_id, user_id, attachment_id, name, watermarked, created_at, updated_at)
+SELECT data_room_id, user_id, attachment_id, name, watermark <> '', created_at, updated_at
+FROM downloads WHERE data_room_id IS NOT NULL;
+
+DELETE FROM downloads WHERE data_room_id IS NOT NULL;
+
+DROP INDEX downloads_data_room_id;
+ALTER TABLE downloads DROP COLUMN data_room_id;
+
+ALTER TABLE projects DROP COLUMN watermark;
+ALTER TABLE attachments DROP COLUMN watermark;
@@ -3,8 +3,10 @@ package models
 import (
 	"database/sql/driver"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Milestone string
@@ -92,6 +94,14 @@ type WPReview struct {
 	Approved bool
 	Comment  string
 	Type     WPReviewType
+
+	// ScreenedAt is when the bank account of the project was last checked,
+	// set on bank account reviews only. ScreeningErrors are the problems
+	// found with its IBAN and SWIFT, ScreeningMatches the sanctions list
+	// entries its beneficiary matches.
+	ScreenedAt       *time.Time
+	ScreeningErrors  pq.StringArray `gorm:"type:text[]"`
+	ScreeningMatches pq.StringArray `gorm:"type:text[]"`
 }
 
 func (WPReview) TableName() string          { return "wp_reviews" }
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttendanceStatus records whether a guest attended a meeting.
+type AttendanceStatus string
+
+const (
+	AttendancePresent AttendanceStatus = "present"
+	AttendanceAbsent  AttendanceStatus = "absent"
+	AttendanceExcused AttendanceStatus = "excused"
+)
+
+func (s *AttendanceStatus) Scan(value interface{}) error {
+	var v, ok = value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid attendance status: %v", v)
+	}
+
+	*s = AttendanceStatus(v)
+	return nil
+}
+
+func (s AttendanceStatus) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return string(s), nil
+}
+
+// AgendaItem is an item of the agenda of a meeting along with the notes
+// taken on it for the minutes.
+type AgendaItem struct {
+	Value
+
+	MeetingID uuid.UUID `json:"meeting_id"`
+	Position  int       `json:"position"`
+	Title     string    `json:"title" validate:"required"`
+	Notes     string    `json:"notes"`
+}
+
+func (AgendaItem) TableName() string { return "meeting_agenda_items" }
+
+// MeetingDecision is a decision taken at a meeting, e.g. residents approving
+// the renovation.
+type MeetingDecision struct {
+	Value
+
+	MeetingID uuid.UUID `json:"meeting_id"`
+	Position  int       `json:"position"`
+	Text      string    `json:"text" validate:"required"`
+}
+
+func (MeetingDecision) TableName() string { return "meeting_decisions" }
+
+// ActionItem is a piece of work agreed on at a meeting. It may be followed up
+// as a task of the meeting's project.
+type ActionItem struct {
+	Value
+
+	MeetingID   uuid.UUID  `json:"meeting_id"`
+	Position    int        `json:"position"`
+	Description string     `json:"description" validate:"required"`
+	Assignee    *uuid.UUID `json:"assignee" gorm:"type:uuid; null"`
+	Deadline    *time.Time `json:"deadline"`
+
+	// Task following up the action item.
+	Task *uuid.UUID `json:"task" gorm:"column:task_id; type:uuid; null"`
+}
+
+func (ActionItem) TableName() string { return "meeting_action_items" }
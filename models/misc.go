@@ -94,6 +94,48 @@ func (v ValidationStatus) String() string {
 	}
 }
 
+func (s ProjectStatus) String() string {
+	switch s {
+	case ProjectStatusPlanning:
+		return "planning"
+	case ProjectStatusInProgress:
+		return "in progress"
+	case ProjectStatusFinished:
+		return "finished"
+	case ProjectStatusAbandoned:
+		return "abandoned"
+	default:
+		return "invalid status"
+	}
+}
+
+func (b Building) String() string {
+	switch b {
+	case BuildingCzechProject:
+		return "Czech project"
+	case BuildingType103:
+		return "103"
+	case BuildingType104:
+		return "104"
+	case BuildingType119:
+		return "119"
+	case BuildingType316:
+		return "316"
+	case BuildingType318:
+		return "318"
+	case BuildingType464:
+		return "464"
+	case BuildingType467:
+		return "467"
+	case BuildingType602:
+		return "602"
+	case BuildingOther:
+		return "other"
+	default:
+		return "invalid building type"
+	}
+}
+
 func (p ProjRoles) String() string {
 	return fmt.Sprintf("ProjRoles: {PM: %v, PaCo: %v, PLSign: %v, TaMa: %v, TeMe: %v}",
 		p.PM, p.PaCo, p.PLSign, p.TaMa, p.TeMe)
@@ -182,6 +224,11 @@ const (
 	UserActionRejectLEARApplication        UserAction = "reject_lear_application"
 	UserActionApproveForfaitingApplication UserAction = "approve_forfaiting_application"
 	UserActionApproveForfaitingPayment     UserAction = "approve_forfaiting_payment"
+	UserActionComment                      UserAction = "comment"
+	UserActionForfaitingPaymentOverdue     UserAction = "forfaiting_payment_overdue"
+	UserActionMention                      UserAction = "mention"
+	UserActionAcceptSuggestion             UserAction = "accept_suggestion"
+	UserActionRejectSuggestion             UserAction = "reject_suggestion"
 )
 
 const (
@@ -199,6 +246,7 @@ const (
 	ProjectT      EntityType = "project"
 	IndoorClimaT  EntityType = "indoor_clima"
 	MeetingT      EntityType = "meeting"
+	TaskT         EntityType = "task"
 )
 
 const (
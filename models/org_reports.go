@@ -86,16 +86,11 @@ type OrganizationReport struct {
 
 func (OrganizationReport) IsEntity() {}
 
-type (
-	set []struct {
-		ID    uuid.UUID
-		Name  string
-		Email string
-		Count int
-	}
-
-	where func(*gorm.DB) *gorm.DB
-)
+type set []struct {
+	ID    uuid.UUID
+	Name  string
+	Email string
+}
 
 func (list OrganizationReportsList) PopulateLear(db *gorm.DB, ids []uuid.UUID) error {
 	var s set
@@ -119,109 +114,48 @@ func (list OrganizationReportsList) PopulateLear(db *gorm.DB, ids []uuid.UUID) e
 	return err
 }
 
-func (list OrganizationReportsList) CountOngoingProjects(db *gorm.DB, ids []uuid.UUID, isOwned bool) error {
-	set, err := countPrjs(db, ids, isOwned,
-		func(db *gorm.DB) *gorm.DB { return db.Where("projects.status = ?", int(ProjectStatusInProgress)) })
-	for _, s := range set {
-		prjreport := list[s.ID]
-
-		switch isOwned {
-		case true:
-			prjreport.OwnProjects.OngoingCount = s.Count
-		case false:
-			prjreport.RelatedProjects.OngoingCount = s.Count
-		}
-
-		list[s.ID] = prjreport
-	}
-	return err
+// organizationProjectCounts are the counts of the projects of an
+// organization precomputed in the organization_project_counts view.
+type organizationProjectCounts struct {
+	OrganizationID    uuid.UUID
+	OwnTotal          int
+	OwnOngoing        int
+	OwnMonitoring     int
+	OwnForfaiting     int
+	RelatedTotal      int
+	RelatedOngoing    int
+	RelatedMonitoring int
+	RelatedForfaiting int
 }
 
-func (list OrganizationReportsList) CountMonitoringPhaseProjects(db *gorm.DB, ids []uuid.UUID, isOwned bool) error {
-	set, err := countPrjs(db, ids, isOwned,
-		func(db *gorm.DB) *gorm.DB {
-			return db.Joins("inner join monitoring_phase on projects.id = monitoring_phase.project_id").Where("monitoring_phase.project_id IS NOT NULL")
-		})
-	for _, s := range set {
-		prjreport := list[s.ID]
-
-		switch isOwned {
-		case true:
-			prjreport.OwnProjects.MonitoringPhaseCount = s.Count
-		case false:
-			prjreport.RelatedProjects.MonitoringPhaseCount = s.Count
+// PopulateProjectCounts reads the counts of the own and related projects of
+// the organizations from the organization_project_counts view, as fresh as
+// its last refresh.
+func (list OrganizationReportsList) PopulateProjectCounts(db *gorm.DB, ids []uuid.UUID) error {
+	var counts []organizationProjectCounts
+	err := db.Table("organization_project_counts").
+		Where("organization_id IN (?)", ids).
+		Find(&counts).Error
+
+	for _, c := range counts {
+		report, ok := list[c.OrganizationID]
+		if !ok {
+			continue
 		}
-
-		list[s.ID] = prjreport
-	}
-	return err
-}
-
-func (list OrganizationReportsList) CountTotalProjects(db *gorm.DB, ids []uuid.UUID, isOwned bool) error {
-	set, err := countPrjs(db, ids, isOwned)
-
-	for _, s := range set {
-		prjreport := list[s.ID]
-
-		switch isOwned {
-		case true:
-			prjreport.OwnProjects.TotalCount = s.Count
-		case false:
-			prjreport.RelatedProjects.TotalCount = s.Count
+		report.OwnProjects = OrganizationProjectsReport{
+			TotalCount:              c.OwnTotal,
+			OngoingCount:            c.OwnOngoing,
+			MonitoringPhaseCount:    c.OwnMonitoring,
+			ApprovedForfaitingCount: c.OwnForfaiting,
 		}
-
-		list[s.ID] = prjreport
-	}
-
-	return err
-}
-
-func (list OrganizationReportsList) CountFAProjects(db *gorm.DB, ids []uuid.UUID, isOwned bool) error {
-	s, err := countPrjs(db, ids, isOwned,
-		func(db *gorm.DB) *gorm.DB {
-			return db.Joins("inner join forfaiting_applications on forfaiting_applications.project_id = projects.id").
-				Joins("inner join fa_reviews on forfaiting_applications.id = fa_reviews.forfaiting_application_id").
-				Where("fa_reviews.approved = ?", true).
-				Where("fa_reviews.type = ?", FAReviewTypeExecutive)
-		})
-
-	for _, el := range s {
-		prjreport := list[el.ID]
-
-		switch isOwned {
-		case true:
-			prjreport.OwnProjects.ApprovedForfaitingCount = el.Count
-		case false:
-			prjreport.RelatedProjects.ApprovedForfaitingCount = el.Count
+		report.RelatedProjects = OrganizationProjectsReport{
+			TotalCount:              c.RelatedTotal,
+			OngoingCount:            c.RelatedOngoing,
+			MonitoringPhaseCount:    c.RelatedMonitoring,
+			ApprovedForfaitingCount: c.RelatedForfaiting,
 		}
-
-		list[el.ID] = prjreport
+		list[c.OrganizationID] = report
 	}
 
 	return err
 }
-
-func countPrjs(db *gorm.DB, ids []uuid.UUID, isOwned bool, w ...where) (set, error) {
-	var s set
-
-	db = db.Select("organizations.ID, count(projects.*)").
-		Table("organizations").
-		Joins("inner join projects on projects.owner = organizations.ID").
-		Joins("inner join assets on assets.ID = projects.asset").
-		Where("organizations.ID IN (?)", ids)
-
-	switch isOwned {
-	case true:
-		db = db.Where("organizations.ID = assets.owner_id").
-			Where("projects.owner = organizations.ID")
-	case false:
-		// XOR: ( A and not B) or ( not A and B)
-		db = db.Where("(organizations.ID = assets.owner_id AND NOT projects.owner = organizations.ID) OR (NOT organizations.ID = assets.owner_id AND  projects.owner = organizations.ID)")
-	}
-
-	for _, cl := range w {
-		db = cl(db)
-	}
-
-	return s, db.Group("organizations.ID").Find(&s).Error
-}
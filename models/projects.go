@@ -38,12 +38,17 @@ type Project struct {
 	PortfolioDirector  uuid.UUID      `json:"portfolio_director"`
 	Milestone          Milestone      `json:"milestone"`
 	FundManager        *uuid.UUID     `json:"fund_manager" gorm:"type:uuid; null"`
+	ContractTemplate   *uuid.UUID     `json:"contract_template" gorm:"type:uuid; null"`
 	WorkPhase          WorkPhase
 	MonitoringPhase    MonitoringPhase
 	CommissioningDate  time.Time
 
 	AssetSnapshot AssetSnapshot `json:"asset_snapshot" gorm:"EMBEDDED;EMBEDDED_PREFIX:asset_"`
 
+	// Watermark is stamped on the downloaded PDFs of the project, unless
+	// they have their own, and on its contract.
+	Watermark Watermark `json:"-"`
+
 	ProjectRoles []ProjectRole `json:"-" gorm:"foreignkey:ProjectID"`
 
 	ForfaitingApplication *ForfaitingApplication `gorm:"foreignkey:project_id"`
@@ -61,6 +66,39 @@ type ProjectComment struct {
 	UserID    uuid.UUID     `gorm:"column:author"`
 	Content   string
 	Topic     string
+
+	// Parent is the first comment of the thread this one replies to, if it
+	// does.
+	Parent *uuid.UUID `gorm:"column:parent_id; type:uuid; null"`
+
+	// TargetKind is what in the project the thread is about, TargetID the
+	// work phase or review and TargetName the contract table or field, if
+	// any. TargetRow and TargetColumn are the cell of the table, if any.
+	TargetKind   CommentTarget `gorm:"default:'project'"`
+	TargetID     *uuid.UUID    `gorm:"type:uuid; null"`
+	TargetName   string
+	TargetRow    *int
+	TargetColumn *int
+
+	// Mentions are the project members mentioned in the comment.
+	Mentions pq.StringArray `gorm:"type:uuid[]"`
+
+	EditedAt *time.Time
+
+	// ResolvedAt and ResolvedBy are set on the first comment of a resolved
+	// thread.
+	ResolvedAt *time.Time
+	ResolvedBy *uuid.UUID `gorm:"type:uuid; null"`
+
+	// Suggestion is the value the first comment of a thread suggests for
+	// the contract field or table cell it is about, if it does, and
+	// SuggestionOriginal the value of the field or cell then. DecidedAt and
+	// DecidedBy are set once it is accepted or rejected.
+	Suggestion         *string
+	SuggestionOriginal *string
+	SuggestionStatus   SuggestionStatus
+	DecidedAt          *time.Time
+	DecidedBy          *uuid.UUID `gorm:"type:uuid; null"`
 }
 
 // CommentAuthor is author of comment.
@@ -249,12 +287,7 @@ func (p *Project) AfterFind() (err error) {
 		return
 	}
 
-	for _, r := range p.ForfaitingApplication.Reviews {
-		if r.Type == FAReviewTypeExecutive {
-			p.IsFAApproved = r.Approved
-		}
-	}
-
+	p.IsFAApproved = p.ForfaitingApplication.Approved()
 	return
 }
 
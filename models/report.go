@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ReportKind is the kind of the records listed by a report.
+type ReportKind string
+
+const (
+	ReportOrganizations ReportKind = "organizations"
+	ReportProjects      ReportKind = "projects"
+	ReportAssets        ReportKind = "assets"
+)
+
+// Valid reports an error for unknown kinds.
+func (k ReportKind) Valid() error {
+	switch k {
+	case ReportOrganizations, ReportProjects, ReportAssets:
+		return nil
+	}
+	return fmt.Errorf("invalid report kind %q", string(k))
+}
+
+// ReportFormat is the file format a report is delivered in.
+type ReportFormat string
+
+const (
+	ReportXLSX ReportFormat = "xlsx"
+	ReportPDF  ReportFormat = "pdf"
+)
+
+// Valid reports an error for unknown formats.
+func (f ReportFormat) Valid() error {
+	switch f {
+	case ReportXLSX, ReportPDF:
+		return nil
+	}
+	return fmt.Errorf("invalid report format %q", string(f))
+}
+
+// ReportFrequency is how often a report is generated.
+type ReportFrequency string
+
+const (
+	ReportWeekly  ReportFrequency = "weekly"
+	ReportMonthly ReportFrequency = "monthly"
+)
+
+// Valid reports an error for unknown frequencies.
+func (f ReportFrequency) Valid() error {
+	switch f {
+	case ReportWeekly, ReportMonthly:
+		return nil
+	}
+	return fmt.Errorf("invalid report frequency %q", string(f))
+}
+
+// ReportDefinition is a saved report generated on schedule and emailed to
+// its subscribers.
+type ReportDefinition struct {
+	Value
+
+	Name      string          `json:"name"`
+	Kind      ReportKind      `json:"kind"`
+	Format    ReportFormat    `json:"format"`
+	Frequency ReportFrequency `json:"frequency"`
+
+	// Country, ProjectStatus and BuildingType narrow the records listed, if
+	// set.
+	Country       Country        `json:"country"`
+	ProjectStatus *ProjectStatus `json:"project_status" gorm:"type:numeric; null"`
+	BuildingType  *Building      `json:"building_type" gorm:"type:numeric; null"`
+
+	// Author is the user the report is generated as, so it lists only what
+	// the author may see.
+	Author      *uuid.UUID     `json:"author" gorm:"column:author_id; type:uuid; null"`
+	Subscribers pq.StringArray `json:"subscribers" gorm:"type:text[]"`
+
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at"`
+
+	// DisabledAt is when the report stopped being generated because its
+	// author is gone. Saving it again makes the user saving it the author.
+	DisabledAt *time.Time `json:"disabled_at"`
+}
+
+func (ReportDefinition) TableName() string { return "report_definitions" }
+
+// ReportRun is a generated report kept in the archive. Its file is attached
+// to it.
+type ReportRun struct {
+	Value
+
+	DefinitionID uuid.UUID `json:"definition"`
+
+	// Rows is the number of records listed and Recipients the number of
+	// subscribers it was emailed to.
+	Rows       int `json:"rows"`
+	Recipients int `json:"recipients"`
+
+	// Error is why the report failed to be generated or delivered, if it
+	// did.
+	Error string `json:"error"`
+}
+
+func (ReportRun) TableName() string { return "report_runs" }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportingView is a materialized view precomputing figures of the reports.
+// Changes to the records it is computed from mark it stale until it is
+// refreshed.
+type ReportingView struct {
+	View        string     `json:"view" gorm:"primary_key"`
+	RefreshedAt time.Time  `json:"refreshed_at"`
+	StaleSince  *time.Time `json:"stale_since"`
+}
+
+func (ReportingView) TableName() string { return "reporting_refreshes" }
+
+// ProjectReportSummary holds the figures of a project precomputed in the
+// project_report_summaries view.
+type ProjectReportSummary struct {
+	ProjectID          uuid.UUID
+	OwnerName          string
+	AssetAddress       string
+	Monitoring         bool
+	ForfaitingApproved bool
+}
+
+func (ProjectReportSummary) TableName() string { return "project_report_summaries" }
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaskStatus string
+
+const (
+	TaskStatusTodo       TaskStatus = "todo"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusBlocked    TaskStatus = "blocked"
+	TaskStatusDone       TaskStatus = "done"
+)
+
+// Task is a piece of work within a project, e.g. a renovation step, which is
+// tracked by the project's task manager.
+type Task struct {
+	Value
+
+	Project     uuid.UUID  `json:"project" gorm:"column:project_id" validate:"required"`
+	Title       string     `json:"title" validate:"required"`
+	Description string     `json:"description"`
+	Status      TaskStatus `json:"status" validate:"required"`
+
+	// Assignee is the project member responsible for the task.
+	Assignee *uuid.UUID `json:"assignee" gorm:"type:uuid; null"`
+	Author   *uuid.UUID `json:"author" gorm:"type:uuid; null"`
+	Deadline *time.Time `json:"deadline"`
+
+	// Milestone of the project the task is part of.
+	Milestone *Milestone `json:"milestone"`
+
+	Attachments []Attachment  `gorm:"foreignKey:owner_id;preload:false"`
+	Comments    []TaskComment `gorm:"foreignkey:TaskID;PRELOAD:true"`
+}
+
+func (Task) Kind() string               { return "task" }
+func (t Task) Key() string              { return t.Title }
+func (Task) TableName() string          { return "tasks" }
+func (Task) Dependencies() []Dependency { return nil }
+
+// TaskComment is comment of task.
+type TaskComment struct {
+	Value
+
+	TaskID  uuid.UUID
+	UserID  uuid.UUID `gorm:"column:author"`
+	Content string
+}
+
+func (TaskComment) Kind() string      { return "task_comment" }
+func (tc TaskComment) Key() string    { return tc.ID.String() }
+func (TaskComment) TableName() string { return "task_comments" }
@@ -12,6 +12,7 @@ const (
 	CreateToken        TokenPurpose = "create"
 	ResetPwdToken      TokenPurpose = "resetpwd"
 	CreateProjectToken TokenPurpose = "createprj"
+	CalendarToken      TokenPurpose = "calendar"
 )
 
 // Scan implements the database/sql.Scanner interface.
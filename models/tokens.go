@@ -23,6 +23,8 @@ var now = time.Now
 //			reports about forgotten password.
 //      createprj       Create project token permits a guest organization to
 //                      create a project for host organization's asset.
+//      calendar        Calendar token permits calendar applications to
+//                      fetch the meetings feed of the user.
 type Token struct {
 	ID      uuid.UUID     `gorm:"primary_key"`
 	Purpose TokenPurpose  `validate:"required" gorm:"column:purpose"`
@@ -53,6 +55,8 @@ func NewToken(purpose TokenPurpose, id uuid.UUID) *Token {
 		t.TTL = 24 * time.Hour
 	case CreateProjectToken:
 		t.TTL = 7 * timeDay
+	case CalendarToken:
+		t.TTL = 365 * timeDay
 	default:
 		t.TTL = 0
 	}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"stageai.tech/sunshine/sunshine/config"
+
+	"github.com/google/uuid"
+)
+
+// WeatherStation is a location for which daily outdoor temperatures are
+// imported.
+type WeatherStation struct {
+	Value
+
+	Name        string  `json:"name" validate:"required"`
+	Country     Country `json:"country" validate:"required"`
+	Coordinates Coords  `json:"coordinates" gorm:"column:coords"`
+}
+
+func (WeatherStation) Kind() string                      { return "weather_station" }
+func (ws WeatherStation) Key() string                    { return ws.Name }
+func (WeatherStation) TableName() string                 { return "weather_stations" }
+func (WeatherStation) Dependencies() []config.Dependency { return nil }
+func (WeatherStation) IsEntity()                         {}
+
+// DailyTemperature is the average outdoor temperature in °C measured by a
+// weather station in a single day.
+type DailyTemperature struct {
+	StationID   uuid.UUID `gorm:"primary_key"`
+	Date        time.Time `gorm:"primary_key;type:date"`
+	Temperature float64
+}
+
+func (DailyTemperature) TableName() string { return "daily_temperatures" }
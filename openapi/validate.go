@@ -116,6 +116,7 @@ func convert(t string) string {
 		"[]Row",
 		"[]OrganizationRole",
 		"[]ProjectRole",
+		"[]Feature",
 		"pq.StringArray",
 		"[]CountryRole":
 		return "array"
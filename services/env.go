@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"stageai.tech/sunshine/sunshine"
+	"stageai.tech/sunshine/sunshine/bankaccount"
 	"stageai.tech/sunshine/sunshine/config"
 	"stageai.tech/sunshine/sunshine/models"
 	"stageai.tech/sunshine/sunshine/stores"
@@ -19,6 +20,8 @@ import (
 type Env struct {
 	General           config.General
 	Paths             config.Paths
+	RecycleBin        config.RecycleBin
+	Retention         config.Retention
 	AssetStore        stores.Store
 	ContractStore     stores.Store
 	OrganizationStore stores.Store
@@ -32,11 +35,14 @@ type Env struct {
 	MPStore           stores.Store
 	GDPRStore         stores.Store
 	CountryStore      stores.Store
+	WeatherStore      stores.Store
+	TaskStore         stores.Store
 	Notifier          stores.Notifier
 	Portfolio         stores.Portfolio
 	SessionStore      sessions.Store
 	TokenStore        stores.TokenStore
 	Mailer            Mailer
+	Screener          bankaccount.Screener
 	Validator         *validator.Validate
 	Debug             bool
 	DB                *gorm.DB
@@ -90,10 +96,21 @@ func NewEnv() (*Env, error) {
 		return nil, err
 	}
 
+	var screener bankaccount.Screener
+	if cfg.Paths.Sanctions != "" {
+		list, err := bankaccount.Load(cfg.Paths.Sanctions)
+		if err != nil {
+			return nil, fmt.Errorf("sanctions list: %w", err)
+		}
+		screener = list
+	}
+
 	raven.SetRelease(sunshine.Version())
 	return &Env{
 		General:           cfg.General,
 		Paths:             cfg.Paths,
+		RecycleBin:        cfg.RecycleBin,
+		Retention:         cfg.Retention,
 		AssetStore:        stores.NewAssetStore(db, validate),
 		ContractStore:     stores.NewContractStore(db, validate),
 		OrganizationStore: stores.NewOrganizationStore(db, validate),
@@ -106,12 +123,15 @@ func NewEnv() (*Env, error) {
 		Portfolio:         stores.NewPortfolioStore(db),
 		GDPRStore:         stores.NewGDPRStore(db, validate),
 		CountryStore:      stores.NewCountryStore(db, validate),
+		WeatherStore:      stores.NewWeatherStationStore(db, validate),
+		TaskStore:         stores.NewTaskStore(db, validate),
 		SessionStore:      sessionStore,
 		ProjectStore:      stores.NewProjectStore(db, validate),
 		TokenStore:        stores.NewTokenStore(db, validate),
 		FAStore:           stores.NewForfaitingApplicationStore(db, validate),
 		FPStore:           stores.NewForfaitingPaymentStore(db, validate),
 		Mailer:            NewMailer(cfg.General, cfg.Mail, sender),
+		Screener:          screener,
 		Validator:         validate,
 		DB:                db,
 	}, raven.SetDSN(cfg.General.SentryDSN)
@@ -131,7 +151,7 @@ func NewTestEnv(t *testing.T) *Env {
 	}
 
 	for _, c := range models.Countries() {
-		if c.IsConsortium() {
+		if c.Consortium() {
 			stores.NewTestPortfolioRole(t, stores.NewUserStore(db, validate), models.PortfolioDirectorRole, c)
 			stores.NewTestPortfolioRole(t, stores.NewUserStore(db, validate), models.DataProtectionOfficerRole, c)
 			stores.NewTestPortfolioRole(t, stores.NewUserStore(db, validate), models.CountryAdminRole, c)
@@ -141,6 +161,8 @@ func NewTestEnv(t *testing.T) *Env {
 	return &Env{
 		General:           cfg.General,
 		Paths:             cfg.Paths,
+		RecycleBin:        cfg.RecycleBin,
+		Retention:         cfg.Retention,
 		AssetStore:        stores.NewAssetStore(db, validate),
 		ContractStore:     stores.NewContractStore(db, validate),
 		OrganizationStore: stores.NewOrganizationStore(db, validate),
@@ -158,6 +180,8 @@ func NewTestEnv(t *testing.T) *Env {
 		FPStore:           stores.NewForfaitingPaymentStore(db, validate),
 		GDPRStore:         stores.NewGDPRStore(db, validate),
 		CountryStore:      stores.NewCountryStore(db, validate),
+		WeatherStore:      stores.NewWeatherStationStore(db, validate),
+		TaskStore:         stores.NewTaskStore(db, validate),
 		Mailer:            NewMailer(cfg.General, cfg.Mail, SendToFile),
 		Debug:             true,
 		Validator:         validate,
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"fmt"
 	"net/mail"
 	"net/smtp"
@@ -27,7 +28,14 @@ type Mailer interface {
 	URL() string
 
 	// Send email to given recipients using settings in the Mailer.
-	Send(to []mail.Address, subject string, he hermes.Email) error
+	Send(to []mail.Address, subject string, he hermes.Email, atts ...Attachment) error
+}
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
 }
 
 type mailer struct {
@@ -66,7 +74,7 @@ func (m mailer) URL() string {
 }
 
 // Send email to given recipients with body using settings in the Mailer.
-func (m mailer) Send(to []mail.Address, subject string, he hermes.Email) error {
+func (m mailer) Send(to []mail.Address, subject string, he hermes.Email, atts ...Attachment) error {
 	var recipients = make([]string, len(to))
 
 	m.gen.Product.Copyright = fmt.Sprintf(
@@ -93,6 +101,12 @@ func (m mailer) Send(to []mail.Address, subject string, he hermes.Email) error {
 		HTML:    []byte(html),
 	}
 
+	for _, a := range atts {
+		if _, err := email.Attach(bytes.NewReader(a.Content), a.Filename, a.ContentType); err != nil {
+			return err
+		}
+	}
+
 	return m.send(email, m.host, m.auth)
 }
 
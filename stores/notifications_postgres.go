@@ -143,6 +143,17 @@ func (n notifier) Broadcast(ctx context.Context, ua models.UserAction, u models.
 			recipients[role.UserID] = struct{}{}
 		}
 		c = prj.Data.(*models.Project).Country
+	case *models.Task:
+		ps := NewProjectStore(n.db, nil)
+		prj, err := ps.Get(ctx, e.Project)
+		if err != nil {
+			sentry.Report(err, "could not get project of task")
+			return
+		}
+		for _, role := range prj.Data.(*models.Project).ProjectRoles {
+			recipients[role.UserID] = struct{}{}
+		}
+		c = prj.Data.(*models.Project).Country
 	case *models.User:
 		// Do not notify anyone
 		c = e.Country
@@ -267,6 +278,8 @@ func (n notifier) GetDocument(ctx context.Context, id uuid.UUID, kind models.Ent
 		s = NewProjectStore(n.db, n.validate)
 	case models.MeetingT:
 		s = NewMeetingsStore(n.db, n.validate)
+	case models.TaskT:
+		s = NewTaskStore(n.db, n.validate)
 	case models.OrganizationT:
 		s = NewOrganizationStore(n.db, n.validate)
 	default:
@@ -356,6 +356,38 @@ func NewCountryStore(db *gorm.DB, v *validator.Validate) Store {
 	}
 }
 
+func NewWeatherStationStore(db *gorm.DB, v *validator.Validate) Store {
+	return store{
+		db:       db,
+		validate: v,
+		index:    "name",
+		new:      func() models.Entity { return new(models.WeatherStation) },
+		search: func(db *gorm.DB, f Filter) *gorm.DB {
+			if f.Country != "" {
+				db = db.Where("weather_stations.country = ?", f.Country)
+			}
+			return db
+		},
+		member: func(db *gorm.DB, ids ...uuid.UUID) *gorm.DB { return db },
+	}
+}
+
+func NewTaskStore(db *gorm.DB, v *validator.Validate) Store {
+	return store{
+		db:       db,
+		validate: v,
+		index:    "id",
+		new:      func() models.Entity { return new(models.Task) },
+		search:   func(q *gorm.DB, f Filter) *gorm.DB { return q },
+		member: func(q *gorm.DB, ids ...uuid.UUID) *gorm.DB {
+			return q.Joins(`left join projects
+				on projects.id = tasks.project_id`).
+				Where("projects.id IN (?)", ids).
+				Where("projects.deleted_at IS NULL")
+		},
+	}
+}
+
 func (s store) DB() *gorm.DB { return s.db }
 
 func (s store) FromKind(kind string) Store {
@@ -384,6 +416,10 @@ func (s store) FromKind(kind string) Store {
 		constructor = NewMonitoringPhaseStore
 	case "forfaiting_payment":
 		constructor = NewForfaitingPaymentStore
+	case "weather_station":
+		constructor = NewWeatherStationStore
+	case "task":
+		constructor = NewTaskStore
 	default:
 		panic(fmt.Sprintf("No store for %s", kind))
 	}
@@ -414,6 +450,10 @@ func (s store) Create(ctx context.Context, e models.Entity) (*models.Document, e
 }
 
 func (s store) Delete(_ context.Context, d *models.Document) error {
+	// records kept in the recycle bin are deleted along with their rows
+	if _, ok := binKinds[d.Data.Kind()]; ok {
+		return Trash(s.db, d.Data.Kind(), d.ID)
+	}
 	return s.db.Delete(d.Data).Error
 }
 
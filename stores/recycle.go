@@ -0,0 +1,146 @@
+package stores
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// BinKind describes where the records of a kind kept in the recycle bin are
+// stored. The record's table is aliased as t in the SQL expressions.
+type BinKind struct {
+	Table   string
+	Name    string
+	Country string
+
+	// Children are the rows deleted and restored along with the record,
+	// besides its attachments.
+	Children []BinChild
+
+	// Roles are the role rows of the record. Roles are not soft deleted,
+	// so they are moved to trashed_roles and back instead.
+	Roles []BinChild
+
+	// Guard prevents purging a record still referred to by rows other
+	// than its children, which would otherwise be deleted in cascade or
+	// left dangling.
+	Guard string
+}
+
+// BinChild is a table whose rows refer to their record in Column.
+type BinChild struct {
+	Table  string
+	Column string
+}
+
+var binKinds = map[string]BinKind{
+	"asset": {
+		Table:   "assets",
+		Name:    "t.address",
+		Country: "t.country",
+		Guard:   "EXISTS (SELECT 1 FROM projects p WHERE p.asset = t.id)",
+	},
+	"organization": {
+		Table:   "organizations",
+		Name:    "t.name",
+		Country: "t.country",
+		Roles:   []BinChild{{"organization_roles", "organization_id"}},
+		Guard: `EXISTS (SELECT 1 FROM assets a WHERE a.owner_id = t.id OR a.esco_id = t.id)
+			OR EXISTS (SELECT 1 FROM projects p WHERE p.owner = t.id)`,
+	},
+	"project": {
+		Table:   "projects",
+		Name:    "t.name",
+		Country: "t.country",
+		Children: []BinChild{
+			{"project_comments", "project_id"},
+			{"contracts", "project_id"},
+		},
+		Roles: []BinChild{{"project_roles", "project_id"}},
+		Guard: `EXISTS (SELECT 1 FROM contracts c WHERE c.project_id = t.id AND c.deleted_at IS DISTINCT FROM t.deleted_at)
+			OR EXISTS (SELECT 1 FROM forfaiting_applications f WHERE f.project_id = t.id)
+			OR EXISTS (SELECT 1 FROM tasks k WHERE k.project_id = t.id)`,
+	},
+	"meeting": {
+		Table:   "meetings",
+		Name:    "t.name",
+		Country: "(SELECT o.country FROM organizations o WHERE o.id = t.host)",
+		Children: []BinChild{
+			{"meeting_guests", "meeting_id"},
+			{"meeting_agenda_items", "meeting_id"},
+			{"meeting_decisions", "meeting_id"},
+			{"meeting_action_items", "meeting_id"},
+		},
+	},
+	"task": {
+		Table:    "tasks",
+		Name:     "t.title",
+		Country:  "(SELECT p.country FROM projects p WHERE p.id = t.project_id)",
+		Children: []BinChild{{"task_comments", "task_id"}},
+	},
+	"user": {
+		Table:   "users",
+		Name:    "t.name",
+		Country: "t.country",
+		Roles: []BinChild{
+			{"organization_roles", "user_id"},
+			{"project_roles", "user_id"},
+			{"country_roles", "user_id"},
+		},
+	},
+}
+
+// LookupBinKind returns how records of kind are kept in the recycle bin. The
+// second result is false if they are not kept there.
+func LookupBinKind(kind string) (BinKind, bool) {
+	k, ok := binKinds[kind]
+	return k, ok
+}
+
+// Trash soft deletes the record of kind along with its attachments and
+// children and moves its roles to trashed_roles, so that they can be
+// restored together.
+func Trash(db *gorm.DB, kind string, id uuid.UUID) error {
+	k, ok := binKinds[kind]
+	if !ok {
+		return fmt.Errorf("no recycle bin for %q", kind)
+	}
+
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, c := range append(k.Children, BinChild{"attachments", "owner_id"}, BinChild{k.Table, "id"}) {
+		err := tx.Exec(fmt.Sprintf("UPDATE %s SET deleted_at = ? WHERE %s = ? AND deleted_at IS NULL", c.Table, c.Column),
+			now, id).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for _, r := range k.Roles {
+		err := tx.Exec(fmt.Sprintf(`INSERT INTO trashed_roles (owner_id, deleted_at, role_table, role)
+			SELECT ?, ?, ?, to_jsonb(r) FROM %s r WHERE r.%s = ?`, r.Table, r.Column),
+			id, now, r.Table, id).Error
+		if err == nil {
+			err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", r.Table, r.Column), id).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
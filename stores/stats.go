@@ -12,8 +12,38 @@ type Stats struct {
 	Organizations int64 `json:"organizations"`
 	Projects      int64 `json:"projects"`
 	Users         int64 `json:"users"`
+
+	// Savings are yearly totals over all projects, in tonnes of CO2
+	// and MWh of primary energy. Expected ones come from the
+	// guaranteed savings, measured ones from the monitoring data.
+	CO2Savings                   float64 `json:"co2_savings"`
+	PrimaryEnergySavings         float64 `json:"primary_energy_savings"`
+	MeasuredCO2Savings           float64 `json:"measured_co2_savings"`
+	MeasuredPrimaryEnergySavings float64 `json:"measured_primary_energy_savings"`
 }
 
+// savingsQuery sums the savings of the projects per country, using the
+// energy factor currently in effect for the project's heating type.
+const savingsQuery = `
+SELECT projects.country,
+	COALESCE(SUM(NULLIF(contracts.fields->>'calculations_qietg', '')::float * f.co2), 0),
+	COALESCE(SUM(NULLIF(contracts.fields->>'calculations_qietg', '')::float * f.primary_energy), 0),
+	COALESCE(SUM(NULLIF(contracts.fields->>'calculations_qsaved', '')::float * f.co2), 0),
+	COALESCE(SUM(NULLIF(contracts.fields->>'calculations_qsaved', '')::float * f.primary_energy), 0)
+FROM projects
+INNER JOIN contracts ON contracts.project_id = projects.id
+INNER JOIN LATERAL (
+	SELECT co2, primary_energy FROM energy_factors
+	WHERE energy_factors.country = projects.country
+		AND energy_factors.heating_type = projects.asset_heating_type
+		AND energy_factors.valid_from <= now()
+		AND energy_factors.deleted_at IS NULL
+	ORDER BY energy_factors.valid_from DESC
+	LIMIT 1
+) f ON true
+WHERE projects.deleted_at IS NULL AND (? = '' OR projects.country::text = ?)
+GROUP BY projects.country`
+
 type entityStats struct {
 	kind    string
 	country models.Country
@@ -85,5 +115,37 @@ func CountryStats(ctx context.Context, s Store, c models.Country) (map[models.Co
 		result[v.country] = cs
 	}
 
+	if err := savingsStats(s, c, result); err != nil {
+		errs.Store("savings", err)
+	}
+
 	return result, newErrorMap(errs)
 }
+
+func savingsStats(s Store, c models.Country, result map[models.Country]Stats) error {
+	rows, err := s.DB().Raw(savingsQuery, c, c).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			country models.Country
+			cs      Stats
+		)
+		if err := rows.Scan(&country,
+			&cs.CO2Savings, &cs.PrimaryEnergySavings,
+			&cs.MeasuredCO2Savings, &cs.MeasuredPrimaryEnergySavings); err != nil {
+			return err
+		}
+
+		stats := result[country]
+		stats.CO2Savings = cs.CO2Savings
+		stats.PrimaryEnergySavings = cs.PrimaryEnergySavings
+		stats.MeasuredCO2Savings = cs.MeasuredCO2Savings
+		stats.MeasuredPrimaryEnergySavings = cs.MeasuredPrimaryEnergySavings
+		result[country] = stats
+	}
+	return rows.Err()
+}
@@ -629,6 +629,13 @@ func TAWithStatus(vtype models.ValidationStatus) TOpts {
 	}
 }
 
+// TAWithCoords modifies the coordinates of an asset.
+func TAWithCoords(c models.Coords) TOpts {
+	return func(_ *testing.T, _ Store, d *models.Document) {
+		d.Data.(*models.Asset).Coordinates = c
+	}
+}
+
 func TAWithCountry(c models.Country) TOpts {
 	return func(_ *testing.T, _ Store, d *models.Document) {
 		d.Data.(*models.Asset).Country = c
@@ -721,21 +728,29 @@ func NewTestFA(t *testing.T, st Store, opts ...TOpts) *models.ForfaitingApplicat
 			Approved: true,
 			Type:     models.FAReviewTypeExecutive,
 			Comment:  "Lorem",
+			Stage:    2,
+			Status:   models.FAReviewApproved,
 		},
 		models.FAReview{
 			Approved: false,
 			Type:     models.FAReviewTypeFinancial,
 			Comment:  "Lorem",
+			Stage:    1,
+			Status:   models.FAReviewOpen,
 		},
 		models.FAReview{
 			Approved: false,
 			Type:     models.FAReviewTypeGuidelines,
 			Comment:  "Lorem",
+			Stage:    1,
+			Status:   models.FAReviewOpen,
 		},
 		models.FAReview{
 			Approved: false,
 			Type:     models.FAReviewTypeTechnical,
 			Comment:  "Lorem",
+			Stage:    1,
+			Status:   models.FAReviewOpen,
 		},
 	}
 
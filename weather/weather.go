@@ -0,0 +1,199 @@
+// Package weather parses daily outdoor temperatures and computes heating
+// degree days out of them.
+package weather
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseTemperature is the base temperature in °C used for heating degree
+// days when none is given.
+const DefaultBaseTemperature = 18.0
+
+const dateLayout = "2006-01-02"
+
+var dateLayouts = []string{dateLayout, "02.01.2006", "02/01/2006", "2006.01.02"}
+
+var (
+	ErrNoHeader = errors.New("weather: missing date or temperature column")
+	ErrNoData   = errors.New("weather: no temperatures")
+)
+
+// Day holds the average outdoor temperature of a single day.
+type Day struct {
+	Date        time.Time
+	Temperature float64
+}
+
+// ParseCSV reads daily temperatures out of r. The first record must be a header
+// with `date` and `temperature` columns; other columns are ignored. Both comma
+// and semicolon separated files are accepted, the latter with decimal comma as
+// usually exported by national met services.
+func ParseCSV(r io.Reader) ([]Day, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(strings.NewReader(string(raw)))
+	if h := firstLine(raw); strings.Count(h, ";") > strings.Count(h, ",") {
+		cr.Comma = ';'
+	}
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			err = ErrNoHeader
+		}
+		return nil, err
+	}
+
+	dcol, tcol := -1, -1
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "date":
+			dcol = i
+		case "temperature", "temp", "t":
+			tcol = i
+		}
+	}
+	if dcol < 0 || tcol < 0 {
+		return nil, ErrNoHeader
+	}
+
+	var result []Day
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) <= dcol || len(rec) <= tcol {
+			return nil, fmt.Errorf("weather: line %d: missing columns", line)
+		}
+		if strings.TrimSpace(rec[tcol]) == "" {
+			// days without measurement are skipped
+			continue
+		}
+
+		d, err := parseDate(rec[dcol])
+		if err != nil {
+			return nil, fmt.Errorf("weather: line %d: %w", line, err)
+		}
+		t, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(rec[tcol]), ",", ".", 1), 64)
+		if err != nil {
+			return nil, fmt.Errorf("weather: line %d: %w", line, err)
+		}
+		result = append(result, Day{Date: d, Temperature: t})
+	}
+
+	if len(result) == 0 {
+		return nil, ErrNoData
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result, nil
+}
+
+func firstLine(b []byte) string {
+	s := string(b)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, l := range dateLayouts {
+		if d, err := time.Parse(l, s); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// Summary holds degree days of a period.
+type Summary struct {
+	// Days is the count of days with a temperature in the period.
+	Days int `json:"days"`
+
+	// HeatingDays is the count of days colder than the base temperature.
+	HeatingDays int `json:"heating_days"`
+
+	// DegreeDays is the sum of base temperature minus the temperature of
+	// all heating days.
+	DegreeDays float64 `json:"degree_days"`
+
+	// AverageTemperature is the average temperature of all days.
+	AverageTemperature float64 `json:"average_temperature"`
+
+	// HeatingAverageTemperature is the average temperature of heating days.
+	HeatingAverageTemperature float64 `json:"heating_average_temperature"`
+}
+
+// Summarize computes the heating degree days of days for given base
+// temperature.
+func Summarize(days []Day, base float64) Summary {
+	var (
+		s            Summary
+		total, htotl float64
+	)
+
+	for _, d := range days {
+		s.Days++
+		total += d.Temperature
+		if d.Temperature < base {
+			s.HeatingDays++
+			s.DegreeDays += base - d.Temperature
+			htotl += d.Temperature
+		}
+	}
+
+	if s.Days > 0 {
+		s.AverageTemperature = round(total / float64(s.Days))
+	}
+	if s.HeatingDays > 0 {
+		s.HeatingAverageTemperature = round(htotl / float64(s.HeatingDays))
+	}
+	s.DegreeDays = round(s.DegreeDays)
+	return s
+}
+
+// Month returns days which are in given month and year.
+func Month(days []Day, year int, m time.Month) []Day {
+	var result []Day
+	for _, d := range days {
+		if d.Date.Year() == year && d.Date.Month() == m {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// Year returns days which are in given year.
+func Year(days []Day, year int) []Day {
+	var result []Day
+	for _, d := range days {
+		if d.Date.Year() == year {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,100 @@
+package weather
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSV(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		days  int
+		first float64
+		err   error
+	}{
+		{
+			name:  "comma",
+			input: "date,temperature\n2020-01-02,-1.5\n2020-01-01,2\n",
+			days:  2,
+			first: 2,
+		},
+		{
+			name:  "semicolon with decimal comma",
+			input: "Station;Date;T\nRiga;01.01.2020;-3,4\nRiga;02.01.2020;\nRiga;03.01.2020;1,1\n",
+			days:  2,
+			first: -3.4,
+		},
+		{
+			name:  "no header",
+			input: "foo,bar\n2020-01-01,2\n",
+			err:   ErrNoHeader,
+		},
+		{
+			name:  "empty",
+			input: "",
+			err:   ErrNoHeader,
+		},
+		{
+			name:  "no data",
+			input: "date,temperature\n",
+			err:   ErrNoData,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			days, err := ParseCSV(strings.NewReader(c.input))
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected error %v; got %v", c.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(days) != c.days {
+				t.Fatalf("expected %d days; got %d", c.days, len(days))
+			}
+			if days[0].Temperature != c.first {
+				t.Errorf("expected first temperature %v; got %v", c.first, days[0].Temperature)
+			}
+		})
+	}
+
+	t.Run("invalid date", func(t *testing.T) {
+		if _, err := ParseCSV(strings.NewReader("date,temp\nyesterday,2\n")); err == nil {
+			t.Error("expected error on invalid date")
+		}
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	day := func(d int, temp float64) Day {
+		return Day{Date: time.Date(2020, time.January, d, 0, 0, 0, 0, time.UTC), Temperature: temp}
+	}
+	days := []Day{day(1, -2), day(2, 8), day(3, 20)}
+
+	s := Summarize(days, DefaultBaseTemperature)
+	want := Summary{
+		Days:                      3,
+		HeatingDays:               2,
+		DegreeDays:                30,
+		AverageTemperature:        8.67,
+		HeatingAverageTemperature: 3,
+	}
+	if s != want {
+		t.Errorf("expected %+v; got %+v", want, s)
+	}
+
+	if s := Summarize(nil, DefaultBaseTemperature); s != (Summary{}) {
+		t.Errorf("expected empty summary; got %+v", s)
+	}
+
+	if n := len(Month(days, 2020, time.January)); n != 3 {
+		t.Errorf("expected 3 days in January; got %d", n)
+	}
+	if n := len(Year(days, 2019)); n != 0 {
+		t.Errorf("expected no days in 2019; got %d", n)
+	}
+}
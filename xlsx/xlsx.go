@@ -0,0 +1,191 @@
+// Package xlsx writes workbooks of a single sheet in the Office Open XML
+// format, e.g. reports to be opened in spreadsheet applications.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the MIME type of the workbooks.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// maxSheetName is the longest sheet name spreadsheet applications accept.
+const maxSheetName = 31
+
+// Sheet is a table with a header row printed in bold. The cells may be
+// strings, integers, floats, booleans, times or nil for empty ones; anything
+// else is written as formatted by fmt.
+type Sheet struct {
+	Name   string
+	Header []string
+	Rows   [][]interface{}
+}
+
+// Write writes the workbook of the sheet to w.
+func Write(w io.Writer, s Sheet) error {
+	z := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypes)},
+		{"_rels/.rels", []byte(rels)},
+		{"xl/workbook.xml", []byte(fmt.Sprintf(workbook, escape(sheetName(s.Name))))},
+		{"xl/_rels/workbook.xml.rels", []byte(workbookRels)},
+		{"xl/styles.xml", []byte(styles)},
+		{"xl/worksheets/sheet1.xml", worksheet(s)},
+	}
+	for _, p := range parts {
+		f, err := z.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(p.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func worksheet(s Sheet) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	row := 0
+	if len(s.Header) > 0 {
+		row++
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, h := range s.Header {
+			fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr" s="1"><is><t xml:space="preserve">%s</t></is></c>`,
+				Column(i), row, escape(h))
+		}
+		b.WriteString(`</row>`)
+	}
+	for _, cells := range s.Rows {
+		row++
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, v := range cells {
+			cell(&b, fmt.Sprintf("%s%d", Column(i), row), v)
+		}
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.Bytes()
+}
+
+func cell(b *bytes.Buffer, ref string, v interface{}) {
+	var number string
+	switch v := v.(type) {
+	case nil:
+		return
+	case int:
+		number = strconv.Itoa(v)
+	case int64:
+		number = strconv.FormatInt(v, 10)
+	case float64:
+		number = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		value := "0"
+		if v {
+			value = "1"
+		}
+		fmt.Fprintf(b, `<c r="%s" t="b"><v>%s</v></c>`, ref, value)
+		return
+	case time.Time:
+		v = v.UTC()
+		if v.IsZero() {
+			return
+		}
+		layout := "2006-01-02 15:04"
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			layout = "2006-01-02"
+		}
+		inlineString(b, ref, v.Format(layout))
+		return
+	case string:
+		inlineString(b, ref, v)
+		return
+	default:
+		inlineString(b, ref, fmt.Sprint(v))
+		return
+	}
+	fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, number)
+}
+
+func inlineString(b *bytes.Buffer, ref, s string) {
+	fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(s))
+}
+
+// Column returns the name of the column with the zero based index, e.g. A
+// for 0 and AA for 26.
+func Column(i int) string {
+	var name []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = append([]byte{byte('A' + (i-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// sheetName returns the name without the characters spreadsheet
+// applications reject, cut to their maximum length.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return ' '
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > maxSheetName {
+		name = string(r[:maxSheetName])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
+
+func escape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const contentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const workbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// styles holds the default cell format and the bold one of the header.
+const styles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
@@ -0,0 +1,80 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, Sheet{
+		Name:   "Projects: 2020/10",
+		Header: []string{"Name", "Flats", "Savings", "Valid", "Since", "Note"},
+		Rows: [][]interface{}{
+			{"Brīvības <10> & co", 42, 12.5, true, time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), nil},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string]string)
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := xml.Unmarshal(b, new(interface{})); err != nil {
+			t.Errorf("%s is no well-formed XML: %v", f.Name, err)
+		}
+		parts[f.Name] = string(b)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("Expected part %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Projects  2020 10"`) {
+		t.Errorf("Expected the sheet name cleaned, got %s", parts["xl/workbook.xml"])
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr" s="1"><is><t xml:space="preserve">Name</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">Brīvības &lt;10&gt; &amp; co</t></is></c>`,
+		`<c r="B2"><v>42</v></c>`,
+		`<c r="C2"><v>12.5</v></c>`,
+		`<c r="D2" t="b"><v>1</v></c>`,
+		`<c r="E2" t="inlineStr"><is><t xml:space="preserve">2020-10-01</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("Expected %s in the sheet", want)
+		}
+	}
+	if strings.Contains(sheet, `r="F2"`) {
+		t.Errorf("Expected no cell for nil")
+	}
+}
+
+func TestColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := Column(i); got != want {
+			t.Errorf("Column(%d): expected %s, got %s", i, want, got)
+		}
+	}
+}